@@ -0,0 +1,237 @@
+// Package grid 提供一个由单个扁平切片支撑的泛型二维网格（第16章多维数组的延伸）。
+//
+// [8][8]string 这样的多维数组大小固定、按值传递；[][]T 每一行是独立分配的切片。
+// Grid[T] 把所有元素按行优先放在一个 []T 里，通过 stride（行跨度）计算下标：
+// 行视图、列视图和子网格都只是同一块内存上的不同“窗口”，不会复制数据。
+package grid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grid 是 rows×cols 的网格。元素 (r, c) 存放在 data[off + r*stride + c]。
+// 子网格与父网格共享 data，修改会互相可见。
+type Grid[T any] struct {
+	data        []T
+	rows, cols  int
+	stride, off int
+}
+
+// New 创建一个元素全部为零值的 rows×cols 网格。
+func New[T any](rows, cols int) *Grid[T] {
+	if rows < 0 || cols < 0 {
+		panic("grid: negative size")
+	}
+	return &Grid[T]{data: make([]T, rows*cols), rows: rows, cols: cols, stride: cols}
+}
+
+// FromRows 用 [][]T 的内容创建网格（复制数据）；各行长度必须一致。
+func FromRows[T any](rows [][]T) (*Grid[T], error) {
+	if len(rows) == 0 {
+		return New[T](0, 0), nil
+	}
+	g := New[T](len(rows), len(rows[0]))
+	for r, row := range rows {
+		if len(row) != g.cols {
+			return nil, fmt.Errorf("grid: row %d has %d columns, want %d", r, len(row), g.cols)
+		}
+		copy(g.Row(r), row)
+	}
+	return g, nil
+}
+
+// Rows 返回行数。
+func (g *Grid[T]) Rows() int { return g.rows }
+
+// Cols 返回列数。
+func (g *Grid[T]) Cols() int { return g.cols }
+
+// In 报告 (r, c) 是否在网格内。
+func (g *Grid[T]) In(r, c int) bool {
+	return r >= 0 && r < g.rows && c >= 0 && c < g.cols
+}
+
+// index 把二维坐标换算成 data 下标，越界时 panic（和数组越界行为一致）。
+func (g *Grid[T]) index(r, c int) int {
+	if !g.In(r, c) {
+		panic(fmt.Sprintf("grid: index (%d, %d) out of range [%d×%d]", r, c, g.rows, g.cols))
+	}
+	return g.off + r*g.stride + c
+}
+
+// At 返回 (r, c) 处的元素。
+func (g *Grid[T]) At(r, c int) T { return g.data[g.index(r, c)] }
+
+// Set 设置 (r, c) 处的元素。
+func (g *Grid[T]) Set(r, c int, v T) { g.data[g.index(r, c)] = v }
+
+// Ptr 返回 (r, c) 处元素的指针，便于原地修改结构体元素。
+func (g *Grid[T]) Ptr(r, c int) *T { return &g.data[g.index(r, c)] }
+
+// Row 返回第 r 行的切片视图（不复制）。
+// 使用三索引切片把容量限制为 cols，对视图 append 不会覆盖下一行。
+func (g *Grid[T]) Row(r int) []T {
+	if r < 0 || r >= g.rows {
+		panic(fmt.Sprintf("grid: row %d out of range [0, %d)", r, g.rows))
+	}
+	start := g.off + r*g.stride
+	return g.data[start : start+g.cols : start+g.cols]
+}
+
+// Col 返回第 c 列的视图（不复制）。列在内存中不连续，所以用 Vector 按步长访问。
+func (g *Grid[T]) Col(c int) Vector[T] {
+	if c < 0 || c >= g.cols {
+		panic(fmt.Sprintf("grid: column %d out of range [0, %d)", c, g.cols))
+	}
+	return Vector[T]{data: g.data, off: g.off + c, step: g.stride, n: g.rows}
+}
+
+// Sub 返回从 (r, c) 开始、大小为 rows×cols 的子网格视图，与 g 共享内存。
+func (g *Grid[T]) Sub(r, c, rows, cols int) *Grid[T] {
+	if r < 0 || c < 0 || rows < 0 || cols < 0 || r+rows > g.rows || c+cols > g.cols {
+		panic(fmt.Sprintf("grid: sub-grid (%d, %d)+%d×%d out of range [%d×%d]", r, c, rows, cols, g.rows, g.cols))
+	}
+	return &Grid[T]{data: g.data, rows: rows, cols: cols, stride: g.stride, off: g.off + r*g.stride + c}
+}
+
+// Clone 返回一个独立、紧凑的副本。
+func (g *Grid[T]) Clone() *Grid[T] {
+	c := New[T](g.rows, g.cols)
+	for r := 0; r < g.rows; r++ {
+		copy(c.Row(r), g.Row(r))
+	}
+	return c
+}
+
+// Fill 把所有元素设为 v。
+func (g *Grid[T]) Fill(v T) {
+	for r := 0; r < g.rows; r++ {
+		row := g.Row(r)
+		for c := range row {
+			row[c] = v
+		}
+	}
+}
+
+// Each 按行优先顺序对每个元素调用 fn。
+func (g *Grid[T]) Each(fn func(r, c int, v T)) {
+	for r := 0; r < g.rows; r++ {
+		for c, v := range g.Row(r) {
+			fn(r, c, v)
+		}
+	}
+}
+
+// ToRows 把网格复制成 [][]T。
+func (g *Grid[T]) ToRows() [][]T {
+	rows := make([][]T, g.rows)
+	for r := range rows {
+		rows[r] = append([]T(nil), g.Row(r)...)
+	}
+	return rows
+}
+
+// Transpose 返回转置后的新网格（cols×rows）。
+func (g *Grid[T]) Transpose() *Grid[T] {
+	t := New[T](g.cols, g.rows)
+	for r := 0; r < g.rows; r++ {
+		for c, v := range g.Row(r) {
+			t.data[c*t.stride+r] = v
+		}
+	}
+	return t
+}
+
+// RotateCW 返回顺时针旋转 90° 后的新网格。
+func (g *Grid[T]) RotateCW() *Grid[T] {
+	t := New[T](g.cols, g.rows)
+	for r := 0; r < g.rows; r++ {
+		for c, v := range g.Row(r) {
+			t.Set(c, g.rows-1-r, v)
+		}
+	}
+	return t
+}
+
+// RotateCCW 返回逆时针旋转 90° 后的新网格。
+func (g *Grid[T]) RotateCCW() *Grid[T] {
+	t := New[T](g.cols, g.rows)
+	for r := 0; r < g.rows; r++ {
+		for c, v := range g.Row(r) {
+			t.Set(g.cols-1-c, r, v)
+		}
+	}
+	return t
+}
+
+// Rotate180 返回旋转 180° 后的新网格。
+func (g *Grid[T]) Rotate180() *Grid[T] {
+	t := New[T](g.rows, g.cols)
+	for r := 0; r < g.rows; r++ {
+		for c, v := range g.Row(r) {
+			t.Set(g.rows-1-r, g.cols-1-c, v)
+		}
+	}
+	return t
+}
+
+// String 按列对齐输出网格，每个元素用 fmt.Sprint 格式化。
+func (g *Grid[T]) String() string {
+	return g.Format(func(v T) string { return fmt.Sprint(v) })
+}
+
+// Format 用 format 把每个元素转成字符串，再按列右对齐输出。
+func (g *Grid[T]) Format(format func(T) string) string {
+	cells := make([]string, 0, g.rows*g.cols)
+	width := make([]int, g.cols)
+	g.Each(func(_, c int, v T) {
+		s := format(v)
+		cells = append(cells, s)
+		width[c] = max(width[c], len([]rune(s)))
+	})
+	var b strings.Builder
+	for r := 0; r < g.rows; r++ {
+		for c := 0; c < g.cols; c++ {
+			if c > 0 {
+				b.WriteByte(' ')
+			}
+			s := cells[r*g.cols+c]
+			b.WriteString(strings.Repeat(" ", width[c]-len([]rune(s))))
+			b.WriteString(s)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Vector 是网格上按固定步长排列的一组元素，例如一列。它是视图，不持有副本。
+type Vector[T any] struct {
+	data         []T
+	off, step, n int
+}
+
+// Len 返回元素个数。
+func (v Vector[T]) Len() int { return v.n }
+
+// At 返回第 i 个元素。
+func (v Vector[T]) At(i int) T { return v.data[v.index(i)] }
+
+// Set 设置第 i 个元素，修改会反映到原网格。
+func (v Vector[T]) Set(i int, x T) { v.data[v.index(i)] = x }
+
+func (v Vector[T]) index(i int) int {
+	if i < 0 || i >= v.n {
+		panic(fmt.Sprintf("grid: vector index %d out of range [0, %d)", i, v.n))
+	}
+	return v.off + i*v.step
+}
+
+// Slice 把视图中的元素复制到一个新切片。
+func (v Vector[T]) Slice() []T {
+	s := make([]T, v.n)
+	for i := range s {
+		s[i] = v.data[v.off+i*v.step]
+	}
+	return s
+}
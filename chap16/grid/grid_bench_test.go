@@ -0,0 +1,141 @@
+package grid
+
+import (
+	"fmt"
+	"testing"
+)
+
+// 对比扁平切片 Grid 与 [][]T 两种二维布局在分配、按行遍历、按列遍历和矩阵乘法上的开销。
+// 扁平布局只分配一次且内存连续；[][]T 每行单独分配，行与行之间不一定相邻。
+//
+//	go test -bench . -benchmem ./chap16/grid
+
+var sink int
+
+var benchSizes = []int{64, 512}
+
+func nestedInts(size int) [][]int {
+	g := make([][]int, size)
+	for r := range g {
+		g[r] = make([]int, size)
+		for c := range g[r] {
+			g[r][c] = r ^ c
+		}
+	}
+	return g
+}
+
+func flatInts(size int) *Grid[int] {
+	g := New[int](size, size)
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			g.Set(r, c, r^c)
+		}
+	}
+	return g
+}
+
+func BenchmarkAlloc(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("flat/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sink += New[int](size, size).Rows()
+			}
+		})
+		b.Run(fmt.Sprintf("nested/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g := make([][]int, size)
+				for r := range g {
+					g[r] = make([]int, size)
+				}
+				sink += len(g)
+			}
+		})
+	}
+}
+
+func BenchmarkRows(b *testing.B) {
+	for _, size := range benchSizes {
+		flat, nested := flatInts(size), nestedInts(size)
+		b.Run(fmt.Sprintf("flat/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := 0
+				for r := 0; r < size; r++ {
+					for _, v := range flat.Row(r) {
+						s += v
+					}
+				}
+				sink += s
+			}
+		})
+		b.Run(fmt.Sprintf("nested/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := 0
+				for _, row := range nested {
+					for _, v := range row {
+						s += v
+					}
+				}
+				sink += s
+			}
+		})
+	}
+}
+
+func BenchmarkCols(b *testing.B) {
+	for _, size := range benchSizes {
+		flat, nested := flatInts(size), nestedInts(size)
+		b.Run(fmt.Sprintf("flat/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := 0
+				for c := 0; c < size; c++ {
+					col := flat.Col(c)
+					for r := 0; r < col.Len(); r++ {
+						s += col.At(r)
+					}
+				}
+				sink += s
+			}
+		})
+		b.Run(fmt.Sprintf("nested/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := 0
+				for c := 0; c < size; c++ {
+					for r := 0; r < size; r++ {
+						s += nested[r][c]
+					}
+				}
+				sink += s
+			}
+		})
+	}
+}
+
+// BenchmarkMul 用较小的尺寸，避免 O(n³) 跑太久。
+func BenchmarkMul(b *testing.B) {
+	const m = 128
+	a := Identity(m)
+	nested := make([][]float64, m)
+	for r := range nested {
+		nested[r] = make([]float64, m)
+		nested[r][r] = 1
+	}
+	b.Run("flat", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = a.Mul(a)
+		}
+	})
+	b.Run("nested", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			out := make([][]float64, m)
+			for r := range out {
+				out[r] = make([]float64, m)
+				for k, x := range nested[r] {
+					for c, y := range nested[k] {
+						out[r][c] += x * y
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package grid
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// numbered 返回元素依次为 0, 1, 2… 的 rows×cols 网格。
+func numbered(rows, cols int) *Grid[int] {
+	g := New[int](rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			g.Set(r, c, r*cols+c)
+		}
+	}
+	return g
+}
+
+// mustPanic 在 fn 没有 panic 时让测试失败。
+func mustPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestFromRows(t *testing.T) {
+	rows := [][]int{{1, 2, 3}, {4, 5, 6}}
+	g, err := FromRows(rows)
+	if err != nil || g.Rows() != 2 || g.Cols() != 3 || !reflect.DeepEqual(g.ToRows(), rows) {
+		t.Fatalf("FromRows = %v, %v", g, err)
+	}
+	rows[0][0] = 99
+	if g.At(0, 0) != 1 {
+		t.Error("FromRows did not copy the data")
+	}
+	if _, err := FromRows([][]int{{1, 2}, {3}}); err == nil {
+		t.Error("ragged rows accepted")
+	}
+	if g, err := FromRows[int](nil); err != nil || g.Rows() != 0 || g.Cols() != 0 {
+		t.Errorf("FromRows(nil) = %v, %v", g, err)
+	}
+	mustPanic(t, "New(-1, 2)", func() { New[int](-1, 2) })
+}
+
+func TestViews(t *testing.T) {
+	g := numbered(3, 4)
+
+	row := g.Row(1)
+	if fmt.Sprint(row) != "[4 5 6 7]" || cap(row) != 4 {
+		t.Errorf("Row(1) = %v, cap %d", row, cap(row))
+	}
+	row[0] = -4
+	_ = append(row, 100) // 容量已满，append 会重新分配，不会写到下一行
+	if g.At(1, 0) != -4 || g.At(2, 0) != 8 {
+		t.Errorf("row view: At(1,0) = %d, At(2,0) = %d", g.At(1, 0), g.At(2, 0))
+	}
+
+	col := g.Col(2)
+	if col.Len() != 3 || fmt.Sprint(col.Slice()) != "[2 6 10]" {
+		t.Errorf("Col(2) = %v", col.Slice())
+	}
+	col.Set(2, -10)
+	if g.At(2, 2) != -10 {
+		t.Error("column view does not write through")
+	}
+
+	*g.Ptr(0, 3) = -3
+	if g.At(0, 3) != -3 {
+		t.Error("Ptr does not point into the grid")
+	}
+
+	for name, fn := range map[string]func(){
+		"At(3, 0)":  func() { g.At(3, 0) },
+		"At(0, -1)": func() { g.At(0, -1) },
+		"Row(3)":    func() { g.Row(3) },
+		"Col(4)":    func() { g.Col(4) },
+		"Col.At(3)": func() { col.At(3) },
+	} {
+		mustPanic(t, name, fn)
+	}
+}
+
+func TestSub(t *testing.T) {
+	g := numbered(4, 5)
+	s := g.Sub(1, 2, 2, 3)
+	if want := [][]int{{7, 8, 9}, {12, 13, 14}}; !reflect.DeepEqual(s.ToRows(), want) {
+		t.Fatalf("Sub(1, 2, 2, 3) = %v", s.ToRows())
+	}
+	s.Set(1, 0, -1)
+	if g.At(2, 2) != -1 {
+		t.Error("sub-grid does not share memory with the parent")
+	}
+	if fmt.Sprint(s.Col(1).Slice()) != "[8 13]" || cap(s.Row(0)) != 3 {
+		t.Errorf("views of a sub-grid: col %v, row cap %d", s.Col(1).Slice(), cap(s.Row(0)))
+	}
+
+	// 子网格的子网格仍然指向同一块内存
+	ss := s.Sub(1, 1, 1, 2)
+	ss.Fill(0)
+	if g.At(2, 3) != 0 || g.At(2, 4) != 0 || g.At(2, 1) != 11 {
+		t.Errorf("nested Sub: %v", g.ToRows())
+	}
+	mustPanic(t, "ss.At(0, 2)", func() { ss.At(0, 2) })
+
+	c := s.Clone()
+	c.Set(0, 0, 100)
+	if g.At(1, 2) != 7 || c.stride != c.Cols() || c.off != 0 {
+		t.Error("Clone is not an independent compact copy")
+	}
+
+	if e := g.Sub(4, 5, 0, 0); e.Rows() != 0 || e.Cols() != 0 {
+		t.Error("empty sub-grid at the corner")
+	}
+	for _, args := range [][4]int{{-1, 0, 1, 1}, {3, 0, 2, 1}, {0, 3, 1, 3}, {0, 0, -1, 1}} {
+		mustPanic(t, fmt.Sprintf("Sub%v", args), func() { g.Sub(args[0], args[1], args[2], args[3]) })
+	}
+}
+
+func TestEach(t *testing.T) {
+	g := numbered(2, 3).Sub(0, 1, 2, 2)
+	var got []string
+	g.Each(func(r, c, v int) { got = append(got, fmt.Sprintf("%d,%d=%d", r, c, v)) })
+	if fmt.Sprint(got) != "[0,0=1 0,1=2 1,0=4 1,1=5]" {
+		t.Errorf("Each visited %v", got)
+	}
+}
+
+func TestTransformations(t *testing.T) {
+	g := numbered(2, 3).Sub(0, 0, 2, 3) // 0 1 2 / 3 4 5
+	for _, tc := range []struct {
+		name string
+		got  *Grid[int]
+		want [][]int
+	}{
+		{"Transpose", g.Transpose(), [][]int{{0, 3}, {1, 4}, {2, 5}}},
+		{"RotateCW", g.RotateCW(), [][]int{{3, 0}, {4, 1}, {5, 2}}},
+		{"RotateCCW", g.RotateCCW(), [][]int{{2, 5}, {1, 4}, {0, 3}}},
+		{"Rotate180", g.Rotate180(), [][]int{{5, 4, 3}, {2, 1, 0}}},
+	} {
+		if !reflect.DeepEqual(tc.got.ToRows(), tc.want) {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got.ToRows(), tc.want)
+		}
+	}
+
+	// 在非紧凑的子网格上同样成立
+	s := numbered(4, 4).Sub(1, 1, 2, 3)
+	for name, got := range map[string]*Grid[int]{
+		"Transpose twice":    s.Transpose().Transpose(),
+		"RotateCW ∘ CCW":     s.RotateCW().RotateCCW(),
+		"RotateCW × 4":       s.RotateCW().RotateCW().RotateCW().RotateCW(),
+		"Rotate180 twice":    s.Rotate180().Rotate180(),
+		"RotateCW × 2 ∘ 180": s.RotateCW().RotateCW().Rotate180(),
+	} {
+		if !reflect.DeepEqual(got.ToRows(), s.ToRows()) {
+			t.Errorf("%s = %v, want %v", name, got.ToRows(), s.ToRows())
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	g, _ := FromRows([][]int{{1, -20}, {300, 4}})
+	if got, want := g.String(), "  1 -20\n300   4\n"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	h, _ := FromRows([][]string{{"格", "a"}, {"bb", "网格"}})
+	if got, want := h.String(), " 格  a\nbb 网格\n"; got != want {
+		t.Errorf("String with wide runes = %q, want %q", got, want)
+	}
+}
+
+func sorted(points []Point) []Point {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].R != points[j].R {
+			return points[i].R < points[j].R
+		}
+		return points[i].C < points[j].C
+	})
+	return points
+}
+
+func TestNeighbors(t *testing.T) {
+	g := numbered(3, 3)
+	for _, tc := range []struct {
+		r, c int
+		conn Connectivity
+		want []Point
+	}{
+		{1, 1, Four, []Point{{0, 1}, {1, 0}, {1, 2}, {2, 1}}},
+		{1, 1, Eight, []Point{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 1}, {2, 2}}},
+		{0, 0, Four, []Point{{0, 1}, {1, 0}}},
+		{0, 0, Eight, []Point{{0, 1}, {1, 0}, {1, 1}}},
+		{2, 1, Eight, []Point{{1, 0}, {1, 1}, {1, 2}, {2, 0}, {2, 2}}},
+	} {
+		if got := sorted(g.Neighbors(tc.r, tc.c, tc.conn)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Neighbors(%d, %d, %d) = %v, want %v", tc.r, tc.c, tc.conn, got, tc.want)
+		}
+	}
+
+	// 子网格的边界就是视图的边界，不会看到父网格中视图外的格子
+	s := g.Sub(1, 1, 2, 2)
+	sum := 0
+	s.EachNeighbor(0, 0, Eight, func(r, c, v int) {
+		if v != g.At(r+1, c+1) {
+			t.Errorf("EachNeighbor passed %d for (%d, %d)", v, r, c)
+		}
+		sum += v
+	})
+	if sum != 5+7+8 {
+		t.Errorf("neighbors of the sub-grid corner sum to %d", sum)
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	const picture = `
+##....
+#..#..
+...###
+##.#..
+..#...`
+	parse := func() *Grid[byte] {
+		var rows [][]byte
+		for _, line := range strings.Split(strings.TrimSpace(picture), "\n") {
+			rows = append(rows, []byte(line))
+		}
+		g, err := FromRows(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+	render := func(g *Grid[byte]) []string {
+		var out []string
+		for r := 0; r < g.Rows(); r++ {
+			out = append(out, string(g.Row(r)))
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		name string
+		conn Connectivity
+		n    int
+		want []string
+	}{
+		{"four", Four, 12, []string{"##oooo", "#oo#oo", "ooo###", "##o#..", "..#..."}},
+		// 8 连通时 (3,2) 经对角线连到 (4,1) 和 (4,3)，左下角和右下角都被填充
+		{"eight", Eight, 19, []string{"##oooo", "#oo#oo", "ooo###", "##o#oo", "oo#ooo"}},
+	} {
+		g := parse()
+		if n := FloodFill(g, 0, 2, 'o', tc.conn); n != tc.n || !reflect.DeepEqual(render(g), tc.want) {
+			t.Errorf("%s: filled %d cells\n%v\nwant %d\n%v", tc.name, n, render(g), tc.n, tc.want)
+		}
+	}
+
+	g := parse()
+	if n := FloodFill(g, 0, 0, '#', Four); n != 0 {
+		t.Errorf("filling with the same value changed %d cells", n)
+	}
+
+	// 只填充子网格内部
+	g = parse()
+	s := g.Sub(3, 3, 2, 3)
+	if n := FloodFill(s, 0, 1, 'x', Four); n != 5 || g.At(2, 5) != '#' || g.At(4, 2) != '#' {
+		t.Errorf("FloodFill on a sub-grid filled %d cells:\n%v", n, render(g))
+	}
+
+	// 大网格：显式栈不会栈溢出
+	big := New[bool](1000, 1000)
+	if n := FloodFill(big, 500, 500, true, Four); n != 1000*1000 {
+		t.Errorf("FloodFill on 1000×1000 filled %d cells", n)
+	}
+}
@@ -0,0 +1,183 @@
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	// ErrShape 表示矩阵尺寸不满足运算要求。
+	ErrShape = errors.New("grid: matrix shape mismatch")
+	// ErrSingular 表示矩阵不可逆（行列式为 0）。
+	ErrSingular = errors.New("grid: matrix is singular")
+)
+
+// epsilon 是判断主元是否为 0 的阈值。
+const epsilon = 1e-12
+
+// Matrix 是 float64 网格，增加了乘法、行列式和求逆等数值运算。
+type Matrix struct {
+	*Grid[float64]
+}
+
+// NewMatrix 创建 rows×cols 的零矩阵。
+func NewMatrix(rows, cols int) Matrix {
+	return Matrix{New[float64](rows, cols)}
+}
+
+// Identity 创建 n×n 单位矩阵。
+func Identity(n int) Matrix {
+	m := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+// MatrixFrom 用 [][]float64 创建矩阵（复制数据）。
+func MatrixFrom(rows [][]float64) (Matrix, error) {
+	g, err := FromRows(rows)
+	if err != nil {
+		return Matrix{}, err
+	}
+	return Matrix{g}, nil
+}
+
+// Mul 返回 m×b；m 的列数必须等于 b 的行数。
+func (m Matrix) Mul(b Matrix) (Matrix, error) {
+	if m.Cols() != b.Rows() {
+		return Matrix{}, fmt.Errorf("%w: %d×%d * %d×%d", ErrShape, m.Rows(), m.Cols(), b.Rows(), b.Cols())
+	}
+	out := NewMatrix(m.Rows(), b.Cols())
+	// i-k-j 的循环顺序让最内层沿着 b 和 out 的行连续访问内存
+	for i := 0; i < m.Rows(); i++ {
+		dst := out.Row(i)
+		for k, a := range m.Row(i) {
+			if a == 0 {
+				continue
+			}
+			for j, x := range b.Row(k) {
+				dst[j] += a * x
+			}
+		}
+	}
+	return out, nil
+}
+
+// Det 用带部分主元的高斯消元计算行列式；m 必须是方阵。
+func (m Matrix) Det() (float64, error) {
+	if m.Rows() != m.Cols() {
+		return 0, fmt.Errorf("%w: determinant of %d×%d", ErrShape, m.Rows(), m.Cols())
+	}
+	a := m.Clone()
+	n := a.Rows()
+	det := 1.0
+	for col := 0; col < n; col++ {
+		p := pivot(a, col)
+		if math.Abs(a.At(p, col)) < epsilon {
+			return 0, nil
+		}
+		if p != col {
+			swapRows(a, p, col)
+			det = -det
+		}
+		d := a.At(col, col)
+		det *= d
+		for r := col + 1; r < n; r++ {
+			eliminate(a, r, col, a.At(r, col)/d)
+		}
+	}
+	return det, nil
+}
+
+// Inverse 用高斯-约旦消元求逆矩阵；不可逆时返回 ErrSingular。
+func (m Matrix) Inverse() (Matrix, error) {
+	if m.Rows() != m.Cols() {
+		return Matrix{}, fmt.Errorf("%w: inverse of %d×%d", ErrShape, m.Rows(), m.Cols())
+	}
+	n := m.Rows()
+	a := m.Clone()
+	inv := Identity(n).Grid
+	for col := 0; col < n; col++ {
+		p := pivot(a, col)
+		if math.Abs(a.At(p, col)) < epsilon {
+			return Matrix{}, ErrSingular
+		}
+		swapRows(a, p, col)
+		swapRows(inv, p, col)
+		d := a.At(col, col)
+		scaleRow(a, col, 1/d)
+		scaleRow(inv, col, 1/d)
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			f := a.At(r, col)
+			eliminate(a, r, col, f)
+			eliminate(inv, r, col, f)
+		}
+	}
+	return Matrix{inv}, nil
+}
+
+// Equal 报告两个矩阵尺寸相同且每个元素之差不超过 tol。
+func (m Matrix) Equal(b Matrix, tol float64) bool {
+	if m.Rows() != b.Rows() || m.Cols() != b.Cols() {
+		return false
+	}
+	for r := 0; r < m.Rows(); r++ {
+		br := b.Row(r)
+		for c, v := range m.Row(r) {
+			if math.Abs(v-br[c]) > tol {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String 用 %g 格式输出矩阵。
+func (m Matrix) String() string {
+	return m.Format(func(v float64) string { return strconv.FormatFloat(v, 'g', 6, 64) })
+}
+
+// pivot 返回第 col 列中从 col 行往下绝对值最大的行（部分主元）。
+func pivot(a *Grid[float64], col int) int {
+	best := col
+	for r := col + 1; r < a.Rows(); r++ {
+		if math.Abs(a.At(r, col)) > math.Abs(a.At(best, col)) {
+			best = r
+		}
+	}
+	return best
+}
+
+func swapRows(a *Grid[float64], i, j int) {
+	if i == j {
+		return
+	}
+	ri, rj := a.Row(i), a.Row(j)
+	for c := range ri {
+		ri[c], rj[c] = rj[c], ri[c]
+	}
+}
+
+func scaleRow(a *Grid[float64], r int, f float64) {
+	row := a.Row(r)
+	for c := range row {
+		row[c] *= f
+	}
+}
+
+// eliminate 执行 row[r] -= f * row[src]。
+func eliminate(a *Grid[float64], r, src int, f float64) {
+	if f == 0 {
+		return
+	}
+	dst, s := a.Row(r), a.Row(src)
+	for c := range dst {
+		dst[c] -= f * s[c]
+	}
+}
@@ -0,0 +1,121 @@
+package grid
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func mustMatrix(t *testing.T, rows [][]float64) Matrix {
+	t.Helper()
+	m, err := MatrixFrom(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMul(t *testing.T) {
+	a := mustMatrix(t, [][]float64{{1, 2, 3}, {4, 5, 6}})
+	b := mustMatrix(t, [][]float64{{7, 8}, {9, 10}, {11, 12}})
+	got, err := a.Mul(b)
+	if want := mustMatrix(t, [][]float64{{58, 64}, {139, 154}}); err != nil || !got.Equal(want, 0) {
+		t.Errorf("a*b =\n%v, %v", got, err)
+	}
+	if got, err := a.Mul(Identity(3)); err != nil || !got.Equal(a, 0) {
+		t.Errorf("a*I =\n%v, %v", got, err)
+	}
+
+	// 子网格视图也能参与运算
+	big := mustMatrix(t, [][]float64{{0, 0, 0}, {0, 1, 2}, {0, 3, 4}})
+	sub := Matrix{big.Sub(1, 1, 2, 2)}
+	if got, err := sub.Mul(sub); err != nil || !got.Equal(mustMatrix(t, [][]float64{{7, 10}, {15, 22}}), 0) {
+		t.Errorf("sub*sub =\n%v, %v", got, err)
+	}
+
+	if _, err := a.Mul(a); !errors.Is(err, ErrShape) {
+		t.Errorf("2×3 * 2×3: %v", err)
+	}
+	if _, err := MatrixFrom([][]float64{{1}, {2, 3}}); err == nil {
+		t.Error("MatrixFrom accepted ragged rows")
+	}
+}
+
+func TestDet(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rows [][]float64
+		want float64
+	}{
+		{"1×1", [][]float64{{-3}}, -3},
+		{"2×2", [][]float64{{3, 8}, {4, 6}}, -14},
+		{"3×3", [][]float64{{6, 1, 1}, {4, -2, 5}, {2, 8, 7}}, -306},
+		{"needs pivoting", [][]float64{{0, 1}, {1, 0}}, -1},
+		{"singular", [][]float64{{1, 2, 3}, {2, 4, 6}, {1, 1, 1}}, 0},
+		{"identity", Identity(4).ToRows(), 1},
+	} {
+		got, err := mustMatrix(t, tc.rows).Det()
+		if err != nil || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: Det = %g, %v; want %g", tc.name, got, err, tc.want)
+		}
+	}
+	if _, err := NewMatrix(2, 3).Det(); !errors.Is(err, ErrShape) {
+		t.Errorf("Det of 2×3: %v", err)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	for _, rows := range [][][]float64{
+		{{4, 7}, {2, 6}},
+		{{0, 1, 2}, {1, 0, 3}, {4, -3, 8}}, // 第一列的主元为 0，必须换行
+		{{2, 0, 0, 0}, {0, 0.5, 0, 0}, {0, 0, -4, 0}, {1, 0, 0, 1}},
+	} {
+		m := mustMatrix(t, rows)
+		inv, err := m.Inverse()
+		if err != nil {
+			t.Errorf("Inverse(%v): %v", rows, err)
+			continue
+		}
+		id := Identity(m.Rows())
+		left, _ := inv.Mul(m)
+		right, _ := m.Mul(inv)
+		if !left.Equal(id, 1e-9) || !right.Equal(id, 1e-9) {
+			t.Errorf("Inverse(%v) =\n%v", rows, inv)
+		}
+		if again, _ := inv.Inverse(); !again.Equal(m, 1e-9) {
+			t.Errorf("Inverse(Inverse(%v)) =\n%v", rows, again)
+		}
+		if m.At(0, 0) != rows[0][0] {
+			t.Error("Inverse modified its receiver")
+		}
+	}
+
+	want := mustMatrix(t, [][]float64{{0.6, -0.7}, {-0.2, 0.4}})
+	if inv, _ := mustMatrix(t, [][]float64{{4, 7}, {2, 6}}).Inverse(); !inv.Equal(want, 1e-12) {
+		t.Errorf("Inverse of [[4 7] [2 6]] =\n%v", inv)
+	}
+
+	if _, err := mustMatrix(t, [][]float64{{1, 2}, {2, 4}}).Inverse(); !errors.Is(err, ErrSingular) {
+		t.Errorf("singular matrix: %v", err)
+	}
+	if _, err := NewMatrix(3, 3).Inverse(); !errors.Is(err, ErrSingular) {
+		t.Errorf("zero matrix: %v", err)
+	}
+	if _, err := NewMatrix(3, 2).Inverse(); !errors.Is(err, ErrShape) {
+		t.Errorf("Inverse of 3×2: %v", err)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := mustMatrix(t, [][]float64{{1, 2}, {3, 4}})
+	b := mustMatrix(t, [][]float64{{1, 2}, {3, 4.001}})
+	if a.Equal(b, 1e-6) || !a.Equal(b, 1e-2) {
+		t.Error("Equal ignores the tolerance")
+	}
+	if a.Equal(NewMatrix(2, 3), 1) {
+		t.Error("matrices of different shapes reported equal")
+	}
+	if got := mustMatrix(t, [][]float64{{0.5, 100}, {1e-7, -2}}).String(); got != "  0.5 100\n1e-07  -2\n" {
+		t.Errorf("String = %q", got)
+	}
+}
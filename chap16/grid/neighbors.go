@@ -0,0 +1,71 @@
+package grid
+
+// Point 是网格中的一个位置。
+type Point struct {
+	R, C int
+}
+
+// Connectivity 决定哪些格子算作相邻。
+type Connectivity int
+
+const (
+	// Four 上下左右 4 连通。
+	Four Connectivity = 4
+	// Eight 包括对角线在内的 8 连通。
+	Eight Connectivity = 8
+)
+
+var (
+	offsets4 = []Point{{-1, 0}, {0, -1}, {0, 1}, {1, 0}}
+	offsets8 = []Point{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}}
+)
+
+func (conn Connectivity) offsets() []Point {
+	if conn == Eight {
+		return offsets8
+	}
+	return offsets4
+}
+
+// EachNeighbor 对 (r, c) 在网格内的每个邻居调用 fn。
+func (g *Grid[T]) EachNeighbor(r, c int, conn Connectivity, fn func(r, c int, v T)) {
+	for _, d := range conn.offsets() {
+		nr, nc := r+d.R, c+d.C
+		if g.In(nr, nc) {
+			fn(nr, nc, g.data[g.off+nr*g.stride+nc])
+		}
+	}
+}
+
+// Neighbors 返回 (r, c) 在网格内的所有邻居坐标。
+func (g *Grid[T]) Neighbors(r, c int, conn Connectivity) []Point {
+	points := make([]Point, 0, int(conn))
+	g.EachNeighbor(r, c, conn, func(nr, nc int, _ T) {
+		points = append(points, Point{nr, nc})
+	})
+	return points
+}
+
+// FloodFill 从 (r, c) 开始，把所有与起点值相同且连通的格子改成 v，返回被修改的格子数。
+// 使用显式栈而不是递归，大网格也不会栈溢出。
+func FloodFill[T comparable](g *Grid[T], r, c int, v T, conn Connectivity) int {
+	target := g.At(r, c)
+	if target == v {
+		return 0
+	}
+	n := 0
+	stack := []Point{{r, c}}
+	g.Set(r, c, v)
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n++
+		g.EachNeighbor(p.R, p.C, conn, func(nr, nc int, cur T) {
+			if cur == target {
+				g.Set(nr, nc, v)
+				stack = append(stack, Point{nr, nc})
+			}
+		})
+	}
+	return n
+}
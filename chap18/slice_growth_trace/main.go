@@ -0,0 +1,63 @@
+// 独立运行：go run ./chap18/slice_growth_trace -n 5000 -size 8
+// 输出 CSV：go run ./chap18/slice_growth_trace -n 100000 -size 24 -format csv > growth.csv
+// 演示：逐个 append，记录每一次扩容的旧/新容量、增长系数以及底层数组是否搬家，
+// 并与 Go 1.18+ growslice 公式推算的容量对比（-check 时核对 256 阈值和约 1.25 倍的系数）。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"books/chap18/slicetrace"
+)
+
+func main() {
+	var (
+		n        = flag.Int("n", 4096, "追加的元素个数")
+		size     = flag.Int("size", 8, "元素大小（字节）")
+		pointers = flag.Bool("pointers", false, "元素是否含指针（size 必须是指针大小的倍数）")
+		format   = flag.String("format", "chart", "输出格式：chart 或 csv")
+		width    = flag.Int("width", 50, "chart 条形的最大宽度")
+		check    = flag.Bool("check", false, "核对文档中的扩容阈值，并报告与公式不一致的扩容")
+	)
+	flag.Parse()
+
+	t, err := slicetrace.AppendSize(*n, *size, *pointers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "csv":
+		err = t.WriteCSV(os.Stdout)
+	case "chart":
+		fmt.Println(runtime.Version())
+		err = t.WriteChart(os.Stdout, *width)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if !*check {
+		return
+	}
+	failed := false
+	if err := t.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, "threshold check failed:\n"+err.Error())
+		failed = true
+	}
+	for _, r := range t.Mismatches() {
+		fmt.Fprintf(os.Stderr, "formula mismatch: cap %d -> %d, predicted %d\n", r.OldCap, r.NewCap, r.Predicted)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "ok: growth matches the documented formula")
+}
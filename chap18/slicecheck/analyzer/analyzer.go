@@ -0,0 +1,225 @@
+// Package analyzer 提供 slicecheck 的静态检查（go/analysis），找出第18章演示的两种 append 陷阱：
+//
+//  1. 丢弃 append 的返回值：_ = append(s2, 999)。
+//     不扩容时它仍然会写进共享的底层数组，扩容时结果又被扔掉，两种情况都不是想要的。
+//  2. 对两索引切片 s2 := s1[lo:hi] 做 append，而之后 s1 仍然被使用。
+//     s2 的容量一直延伸到 s1 的末尾，append 会覆盖 s1[hi] 之后的元素；
+//     应当改用三索引切片 s1[lo:hi:hi]（见 getIndependentSlice）或先复制。
+//
+// 检查按源码顺序进行，不做控制流分析：父切片在 append 之后被重新赋值（例如重置）就不再算“仍在使用”。
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer 检查丢弃的 append 结果和对共享底层数组的两索引切片的 append。
+var Analyzer = &analysis.Analyzer{
+	Name:     "slicecheck",
+	Doc:      "report discarded append results and appends to two-index reslices whose parent slice is still used",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// reslice 记录一次 child = parent[lo:hi] 的赋值。
+type reslice struct {
+	parent *types.Var
+	pos    token.Pos
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		var body *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			body = fn.Body
+		case *ast.FuncLit:
+			body = fn.Body
+		}
+		if body != nil {
+			checkBody(pass, body)
+		}
+	})
+	return nil, nil
+}
+
+// checkBody 检查一个函数体（不进入嵌套的函数字面量，它们会被单独检查）。
+func checkBody(pass *analysis.Pass, body *ast.BlockStmt) {
+	// 按源码顺序记录每个局部变量最近一次被赋值为两索引切片的位置；
+	// 被赋成其他值时清除记录。
+	latest := make(map[*types.Var]*reslice)
+	// uses 记录每个变量所有被读取的位置，kills 记录被整体重新赋值的位置。
+	uses := make(map[*types.Var][]token.Pos)
+	kills := make(map[*types.Var][]token.Pos)
+	lhs := make(map[*ast.Ident]bool)
+
+	type appendSite struct {
+		call      *ast.CallExpr
+		child     *types.Var // append(child, ...) 时的 child；直接 append(parent[lo:hi], ...) 时为 nil
+		parent    *types.Var
+		discarded bool
+	}
+	var sites []appendSite
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			for i, l := range n.Lhs {
+				id, ok := l.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				lhs[id] = true
+				v := localVar(pass, id)
+				if v == nil {
+					continue
+				}
+				if n.Tok == token.ASSIGN || n.Tok == token.DEFINE {
+					// 右侧对 v 的读取发生在赋值之前，所以以语句结束位置作为失效点
+					kills[v] = append(kills[v], n.End())
+				}
+				if len(n.Lhs) != len(n.Rhs) {
+					delete(latest, v)
+					continue
+				}
+				if parent := twoIndexParent(pass, n.Rhs[i]); parent != nil && parent != v {
+					latest[v] = &reslice{parent: parent, pos: n.Pos()}
+				} else if !isAppendTo(pass, n.Rhs[i], v) {
+					// v = append(v, ...) 仍然是同一个（可能共享的）切片，保留记录
+					delete(latest, v)
+				}
+			}
+			for i, r := range n.Rhs {
+				call, ok := r.(*ast.CallExpr)
+				if !ok || !isBuiltinAppend(pass, call) || len(call.Args) == 0 {
+					continue
+				}
+				discarded := len(n.Lhs) == len(n.Rhs) && isBlank(n.Lhs[i])
+				if discarded {
+					pass.Reportf(call.Pos(), "result of append is discarded; without reallocation it still writes into the shared backing array")
+				}
+				site := appendSite{call: call, discarded: discarded}
+				if id, ok := call.Args[0].(*ast.Ident); ok {
+					if v := localVar(pass, id); v != nil {
+						if rs := latest[v]; rs != nil {
+							site.child, site.parent = v, rs.parent
+						}
+					}
+				} else if parent := twoIndexParent(pass, call.Args[0]); parent != nil {
+					site.parent = parent
+				}
+				if site.parent != nil {
+					sites = append(sites, site)
+				}
+			}
+		case *ast.Ident:
+			if lhs[n] {
+				return true
+			}
+			if v := localVar(pass, n); v != nil {
+				uses[v] = append(uses[v], n.Pos())
+			}
+		}
+		return true
+	})
+
+	for _, s := range sites {
+		if s.discarded {
+			continue
+		}
+		if !usedAfter(s.parent, s.call.End(), uses, kills) {
+			continue
+		}
+		if s.child != nil {
+			pass.Reportf(s.call.Pos(),
+				"append to %s may overwrite elements of %s: %s is a two-index reslice of %s sharing its backing array; use a three-index slice %s[lo:hi:hi] or copy",
+				s.child.Name(), s.parent.Name(), s.child.Name(), s.parent.Name(), s.parent.Name())
+		} else {
+			pass.Reportf(s.call.Pos(),
+				"append to a two-index reslice of %s may overwrite elements of %s; use a three-index slice %s[lo:hi:hi] or copy",
+				s.parent.Name(), s.parent.Name(), s.parent.Name())
+		}
+	}
+}
+
+// usedAfter 报告 v 在 pos 之后、被整体重新赋值之前是否还被读取。
+func usedAfter(v *types.Var, pos token.Pos, uses, kills map[*types.Var][]token.Pos) bool {
+	firstKill := token.NoPos
+	for _, k := range kills[v] {
+		if k > pos && (firstKill == token.NoPos || k < firstKill) {
+			firstKill = k
+		}
+	}
+	for _, u := range uses[v] {
+		if u > pos && (firstKill == token.NoPos || u < firstKill) {
+			return true
+		}
+	}
+	return false
+}
+
+// twoIndexParent 在 e 形如 parent[lo:hi]（不带 max）且 parent 是切片或数组类型的局部变量时返回 parent。
+func twoIndexParent(pass *analysis.Pass, e ast.Expr) *types.Var {
+	se, ok := astutil.Unparen(e).(*ast.SliceExpr)
+	if !ok || se.Slice3 {
+		return nil
+	}
+	id, ok := astutil.Unparen(se.X).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v := localVar(pass, id)
+	if v == nil {
+		return nil
+	}
+	switch v.Type().Underlying().(type) {
+	case *types.Slice, *types.Array:
+		return v
+	}
+	return nil
+}
+
+// isAppendTo 报告 e 是否为 append(v, ...)。
+func isAppendTo(pass *analysis.Pass, e ast.Expr, v *types.Var) bool {
+	call, ok := astutil.Unparen(e).(*ast.CallExpr)
+	if !ok || !isBuiltinAppend(pass, call) || len(call.Args) == 0 {
+		return false
+	}
+	id, ok := call.Args[0].(*ast.Ident)
+	return ok && localVar(pass, id) == v
+}
+
+func isBuiltinAppend(pass *analysis.Pass, call *ast.CallExpr) bool {
+	id, ok := astutil.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == "append"
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+// localVar 返回标识符对应的函数内变量（包括参数），包级变量和字段返回 nil。
+func localVar(pass *analysis.Pass, id *ast.Ident) *types.Var {
+	obj := pass.TypesInfo.ObjectOf(id)
+	v, ok := obj.(*types.Var)
+	if !ok || v.IsField() || v.Parent() == nil || v.Parent() == pass.Pkg.Scope() {
+		return nil
+	}
+	return v
+}
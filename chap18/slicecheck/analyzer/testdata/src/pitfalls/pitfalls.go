@@ -0,0 +1,92 @@
+// Package pitfalls 收集第18章演示过的 append 陷阱，// want 注释标出 slicecheck 应当报告的位置
+// （analysistest 约定的写法）。
+package pitfalls
+
+import "fmt"
+
+// slice_expand_verify：丢弃 append 的返回值，s1[2] 被悄悄改成 999。
+func discardedAppend() {
+	s1 := []int{1, 2, 3}
+	s2 := s1[:2]
+	_ = append(s2, 999) // want `result of append is discarded`
+	fmt.Println(s1, s2)
+}
+
+// append_mechanism_three_index：普通切片 append 后原数组被修改。
+func twoIndexAppend() {
+	planets := []string{"Mercury", "Venus", "Earth", "Mars", "Jupiter", "Saturn", "Uranus", "Neptune"}
+	slice1 := planets[2:5]
+	slice1 = append(slice1, "NewPlanet") // want `append to slice1 may overwrite elements of planets`
+	fmt.Println(slice1, planets)
+}
+
+// 同上，整数版本。
+func normalSlice() {
+	base := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	normalSlice := base[2:6]
+	normalSlice = append(normalSlice, 99) // want `append to normalSlice may overwrite elements of base`
+	fmt.Println(normalSlice, base)
+}
+
+// 直接对两索引切片表达式 append。
+func inlineReslice() {
+	base := []int{1, 2, 3, 4}
+	head := append(base[:1], 100) // want `append to a two-index reslice of base`
+	fmt.Println(head, base)
+}
+
+// 数组同样会被覆盖。
+func arrayParent() {
+	arr := [5]int{1, 2, 3, 4, 5}
+	s := arr[:2]
+	s = append(s, 9) // want `append to s may overwrite elements of arr`
+	fmt.Println(s, arr)
+}
+
+// 三索引切片限制了容量，append 一定扩容，不报告。
+func threeIndex() {
+	planets := []string{"Mercury", "Venus", "Earth", "Mars", "Jupiter"}
+	slice2 := planets[2:5:5]
+	slice2 = append(slice2, "NewPlanet")
+	fmt.Println(slice2, planets)
+}
+
+// getIndependentSlice 是手写的修复方式，不报告。
+func getIndependentSlice(data []string, start, end int) []string {
+	if end > len(data) {
+		end = len(data)
+	}
+	return data[start:end:end]
+}
+
+func useIndependent() {
+	originalData := []string{"a", "b", "c", "d", "e", "f"}
+	independent := getIndependentSlice(originalData, 2, 5)
+	independent = append(independent, "x", "y", "z")
+	fmt.Println(independent, originalData)
+}
+
+// append 之后父切片被整体重置，旧的底层数组不再被使用，不报告。
+func parentReset() {
+	planets := []string{"Mercury", "Venus", "Earth", "Mars"}
+	slice1 := planets[0:2]
+	slice1 = append(slice1, "NewPlanet")
+	planets = []string{"Mercury", "Venus"}
+	fmt.Println(slice1, planets)
+}
+
+// append 之后父切片不再使用，不报告。
+func parentDead() []int {
+	buf := []int{1, 2, 3, 4}
+	head := buf[:2]
+	head = append(head, 5)
+	return head
+}
+
+// 先复制再 append，不报告。
+func copied() {
+	s1 := []int{1, 2, 3}
+	s2 := append([]int(nil), s1[:2]...)
+	s2 = append(s2, 999)
+	fmt.Println(s1, s2)
+}
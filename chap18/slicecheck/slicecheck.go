@@ -0,0 +1,79 @@
+// Package slicecheck 检查两个切片是否共享同一段底层数组（第18章 append 共享陷阱的运行时工具）。
+//
+// chap18/slice_expand_verify 演示了 s2 := s1[:2] 之后 _ = append(s2, 999) 会悄悄改掉 s1[2]；
+// 这里的函数直接比较底层数组地址，回答“两个切片在内存中是否重叠、重叠多少个元素”，
+// 以及“对 s 做不扩容的 append 会覆盖 other 的多少个元素”。
+// 静态检查见子包 analyzer。
+package slicecheck
+
+import "unsafe"
+
+// span 返回 s 的 [0:n) 元素在内存中的地址区间 [lo, hi)。
+func span[T any](s []T, n int) (lo, hi uintptr) {
+	var zero T
+	size := unsafe.Sizeof(zero)
+	if n == 0 || size == 0 {
+		return 0, 0
+	}
+	lo = uintptr(unsafe.Pointer(unsafe.SliceData(s)))
+	return lo, lo + uintptr(n)*size
+}
+
+// overlapCount 计算两个地址区间重叠的元素个数。
+func overlapCount(aLo, aHi, bLo, bHi, size uintptr) int {
+	lo, hi := max(aLo, bLo), min(aHi, bHi)
+	if lo >= hi || size == 0 {
+		return 0
+	}
+	return int((hi - lo) / size)
+}
+
+// Overlap 返回 a[0:len(a)] 与 b[0:len(b)] 在内存中重叠的元素个数；0 表示互不影响。
+func Overlap[T any](a, b []T) int {
+	var zero T
+	aLo, aHi := span(a, len(a))
+	bLo, bHi := span(b, len(b))
+	return overlapCount(aLo, aHi, bLo, bHi, unsafe.Sizeof(zero))
+}
+
+// Overlaps 报告修改 a 的元素是否可能被 b 看到。
+func Overlaps[T any](a, b []T) bool {
+	return Overlap(a, b) > 0
+}
+
+// SharesBacking 报告 a 和 b 的容量范围 [0:cap) 是否落在同一段底层数组上。
+// 即使当前 len 范围不重叠，只要容量重叠，append 就可能互相覆盖。
+func SharesBacking[T any](a, b []T) bool {
+	var zero T
+	aLo, aHi := span(a, cap(a))
+	bLo, bHi := span(b, cap(b))
+	return overlapCount(aLo, aHi, bLo, bHi, unsafe.Sizeof(zero)) > 0
+}
+
+// AppendClobbers 返回对 s 追加元素（且不触发扩容）时，最多会覆盖 other 中多少个元素，
+// 也就是 s[len(s):cap(s)] 与 other[0:len(other)] 的重叠数。
+//
+//	s1 := []int{1, 2, 3}
+//	s2 := s1[:2]
+//	AppendClobbers(s2, s1) // 1：append(s2, x) 会写到 s1[2]
+//	AppendClobbers(s1[:2:2], s1) // 0：三索引切片限制了容量，append 一定会扩容
+func AppendClobbers[T any](s, other []T) int {
+	var zero T
+	size := unsafe.Sizeof(zero)
+	if size == 0 || cap(s) == len(s) {
+		return 0
+	}
+	spare := s[len(s):cap(s)]
+	sLo, sHi := span(spare, len(spare))
+	oLo, oHi := span(other, len(other))
+	return overlapCount(sLo, sHi, oLo, oHi, size)
+}
+
+// Independent 返回 s 的一个独立副本，长度和容量都等于 len(s)，之后的修改和 append 都不会影响原切片。
+// 与 getIndependentSlice 的三索引写法不同，它连元素本身也不再共享。
+func Independent[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
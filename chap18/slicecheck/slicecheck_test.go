@@ -0,0 +1,96 @@
+package slicecheck
+
+import "testing"
+
+func TestOverlap(t *testing.T) {
+	base := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	other := make([]int, 10)
+	for _, tc := range []struct {
+		name string
+		a, b []int
+		want int
+	}{
+		{"disjoint arrays", base, other, 0},
+		{"adjacent ranges", base[:5], base[5:], 0},
+		{"partial", base[2:6], base[4:9], 2},
+		{"contained", base, base[3:5], 2},
+		{"identical", base[1:4], base[1:4], 3},
+		{"zero length", base[3:3], base, 0},
+		{"both empty", base[:0], base[:0], 0},
+		{"nil", nil, base, 0},
+	} {
+		if got := Overlap(tc.a, tc.b); got != tc.want {
+			t.Errorf("%s: Overlap = %d, want %d", tc.name, got, tc.want)
+		}
+		if got := Overlap(tc.b, tc.a); got != tc.want {
+			t.Errorf("%s: Overlap (swapped) = %d, want %d", tc.name, got, tc.want)
+		}
+		if got := Overlaps(tc.a, tc.b); got != (tc.want > 0) {
+			t.Errorf("%s: Overlaps = %v", tc.name, got)
+		}
+	}
+
+	// 元素大小不是 1 时按元素而不是字节计数
+	type pair struct{ a, b int64 }
+	pairs := make([]pair, 6)
+	if got := Overlap(pairs[1:4], pairs[2:6]); got != 2 {
+		t.Errorf("struct elements: Overlap = %d, want 2", got)
+	}
+	// 零大小的元素不占内存，永远不重叠
+	empty := make([]struct{}, 4)
+	if got := Overlap(empty, empty); got != 0 {
+		t.Errorf("zero-size elements: Overlap = %d, want 0", got)
+	}
+}
+
+func TestSharesBacking(t *testing.T) {
+	base := make([]int, 4, 10)
+	if !SharesBacking(base[:2], base[2:4]) {
+		t.Error("base[:2] and base[2:4]: capacities overlap, want true")
+	}
+	if Overlaps(base[:2], base[2:4]) {
+		t.Error("base[:2] and base[2:4]: lengths do not overlap")
+	}
+	if SharesBacking(base[:2:2], base[2:4]) {
+		t.Error("three-index slice limits capacity, want false")
+	}
+	if SharesBacking(base, make([]int, 4)) {
+		t.Error("separate arrays, want false")
+	}
+}
+
+func TestAppendClobbers(t *testing.T) {
+	s1 := []int{1, 2, 3}
+	s2 := s1[:2]
+	if got := AppendClobbers(s2, s1); got != 1 {
+		t.Errorf("AppendClobbers(s1[:2], s1) = %d, want 1", got)
+	}
+	if got := AppendClobbers(s1[:2:2], s1); got != 0 {
+		t.Errorf("AppendClobbers(s1[:2:2], s1) = %d, want 0", got)
+	}
+	planets := []string{"Mercury", "Venus", "Earth", "Mars", "Jupiter", "Saturn", "Uranus", "Neptune"}
+	if got := AppendClobbers(planets[2:5], planets); got != 3 {
+		t.Errorf("AppendClobbers(planets[2:5], planets) = %d, want 3", got)
+	}
+	// 验证预测：不扩容的 append 确实改掉了 s1[2]
+	_ = append(s2, 999)
+	if s1[2] != 999 {
+		t.Errorf("s1 = %v, want s1[2] overwritten", s1)
+	}
+}
+
+func TestIndependent(t *testing.T) {
+	s := []int{1, 2, 3}
+	c := Independent(s[:2])
+	if len(c) != 2 || cap(c) != 2 || SharesBacking(c, s) {
+		t.Fatalf("Independent = %v (cap %d), shares backing %v", c, cap(c), SharesBacking(c, s))
+	}
+	c = append(c, 99)
+	c[0] = -1
+	if s[0] != 1 || s[2] != 3 {
+		t.Errorf("original changed: %v", s)
+	}
+	if Independent[int](nil) != nil {
+		t.Error("Independent(nil) != nil")
+	}
+}
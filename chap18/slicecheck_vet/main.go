@@ -0,0 +1,15 @@
+// 作为 vet 工具：go build -o slicecheck ./chap18/slicecheck_vet && go vet -vettool=$(pwd)/slicecheck ./chap18/slice_expand_verify
+// 检查示例陷阱：go vet -vettool=$(pwd)/slicecheck ./chap18/slicecheck/analyzer/testdata/src/pitfalls
+// 也可以直接 go run ./chap18/slicecheck_vet <包>，但这种方式要求 x/tools 能读取当前工具链的导出数据。
+// 演示：把 chap18/slicecheck/analyzer 包装成命令行工具，检查丢弃的 append 结果和共享底层数组的 append。
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"books/chap18/slicecheck/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,74 @@
+package slicetrace
+
+const (
+	maxSmallSize = 32768
+	pageSize     = 8192
+	// minSizeForMallocHeader 以上的含指针对象要额外留出 mallocHeaderSize 字节的头部，
+	// mallocHeaderSize 随工具链版本不同，见 header_go122.go。
+	minSizeForMallocHeader = 512
+)
+
+// sizeClasses 是 runtime/sizeclasses.go 中小对象的内存规格（字节）。
+var sizeClasses = []int{
+	0, 8, 16, 24, 32, 48, 64, 80, 96, 112, 128, 144, 160, 176, 192, 208, 224, 240, 256,
+	288, 320, 352, 384, 416, 448, 480, 512, 576, 640, 704, 768, 896, 1024, 1152, 1280,
+	1408, 1536, 1792, 2048, 2304, 2688, 3072, 3200, 3456, 4096, 4864, 5376, 6144, 6528,
+	6784, 6912, 8192, 9472, 9728, 10240, 10880, 12288, 13568, 14336, 16384, 18432, 19072,
+	20480, 21760, 24576, 27264, 28672, 32768,
+}
+
+// GrowCap 是 growslice 的第一步：只按公式计算新容量，不考虑内存规格取整。
+//
+//	newLen > 2*oldCap       → newLen
+//	oldCap < 256            → 2*oldCap
+//	否则反复 newcap += (newcap + 3*256) / 4，直到 >= newLen
+//
+// 系数从 256 处的 2 倍平滑过渡到很大容量时的 1.25 倍。
+func GrowCap(oldCap, newLen int) int {
+	newcap := oldCap
+	doublecap := newcap + newcap
+	if newLen > doublecap {
+		return newLen
+	}
+	if oldCap < Threshold {
+		return doublecap
+	}
+	for {
+		newcap += (newcap + 3*Threshold) >> 2
+		if uint(newcap) >= uint(newLen) {
+			return newcap
+		}
+	}
+}
+
+// roundupsize 把申请的字节数向上取整到分配器实际给出的大小。
+// 小对象取整到 sizeClasses 中的规格（含指针的对象要先留出头部），大对象按页取整。
+func roundupsize(size int, pointers bool) int {
+	if size <= maxSmallSize-mallocHeaderSize {
+		req := size
+		if pointers && size > minSizeForMallocHeader {
+			req += mallocHeaderSize
+		}
+		return roundClass(req) - (req - size)
+	}
+	return (size + pageSize - 1) &^ (pageSize - 1)
+}
+
+func roundClass(size int) int {
+	for _, c := range sizeClasses {
+		if c >= size {
+			return c
+		}
+	}
+	return size
+}
+
+// NextCap 推算一次扩容后的容量：先用 GrowCap 算出元素个数，
+// 再把字节数按内存规格向上取整，多出来的空间也折算成容量。
+func NextCap(oldCap, newLen, elemSize int, pointers bool) int {
+	newcap := GrowCap(oldCap, newLen)
+	if elemSize == 0 {
+		return newcap
+	}
+	return roundupsize(newcap*elemSize, pointers) / elemSize
+}
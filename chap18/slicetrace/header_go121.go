@@ -0,0 +1,6 @@
+//go:build !go1.22
+
+package slicetrace
+
+// mallocHeaderSize 在 Go 1.22 之前为 0：含指针对象的类型信息放在堆位图里，不占用对象本身。
+const mallocHeaderSize = 0
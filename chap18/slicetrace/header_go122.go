@@ -0,0 +1,7 @@
+//go:build go1.22
+
+package slicetrace
+
+// mallocHeaderSize 是 Go 1.22 起分配器给含指针、且大于 minSizeForMallocHeader 的对象
+// 额外加的类型头部（runtime/mbitmap.go），它占用的空间不能用来存放元素。
+const mallocHeaderSize = 8
@@ -0,0 +1,64 @@
+package slicetrace
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV 以 CSV 格式输出每一次扩容，第一行是表头。
+func (t *Trace) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"len", "old_cap", "new_cap", "factor", "moved", "predicted_cap", "bytes"}); err != nil {
+		return err
+	}
+	for _, r := range t.Reallocs {
+		record := []string{
+			strconv.Itoa(r.Len),
+			strconv.Itoa(r.OldCap),
+			strconv.Itoa(r.NewCap),
+			strconv.FormatFloat(r.Factor, 'f', 3, 64),
+			strconv.FormatBool(r.Moved),
+			strconv.Itoa(r.Predicted),
+			strconv.Itoa(r.NewCap * t.ElemSize),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteChart 输出 ASCII 增长曲线：每次扩容一行，条形长度与 log2(新容量) 成正比，
+// 右侧标出增长系数，并用 ! 标记与公式推算不一致的扩容。
+func (t *Trace) WriteChart(w io.Writer, width int) error {
+	if width <= 0 {
+		width = 50
+	}
+	fmt.Fprintf(w, "elem=%dB pointers=%v n=%d reallocs=%d\n", t.ElemSize, t.Pointers, t.N, len(t.Reallocs))
+	if len(t.Reallocs) == 0 {
+		return nil
+	}
+	top := math.Log2(float64(t.Reallocs[len(t.Reallocs)-1].NewCap) + 1)
+	for _, r := range t.Reallocs {
+		bar := int(math.Round(math.Log2(float64(r.NewCap)+1) / top * float64(width)))
+		mark := " "
+		if r.NewCap != r.Predicted {
+			mark = "!"
+		}
+		zone := "2x"
+		if r.OldCap >= Threshold {
+			zone = "1.25x"
+		}
+		_, err := fmt.Fprintf(w, "%8d -> %-8d %-*s x%5.3f %-5s %s\n",
+			r.OldCap, r.NewCap, width, strings.Repeat("#", bar), r.Factor, zone, mark)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
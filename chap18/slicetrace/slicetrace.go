@@ -0,0 +1,176 @@
+// Package slicetrace 逐个 append 元素，记录切片每一次扩容（第18章 append 扩容的验证工具）。
+//
+// 每次 cap 变化都会记下旧容量、新容量、增长系数以及底层数组首地址是否改变，
+// 并和按 Go 1.18+ growslice 公式（256 为界，之后约 1.25 倍，再按内存规格向上取整）
+// 推算出的容量对比，用来核对 01-datastruct/01-slice/02-slice扩容.md 与当前工具链是否一致。
+// 容量公式从 Go 1.18 起没有变化；内存规格取整在 Go 1.22 起会给含指针的对象留出分配头部，
+// 这部分按编译所用的工具链版本选择（header_go122.go / header_go121.go）。
+package slicetrace
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Threshold 是 growslice 从“翻倍”切换到“约 1.25 倍”的容量分界。
+const Threshold = 256
+
+// Realloc 描述一次扩容。
+type Realloc struct {
+	Len       int     // 触发扩容的这次 append 之后的长度
+	OldCap    int     // 扩容前容量
+	NewCap    int     // 扩容后容量
+	Factor    float64 // NewCap / OldCap；OldCap 为 0 时为 0
+	Moved     bool    // 底层数组首地址是否改变
+	Predicted int     // 按公式推算的新容量
+}
+
+// Trace 是一次完整的追踪结果。
+type Trace struct {
+	ElemSize int
+	Pointers bool
+	N        int
+	Reallocs []Realloc
+}
+
+// Append 向 []T 逐个追加 n 个零值元素并记录每次扩容。
+// T 在编译期确定，得到的就是普通 append 的真实行为。
+// 注意：较新的编译器会给不逃逸的切片先在栈上预留 32 字节，
+// 这时第一次扩容（0 -> 32/elemSize）来自编译器而不是 growslice，会出现在 Mismatches 里。
+func Append[T any](n int) *Trace {
+	var zero T
+	t := &Trace{
+		ElemSize: int(unsafe.Sizeof(zero)),
+		Pointers: hasPointers(reflect.TypeOf(&zero).Elem()),
+		N:        n,
+	}
+	var s []T
+	for i := 0; i < n; i++ {
+		oldCap, oldAddr := cap(s), dataAddr(s)
+		s = append(s, zero)
+		if cap(s) != oldCap {
+			t.record(len(s), oldCap, cap(s), oldAddr, dataAddr(s))
+		}
+	}
+	return t
+}
+
+// AppendSize 在运行时构造元素大小为 elemSize 字节的类型（[elemSize]byte，
+// pointers 为 true 时改用指针数组），再用 reflect.Append 逐个追加 n 个元素。
+// reflect.Append 与普通 append 走同一个 runtime.growslice。
+func AppendSize(n, elemSize int, pointers bool) (*Trace, error) {
+	if elemSize <= 0 {
+		return nil, errors.New("slicetrace: element size must be positive")
+	}
+	elem := reflect.TypeOf(byte(0))
+	count := elemSize
+	if pointers {
+		ptrSize := int(unsafe.Sizeof(uintptr(0)))
+		if elemSize%ptrSize != 0 {
+			return nil, fmt.Errorf("slicetrace: element size %d with pointers must be a multiple of %d", elemSize, ptrSize)
+		}
+		elem = reflect.TypeOf((*byte)(nil))
+		count = elemSize / ptrSize
+	}
+	typ := reflect.ArrayOf(count, elem)
+	t := &Trace{ElemSize: elemSize, Pointers: pointers, N: n}
+	s := reflect.MakeSlice(reflect.SliceOf(typ), 0, 0)
+	zero := reflect.Zero(typ)
+	for i := 0; i < n; i++ {
+		oldCap, oldAddr := s.Cap(), valueAddr(s)
+		s = reflect.Append(s, zero)
+		if s.Cap() != oldCap {
+			t.record(s.Len(), oldCap, s.Cap(), oldAddr, valueAddr(s))
+		}
+	}
+	return t, nil
+}
+
+func (t *Trace) record(length, oldCap, newCap int, oldAddr, newAddr uintptr) {
+	r := Realloc{
+		Len:       length,
+		OldCap:    oldCap,
+		NewCap:    newCap,
+		Moved:     oldAddr != 0 && oldAddr != newAddr,
+		Predicted: NextCap(oldCap, length, t.ElemSize, t.Pointers),
+	}
+	if oldCap > 0 {
+		r.Factor = float64(newCap) / float64(oldCap)
+	}
+	t.Reallocs = append(t.Reallocs, r)
+}
+
+// dataAddr 和 chap18/slice_expand_verify 里的 backingAddr 一样，返回底层数组首地址；
+// cap 为 0 时返回 0。
+func dataAddr[T any](s []T) uintptr {
+	if cap(s) == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(unsafe.SliceData(s)))
+}
+
+func valueAddr(v reflect.Value) uintptr {
+	if v.Cap() == 0 {
+		return 0
+	}
+	return v.Pointer()
+}
+
+// hasPointers 报告类型的内存里是否含有指针（影响 GC 扫描和分配头部）。
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Chan, reflect.Func,
+		reflect.Slice, reflect.String, reflect.Interface:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// largeBytes 以上的分配按页取整，取整误差可以忽略，增长系数应当贴近公式。
+const largeBytes = 1 << 20
+
+// Check 核对文档中的扩容阈值，所有不符合的扩容会一并返回：
+//   - 旧容量小于 Threshold 时，新容量至少翻倍；
+//   - 旧容量达到 Threshold 后，新容量不小于 GrowCap 的结果（取整只会变大）；
+//   - 旧容量达到 16*Threshold 且底层数组超过 1MB 时，系数落在 1.25 附近（1.25～1.32）。
+//
+// 中等大小的切片会被内存规格取整放大（例如 1024 -> 1638，甚至 286 -> 573 略超 2 倍），
+// 所以不对它们要求系数。
+func (t *Trace) Check() error {
+	var errs []error
+	for _, r := range t.Reallocs {
+		switch {
+		case r.OldCap == 0:
+		case r.OldCap < Threshold:
+			if r.NewCap < 2*r.OldCap {
+				errs = append(errs, fmt.Errorf("cap %d -> %d: want at least 2x below %d", r.OldCap, r.NewCap, Threshold))
+			}
+		case r.NewCap < GrowCap(r.OldCap, r.Len):
+			errs = append(errs, fmt.Errorf("cap %d -> %d: below formula capacity %d", r.OldCap, r.NewCap, GrowCap(r.OldCap, r.Len)))
+		case r.OldCap >= 16*Threshold && r.OldCap*t.ElemSize >= largeBytes && (r.Factor < 1.25 || r.Factor > 1.32):
+			errs = append(errs, fmt.Errorf("cap %d -> %d: factor %.3f, want ~1.25", r.OldCap, r.NewCap, r.Factor))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Mismatches 返回实际容量与公式推算不一致的扩容。
+func (t *Trace) Mismatches() []Realloc {
+	var out []Realloc
+	for _, r := range t.Reallocs {
+		if r.NewCap != r.Predicted {
+			out = append(out, r)
+		}
+	}
+	return out
+}
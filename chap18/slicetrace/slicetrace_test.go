@@ -0,0 +1,206 @@
+package slicetrace
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGrowCap(t *testing.T) {
+	for _, tc := range []struct {
+		oldCap, newLen, want int
+	}{
+		{0, 1, 1},
+		{0, 5, 5},       // 一次追加超过 2 倍：直接用 newLen
+		{4, 11, 11},     // 同上
+		{1, 2, 2},       // 256 以下翻倍
+		{100, 101, 200}, // 同上
+		{255, 256, 510}, // 分界前最后一次翻倍
+		{256, 257, 512}, // 256 + (256+768)/4，恰好也是 2 倍
+		{512, 513, 832},
+		{1024, 1025, 1472},
+		{100000, 100001, 125192}, // 很大时趋近 1.25 倍
+		{256, 1000, 1000},        // 超过 2 倍
+		{300, 500, 567},          // 一轮公式不够时会继续增长：300 → 567
+	} {
+		if got := GrowCap(tc.oldCap, tc.newLen); got != tc.want {
+			t.Errorf("GrowCap(%d, %d) = %d, want %d", tc.oldCap, tc.newLen, got, tc.want)
+		}
+	}
+
+	// 越过 256 之后，每一步的系数单调下降并趋近 1.25
+	prev := 2.0
+	for c := Threshold; c < 1<<24; c = GrowCap(c, c+1) {
+		f := float64(GrowCap(c, c+1)) / float64(c)
+		if f > prev || f < 1.25 {
+			t.Fatalf("cap %d: factor %.4f after %.4f", c, f, prev)
+		}
+		prev = f
+	}
+	if prev > 1.2501 {
+		t.Errorf("factor for very large slices = %.4f, want ~1.25", prev)
+	}
+}
+
+func TestNextCap(t *testing.T) {
+	for _, tc := range []struct {
+		oldCap, newLen, elemSize int
+		pointers                 bool
+		want                     int
+	}{
+		{0, 1, 8, false, 1},
+		{0, 1, 1, false, 8},  // 最小的内存规格是 8 字节
+		{3, 4, 8, false, 6},  // 48 字节正好是一个规格
+		{5, 6, 8, false, 10}, // 80 字节正好是一个规格
+		{256, 257, 8, false, 512},
+		{512, 513, 8, false, 848}, // 6656 字节 → 6784
+		{1024, 1025, 8, false, 1536},
+		{5, 6, 24, false, 10},            // 240 字节正好是一个规格
+		{3, 4, 0, false, 6},              // 零大小元素不分配内存
+		{4096, 4097, 8, false, 6144},     // 超过 32KB 后按页取整：5120*8 = 40960
+		{10000, 10001, 16, false, 12800}, // 12500*16 = 200000 → 25 页
+	} {
+		if got := NextCap(tc.oldCap, tc.newLen, tc.elemSize, tc.pointers); got != tc.want {
+			t.Errorf("NextCap(%d, %d, %d, %v) = %d, want %d", tc.oldCap, tc.newLen, tc.elemSize, tc.pointers, got, tc.want)
+		}
+	}
+
+	// 小对象和不含指针的对象不受分配头部影响
+	if a, b := NextCap(16, 17, 8, true), NextCap(16, 17, 8, false); a != b {
+		t.Errorf("256-byte object: with pointers %d, without %d", a, b)
+	}
+	// 超过 512 字节的含指针对象要为头部留出空间：1024 字节 + 8 → 1152 规格，剩 1144 字节
+	want := 128
+	if mallocHeaderSize > 0 {
+		want = 143
+	}
+	if got := NextCap(64, 65, 8, true); got != want {
+		t.Errorf("NextCap(64, 65, 8, pointers) = %d, want %d (malloc header %d)", got, want, mallocHeaderSize)
+	}
+}
+
+// stackBuffered 报告这次扩容是否来自编译器给不逃逸切片预留的栈上缓冲，而不是 growslice。
+func stackBuffered(r Realloc, elemSize int) bool {
+	return r.OldCap == 0 && elemSize > 0 && r.NewCap == 32/elemSize
+}
+
+// mismatches 返回除栈上缓冲以外与公式不一致的扩容。
+func mismatches(t *Trace) []string {
+	var out []string
+	for _, r := range t.Mismatches() {
+		if !stackBuffered(r, t.ElemSize) {
+			out = append(out, fmt.Sprintf("cap %d -> %d (len %d), predicted %d", r.OldCap, r.NewCap, r.Len, r.Predicted))
+		}
+	}
+	return out
+}
+
+// TestAppendMatchesFormula 用真实的 append 核对公式：覆盖 256 分界前后，
+// 以及大到按页取整、系数贴近 1.25 的容量。
+func TestAppendMatchesFormula(t *testing.T) {
+	type triple struct{ a, b, c *int }
+	traces := map[string]*Trace{
+		"byte":       Append[byte](300000),
+		"int64":      Append[int64](300000),
+		"[24]byte":   Append[[24]byte](100000),
+		"string":     Append[string](100000),
+		"*int":       Append[*int](300000),
+		"triple":     Append[triple](50000),
+		"[100]int32": Append[[100]int32](20000),
+	}
+	for _, size := range []int{8, 12, 40, 72, 1000} {
+		tr, err := AppendSize(50000, size, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		traces[fmt.Sprintf("AppendSize(%d)", size)] = tr
+	}
+	for _, size := range []int{8, 16, 64, 520} {
+		tr, err := AppendSize(50000, size, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		traces[fmt.Sprintf("AppendSize(%d, pointers)", size)] = tr
+	}
+
+	for name, tr := range traces {
+		if m := mismatches(tr); len(m) > 0 {
+			t.Errorf("%s (elem %d bytes, pointers %v): %d mismatches\n%s",
+				name, tr.ElemSize, tr.Pointers, len(m), strings.Join(m, "\n"))
+		}
+		if err := tr.Check(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if !crossesThreshold(tr) {
+			t.Errorf("%s: trace does not cross cap %d", name, Threshold)
+		}
+	}
+}
+
+func crossesThreshold(t *Trace) bool {
+	below, above := false, false
+	for _, r := range t.Reallocs {
+		below = below || (r.OldCap > 0 && r.OldCap < Threshold)
+		above = above || r.OldCap >= Threshold
+	}
+	return below && above
+}
+
+func TestGrowthFactor(t *testing.T) {
+	tr := Append[int64](1 << 20)
+	var small, large []Realloc
+	for _, r := range tr.Reallocs {
+		switch {
+		case r.OldCap > 0 && r.OldCap < Threshold:
+			small = append(small, r)
+		case r.OldCap*tr.ElemSize >= largeBytes:
+			large = append(large, r)
+		}
+	}
+	for _, r := range small {
+		if r.Factor < 2 {
+			t.Errorf("cap %d -> %d below %d: factor %.3f, want >= 2", r.OldCap, r.NewCap, Threshold, r.Factor)
+		}
+	}
+	if len(large) < 3 {
+		t.Fatalf("only %d reallocations above 1MB", len(large))
+	}
+	for _, r := range large {
+		if r.Factor < 1.25 || r.Factor > 1.27 {
+			t.Errorf("cap %d -> %d: factor %.4f, want ~1.25", r.OldCap, r.NewCap, r.Factor)
+		}
+		if !r.Moved {
+			t.Errorf("cap %d -> %d: backing array did not move", r.OldCap, r.NewCap)
+		}
+	}
+}
+
+func TestCheckReportsViolations(t *testing.T) {
+	tr := &Trace{ElemSize: 8, Reallocs: []Realloc{
+		{Len: 9, OldCap: 8, NewCap: 12, Factor: 1.5},
+		{Len: 513, OldCap: 512, NewCap: 600, Factor: 600.0 / 512},
+		{Len: 200001, OldCap: 200000, NewCap: 300000, Factor: 1.5},
+	}}
+	err := tr.Check()
+	if err == nil {
+		t.Fatal("Check accepted a trace that breaks every rule")
+	}
+	for _, want := range []string{"cap 8 -> 12", "cap 512 -> 600", "cap 200000 -> 300000"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Check error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestAppendSizeErrors(t *testing.T) {
+	if _, err := AppendSize(10, 0, false); err == nil {
+		t.Error("AppendSize accepted element size 0")
+	}
+	if _, err := AppendSize(10, 12, true); err == nil {
+		t.Error("AppendSize accepted a pointer element size that is not a multiple of the pointer size")
+	}
+	tr, err := AppendSize(10, 24, true)
+	if err != nil || tr.ElemSize != 24 || !tr.Pointers || tr.N != 10 {
+		t.Errorf("AppendSize(10, 24, true) = %+v, %v", tr, err)
+	}
+}
@@ -0,0 +1,163 @@
+package life
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cellEngine 让密集和稀疏两种实现用同一组测试。
+type cellEngine interface {
+	Step()
+	cells() []Point
+}
+
+type denseEngine struct{ *Life }
+
+func (d denseEngine) cells() []Point {
+	var out []Point
+	u := d.Universe()
+	for y := 0; y < u.Height(); y++ {
+		for x := 0; x < u.Width(); x++ {
+			if u.Alive(x, y) {
+				out = append(out, Point{x, y})
+			}
+		}
+	}
+	return out
+}
+
+type sparseEngine struct{ *Sparse }
+
+func (s sparseEngine) cells() []Point { return s.Cells() }
+
+// engines 把图案放在 (origin, origin)，返回两种实现。密集宇宙足够大，飞船在测试期间碰不到边界。
+func engines(t *testing.T, name string, origin int) map[string]cellEngine {
+	t.Helper()
+	p, err := Builtin(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := NewUniverse(64, 64, Bounded)
+	u.Place(p, origin, origin)
+	s := NewSparse()
+	s.Place(p, origin, origin)
+	return map[string]cellEngine{"dense": denseEngine{NewLife(u)}, "sparse": sparseEngine{s}}
+}
+
+func shift(cells []Point, dx, dy int) []Point {
+	out := make([]Point, len(cells))
+	for i, c := range cells {
+		out[i] = Point{c.X + dx, c.Y + dy}
+	}
+	return out
+}
+
+func TestOscillatorPeriods(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		period int
+	}{
+		{"block", 1},
+		{"blinker", 2},
+		{"toad", 2},
+		{"beacon", 2},
+		{"pulsar", 3},
+	} {
+		for kind, e := range engines(t, tc.name, 20) {
+			t.Run(tc.name+"/"+kind, func(t *testing.T) {
+				start := e.cells()
+				for gen := 1; gen <= 2*tc.period; gen++ {
+					e.Step()
+					same := reflect.DeepEqual(e.cells(), start)
+					if want := gen%tc.period == 0; same != want {
+						t.Fatalf("generation %d: equal to start = %v, want %v (period %d)", gen, same, want, tc.period)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSpaceships(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		dx, dy int // 每 4 代的位移
+	}{
+		{"glider", 1, 1},
+		{"lwss", -2, 0},
+	} {
+		for kind, e := range engines(t, tc.name, 30) {
+			t.Run(tc.name+"/"+kind, func(t *testing.T) {
+				start := e.cells()
+				for k := 1; k <= 5; k++ {
+					for i := 0; i < 4; i++ {
+						e.Step()
+					}
+					if got, want := e.cells(), shift(start, k*tc.dx, k*tc.dy); !reflect.DeepEqual(got, want) {
+						t.Fatalf("after %d generations:\ngot  %v\nwant %v", 4*k, got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestToroidalGliderWraps(t *testing.T) {
+	p, _ := Builtin("glider")
+	u := NewUniverse(8, 8, Toroidal)
+	u.Place(p, 0, 0)
+	start := u.Clone()
+	l := NewLife(u)
+	for i := 0; i < 4*8; i++ { // 8 次位移之后回到原处
+		l.Step()
+	}
+	if !l.Universe().Equal(start) {
+		t.Fatalf("glider did not wrap back to start:\n%s", l)
+	}
+}
+
+func TestDenseMatchesSparse(t *testing.T) {
+	p, _ := Builtin("r_pentomino")
+	u := NewUniverse(200, 200, Bounded)
+	u.Place(p, 100, 100)
+	l := NewLife(u)
+	s := NewSparse()
+	s.Place(p, 100, 100)
+	for gen := 1; gen <= 100; gen++ {
+		l.Step()
+		s.Step()
+		if !s.Window(Point{}, 200, 200).Equal(l.Universe()) {
+			t.Fatalf("generation %d: dense and sparse differ", gen)
+		}
+	}
+}
+
+func TestParseFormatsAgree(t *testing.T) {
+	plain, err := ParsePlaintext(strings.NewReader("!Name: Glider\n.O.\n..O\nOOO\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rle, err := ParseRLE(strings.NewReader("#N Glider\nx = 3, y = 3, rule = B3/S23\nbob$2bo$3o!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plain.Cells, rle.Cells) || plain.Name != "Glider" || rle.Name != "Glider" {
+		t.Fatalf("plaintext %v %v, rle %v %v", plain.Name, plain.Cells, rle.Name, rle.Cells)
+	}
+}
+
+func TestParseRLETags(t *testing.T) {
+	// 多状态规则中的其他字母也是活细胞
+	p, err := ParseRLE(strings.NewReader("x = 3, y = 1\nAxZ!\n"))
+	if err != nil || len(p.Cells) != 3 {
+		t.Fatalf("letters: cells %v, err %v", p, err)
+	}
+	for _, c := range "[\\]^_`" {
+		_, err := ParseRLE(strings.NewReader(fmt.Sprintf("x = 1, y = 1\n%c!\n", c)))
+		if err == nil {
+			t.Errorf("tag %q accepted", c)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package life
+
+import (
+	"runtime"
+	"sync"
+)
+
+// band 是交给一个 worker 计算的行区间 [y0, y1)，a 为当前代，b 为下一代。
+type band struct {
+	a, b   *Universe
+	y0, y1 int
+}
+
+// Parallel 把网格按行切成若干条带，交给固定数量的 goroutine 并发计算下一代。
+//
+// 每个 worker 只读 a、只写 b 中属于自己的行，条带互不重叠，所以不需要加锁；
+// Step 用 WaitGroup 等所有条带完成后再交换 a、b（双缓冲）。
+// 结果与串行的 Step 逐位相同。
+type Parallel struct {
+	a, b       *Universe
+	bands      [][2]int
+	jobs       chan band
+	wg         sync.WaitGroup
+	closeOnce  sync.Once
+	generation int
+}
+
+// NewParallel 以 u 作为第 0 代创建并行引擎，并启动 workers 个 goroutine。
+// workers <= 0 时使用 runtime.GOMAXPROCS(0)；条带数不会超过网格行数。
+// 用完后必须调用 Close 结束 worker。
+func NewParallel(u *Universe, workers int) *Parallel {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, u.height)
+	p := &Parallel{
+		a:     u,
+		b:     NewUniverse(u.width, u.height, u.topology),
+		bands: splitRows(u.height, workers),
+		jobs:  make(chan band, workers),
+	}
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// splitRows 把 height 行尽量平均地分成 n 段。
+func splitRows(height, n int) [][2]int {
+	bands := make([][2]int, 0, n)
+	y := 0
+	for i := 0; i < n; i++ {
+		size := height / n
+		if i < height%n {
+			size++
+		}
+		bands = append(bands, [2]int{y, y + size})
+		y += size
+	}
+	return bands
+}
+
+func (p *Parallel) worker() {
+	for job := range p.jobs {
+		StepRows(job.a, job.b, job.y0, job.y1)
+		p.wg.Done()
+	}
+}
+
+// Step 并发推进一代。Step 不能与其他方法并发调用。
+func (p *Parallel) Step() {
+	p.wg.Add(len(p.bands))
+	for _, r := range p.bands {
+		p.jobs <- band{a: p.a, b: p.b, y0: r[0], y1: r[1]}
+	}
+	p.wg.Wait()
+	p.a, p.b = p.b, p.a
+	p.generation++
+}
+
+// Close 结束所有 worker；之后不能再调用 Step。重复调用是安全的。
+func (p *Parallel) Close() {
+	p.closeOnce.Do(func() { close(p.jobs) })
+}
+
+// Workers 返回条带（worker）数量。
+func (p *Parallel) Workers() int { return len(p.bands) }
+
+// Universe 返回当前这一代的宇宙。
+func (p *Parallel) Universe() *Universe { return p.a }
+
+// Generation 返回已经推进的代数。
+func (p *Parallel) Generation() int { return p.generation }
+
+// Population 返回当前活细胞数量。
+func (p *Parallel) Population() int { return p.a.Population() }
+
+// String 输出当前这一代。
+func (p *Parallel) String() string { return p.a.String() }
+
+var _ Engine = (*Parallel)(nil)
@@ -0,0 +1,53 @@
+package life
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// TestParallelMatchesSerial 逐代比较并行和串行结果，应当用 go test -race ./chap20/life 运行：
+// worker 之间只共享只读的当前代，竞态检测器会报告任何越过条带边界的写入。
+func TestParallelMatchesSerial(t *testing.T) {
+	for _, topology := range []Topology{Toroidal, Bounded} {
+		start := NewUniverse(61, 37, topology) // 行数不能被大多数 worker 数整除
+		start.Seed(rand.New(rand.NewSource(1)), 0.35)
+		for _, workers := range []int{1, 2, 3, 4, 7, 16, 100} {
+			t.Run(fmt.Sprintf("%v/workers=%d", topology, workers), func(t *testing.T) {
+				serial := NewLife(start.Clone())
+				p := NewParallel(start.Clone(), workers)
+				defer p.Close()
+				if want := min(workers, start.Height()); p.Workers() != want {
+					t.Fatalf("Workers() = %d, want %d", p.Workers(), want)
+				}
+				for gen := 1; gen <= 30; gen++ {
+					serial.Step()
+					p.Step()
+					if !p.Universe().Equal(serial.Universe()) {
+						t.Fatalf("workers=%d generation %d: parallel differs from serial", workers, gen)
+					}
+				}
+				if p.Generation() != 30 || p.Population() != serial.Population() {
+					t.Fatalf("workers=%d: generation %d population %d, want 30 %d",
+						workers, p.Generation(), p.Population(), serial.Population())
+				}
+			})
+		}
+	}
+}
+
+func TestSplitRows(t *testing.T) {
+	for _, tc := range []struct{ height, n int }{{10, 1}, {10, 3}, {10, 10}, {7, 4}} {
+		bands := splitRows(tc.height, tc.n)
+		y := 0
+		for _, b := range bands {
+			if b[0] != y || b[1]-b[0] < tc.height/tc.n || b[1]-b[0] > tc.height/tc.n+1 {
+				t.Fatalf("splitRows(%d, %d) = %v", tc.height, tc.n, bands)
+			}
+			y = b[1]
+		}
+		if len(bands) != tc.n || y != tc.height {
+			t.Fatalf("splitRows(%d, %d) = %v", tc.height, tc.n, bands)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package life
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownPattern 表示内置图案库里没有这个名字。
+var ErrUnknownPattern = errors.New("life: unknown pattern")
+
+// Point 是网格上的一个坐标，X 向右、Y 向下增长。
+type Point struct {
+	X, Y int
+}
+
+// Pattern 是从图案文件读出的一组活细胞，坐标相对于图案左上角。
+type Pattern struct {
+	Name     string
+	Comments []string
+	Width    int
+	Height   int
+	Cells    []Point
+}
+
+// add 追加一个活细胞并同步更新宽高。
+func (p *Pattern) add(x, y int) {
+	p.Cells = append(p.Cells, Point{x, y})
+	p.Width = max(p.Width, x+1)
+	p.Height = max(p.Height, y+1)
+}
+
+// String 以纯文本格式输出图案。
+func (p *Pattern) String() string {
+	u := NewUniverse(max(p.Width, 1), max(p.Height, 1), Bounded)
+	u.Place(p, 0, 0)
+	return u.String()
+}
+
+// ParsePlaintext 解析 .cells 纯文本格式：
+// 以 ! 开头的是注释（!Name: 给出名称），O 或 * 表示活细胞，. 表示死细胞。
+func ParsePlaintext(r io.Reader) (*Pattern, error) {
+	p := &Pattern{}
+	sc := bufio.NewScanner(r)
+	line, y := 0, 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), " \t\r")
+		if strings.HasPrefix(text, "!") {
+			comment := strings.TrimSpace(text[1:])
+			if name, ok := strings.CutPrefix(comment, "Name:"); ok {
+				p.Name = strings.TrimSpace(name)
+			} else if comment != "" {
+				p.Comments = append(p.Comments, comment)
+			}
+			continue
+		}
+		for x, c := range []byte(text) {
+			switch c {
+			case 'O', 'o', '*':
+				p.add(x, y)
+			case '.':
+			default:
+				return nil, fmt.Errorf("life: plaintext line %d: unexpected %q", line, c)
+			}
+		}
+		p.Height = max(p.Height, y+1)
+		p.Width = max(p.Width, len(text))
+		y++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseRLE 解析 .rle 游程编码格式，例如滑翔机：
+//
+//	#N Glider
+//	x = 3, y = 3, rule = B3/S23
+//	bob$2bo$3o!
+//
+// b 表示死细胞，o 表示活细胞，$ 表示换行，! 表示结束，前缀数字是重复次数。
+// 只支持标准的 B3/S23 规则。
+func ParseRLE(r io.Reader) (*Pattern, error) {
+	p := &Pattern{}
+	sc := bufio.NewScanner(r)
+	line := 0
+	header := false
+	x, y, run := 0, 0, 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#"):
+			if len(text) < 2 {
+				continue
+			}
+			body := strings.TrimSpace(text[2:])
+			switch text[1] {
+			case 'N':
+				p.Name = body
+			case 'C', 'c':
+				p.Comments = append(p.Comments, body)
+			}
+			continue
+		case !header && strings.HasPrefix(text, "x"):
+			header = true
+			w, h, err := parseRLEHeader(text)
+			if err != nil {
+				return nil, fmt.Errorf("life: rle line %d: %w", line, err)
+			}
+			p.Width, p.Height = w, h
+			continue
+		}
+
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+			switch {
+			case c >= '0' && c <= '9':
+				run = run*10 + int(c-'0')
+				continue
+			case c == ' ' || c == '\t':
+				continue
+			}
+			n := max(run, 1)
+			run = 0
+			switch c {
+			case 'b', '.':
+				x += n
+			case '$':
+				x = 0
+				y += n
+			case '!':
+				return p, nil
+			default:
+				// 多状态规则里的其他字母也一律当作活细胞
+				if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+					return nil, fmt.Errorf("life: rle line %d: unexpected %q", line, c)
+				}
+				for k := 0; k < n; k++ {
+					p.add(x, y)
+					x++
+				}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("life: rle line %d: missing terminating '!'", line)
+}
+
+// parseRLEHeader 解析 "x = 3, y = 3, rule = B3/S23" 这样的头部。
+func parseRLEHeader(text string) (w, h int, err error) {
+	for _, field := range strings.Split(text, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return 0, 0, fmt.Errorf("malformed header field %q", field)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "x", "y":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return 0, 0, fmt.Errorf("invalid %s %q", key, value)
+			}
+			if key == "x" {
+				w = n
+			} else {
+				h = n
+			}
+		case "rule":
+			if r := strings.ToUpper(value); r != "B3/S23" && r != "23/3" {
+				return 0, 0, fmt.Errorf("unsupported rule %q", value)
+			}
+		}
+	}
+	return w, h, nil
+}
+
+// ReadPattern 根据文件扩展名（.rle 或 .cells/.txt）选择解析器。
+func ReadPattern(name string, r io.Reader) (*Pattern, error) {
+	var (
+		p   *Pattern
+		err error
+	)
+	switch ext := strings.ToLower(path.Ext(name)); ext {
+	case ".rle":
+		p, err = ParseRLE(r)
+	case ".cells", ".txt":
+		p, err = ParsePlaintext(r)
+	default:
+		return nil, fmt.Errorf("life: unsupported pattern format %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	return p, nil
+}
+
+// LoadPattern 从磁盘读取图案文件。
+func LoadPattern(filename string) (*Pattern, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadPattern(filepath.ToSlash(filename), f)
+}
+
+//go:embed patterns
+var builtinFS embed.FS
+
+// Builtin 按名字（不含扩展名，例如 "glider"、"gosper_glider_gun"）读取内置图案。
+func Builtin(name string) (*Pattern, error) {
+	for _, ext := range []string{".rle", ".cells"} {
+		f, err := builtinFS.Open("patterns/" + name + ext)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		return ReadPattern(name+ext, f)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownPattern, name)
+}
+
+// BuiltinNames 返回所有内置图案的名字（已排序）。
+func BuiltinNames() []string {
+	entries, _ := builtinFS.ReadDir("patterns")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
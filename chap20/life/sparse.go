@@ -0,0 +1,129 @@
+package life
+
+import "sort"
+
+// Sparse 是无边界的稀疏宇宙：只用集合记录活细胞。
+// 每一代的开销只和活细胞数量成正比，与宇宙大小无关，适合很大或无限的宇宙。
+type Sparse struct {
+	cells      map[Point]struct{}
+	generation int
+}
+
+// NewSparse 创建一个空的稀疏宇宙。
+func NewSparse() *Sparse {
+	return &Sparse{cells: make(map[Point]struct{})}
+}
+
+// Set 设置 (x, y) 处细胞的状态。
+func (s *Sparse) Set(x, y int, alive bool) {
+	if alive {
+		s.cells[Point{x, y}] = struct{}{}
+	} else {
+		delete(s.cells, Point{x, y})
+	}
+}
+
+// Alive 报告 (x, y) 处细胞是否存活。
+func (s *Sparse) Alive(x, y int) bool {
+	_, ok := s.cells[Point{x, y}]
+	return ok
+}
+
+// Place 把图案的左上角放到 (x, y)。
+func (s *Sparse) Place(p *Pattern, x, y int) {
+	for _, c := range p.Cells {
+		s.Set(x+c.X, y+c.Y, true)
+	}
+}
+
+// Step 推进一代：先给每个活细胞的 8 个邻居计数，再按规则生成新集合。
+func (s *Sparse) Step() {
+	counts := make(map[Point]int, len(s.cells)*8)
+	for p := range s.cells {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					counts[Point{p.X + dx, p.Y + dy}]++
+				}
+			}
+		}
+	}
+	next := make(map[Point]struct{}, len(s.cells))
+	for p, n := range counts {
+		_, alive := s.cells[p]
+		if rule(alive, n) {
+			next[p] = struct{}{}
+		}
+	}
+	s.cells = next
+	s.generation++
+}
+
+// Generation 返回已经推进的代数。
+func (s *Sparse) Generation() int { return s.generation }
+
+// Population 返回活细胞数量。
+func (s *Sparse) Population() int { return len(s.cells) }
+
+// Cells 返回所有活细胞，按先行后列排序。
+func (s *Sparse) Cells() []Point {
+	cells := make([]Point, 0, len(s.cells))
+	for p := range s.cells {
+		cells = append(cells, p)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+	return cells
+}
+
+// Bounds 返回包住所有活细胞的最小矩形 [lo, hi]；空宇宙时 ok 为 false。
+func (s *Sparse) Bounds() (lo, hi Point, ok bool) {
+	for p := range s.cells {
+		if !ok {
+			lo, hi, ok = p, p, true
+			continue
+		}
+		lo.X, lo.Y = min(lo.X, p.X), min(lo.Y, p.Y)
+		hi.X, hi.Y = max(hi.X, p.X), max(hi.Y, p.Y)
+	}
+	return lo, hi, ok
+}
+
+// Window 把以 origin 为左上角、大小为 width×height 的区域复制到一个固定边界的 Universe，
+// 便于和 Universe 比较或在终端里显示。
+func (s *Sparse) Window(origin Point, width, height int) *Universe {
+	u := NewUniverse(width, height, Bounded)
+	for p := range s.cells {
+		x, y := p.X-origin.X, p.Y-origin.Y
+		if x >= 0 && x < width && y >= 0 && y < height {
+			u.cells[y][x] = true
+		}
+	}
+	return u
+}
+
+// String 以纯文本格式输出包住所有活细胞的最小矩形。
+func (s *Sparse) String() string {
+	lo, hi, ok := s.Bounds()
+	if !ok {
+		return ""
+	}
+	return s.Window(lo, hi.X-lo.X+1, hi.Y-lo.Y+1).String()
+}
+
+// Engine 是终端播放器需要的最小接口，*Life 和 *Sparse 都实现了它。
+type Engine interface {
+	Step()
+	Generation() int
+	Population() int
+	String() string
+}
+
+var (
+	_ Engine = (*Life)(nil)
+	_ Engine = (*Sparse)(nil)
+)
@@ -0,0 +1,246 @@
+// Package life 实现康威生命游戏（第20章切片综合练习）。
+//
+// Universe 用二维切片 [][]bool 表示一块有限网格，边界既可以环绕（Toroidal），
+// 也可以是固定边界（Bounded）；Sparse 用集合只记录活细胞，适合很大的宇宙。
+package life
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Topology 决定网格边界的处理方式。
+type Topology int
+
+const (
+	// Toroidal 环形宇宙：越过右边界回到左边界，越过下边界回到上边界。
+	Toroidal Topology = iota
+	// Bounded 固定边界：网格外的细胞永远是死的。
+	Bounded
+)
+
+// String 返回拓扑名称。
+func (t Topology) String() string {
+	switch t {
+	case Toroidal:
+		return "toroidal"
+	case Bounded:
+		return "bounded"
+	}
+	return "unknown"
+}
+
+// Universe 是一块 width×height 的细胞网格，cells[y][x] 为 true 表示活细胞。
+type Universe struct {
+	width, height int
+	topology      Topology
+	cells         [][]bool
+}
+
+// NewUniverse 创建一个全部为死细胞的宇宙。
+// 所有行共享同一个底层数组，这样 Clone 和 Step 只需要一次内存分配。
+func NewUniverse(width, height int, topology Topology) *Universe {
+	if width <= 0 || height <= 0 {
+		panic("life: universe size must be positive")
+	}
+	backing := make([]bool, width*height)
+	cells := make([][]bool, height)
+	for y := range cells {
+		cells[y] = backing[y*width : (y+1)*width : (y+1)*width]
+	}
+	return &Universe{width: width, height: height, topology: topology, cells: cells}
+}
+
+// Width 返回宇宙宽度。
+func (u *Universe) Width() int { return u.width }
+
+// Height 返回宇宙高度。
+func (u *Universe) Height() int { return u.height }
+
+// Topology 返回边界类型。
+func (u *Universe) Topology() Topology { return u.topology }
+
+// Row 返回第 y 行的切片视图（不复制），越界时返回 nil。
+func (u *Universe) Row(y int) []bool {
+	if y < 0 || y >= u.height {
+		return nil
+	}
+	return u.cells[y]
+}
+
+// wrap 把坐标映射回网格内；固定边界下越界返回 ok=false。
+func (u *Universe) wrap(x, y int) (int, int, bool) {
+	if u.topology == Bounded {
+		if x < 0 || x >= u.width || y < 0 || y >= u.height {
+			return 0, 0, false
+		}
+		return x, y, true
+	}
+	x = ((x % u.width) + u.width) % u.width
+	y = ((y % u.height) + u.height) % u.height
+	return x, y, true
+}
+
+// Set 设置 (x, y) 处细胞的状态；固定边界下越界的写入会被忽略。
+func (u *Universe) Set(x, y int, alive bool) {
+	if x, y, ok := u.wrap(x, y); ok {
+		u.cells[y][x] = alive
+	}
+}
+
+// Alive 报告 (x, y) 处细胞是否存活。
+func (u *Universe) Alive(x, y int) bool {
+	x, y, ok := u.wrap(x, y)
+	return ok && u.cells[y][x]
+}
+
+// Neighbors 统计 (x, y) 周围 8 个邻居中的活细胞数量。
+func (u *Universe) Neighbors(x, y int) int {
+	n := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if (dx != 0 || dy != 0) && u.Alive(x+dx, y+dy) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Next 按 B3/S23 规则计算 (x, y) 处细胞在下一代的状态。
+func (u *Universe) Next(x, y int) bool {
+	return rule(u.Alive(x, y), u.Neighbors(x, y))
+}
+
+// rule 是生命游戏的 B3/S23 规则：
+// 活细胞有 2 或 3 个邻居时存活，死细胞恰好有 3 个邻居时诞生。
+func rule(alive bool, neighbors int) bool {
+	return neighbors == 3 || (alive && neighbors == 2)
+}
+
+// Seed 以 density（0～1）的概率随机放置活细胞。
+func (u *Universe) Seed(r *rand.Rand, density float64) {
+	for y := range u.cells {
+		for x := range u.cells[y] {
+			u.cells[y][x] = r.Float64() < density
+		}
+	}
+}
+
+// Clear 把所有细胞置为死亡。
+func (u *Universe) Clear() {
+	for y := range u.cells {
+		clear(u.cells[y])
+	}
+}
+
+// Place 把图案的左上角放到 (x, y)。
+func (u *Universe) Place(p *Pattern, x, y int) {
+	for _, c := range p.Cells {
+		u.Set(x+c.X, y+c.Y, true)
+	}
+}
+
+// Population 返回活细胞总数。
+func (u *Universe) Population() int {
+	n := 0
+	for y := range u.cells {
+		for _, alive := range u.cells[y] {
+			if alive {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Clone 返回一个独立的副本（深拷贝底层数组）。
+func (u *Universe) Clone() *Universe {
+	c := NewUniverse(u.width, u.height, u.topology)
+	for y := range u.cells {
+		copy(c.cells[y], u.cells[y])
+	}
+	return c
+}
+
+// Equal 报告两个宇宙的尺寸、拓扑和每个细胞是否完全一致。
+func (u *Universe) Equal(o *Universe) bool {
+	if u.width != o.width || u.height != o.height || u.topology != o.topology {
+		return false
+	}
+	for y := range u.cells {
+		for x := range u.cells[y] {
+			if u.cells[y][x] != o.cells[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String 以纯文本格式（活细胞 O，死细胞 .）输出整个宇宙。
+func (u *Universe) String() string {
+	var b strings.Builder
+	b.Grow((u.width + 1) * u.height)
+	for y := range u.cells {
+		for _, alive := range u.cells[y] {
+			if alive {
+				b.WriteByte('O')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Step 根据 a 的状态计算下一代并写入 b（双缓冲，a 不会被修改）。
+// a 和 b 必须尺寸相同。
+func Step(a, b *Universe) {
+	StepRows(a, b, 0, a.height)
+}
+
+// StepRows 只计算 [y0, y1) 行的下一代写入 b，其余行保持不变。
+// 不同的行区间互不重叠，可以分给不同的 goroutine 并发计算。
+func StepRows(a, b *Universe, y0, y1 int) {
+	if a.width != b.width || a.height != b.height {
+		panic("life: universes must have the same size")
+	}
+	for y := y0; y < y1; y++ {
+		row := b.cells[y]
+		for x := range row {
+			row[x] = a.Next(x, y)
+		}
+	}
+}
+
+// Life 持有两个宇宙并在每一代之间交换，避免反复分配内存。
+type Life struct {
+	a, b       *Universe
+	generation int
+}
+
+// NewLife 以 u 作为第 0 代创建一局游戏；u 之后归 Life 所有。
+func NewLife(u *Universe) *Life {
+	return &Life{a: u, b: NewUniverse(u.width, u.height, u.topology)}
+}
+
+// Step 推进一代。
+func (l *Life) Step() {
+	Step(l.a, l.b)
+	l.a, l.b = l.b, l.a
+	l.generation++
+}
+
+// Universe 返回当前这一代的宇宙。
+func (l *Life) Universe() *Universe { return l.a }
+
+// Generation 返回已经推进的代数。
+func (l *Life) Generation() int { return l.generation }
+
+// Population 返回当前活细胞数量。
+func (l *Life) Population() int { return l.a.Population() }
+
+// String 输出当前这一代。
+func (l *Life) String() string { return l.a.String() }
@@ -0,0 +1,124 @@
+// 独立运行：go run ./chap20/life_bench -width 1024 -height 1024 -generations 50
+// 竞态检测：go test -race ./chap20/life（并行与串行逐代比较），或 go run -race ./chap20/life_bench -width 128 -height 128 -generations 20
+// 演示：串行 Step 与按行分条带的并行引擎对比，报告不同 GOMAXPROCS、worker 数量下每秒能算多少代，
+// 并逐位校验并行结果与串行结果一致（配合第30章 worker pool、第31章 sync 阅读）。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"books/chap20/life"
+)
+
+func main() {
+	var (
+		width       = flag.Int("width", 512, "宇宙宽度")
+		height      = flag.Int("height", 512, "宇宙高度")
+		generations = flag.Int("generations", 100, "每组测量运行的代数")
+		seed        = flag.Int64("seed", 1, "随机播种使用的种子")
+		procs       = flag.String("procs", "", "逗号分隔的 GOMAXPROCS 列表；为空时取 1,2,4,… 直到 CPU 数")
+		workers     = flag.String("workers", "", "逗号分隔的 worker 数量列表；为空时与 GOMAXPROCS 相同")
+	)
+	flag.Parse()
+
+	procList, err := parseList(*procs, powersOfTwo(runtime.NumCPU()))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	workerList, err := parseList(*workers, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	start := life.NewUniverse(*width, *height, life.Toroidal)
+	start.Seed(rand.New(rand.NewSource(*seed)), 0.3)
+
+	// 串行结果作为基准，同时用于校验并行结果
+	serial := life.NewLife(start.Clone())
+	elapsed := run(serial, *generations)
+	base := rate(*generations, elapsed)
+	fmt.Printf("universe %dx%d, %d generations, NumCPU=%d\n\n", *width, *height, *generations, runtime.NumCPU())
+	fmt.Printf("%-10s %-10s %-8s %12s %8s %s\n", "engine", "GOMAXPROCS", "workers", "gen/s", "speedup", "identical")
+	fmt.Printf("%-10s %-10d %-8d %12.1f %8.2f %v\n", "serial", runtime.GOMAXPROCS(0), 1, base, 1.0, true)
+
+	identical := true
+	for _, n := range procList {
+		runtime.GOMAXPROCS(n)
+		ws := workerList
+		if len(ws) == 0 {
+			ws = []int{n}
+		}
+		for _, w := range ws {
+			p := life.NewParallel(start.Clone(), w)
+			elapsed := run(p, *generations)
+			same := p.Universe().Equal(serial.Universe())
+			p.Close()
+			r := rate(*generations, elapsed)
+			fmt.Printf("%-10s %-10d %-8d %12.1f %8.2f %v\n", "parallel", n, p.Workers(), r, speedup(r, base), same)
+			identical = identical && same
+		}
+	}
+	if !identical {
+		fmt.Fprintln(os.Stderr, "parallel result differs from serial result")
+		os.Exit(1)
+	}
+}
+
+// run 推进 n 代并返回耗时。
+func run(e life.Engine, n int) time.Duration {
+	begin := time.Now()
+	for i := 0; i < n; i++ {
+		e.Step()
+	}
+	return time.Since(begin)
+}
+
+// rate 把耗时换算成每秒代数；计时器精度不够、耗时为 0 时返回 0，而不是 +Inf。
+func rate(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+// speedup 返回 r 相对 base 的倍数，base 为 0 时返回 0。
+func speedup(r, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return r / base
+}
+
+// powersOfTwo 返回 1,2,4,… 直到 limit（limit 本身一定包含在内）。
+func powersOfTwo(limit int) []int {
+	var list []int
+	for n := 1; n < limit; n *= 2 {
+		list = append(list, n)
+	}
+	return append(list, limit)
+}
+
+// parseList 解析 "1,2,4" 这样的正整数列表；s 为空时返回 def。
+func parseList(s string, def []int) ([]int, error) {
+	if s == "" {
+		return def, nil
+	}
+	var list []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid list element %q", field)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
@@ -0,0 +1,119 @@
+// 独立运行：go run ./chap20/life_play -pattern gosper_glider_gun
+// 无界面模式：go run ./chap20/life_play -pattern glider -generations 4 -dump
+// 演示：用 chap20/life 在终端里播放生命游戏，用 ANSI 转义序列原地重绘每一代。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"books/chap20/life"
+)
+
+func main() {
+	var (
+		pattern     = flag.String("pattern", "", "内置图案名或 .rle/.cells 文件路径；为空时随机播种")
+		width       = flag.Int("width", 80, "宇宙宽度（grid 引擎）")
+		height      = flag.Int("height", 24, "宇宙高度（grid 引擎）")
+		bounded     = flag.Bool("bounded", false, "使用固定边界而不是环形宇宙（grid 引擎）")
+		engine      = flag.String("engine", "grid", "引擎：grid（二维切片）、parallel（按行分条带并发）或 sparse（稀疏集合，无边界）")
+		workers     = flag.Int("workers", 0, "parallel 引擎的 worker 数量；0 表示 GOMAXPROCS")
+		generations = flag.Int("generations", 0, "运行多少代；0 表示一直播放")
+		delay       = flag.Duration("delay", 100*time.Millisecond, "每一代之间的间隔")
+		seed        = flag.Int64("seed", time.Now().UnixNano(), "随机播种使用的种子")
+		density     = flag.Float64("density", 0.25, "随机播种时活细胞的比例")
+		dump        = flag.Bool("dump", false, "无界面模式：直接计算到最后一代并输出纯文本")
+		list        = flag.Bool("list", false, "列出所有内置图案")
+	)
+	flag.Parse()
+
+	if *list {
+		fmt.Println(strings.Join(life.BuiltinNames(), "\n"))
+		return
+	}
+
+	e, err := newEngine(*engine, *pattern, *width, *height, *bounded, *seed, *density, *workers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if p, ok := e.(*life.Parallel); ok {
+		defer p.Close()
+	}
+
+	if *dump {
+		for e.Generation() < *generations {
+			e.Step()
+		}
+		fmt.Printf("#generation %d population %d\n", e.Generation(), e.Population())
+		fmt.Print(e.String())
+		return
+	}
+
+	fmt.Print("\033[2J\033[?25l") // 清屏并隐藏光标
+	defer fmt.Print("\033[?25h")
+	for {
+		fmt.Print("\033[H") // 光标回到左上角，原地重绘
+		fmt.Printf("generation %d  population %d\033[K\n", e.Generation(), e.Population())
+		fmt.Print(e.String())
+		if *generations > 0 && e.Generation() >= *generations {
+			return
+		}
+		time.Sleep(*delay)
+		e.Step()
+	}
+}
+
+// newEngine 根据命令行参数创建引擎并放置初始图案。
+func newEngine(kind, pattern string, width, height int, bounded bool, seed int64, density float64, workers int) (life.Engine, error) {
+	var p *life.Pattern
+	if pattern != "" {
+		var err error
+		if strings.ContainsAny(pattern, "./\\") {
+			p, err = life.LoadPattern(pattern)
+		} else {
+			p, err = life.Builtin(pattern)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch kind {
+	case "grid", "parallel":
+		topology := life.Toroidal
+		if bounded {
+			topology = life.Bounded
+		}
+		u := life.NewUniverse(width, height, topology)
+		if p != nil {
+			// 图案居中放置
+			u.Place(p, (width-p.Width)/2, (height-p.Height)/2)
+		} else {
+			u.Seed(rand.New(rand.NewSource(seed)), density)
+		}
+		if kind == "parallel" {
+			return life.NewParallel(u, workers), nil
+		}
+		return life.NewLife(u), nil
+	case "sparse":
+		s := life.NewSparse()
+		if p == nil {
+			// 稀疏宇宙没有边界，随机播种只在 width×height 的区域里进行
+			r := rand.New(rand.NewSource(seed))
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					s.Set(x, y, r.Float64() < density)
+				}
+			}
+		} else {
+			s.Place(p, 0, 0)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown engine %q", kind)
+}
@@ -0,0 +1,156 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDMS 表示度分秒字符串无法解析。
+var ErrInvalidDMS = errors.New("geo: invalid DMS")
+
+// Axis 表示一个角度是纬度还是经度。
+type Axis int
+
+const (
+	// AnyAxis 表示没有半球字母，无法判断是纬度还是经度。
+	AnyAxis Axis = iota
+	// Latitude 纬度（N/S）。
+	Latitude
+	// Longitude 经度（E/W）。
+	Longitude
+)
+
+// ParseDMS 解析度分秒或十进制度字符串，例如：
+//
+//	4°35'22.2" S
+//	137°26′30.1″E
+//	-4.5895
+//	N 51 30 2.5
+//
+// 返回带符号的十进制度（南纬、西经为负）以及由半球字母推断出的轴。
+func ParseDMS(s string) (float64, Axis, error) {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return 0, AnyAxis, fmt.Errorf("%w: empty string", ErrInvalidDMS)
+	}
+
+	sign, axis := 1.0, AnyAxis
+	hemisphere := func(c byte) bool {
+		switch c {
+		case 'N', 'n':
+			axis = Latitude
+		case 'S', 's':
+			sign, axis = -1, Latitude
+		case 'E', 'e':
+			axis = Longitude
+		case 'W', 'w':
+			sign, axis = -1, Longitude
+		default:
+			return false
+		}
+		return true
+	}
+	if hemisphere(text[len(text)-1]) {
+		text = text[:len(text)-1]
+	} else if hemisphere(text[0]) {
+		text = text[1:]
+	}
+
+	// 把各种度、分、秒符号统一替换成空格，再按空白切分
+	fields := strings.Fields(strings.NewReplacer(
+		"°", " ", "º", " ", "'", " ", "′", " ", "\"", " ", "″", " ",
+	).Replace(text))
+	if len(fields) == 0 || len(fields) > 3 {
+		return 0, AnyAxis, fmt.Errorf("%w: %q", ErrInvalidDMS, s)
+	}
+
+	var parts [3]float64
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return 0, AnyAxis, fmt.Errorf("%w: %q", ErrInvalidDMS, s)
+		}
+		parts[i] = v
+	}
+	if parts[0] < 0 {
+		if axis != AnyAxis {
+			return 0, AnyAxis, fmt.Errorf("%w: %q has both a sign and a hemisphere", ErrInvalidDMS, s)
+		}
+		sign, parts[0] = -1, -parts[0]
+	}
+	if (len(fields) > 1 && parts[0] != math.Trunc(parts[0])) ||
+		parts[1] < 0 || parts[1] >= 60 || parts[2] < 0 || parts[2] >= 60 {
+		return 0, AnyAxis, fmt.Errorf("%w: %q", ErrInvalidDMS, s)
+	}
+	return sign * (parts[0] + parts[1]/60 + parts[2]/3600), axis, nil
+}
+
+// ParseCoordinate 把纬度和经度两个字符串解析为 Coordinate，并检查半球字母和取值范围。
+func ParseCoordinate(lat, long string) (Coordinate, error) {
+	la, axis, err := ParseDMS(lat)
+	if err != nil {
+		return Coordinate{}, err
+	}
+	if axis == Longitude {
+		return Coordinate{}, fmt.Errorf("%w: latitude %q uses E/W", ErrInvalidDMS, lat)
+	}
+	lo, axis, err := ParseDMS(long)
+	if err != nil {
+		return Coordinate{}, err
+	}
+	if axis == Latitude {
+		return Coordinate{}, fmt.Errorf("%w: longitude %q uses N/S", ErrInvalidDMS, long)
+	}
+	c := Coordinate{Lat: la, Long: lo}
+	if !c.Valid() {
+		return Coordinate{}, fmt.Errorf("geo: coordinate %v out of range", c)
+	}
+	return c, nil
+}
+
+// FormatDMS 把十进制度格式化为度分秒，例如 -4.5895 纬度 → 4°35'22.2" S。
+// precision 是秒的小数位数。
+func FormatDMS(degrees float64, axis Axis, precision int) string {
+	hemi := ""
+	switch axis {
+	case Latitude:
+		hemi = " N"
+		if degrees < 0 {
+			hemi = " S"
+		}
+	case Longitude:
+		hemi = " E"
+		if degrees < 0 {
+			hemi = " W"
+		}
+	}
+	sign := ""
+	if axis == AnyAxis && degrees < 0 {
+		sign = "-"
+	}
+
+	// 先按秒四舍五入，避免出现 59.99" 进位后变成 60"
+	scale := math.Pow(10, float64(precision))
+	total := math.Round(math.Abs(degrees)*3600*scale) / scale
+	d := math.Floor(total / 3600)
+	m := math.Floor((total - d*3600) / 60)
+	sec := total - d*3600 - m*60
+	width := 2 // 秒的整数部分固定两位
+	if precision > 0 {
+		width += precision + 1
+	}
+	return fmt.Sprintf("%s%.0f°%02.0f'%0*.*f\"%s", sign, d, m, width, precision, sec, hemi)
+}
+
+// DMS 以度分秒输出坐标，例如 4°35'22.2" S, 137°26'30.1" E。
+func (c Coordinate) DMS() string {
+	return FormatDMS(c.Lat, Latitude, 1) + ", " + FormatDMS(normalizeLong(c.Long), Longitude, 1)
+}
+
+// Decimal 以固定小数位的十进制度输出坐标，例如 -4.589500, 137.441700。
+func (c Coordinate) Decimal(precision int) string {
+	return strconv.FormatFloat(c.Lat, 'f', precision, 64) + ", " + strconv.FormatFloat(c.Long, 'f', precision, 64)
+}
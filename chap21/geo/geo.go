@@ -0,0 +1,192 @@
+// Package geo 在球面和椭球面上计算坐标之间的距离（替代第21章 distance 的平面近似）。
+//
+// chap21/structs.go 里的 distance 原先把经纬度差当作平面坐标，再乘以 111 公里，
+// 只在赤道附近、短距离时勉强可用。这里提供：
+//   - Haversine：把天体当作半径为 Radius 的球；
+//   - Vincenty / Inverse：把天体当作扁率为 Flattening 的椭球，精度到毫米级，Inverse 还给出两端的方位角；
+//   - 初始方位角、给定方位角和距离求终点、两点的中点；
+//   - 度分秒（DMS）解析和格式化、GeoJSON 编码。
+//
+// 所有角度都以度为单位，所有距离都以公里为单位。
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Coordinate 是天体表面的一点，Lat 为纬度（北正南负），Long 为经度（东正西负，也接受 0～360）。
+type Coordinate struct {
+	Lat  float64 `json:"lat"`
+	Long float64 `json:"long"`
+}
+
+// String 以十进制度输出坐标。
+func (c Coordinate) String() string {
+	return fmt.Sprintf("(%.4f, %.4f)", c.Lat, c.Long)
+}
+
+// Valid 报告纬度是否在 [-90, 90] 内、经度是否在 [-180, 360] 内。
+func (c Coordinate) Valid() bool {
+	return c.Lat >= -90 && c.Lat <= 90 && c.Long >= -180 && c.Long <= 360 &&
+		!math.IsNaN(c.Lat) && !math.IsNaN(c.Long)
+}
+
+// Normalize 把经度规范到 (-180, 180]。
+func (c Coordinate) Normalize() Coordinate {
+	c.Long = normalizeLong(c.Long)
+	return c
+}
+
+// Body 描述一个天体的形状。
+type Body struct {
+	Name string
+	// Radius 是平均半径（公里），Haversine 和 Destination 使用。
+	Radius float64
+	// SemiMajor 是赤道半径（公里），Flattening 是扁率 (a-b)/a，Vincenty 使用。
+	SemiMajor  float64
+	Flattening float64
+}
+
+var (
+	// Earth 使用 WGS-84 椭球和 IUGG 平均半径。
+	Earth = Body{Name: "Earth", Radius: 6371.0088, SemiMajor: 6378.137, Flattening: 1 / 298.257223563}
+	// Mars 使用 IAU 参考椭球（赤道半径 3396.19 公里，极半径 3376.20 公里）。
+	Mars = Body{Name: "Mars", Radius: 3389.5, SemiMajor: 3396.19, Flattening: (3396.19 - 3376.20) / 3396.19}
+)
+
+// Sphere 返回半径为 radius 公里的理想球体。
+func Sphere(name string, radius float64) Body {
+	return Body{Name: name, Radius: radius, SemiMajor: radius}
+}
+
+// ErrNoConvergence 表示 Vincenty 迭代没有收敛（两点几乎位于对跖点）。
+var ErrNoConvergence = errors.New("geo: vincenty formula failed to converge")
+
+func rad(deg float64) float64 { return deg * math.Pi / 180 }
+func deg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// normalizeLong 把经度规范到 (-180, 180]。
+func normalizeLong(long float64) float64 {
+	if long > -180 && long <= 180 {
+		return long
+	}
+	long = math.Mod(long+180, 360)
+	if long <= 0 {
+		long += 360
+	}
+	return long - 180
+}
+
+// Haversine 用半正矢公式计算两点之间的大圆距离（公里）。
+func (b Body) Haversine(p1, p2 Coordinate) float64 {
+	lat1, lat2 := rad(p1.Lat), rad(p2.Lat)
+	dLat := lat2 - lat1
+	dLong := rad(p2.Long - p1.Long)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * b.Radius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Vincenty 用 Vincenty 反解公式计算椭球面上的测地线距离（公里）。
+// 两点接近对跖点时可能不收敛，此时返回 ErrNoConvergence，可以退回 Haversine。
+func (b Body) Vincenty(p1, p2 Coordinate) (float64, error) {
+	g, err := b.Inverse(p1, p2)
+	return g.Distance, err
+}
+
+// Geodesic 是椭球面上两点之间的测地线。
+type Geodesic struct {
+	Distance       float64 // 公里
+	InitialBearing float64 // 在起点的方位角（度，正北为 0，顺时针 [0, 360)）
+	FinalBearing   float64 // 到达终点时的前进方向；反方位角是它加减 180°
+}
+
+// Inverse 用 Vincenty 反解公式同时求出测地线距离和两端的方位角。
+// 与 Bearing 不同，方位角是椭球面上的结果。重合的两点距离为 0、方位角为 0。
+func (b Body) Inverse(p1, p2 Coordinate) (Geodesic, error) {
+	a := b.SemiMajor
+	f := b.Flattening
+	bb := a * (1 - f)
+
+	L := rad(p2.Long - p1.Long)
+	U1 := math.Atan((1 - f) * math.Tan(rad(p1.Lat)))
+	U2 := math.Atan((1 - f) * math.Tan(rad(p2.Lat)))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	lambda := L
+	var sinLambda, cosLambda, sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	for i := 0; ; i++ {
+		if i == 200 {
+			return Geodesic{}, ErrNoConvergence
+		}
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return Geodesic{}, nil // 重合的两点
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 { // 两点都在赤道上时 cos2Alpha 为 0
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		C := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = L + (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < 1e-12 {
+			break
+		}
+	}
+
+	uSq := cos2Alpha * (a*a - bb*bb) / (bb * bb)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	sinLambda, cosLambda = math.Sincos(lambda)
+	alpha1 := math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+	alpha2 := math.Atan2(cosU1*sinLambda, -sinU1*cosU2+cosU1*sinU2*cosLambda)
+	return Geodesic{
+		Distance:       bb * A * (sigma - deltaSigma),
+		InitialBearing: math.Mod(deg(alpha1)+360, 360),
+		FinalBearing:   math.Mod(deg(alpha2)+360, 360),
+	}, nil
+}
+
+// Bearing 返回从 p1 出发沿大圆前往 p2 的初始方位角（度，正北为 0，顺时针 [0, 360)）。
+// 这是球面模型的结果，与椭球面上的测地线方位角可能相差零点几度。
+func Bearing(p1, p2 Coordinate) float64 {
+	lat1, lat2 := rad(p1.Lat), rad(p2.Lat)
+	dLong := rad(p2.Long - p1.Long)
+	y := math.Sin(dLong) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLong)
+	return math.Mod(deg(math.Atan2(y, x))+360, 360)
+}
+
+// Destination 返回从 p 出发、沿初始方位角 bearing 走过 distance 公里后到达的点（球面模型）。
+func (b Body) Destination(p Coordinate, bearing, distance float64) Coordinate {
+	delta := distance / b.Radius
+	theta := rad(bearing)
+	lat1, long1 := rad(p.Lat), rad(p.Long)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	long2 := long1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1),
+		math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	return Coordinate{Lat: deg(lat2), Long: normalizeLong(deg(long2))}
+}
+
+// Midpoint 返回两点之间大圆路径的中点。
+func Midpoint(p1, p2 Coordinate) Coordinate {
+	lat1, lat2 := rad(p1.Lat), rad(p2.Lat)
+	long1 := rad(p1.Long)
+	dLong := rad(p2.Long - p1.Long)
+	bx := math.Cos(lat2) * math.Cos(dLong)
+	by := math.Cos(lat2) * math.Sin(dLong)
+	lat := math.Atan2(math.Sin(lat1)+math.Sin(lat2), math.Hypot(math.Cos(lat1)+bx, by))
+	long := long1 + math.Atan2(by, math.Cos(lat1)+bx)
+	return Coordinate{Lat: deg(lat), Long: normalizeLong(deg(long))}
+}
@@ -0,0 +1,231 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+// dms 把度分秒换算成十进制度，负号作用于整个角度。
+func dms(d, m, s float64) float64 {
+	v := math.Abs(d) + m/60 + s/3600
+	if d < 0 {
+		return -v
+	}
+	return v
+}
+
+func near(got, want, tol float64) bool { return math.Abs(got-want) <= tol }
+
+// angleNear 比较两个方位角，考虑 0°/360° 处的环绕。
+func angleNear(got, want, tol float64) bool {
+	d := math.Mod(math.Abs(got-want), 360)
+	return math.Min(d, 360-d) <= tol
+}
+
+// Geoscience Australia 发布的 Vincenty 算例（GDA94 / GRS80，与 WGS-84 在毫米级一致）：
+// Flinders Peak → Buninyong，距离 54 972.271 米，方位角 306°52′05.37″，反方位角 127°10′25.07″。
+var (
+	flindersPeak = Coordinate{Lat: dms(-37, 57, 3.72030), Long: dms(144, 25, 29.52440)}
+	buninyong    = Coordinate{Lat: dms(-37, 39, 10.15610), Long: dms(143, 55, 35.38390)}
+)
+
+func TestVincentyReference(t *testing.T) {
+	g, err := Earth.Inverse(flindersPeak, buninyong)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(g.Distance, 54.972271, 0.000001) {
+		t.Errorf("distance = %.6f km, want 54.972271", g.Distance)
+	}
+	if want := dms(306, 52, 5.37); !angleNear(g.InitialBearing, want, 0.01/3600) {
+		t.Errorf("initial bearing = %s, want 306°52'05.37\"", FormatDMS(g.InitialBearing, AnyAxis, 2))
+	}
+	if want := dms(127, 10, 25.07) + 180; !angleNear(g.FinalBearing, want, 0.01/3600) {
+		t.Errorf("final bearing = %s, want reverse azimuth 127°10'25.07\"", FormatDMS(g.FinalBearing, AnyAxis, 2))
+	}
+	d, err := Earth.Vincenty(buninyong, flindersPeak)
+	if err != nil || !near(d, g.Distance, 1e-9) {
+		t.Errorf("reverse direction: %v, %v", d, err)
+	}
+}
+
+func TestVincentyEdgeCases(t *testing.T) {
+	p := Coordinate{Lat: 10, Long: 20}
+	if d, err := Earth.Vincenty(p, p); d != 0 || err != nil {
+		t.Errorf("same point: %v, %v", d, err)
+	}
+	// 赤道上的两点：cos²α 为 0 的分支。赤道一度的弧长是 a·π/180
+	d, err := Earth.Vincenty(Coordinate{0, 0}, Coordinate{0, 1})
+	if want := Earth.SemiMajor * math.Pi / 180; err != nil || !near(d, want, 1e-9) {
+		t.Errorf("equator: %v km, want %v (%v)", d, want, err)
+	}
+	// 子午线上极点到极点是子午圈长度的一半：WGS-84 为 20 003.931 458 6 公里
+	d, err = Earth.Vincenty(Coordinate{90, 0}, Coordinate{-90, 0})
+	if err != nil || !near(d, 20003.9314586, 1e-6) {
+		t.Errorf("pole to pole: %v km (%v)", d, err)
+	}
+	// 几乎对跖的两点：迭代不收敛
+	if _, err := Earth.Vincenty(Coordinate{0, 0}, Coordinate{0.5, 179.7}); !errors.Is(err, ErrNoConvergence) {
+		t.Errorf("near-antipodal: err = %v, want ErrNoConvergence", err)
+	}
+	// 扁率为 0 的球体上 Vincenty 与 Haversine 一致
+	s := Sphere("ball", 1000)
+	a, b := Coordinate{12, 34}, Coordinate{-56, 78}
+	if v, err := s.Vincenty(a, b); err != nil || !near(v, s.Haversine(a, b), 1e-6) {
+		t.Errorf("sphere: vincenty %v, haversine %v (%v)", v, s.Haversine(a, b), err)
+	}
+}
+
+func TestHaversineReference(t *testing.T) {
+	// Rosetta Code 的算例：纳什维尔 BNA → 洛杉矶 LAX，半径 6372.8 公里时为 2887.26 公里
+	bna := Coordinate{Lat: 36.12, Long: -86.67}
+	lax := Coordinate{Lat: 33.94, Long: -118.40}
+	if d := Sphere("earth", 6372.8).Haversine(bna, lax); !near(d, 2887.26, 0.01) {
+		t.Errorf("BNA→LAX = %.2f km, want 2887.26", d)
+	}
+	// 经度 0～360 与 -180～180 的写法结果相同
+	if d1, d2 := Earth.Haversine(bna, lax), Earth.Haversine(bna, Coordinate{33.94, 360 - 118.40}); !near(d1, d2, 1e-9) {
+		t.Errorf("long 241.6 vs -118.4: %v vs %v", d2, d1)
+	}
+}
+
+// 球面公式的参考值来自 Chris Veness 的 “Calculate distance, bearing and more between Latitude/Longitude points”
+// （movable-type.co.uk），半径 6371 公里。
+func TestSphericalReference(t *testing.T) {
+	earth := Sphere("earth", 6371)
+	landsEnd := Coordinate{Lat: dms(50, 3, 59), Long: dms(-5, 42, 53)}
+	johnOGroats := Coordinate{Lat: dms(58, 38, 38), Long: dms(-3, 4, 12)}
+
+	if d := earth.Haversine(landsEnd, johnOGroats); !near(d, 968.9, 0.05) {
+		t.Errorf("distance = %.2f km, want 968.9", d)
+	}
+	if b := Bearing(landsEnd, johnOGroats); !angleNear(b, dms(9, 7, 11), 1.0/3600) {
+		t.Errorf("bearing = %s, want 009°07'11\"", FormatDMS(b, AnyAxis, 0))
+	}
+	mid := Midpoint(landsEnd, johnOGroats)
+	if !near(mid.Lat, dms(54, 21, 44), 1.0/3600) || !near(mid.Long, dms(-4, 31, 50), 1.0/3600) {
+		t.Errorf("midpoint = %s, want 54°21'44\" N, 004°31'50\" W", mid.DMS())
+	}
+
+	start := Coordinate{Lat: dms(53, 19, 14), Long: dms(-1, 43, 47)}
+	dest := earth.Destination(start, dms(96, 1, 18), 124.8)
+	if !near(dest.Lat, dms(53, 11, 18), 1.0/3600) || !near(dest.Long, dms(0, 8, 0), 1.0/3600) {
+		t.Errorf("destination = %s, want 53°11'18\" N, 0°08'00\" E", dest.DMS())
+	}
+
+	// Destination 与 Bearing、Haversine 互为逆运算
+	back := earth.Destination(landsEnd, Bearing(landsEnd, johnOGroats), earth.Haversine(landsEnd, johnOGroats))
+	if !near(back.Lat, johnOGroats.Lat, 1e-9) || !near(back.Long, johnOGroats.Long, 1e-9) {
+		t.Errorf("destination round trip = %v, want %v", back, johnOGroats)
+	}
+	// 跨越日界线时经度规范到 (-180, 180]
+	if p := earth.Destination(Coordinate{0, 179.5}, 90, 111.2); p.Long > -179 || p.Long < -180 {
+		t.Errorf("across the antimeridian: %v", p)
+	}
+}
+
+func TestParseDMS(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want float64
+		axis Axis
+	}{
+		{`4°35'22.2" S`, -dms(4, 35, 22.2), Latitude},
+		{`137°26′30.1″E`, dms(137, 26, 30.1), Longitude},
+		{`N 51 30 2.5`, dms(51, 30, 2.5), Latitude},
+		{`77°W`, -77, Longitude},
+		{`-4.5895`, -4.5895, AnyAxis},
+		{` 12.5 `, 12.5, AnyAxis},
+		{`0°0'0"N`, 0, Latitude},
+	} {
+		got, axis, err := ParseDMS(tc.in)
+		if err != nil || !near(got, tc.want, 1e-12) || axis != tc.axis {
+			t.Errorf("ParseDMS(%q) = %v, %v, %v; want %v, %v", tc.in, got, axis, err, tc.want, tc.axis)
+		}
+	}
+	for _, in := range []string{``, `S`, `abc`, `-4°35' S`, `4°60'`, `4°35'60"`, `4.5°30'`, `1 2 3 4`, `4°-5'`} {
+		if _, _, err := ParseDMS(in); !errors.Is(err, ErrInvalidDMS) {
+			t.Errorf("ParseDMS(%q): err = %v, want ErrInvalidDMS", in, err)
+		}
+	}
+}
+
+func TestFormatDMS(t *testing.T) {
+	for _, tc := range []struct {
+		deg       float64
+		axis      Axis
+		precision int
+		want      string
+	}{
+		{-dms(4, 35, 22.2), Latitude, 1, `4°35'22.2" S`},
+		{dms(137, 26, 30.1), Longitude, 1, `137°26'30.1" E`},
+		{-77, Longitude, 0, `77°00'00" W`},
+		{dms(306, 52, 5.37), AnyAxis, 2, `306°52'05.37"`},
+		{-0.5, AnyAxis, 0, `-0°30'00"`},
+		{dms(9, 59, 59.96), Latitude, 1, `10°00'00.0" N`}, // 秒进位到分、分进位到度
+	} {
+		if got := FormatDMS(tc.deg, tc.axis, tc.precision); got != tc.want {
+			t.Errorf("FormatDMS(%v, %v, %d) = %s, want %s", tc.deg, tc.axis, tc.precision, got, tc.want)
+		}
+	}
+	// 往返：格式化后再解析得到同一个角度（精确到所保留的小数位）
+	for _, s := range []string{`4°35'22.2" S`, `137°26'30.1" E`, `37°57'03.7" S`, `0°00'00.1" N`} {
+		v, axis, err := ParseDMS(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatDMS(v, axis, 1); got != s {
+			t.Errorf("round trip %s → %v → %s", s, v, got)
+		}
+	}
+}
+
+func TestParseCoordinate(t *testing.T) {
+	c, err := ParseCoordinate(`4°35'22.2" S`, `137°26'30.1" E`)
+	if err != nil || c.DMS() != `4°35'22.2" S, 137°26'30.1" E` {
+		t.Errorf("ParseCoordinate = %v (%s), %v", c, c.DMS(), err)
+	}
+	if c.Decimal(4) != "-4.5895, 137.4417" {
+		t.Errorf("Decimal(4) = %s", c.Decimal(4))
+	}
+	for _, tc := range [][2]string{{`10 E`, `20 E`}, {`10 N`, `20 S`}, {`91`, `0`}, {`0`, `-181`}} {
+		if _, err := ParseCoordinate(tc[0], tc[1]); err == nil {
+			t.Errorf("ParseCoordinate(%q, %q) accepted", tc[0], tc[1])
+		}
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	gale := Coordinate{Lat: -4.5895, Long: 137.4417}
+	data, err := gale.MarshalGeoJSON()
+	if want := `{"type":"Point","coordinates":[137.4417,-4.5895]}`; err != nil || string(data) != want {
+		t.Errorf("MarshalGeoJSON = %s, %v; want %s", data, err, want)
+	}
+	// 经度 350 输出为 -10
+	line := LineString(Coordinate{1, 350}, Coordinate{2, 3})
+	if string(line.Coordinates) != `[[-10,1],[3,2]]` || line.Type != "LineString" {
+		t.Errorf("LineString = %s %s", line.Type, line.Coordinates)
+	}
+
+	fc := NewFeatureCollection(NewFeature(gale.Point(), map[string]any{"name": "Gale"}), NewFeature(line, nil))
+	data, err = json.Marshal(fc)
+	want := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[137.4417,-4.5895]},"properties":{"name":"Gale"}},` +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[-10,1],[3,2]]},"properties":{}}]}`
+	if err != nil || string(data) != want {
+		t.Errorf("FeatureCollection =\n%s\nwant\n%s", data, want)
+	}
+	if data, _ := json.Marshal(NewFeatureCollection()); string(data) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("empty collection = %s", data)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[float64]float64{180: 180, -180: 180, 190: -170, 360: 0, -190: 170, 540: 180} {
+		if got := (Coordinate{0, in}).Normalize().Long; !near(got, want, 1e-12) {
+			t.Errorf("Normalize(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
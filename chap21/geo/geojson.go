@@ -0,0 +1,64 @@
+package geo
+
+import "encoding/json"
+
+// Geometry 是 GeoJSON（RFC 7946）几何对象。注意 GeoJSON 的坐标顺序是 [经度, 纬度]。
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Feature 是带属性的 GeoJSON 要素。
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// FeatureCollection 是一组要素。
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// position 返回 GeoJSON 的 [经度, 纬度]，经度规范到 (-180, 180]。
+func (c Coordinate) position() [2]float64 {
+	return [2]float64{normalizeLong(c.Long), c.Lat}
+}
+
+// Point 返回该坐标的 GeoJSON Point 几何。
+func (c Coordinate) Point() Geometry {
+	data, _ := json.Marshal(c.position())
+	return Geometry{Type: "Point", Coordinates: data}
+}
+
+// LineString 返回依次连接各坐标的 GeoJSON LineString 几何。
+func LineString(coords ...Coordinate) Geometry {
+	positions := make([][2]float64, len(coords))
+	for i, c := range coords {
+		positions[i] = c.position()
+	}
+	data, _ := json.Marshal(positions)
+	return Geometry{Type: "LineString", Coordinates: data}
+}
+
+// NewFeature 用几何和属性创建要素；properties 为 nil 时输出 {}。
+func NewFeature(g Geometry, properties map[string]any) Feature {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	return Feature{Type: "Feature", Geometry: g, Properties: properties}
+}
+
+// NewFeatureCollection 把多个要素组合成集合。
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// MarshalGeoJSON 把坐标编码为 GeoJSON Point，例如 {"type":"Point","coordinates":[137.4417,-4.5895]}。
+func (c Coordinate) MarshalGeoJSON() ([]byte, error) {
+	return json.Marshal(c.Point())
+}
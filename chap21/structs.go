@@ -7,7 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
+
+	"books/chap21/geo"
 )
 
 // ============================================
@@ -277,7 +278,7 @@ func main() {
 	coord := Coordinate{-4.5895, 137.4417}
 	fmt.Printf("  坐标: %+v\n", coord)
 	fmt.Printf("  字符串表示: %s\n", coord.String())
-	fmt.Printf("  距离原点的距离: %.2f\n", coord.DistanceFromOrigin())
+	fmt.Printf("  距离原点的距离: %.2f 公里\n", coord.DistanceFromOrigin())
 	fmt.Println()
 
 	// ============================================
@@ -381,11 +382,10 @@ func main() {
 // ============================================
 
 // distance 计算两个坐标之间的距离（使用结构简化参数）
+// 两个着陆点都在火星上，用 geo 包按火星半径计算大圆距离（公里）
 func distance(p1, p2 Coordinate) float64 {
-	// 简化的距离计算（实际应该使用更精确的公式）
-	latDiff := p1.Lat - p2.Lat
-	longDiff := p1.Long - p2.Long
-	return math.Sqrt(latDiff*latDiff + longDiff*longDiff) * 111.0 // 粗略转换为公里
+	// 字段相同的结构类型可以直接转换
+	return geo.Mars.Haversine(geo.Coordinate(p1), geo.Coordinate(p2))
 }
 
 // String 为 Coordinate 实现 Stringer 接口
@@ -393,9 +393,9 @@ func (c Coordinate) String() string {
 	return fmt.Sprintf("(%.4f, %.4f)", c.Lat, c.Long)
 }
 
-// DistanceFromOrigin 计算距离原点的距离
+// DistanceFromOrigin 计算到经纬度原点 (0, 0) 的火星表面距离（公里）
 func (c Coordinate) DistanceFromOrigin() float64 {
-	return math.Sqrt(c.Lat*c.Lat + c.Long*c.Long)
+	return distance(c, Coordinate{})
 }
 
 // modifyCoordinate 修改坐标（值传递，不会改变原值）
@@ -0,0 +1,139 @@
+// Package middleware 是基于 net/http 的中间件链和小型路由（第22章 Handler/Logger 组合的实战版本）。
+//
+// chap22 用 Handler 接口、Logger 结构体演示“组合优于继承”，chap23 用 MiddlewareChain、
+// LoggingMiddleware、Router 演示转发。这里把同样的思路落到 http.Handler 上：
+//
+//	chain := middleware.NewChain(
+//		middleware.RequestID(),
+//		middleware.Logging(logger),
+//		middleware.Recover(logger),
+//		middleware.Timeout(2*time.Second),
+//	)
+//	router := middleware.NewRouter()
+//	router.HandleFunc(http.MethodGet, "/users/:id", showUser)
+//	http.ListenAndServe(":8080", chain.Then(router))
+//
+// 每个中间件都是 func(http.Handler) http.Handler，写在前面的位于最外层。
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Middleware 包装一个 http.Handler，在它前后加入额外的处理。
+type Middleware func(http.Handler) http.Handler
+
+// Chain 是一串按顺序执行的中间件。Chain 是不可变的，Append 返回新的 Chain，
+// 因此可以在一条公共链的基础上为不同路由扩展出不同的链。
+type Chain struct {
+	mws []Middleware
+}
+
+// NewChain 用给定的中间件创建链，mws[0] 最先执行（位于最外层）。
+func NewChain(mws ...Middleware) Chain {
+	return Chain{mws: append([]Middleware(nil), mws...)}
+}
+
+// Append 返回在 c 之后追加了 mws 的新链，c 本身不变。
+func (c Chain) Append(mws ...Middleware) Chain {
+	out := make([]Middleware, 0, len(c.mws)+len(mws))
+	out = append(out, c.mws...)
+	out = append(out, mws...)
+	return Chain{mws: out}
+}
+
+// Extend 返回先执行 c、再执行 other 的新链。
+func (c Chain) Extend(other Chain) Chain {
+	return c.Append(other.mws...)
+}
+
+// Then 把链应用到 h 上；h 为 nil 时使用 http.DefaultServeMux。
+func (c Chain) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		h = c.mws[i](h)
+	}
+	return h
+}
+
+// ThenFunc 等价于 Then(http.HandlerFunc(fn))。
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	if fn == nil {
+		return c.Then(nil)
+	}
+	return c.Then(fn)
+}
+
+// Handler 与 chap22 的 Handler 接口相同：只返回一段文本。
+type Handler interface {
+	Handle() string
+}
+
+// Adapt 把 chap22 风格的 Handler 适配为 http.Handler，以 text/plain 输出 Handle 的结果。
+func Adapt(h Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, h.Handle())
+	})
+}
+
+// statusWriter 记录写出的状态码和字节数，供 Logging 和 Recover 使用。
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// wrap 返回 w 的 statusWriter；w 已经是 statusWriter 时直接复用。
+func wrap(w http.ResponseWriter) *statusWriter {
+	if sw, ok := w.(*statusWriter); ok {
+		return sw
+	}
+	return &statusWriter{ResponseWriter: w}
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Status 返回已写出的状态码；还没写出时返回 0。
+func (w *statusWriter) Status() int { return w.status }
+
+// Unwrap 让 http.ResponseController 能找到底层的 ResponseWriter。
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// Flush 实现 http.Flusher，便于流式响应穿过中间件。
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker，便于 WebSocket 等协议升级穿过中间件。
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("middleware: underlying ResponseWriter does not support hijacking")
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions 配置跨域资源共享。
+type CORSOptions struct {
+	// AllowedOrigins 是允许的来源，例如 https://example.com；"*" 表示任意来源。
+	AllowedOrigins []string
+	// AllowedMethods 为空时允许 GET、HEAD、POST。
+	AllowedMethods []string
+	// AllowedHeaders 是预检请求中允许的请求头；为空时回显预检请求所要求的头。
+	AllowedHeaders []string
+	// ExposedHeaders 是浏览器脚本可以读取的响应头。
+	ExposedHeaders []string
+	// AllowCredentials 允许携带 Cookie；此时不会回 "*"，而是回显具体来源。
+	AllowCredentials bool
+	// MaxAge 是预检结果的缓存时间，0 表示不发送 Access-Control-Max-Age。
+	MaxAge time.Duration
+}
+
+// CORS 为跨域请求添加 Access-Control-* 响应头。预检请求（带 Access-Control-Request-Method
+// 的 OPTIONS）直接回 204，不再交给后面的处理器；来源不被允许时不添加任何 CORS 头。
+func CORS(opts CORSOptions) Middleware {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	anyOrigin := false
+	origins := make(map[string]bool)
+	for _, o := range opts.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+		}
+		origins[strings.ToLower(o)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+			if origin == "" || !(anyOrigin || origins[strings.ToLower(origin)]) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					h.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			if allowHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", allowHeaders)
+			} else if req := r.Header.Get("Access-Control-Request-Headers"); req != "" {
+				h.Set("Access-Control-Allow-Headers", req)
+			}
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge/time.Second)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
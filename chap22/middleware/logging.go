@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Logging 在请求结束后记录一行访问日志：方法、路径、状态码、字节数、耗时和请求 ID。
+// logger 为 nil 时使用 slog.Default()。
+func Logging(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := wrap(w)
+			defer func() {
+				status := sw.status
+				if status == 0 {
+					status = http.StatusOK // 处理器什么都没写，net/http 默认回 200
+				}
+				attrs := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Int64("bytes", sw.bytes),
+					slog.Duration("duration", time.Since(start)),
+				}
+				if id, ok := RequestIDFrom(r.Context()); ok {
+					attrs = append(attrs, slog.String("request_id", id))
+				}
+				logger.LogAttrs(r.Context(), levelFor(status), "request", attrs...)
+			}()
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+func levelFor(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Recover 捕获处理器中的 panic，记录错误和调用栈，并在响应尚未写出时返回 500。
+// http.ErrAbortHandler 会被原样重新抛出，net/http 用它来中止响应而不打印日志。
+// logger 为 nil 时使用 slog.Default()。
+func Recover(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := wrap(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				attrs := []slog.Attr{
+					slog.String("panic", fmt.Sprint(v)),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("stack", string(debug.Stack())),
+				}
+				if id, ok := RequestIDFrom(r.Context()); ok {
+					attrs = append(attrs, slog.String("request_id", id))
+				}
+				logger.LogAttrs(r.Context(), slog.LevelError, "panic recovered", attrs...)
+				if sw.status == 0 {
+					http.Error(sw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer 让处理器 goroutine 和测试同时写日志时没有竞态。
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func testRouter() *Router {
+	router := NewRouter()
+	router.HandleFunc(http.MethodGet, "/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "root")
+	})
+	router.HandleFunc(http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s", URLParam(r, "id"))
+	})
+	router.HandleFunc(http.MethodPut, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "put %s", URLParam(r, "id"))
+	})
+	router.HandleFunc(http.MethodGet, "/users/me", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "current user")
+	})
+	router.HandleFunc(http.MethodGet, "/users/:id/posts/:post", func(w http.ResponseWriter, r *http.Request) {
+		ps := ParamsFrom(r.Context())
+		fmt.Fprintf(w, "user %s post %s", ps.Get("id"), ps.Get("post"))
+	})
+	router.HandleFunc(http.MethodGet, "/static/*path", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "file %q", URLParam(r, "path"))
+	})
+	router.HandleFunc(http.MethodGet, "/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	})
+	router.HandleFunc(http.MethodGet, "/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			fmt.Fprint(w, "finally")
+		case <-r.Context().Done():
+		}
+	})
+	router.HandleFunc(http.MethodGet, "/id", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := RequestIDFrom(r.Context())
+		fmt.Fprint(w, id)
+	})
+	return router
+}
+
+// testApp 与 middleware_demo 的链相同。
+func testApp(log *syncBuffer) http.Handler {
+	logger := slog.New(slog.NewTextHandler(log, nil))
+	return NewChain(RequestID(), Logging(logger), Recover(logger)).Append(
+		CORS(CORSOptions{
+			AllowedOrigins: []string{"https://example.com"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			MaxAge:         10 * time.Minute,
+		}),
+		Timeout(50*time.Millisecond),
+	).Then(testRouter())
+}
+
+func serve(h http.Handler, method, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRouter(t *testing.T) {
+	app := testApp(&syncBuffer{})
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		body         string
+		allow        string
+	}{
+		{http.MethodGet, "/", 200, "root", ""},
+		{http.MethodGet, "/users/me", 200, "current user", ""}, // 静态段优先于参数段
+		{http.MethodGet, "/users/42", 200, "user 42", ""},
+		{http.MethodPut, "/users/42", 200, "put 42", ""},
+		{http.MethodGet, "/users/42/posts/7", 200, "user 42 post 7", ""},
+		{http.MethodGet, "/static/css/site.css", 200, `file "css/site.css"`, ""},
+		{http.MethodGet, "/static", 200, `file ""`, ""},
+		{http.MethodHead, "/users/42", 200, "user 42", ""}, // HEAD 使用 GET 的处理器
+		{http.MethodGet, "/nope", 404, "404 page not found", ""},
+		{http.MethodGet, "/users", 404, "404 page not found", ""},
+		{http.MethodGet, "/users/42/posts", 404, "404 page not found", ""},
+		{http.MethodPost, "/users/42", 405, "Method Not Allowed", "GET, HEAD, PUT"},
+		{http.MethodDelete, "/", 405, "Method Not Allowed", "GET, HEAD"},
+	} {
+		rec := serve(app, tc.method, tc.path)
+		if body := strings.TrimSpace(rec.Body.String()); rec.Code != tc.status || body != tc.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tc.method, tc.path, rec.Code, body, tc.status, tc.body)
+		}
+		if got := rec.Header().Get("Allow"); got != tc.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", tc.method, tc.path, got, tc.allow)
+		}
+	}
+}
+
+func TestRouterConflicts(t *testing.T) {
+	for _, patterns := range [][]string{
+		{"/users/:id", "/users/:name"},
+		{"/a", "/a"},
+		{"/static/*path/x"},
+		{"users"},
+		{"/users/:"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering %v did not panic", patterns)
+				}
+			}()
+			rt := NewRouter()
+			for _, p := range patterns {
+				rt.HandleFunc(http.MethodGet, p, func(http.ResponseWriter, *http.Request) {})
+			}
+		}()
+	}
+
+	rt := NewRouter()
+	rt.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "custom", http.StatusTeapot)
+	})
+	if rec := serve(rt, http.MethodGet, "/x"); rec.Code != http.StatusTeapot {
+		t.Errorf("custom NotFound: %d", rec.Code)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	log := &syncBuffer{}
+	app := testApp(log)
+	rec := serve(app, http.MethodGet, "/panic", RequestIDHeader, "trace-panic")
+	if rec.Code != http.StatusInternalServerError || strings.TrimSpace(rec.Body.String()) != "Internal Server Error" {
+		t.Errorf("panic: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(RequestIDHeader) != "trace-panic" {
+		t.Errorf("request ID lost after panic: %q", rec.Header().Get(RequestIDHeader))
+	}
+	out := log.String()
+	for _, want := range []string{`msg="panic recovered"`, `panic="something went wrong"`, "request_id=trace-panic", "status=500", "level=ERROR"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %s:\n%s", want, out)
+		}
+	}
+
+	// http.ErrAbortHandler 原样抛出
+	h := Recover(slog.New(slog.NewTextHandler(&syncBuffer{}, nil)))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	serve(h, http.MethodGet, "/")
+}
+
+func TestTimeout(t *testing.T) {
+	app := testApp(&syncBuffer{})
+	start := time.Now()
+	rec := serve(app, http.MethodGet, "/slow")
+	if rec.Code != http.StatusServiceUnavailable || strings.TrimSpace(rec.Body.String()) != "request timed out" {
+		t.Errorf("slow: %d %q", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("timeout took %v", elapsed)
+	}
+
+	// 在截止时间内完成的处理器：状态码、头部和正文原样复制
+	h := Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("no deadline on request context")
+		}
+		w.Header().Set("X-Test", "1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "made")
+	}))
+	rec = serve(h, http.MethodGet, "/")
+	if rec.Code != http.StatusCreated || rec.Body.String() != "made" || rec.Header().Get("X-Test") != "1" {
+		t.Errorf("fast handler: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	// 处理器 goroutine 中的 panic 带回来，外层 Recover 返回 500
+	rec = serve(app, http.MethodGet, "/panic")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("panic under Timeout: %d", rec.Code)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	app := testApp(&syncBuffer{})
+
+	rec := serve(app, http.MethodGet, "/id", RequestIDHeader, "trace-abc")
+	if rec.Header().Get(RequestIDHeader) != "trace-abc" || rec.Body.String() != "trace-abc" {
+		t.Errorf("incoming ID: header %q, handler saw %q", rec.Header().Get(RequestIDHeader), rec.Body.String())
+	}
+
+	seen := map[string]bool{}
+	for _, incoming := range []string{"", "has space", strings.Repeat("x", 129), "bad\x01"} {
+		rec := serve(app, http.MethodGet, "/id", RequestIDHeader, incoming)
+		id := rec.Header().Get(RequestIDHeader)
+		if len(id) != 32 || strings.Trim(id, "0123456789abcdef") != "" || rec.Body.String() != id || seen[id] {
+			t.Errorf("incoming %q: generated %q, handler saw %q", incoming, id, rec.Body.String())
+		}
+		seen[id] = true
+	}
+}
+
+func TestCORS(t *testing.T) {
+	app := testApp(&syncBuffer{})
+
+	rec := serve(app, http.MethodOptions, "/users/1",
+		"Origin", "https://example.com", "Access-Control-Request-Method", "POST", "Access-Control-Request-Headers", "X-Token")
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "X-Token", // 没有配置 AllowedHeaders 时回显
+		"Access-Control-Max-Age":       "600",
+	}
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Errorf("preflight: %d %q", rec.Code, rec.Body.String())
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("preflight %s = %q, want %q", k, got, v)
+		}
+	}
+	if vary := strings.Join(rec.Header().Values("Vary"), ", "); vary != "Origin, Access-Control-Request-Method, Access-Control-Request-Headers" {
+		t.Errorf("preflight Vary = %q", vary)
+	}
+
+	rec = serve(app, http.MethodGet, "/users/1", "Origin", "https://example.com")
+	if rec.Code != 200 || rec.Body.String() != "user 1" || rec.Header().Get("Access-Control-Allow-Origin") != "https://example.com" ||
+		rec.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Errorf("simple request: %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	for _, header := range [][]string{
+		{"Origin", "https://evil.example"},
+		{"Origin", "https://evil.example", "Access-Control-Request-Method", "POST"},
+		{},
+	} {
+		method := http.MethodGet
+		if len(header) > 2 {
+			method = http.MethodOptions
+		}
+		rec := serve(app, method, "/users/1", header...)
+		if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s %v: got CORS headers %v", method, header, rec.Header())
+		}
+		if method == http.MethodOptions && rec.Code != http.StatusNoContent {
+			t.Errorf("disallowed preflight: %d", rec.Code)
+		}
+	}
+
+	// 任意来源：不带凭证时回 *，带凭证时回显来源
+	ok := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	rec = serve(CORS(CORSOptions{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X-Request-ID"}})(ok),
+		http.MethodGet, "/", "Origin", "https://a.example")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" || rec.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+		t.Errorf("any origin: %v", rec.Header())
+	}
+	rec = serve(CORS(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})(ok),
+		http.MethodGet, "/", "Origin", "https://a.example")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://a.example" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("credentials: %v", rec.Header())
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var trace []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trace = append(trace, name+">")
+				next.ServeHTTP(w, r)
+				trace = append(trace, "<"+name)
+			})
+		}
+	}
+	base := NewChain(mark("a"), mark("b"))
+	extended := base.Append(mark("c"))
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { trace = append(trace, "h") })
+
+	serve(extended.Then(h), http.MethodGet, "/")
+	if got := strings.Join(trace, " "); got != "a> b> c> h <c <b <a" {
+		t.Errorf("order = %s", got)
+	}
+	trace = nil
+	serve(base.Then(h), http.MethodGet, "/")
+	if got := strings.Join(trace, " "); got != "a> b> h <b <a" {
+		t.Errorf("Append changed the base chain: %s", got)
+	}
+	trace = nil
+	serve(NewChain(mark("x")).Extend(base).Then(h), http.MethodGet, "/")
+	if got := strings.Join(trace, " "); got != "x> a> b> h <b <a <x" {
+		t.Errorf("Extend order = %s", got)
+	}
+}
+
+func TestLoggingStatus(t *testing.T) {
+	log := &syncBuffer{}
+	app := testApp(log)
+	serve(app, http.MethodGet, "/users/1", RequestIDHeader, "r1")
+	serve(app, http.MethodGet, "/nope", RequestIDHeader, "r2")
+	out := log.String()
+	for _, want := range []string{
+		"level=INFO msg=request method=GET path=/users/1 status=200 bytes=6",
+		"level=WARN msg=request method=GET path=/nope status=404",
+		"request_id=r1", "request_id=r2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+}
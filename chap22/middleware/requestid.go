@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader 是读取和回写请求 ID 的头部。
+const RequestIDHeader = "X-Request-ID"
+
+type ctxKey string
+
+const requestIDKey ctxKey = "request_id"
+
+// WithRequestID 返回携带请求 ID 的 ctx，用法同 10-context_pkg 里的 WithTraceID。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFrom 从 ctx 中取出请求 ID。
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// RequestID 为每个请求分配 ID：请求头里已有合法的 X-Request-ID 时沿用（便于跨服务传递），
+// 否则生成 16 字节随机十六进制串。ID 写入 ctx 并回写到响应头。
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// validRequestID 只接受 1～128 个可打印 ASCII 字符，避免把任意内容带进日志。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
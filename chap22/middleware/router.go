@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Router 是按路径段组织的前缀树路由，支持三种段：
+//
+//	/users          静态段
+//	/users/:id      参数段，匹配一个非空段
+//	/static/*path   通配段，匹配剩余的全部路径（只能出现在末尾）
+//
+// 同一位置上静态段优先于参数段，参数段优先于通配段，匹配失败时会回溯。
+// 路径匹配但方法不对时返回 405 并设置 Allow 头；HEAD 没有单独注册时使用 GET 的处理器。
+type Router struct {
+	root *node
+	// NotFound 处理没有匹配的路径，为 nil 时使用 http.NotFound。
+	NotFound http.Handler
+}
+
+type node struct {
+	static   map[string]*node
+	param    *node
+	wildcard *node
+	name     string // 参数段或通配段的参数名
+	pattern  string // 注册时的完整模式，用于报告冲突
+	handlers map[string]http.Handler
+}
+
+// NewRouter 创建空路由。
+func NewRouter() *Router {
+	return &Router{root: &node{}}
+}
+
+// Handle 为 method 和 pattern 注册处理器。pattern 必须以 / 开头；
+// 与已有路由冲突（重复注册、同一位置的参数名不同、通配段不在末尾）时 panic，
+// 与 http.ServeMux 的做法一致，这类错误应当在启动时暴露。
+func (rt *Router) Handle(method, pattern string, h http.Handler) {
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("middleware: pattern %q must begin with /", pattern))
+	}
+	if h == nil {
+		panic(fmt.Sprintf("middleware: nil handler for %s %s", method, pattern))
+	}
+	segs := split(pattern)
+	n := rt.root
+	for i, seg := range segs {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			name := seg[1:]
+			if name == "" {
+				panic(fmt.Sprintf("middleware: empty parameter name in %q", pattern))
+			}
+			if n.param == nil {
+				n.param = &node{name: name}
+			} else if n.param.name != name {
+				panic(fmt.Sprintf("middleware: parameter :%s in %q conflicts with :%s", name, pattern, n.param.name))
+			}
+			n = n.param
+		case strings.HasPrefix(seg, "*"):
+			name := seg[1:]
+			if i != len(segs)-1 {
+				panic(fmt.Sprintf("middleware: wildcard must be the last segment in %q", pattern))
+			}
+			if n.wildcard == nil {
+				n.wildcard = &node{name: name}
+			} else if n.wildcard.name != name {
+				panic(fmt.Sprintf("middleware: wildcard *%s in %q conflicts with *%s", name, pattern, n.wildcard.name))
+			}
+			n = n.wildcard
+		default:
+			if n.static == nil {
+				n.static = make(map[string]*node)
+			}
+			child, ok := n.static[seg]
+			if !ok {
+				child = &node{}
+				n.static[seg] = child
+			}
+			n = child
+		}
+	}
+	if n.handlers == nil {
+		n.handlers = make(map[string]http.Handler)
+	}
+	if _, dup := n.handlers[method]; dup {
+		panic(fmt.Sprintf("middleware: %s %s conflicts with %s", method, pattern, n.pattern))
+	}
+	n.pattern = pattern
+	n.handlers[method] = h
+}
+
+// HandleFunc 等价于 Handle(method, pattern, http.HandlerFunc(fn))。
+func (rt *Router) HandleFunc(method, pattern string, fn http.HandlerFunc) {
+	rt.Handle(method, pattern, fn)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n, params := rt.root.match(split(r.URL.Path), nil)
+	if n == nil {
+		if rt.NotFound != nil {
+			rt.NotFound.ServeHTTP(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+	h := n.handlers[r.Method]
+	if h == nil && r.Method == http.MethodHead {
+		h = n.handlers[http.MethodGet]
+	}
+	if h == nil {
+		w.Header().Set("Allow", n.allow())
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if len(params) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), paramsKey, params))
+	}
+	h.ServeHTTP(w, r)
+}
+
+// match 在 n 下查找能匹配 segs 且注册了处理器的节点。
+func (n *node) match(segs []string, params Params) (*node, Params) {
+	if len(segs) == 0 {
+		if len(n.handlers) > 0 {
+			return n, params
+		}
+		// /static/*path 也匹配 /static
+		if n.wildcard != nil && len(n.wildcard.handlers) > 0 {
+			return n.wildcard, append(params, Param{n.wildcard.name, ""})
+		}
+		return nil, nil
+	}
+	seg, rest := segs[0], segs[1:]
+	if child, ok := n.static[seg]; ok {
+		if m, p := child.match(rest, params); m != nil {
+			return m, p
+		}
+	}
+	if n.param != nil && seg != "" {
+		if m, p := n.param.match(rest, append(params, Param{n.param.name, seg})); m != nil {
+			return m, p
+		}
+	}
+	if n.wildcard != nil && len(n.wildcard.handlers) > 0 {
+		return n.wildcard, append(params, Param{n.wildcard.name, strings.Join(segs, "/")})
+	}
+	return nil, nil
+}
+
+// allow 返回 Allow 头的值，方法按字母排序。
+func (n *node) allow() string {
+	methods := make([]string, 0, len(n.handlers)+1)
+	for m := range n.handlers {
+		methods = append(methods, m)
+	}
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+// split 把 /a/b/c 切成 [a b c]；根路径 / 得到空切片，末尾的 / 保留为一个空段。
+func split(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// Param 是一个路径参数。
+type Param struct {
+	Key, Value string
+}
+
+// Params 是按模式中出现顺序排列的路径参数。
+type Params []Param
+
+// Get 返回名为 key 的参数值，不存在时返回空字符串。
+func (ps Params) Get(key string) string {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+const paramsKey ctxKey = "route_params"
+
+// ParamsFrom 返回 Router 写入 ctx 的路径参数。
+func ParamsFrom(ctx context.Context) Params {
+	ps, _ := ctx.Value(paramsKey).(Params)
+	return ps
+}
+
+// URLParam 返回请求中名为 key 的路径参数，例如 /users/:id 中的 id。
+func URLParam(r *http.Request, key string) string {
+	return ParamsFrom(r.Context()).Get(key)
+}
@@ -0,0 +1,112 @@
+// 独立运行：go run ./chap22/middleware_demo
+// 演示：中间件链 + 前缀树路由，全部用 httptest 在内存中完成，不监听端口。
+// 逐项断言状态码、头部和正文的测试见 go test ./chap22/middleware。
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"books/chap22/middleware"
+)
+
+// MyHandler 同 chap22/no_classes_composition.go。
+type MyHandler struct {
+	name string
+}
+
+func (h *MyHandler) Handle() string {
+	return fmt.Sprintf("Handler %s processed request", h.name)
+}
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" || a.Key == "stack" {
+				return slog.Attr{} // 去掉不稳定的字段，让输出可以对照
+			}
+			return a
+		},
+	}))
+
+	router := middleware.NewRouter()
+	router.Handle(http.MethodGet, "/", middleware.Adapt(&MyHandler{name: "API Handler"}))
+	router.HandleFunc(http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s\n", middleware.URLParam(r, "id"))
+	})
+	router.HandleFunc(http.MethodGet, "/users/me", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "current user")
+	})
+	router.HandleFunc(http.MethodGet, "/users/:id/posts/:post", func(w http.ResponseWriter, r *http.Request) {
+		ps := middleware.ParamsFrom(r.Context())
+		fmt.Fprintf(w, "user %s post %s\n", ps.Get("id"), ps.Get("post"))
+	})
+	router.HandleFunc(http.MethodGet, "/static/*path", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "file %q\n", middleware.URLParam(r, "path"))
+	})
+	router.HandleFunc(http.MethodGet, "/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	})
+	router.HandleFunc(http.MethodGet, "/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			fmt.Fprintln(w, "finally")
+		case <-r.Context().Done():
+		}
+	})
+
+	base := middleware.NewChain(
+		middleware.RequestID(),
+		middleware.Logging(logger),
+		middleware.Recover(logger),
+	)
+	app := base.Append(
+		middleware.CORS(middleware.CORSOptions{
+			AllowedOrigins: []string{"https://example.com"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			MaxAge:         10 * time.Minute,
+		}),
+		middleware.Timeout(50*time.Millisecond),
+	).Then(router)
+
+	do := func(title, method, path string, header ...string) {
+		req := httptest.NewRequest(method, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		body, _ := io.ReadAll(rec.Body)
+		fmt.Printf("--- %s: %s %s → %d %q\n", title, method, path, rec.Code, strings.TrimSpace(string(body)))
+		for _, k := range []string{"Allow", "Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Max-Age"} {
+			if v := rec.Header().Get(k); v != "" {
+				fmt.Printf("    %s: %s\n", k, v)
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("=== 1. 路由：静态段优先、参数、通配 ===")
+	do("chap22 Handler", http.MethodGet, "/")
+	do("静态段", http.MethodGet, "/users/me")
+	do("参数段", http.MethodGet, "/users/42", middleware.RequestIDHeader, "trace-abc")
+	do("两个参数", http.MethodGet, "/users/42/posts/7")
+	do("通配段", http.MethodGet, "/static/css/site.css")
+	do("不存在", http.MethodGet, "/nope")
+	do("方法不对", http.MethodPost, "/users/42")
+
+	fmt.Println("=== 2. Recover 和 Timeout ===")
+	do("panic", http.MethodGet, "/panic")
+	do("超时", http.MethodGet, "/slow")
+
+	fmt.Println("=== 3. CORS ===")
+	do("允许的来源", http.MethodGet, "/users/1", "Origin", "https://example.com")
+	do("预检", http.MethodOptions, "/users/1", "Origin", "https://example.com", "Access-Control-Request-Method", "POST")
+	do("不允许的来源", http.MethodGet, "/users/1", "Origin", "https://evil.example")
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// candidate 是某个字段提供的一个方法。depth 是方法在字段类型内部被提升的层数，
+// 与 Go 选择器的规则一致：深度最小者胜出，最小深度上有多个则有歧义。
+type candidate struct {
+	field *types.Var
+	fn    *types.Func
+	depth int
+}
+
+// forward 是一个要生成的转发方法。
+type forward struct {
+	name  string
+	field string
+	sig   *types.Signature
+}
+
+// generate 加载 cfg.dir 中的包，返回格式化后的生成代码。
+func generate(cfg config) ([]byte, error) {
+	pkg, err := load(cfg.dir, cfg.output)
+	if err != nil {
+		return nil, err
+	}
+	obj, _ := pkg.Scope().Lookup(cfg.typeName).(*types.TypeName)
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found in package %s", cfg.typeName, pkg.Name())
+	}
+	named, _ := obj.Type().(*types.Named)
+	if named == nil {
+		return nil, fmt.Errorf("%s is not a named type", cfg.typeName)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct type", cfg.typeName)
+	}
+
+	own := make(map[string]bool)
+	for i := 0; i < named.NumMethods(); i++ {
+		own[named.Method(i).Name()] = true
+	}
+	fieldByName := make(map[string]*types.Var)
+	for i := 0; i < st.NumFields(); i++ {
+		fieldByName[st.Field(i).Name()] = st.Field(i)
+	}
+	hooks := map[string]bool{cfg.before: true, cfg.after: true, cfg.guard: true}
+	if err := checkHooks(pkg, named, cfg); err != nil {
+		return nil, err
+	}
+
+	// 选出转发来源字段
+	var sources []*types.Var
+	if len(cfg.fields) == 0 {
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Embedded() {
+				sources = append(sources, st.Field(i))
+			}
+		}
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("%s has no embedded fields; use -field to choose fields", cfg.typeName)
+		}
+	} else {
+		for _, name := range cfg.fields {
+			f := fieldByName[strings.TrimSpace(name)]
+			if f == nil {
+				return nil, fmt.Errorf("%s has no field %s", cfg.typeName, name)
+			}
+			sources = append(sources, f)
+		}
+	}
+
+	candidates := make(map[string][]candidate)
+	for _, f := range sources {
+		t := f.Type()
+		switch t.Underlying().(type) {
+		case *types.Pointer, *types.Interface:
+		default:
+			t = types.NewPointer(t) // 通过 *T 接收者转发，字段可寻址，指针方法也可用
+		}
+		ms := types.NewMethodSet(t)
+		for i := 0; i < ms.Len(); i++ {
+			sel := ms.At(i)
+			fn := sel.Obj().(*types.Func)
+			if !fn.Exported() {
+				continue
+			}
+			candidates[fn.Name()] = append(candidates[fn.Name()], candidate{f, fn, len(sel.Index())})
+		}
+	}
+
+	// 需要生成的方法名
+	var wanted []string
+	var ifaceType *types.Interface
+	if cfg.iface != "" {
+		io, _ := pkg.Scope().Lookup(cfg.iface).(*types.TypeName)
+		if io == nil {
+			return nil, fmt.Errorf("interface %s not found in package %s", cfg.iface, pkg.Name())
+		}
+		if ifaceType, ok = io.Type().Underlying().(*types.Interface); !ok {
+			return nil, fmt.Errorf("%s is not an interface type", cfg.iface)
+		}
+		for i := 0; i < ifaceType.NumMethods(); i++ {
+			wanted = append(wanted, ifaceType.Method(i).Name())
+		}
+	} else {
+		for name := range candidates {
+			wanted = append(wanted, name)
+		}
+	}
+	sort.Strings(wanted)
+	for m := range cfg.resolve {
+		if len(candidates[m]) == 0 {
+			return nil, fmt.Errorf("-resolve %s: no selected field provides %s", m, m)
+		}
+	}
+
+	var (
+		out  []forward
+		errs []error
+	)
+	for _, name := range wanted {
+		if own[name] || hooks[name] {
+			continue // 手写的方法优先
+		}
+		if fieldByName[name] != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: type already has a field named %s; a method of the same name would not compile",
+				cfg.typeName, name, name))
+			continue
+		}
+		cands := candidates[name]
+		if len(cands) == 0 {
+			errs = append(errs, fmt.Errorf("%s.%s: required by %s but no selected field provides it", cfg.typeName, name, cfg.iface))
+			continue
+		}
+		c, err := pick(cfg, name, cands)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sig := c.fn.Type().(*types.Signature)
+		if ifaceType != nil {
+			want := ifaceMethod(ifaceType, name).Type().(*types.Signature)
+			if !types.Identical(stripRecv(sig), stripRecv(want)) {
+				errs = append(errs, fmt.Errorf("%s.%s: %s.%s has signature %s, but %s wants %s",
+					cfg.typeName, name, c.field.Name(), name, stripRecv(sig), cfg.iface, stripRecv(want)))
+				continue
+			}
+		}
+		out = append(out, forward{name: name, field: c.field.Name(), sig: sig})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("nothing to generate for %s: every method is already declared", cfg.typeName)
+	}
+	return render(cfg, pkg, named, out)
+}
+
+// pick 在同名候选中选出转发目标，遵循 Go 选择器的深度规则。
+func pick(cfg config, name string, cands []candidate) (candidate, error) {
+	if f, ok := cfg.resolve[name]; ok {
+		for _, c := range cands {
+			if c.field.Name() == f {
+				return c, nil
+			}
+		}
+		return candidate{}, fmt.Errorf("%s.%s: -resolve names field %s, which does not provide %s", cfg.typeName, name, f, name)
+	}
+	best := cands[0].depth
+	for _, c := range cands[1:] {
+		best = min(best, c.depth)
+	}
+	var top []candidate
+	for _, c := range cands {
+		if c.depth == best {
+			top = append(top, c)
+		}
+	}
+	if len(top) == 1 {
+		return top[0], nil
+	}
+	from := make([]string, len(top))
+	for i, c := range top {
+		from[i] = cfg.typeName + "." + c.field.Name() + "." + name
+	}
+	return candidate{}, fmt.Errorf("%s.%s: ambiguous selector: provided by %s at the same depth\n\t"+
+		"x.%s() would not compile; add -resolve %s=%s or write %s by hand",
+		cfg.typeName, name, strings.Join(from, " and "), name, name, top[0].field.Name(), name)
+}
+
+func ifaceMethod(it *types.Interface, name string) *types.Func {
+	for i := 0; i < it.NumMethods(); i++ {
+		if it.Method(i).Name() == name {
+			return it.Method(i)
+		}
+	}
+	return nil
+}
+
+func stripRecv(sig *types.Signature) *types.Signature {
+	return types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic())
+}
+
+// checkHooks 确认钩子方法存在且签名正确，避免生成无法编译的代码。
+func checkHooks(pkg *types.Package, named *types.Named, cfg config) error {
+	str := types.Typ[types.String]
+	check := func(name string, results ...types.Type) error {
+		if name == "" {
+			return nil
+		}
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, pkg, name)
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			return fmt.Errorf("hook %s: %s has no method %s", name, cfg.typeName, name)
+		}
+		want := types.NewSignatureType(nil, nil, nil,
+			types.NewTuple(types.NewVar(token.NoPos, pkg, "method", str)), tuple(pkg, results), false)
+		if got := stripRecv(fn.Type().(*types.Signature)); !types.Identical(got, want) {
+			return fmt.Errorf("hook %s: signature is %s, want %s", name, got, want)
+		}
+		return nil
+	}
+	return errors.Join(
+		check(cfg.before),
+		check(cfg.after),
+		check(cfg.guard, types.Typ[types.Bool]),
+	)
+}
+
+func tuple(pkg *types.Package, ts []types.Type) *types.Tuple {
+	vars := make([]*types.Var, len(ts))
+	for i, t := range ts {
+		vars[i] = types.NewVar(token.NoPos, pkg, "", t)
+	}
+	return types.NewTuple(vars...)
+}
+
+// load 解析并类型检查 dir 中的包，跳过 skip（上一次生成的文件），以免方法重复声明。
+// 类型错误会被忽略：手写代码可能正依赖尚未生成的方法。
+func load(dir, skip string) (*types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	skipAbs, _ := filepath.Abs(skip)
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		p := filepath.Join(dir, name)
+		if abs, _ := filepath.Abs(p); abs == skipAbs {
+			continue
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(bp.Name, fset, files, nil)
+	return pkg, nil
+}
+
+// render 输出生成的源码。
+func render(cfg config, pkg *types.Package, named *types.Named, methods []forward) ([]byte, error) {
+	imports := make(map[string]string) // path → name
+	qual := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		imports[p.Path()] = p.Name()
+		return p.Name()
+	}
+
+	recv := receiverName(named)
+	var body bytes.Buffer
+	for _, m := range methods {
+		writeMethod(&body, cfg, recv, m, qual)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"%s\"; DO NOT EDIT.\n\npackage %s\n\n", command(cfg), pkg.Name())
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for p := range imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		buf.WriteString("import (\n")
+		for _, p := range paths {
+			if imports[p] != path.Base(p) {
+				fmt.Fprintf(&buf, "\t%s %q\n", imports[p], p)
+			} else {
+				fmt.Fprintf(&buf, "\t%q\n", p)
+			}
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// command 重建生成命令，只包含影响结果的参数，使 -output、-check 不改变文件头。
+func command(cfg config) string {
+	parts := []string{"forwardgen", "-type", cfg.typeName}
+	add := func(flag, v string) {
+		if v != "" {
+			parts = append(parts, flag, v)
+		}
+	}
+	add("-field", strings.Join(cfg.fields, ","))
+	add("-iface", cfg.iface)
+	add("-before", cfg.before)
+	add("-after", cfg.after)
+	add("-guard", cfg.guard)
+	if len(cfg.resolve) > 0 {
+		kv := make([]string, 0, len(cfg.resolve))
+		for m, f := range cfg.resolve {
+			kv = append(kv, m+"="+f)
+		}
+		sort.Strings(kv)
+		add("-resolve", strings.Join(kv, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// receiverName 沿用类型已有方法的接收者名，没有时取类型名首字母小写。
+func receiverName(named *types.Named) string {
+	for i := 0; i < named.NumMethods(); i++ {
+		if r := named.Method(i).Type().(*types.Signature).Recv(); r != nil && r.Name() != "" && r.Name() != "_" {
+			return r.Name()
+		}
+	}
+	return strings.ToLower(named.Obj().Name()[:1])
+}
+
+func writeMethod(w *bytes.Buffer, cfg config, recv string, m forward, qual types.Qualifier) {
+	used := map[string]bool{recv: true}
+	unique := func(name, fallback string) string {
+		if name == "" || name == "_" || used[name] {
+			name = fallback
+		}
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		return name
+	}
+
+	params := m.sig.Params()
+	var decl, call []string
+	for i := 0; i < params.Len(); i++ {
+		p := params.At(i)
+		name := unique(p.Name(), fmt.Sprintf("a%d", i))
+		typ := types.TypeString(p.Type(), qual)
+		if m.sig.Variadic() && i == params.Len()-1 {
+			typ = "..." + types.TypeString(p.Type().(*types.Slice).Elem(), qual)
+			call = append(call, name+"...")
+		} else {
+			call = append(call, name)
+		}
+		decl = append(decl, name+" "+typ)
+	}
+
+	// 有 guard 时结果需要具名，guard 返回 false 时用空 return 返回零值
+	results := m.sig.Results()
+	var res []string
+	for i := 0; i < results.Len(); i++ {
+		typ := types.TypeString(results.At(i).Type(), qual)
+		if cfg.guard != "" {
+			typ = unique(results.At(i).Name(), fmt.Sprintf("r%d", i)) + " " + typ
+		}
+		res = append(res, typ)
+	}
+	resList := strings.Join(res, ", ")
+	if len(res) > 1 || (len(res) == 1 && cfg.guard != "") {
+		resList = "(" + resList + ")"
+	}
+
+	fmt.Fprintf(w, "// %s 转发给 %s。\n", m.name, m.field)
+	fmt.Fprintf(w, "func (%s *%s) %s(%s) %s {\n", recv, cfg.typeName, m.name, strings.Join(decl, ", "), resList)
+	if cfg.guard != "" {
+		fmt.Fprintf(w, "if !%s.%s(%q) {\nreturn\n}\n", recv, cfg.guard, m.name)
+	}
+	if cfg.before != "" {
+		fmt.Fprintf(w, "%s.%s(%q)\n", recv, cfg.before, m.name)
+	}
+	if cfg.after != "" {
+		fmt.Fprintf(w, "defer %s.%s(%q)\n", recv, cfg.after, m.name)
+	}
+	ret := ""
+	if results.Len() > 0 {
+		ret = "return "
+	}
+	fmt.Fprintf(w, "%s%s.%s.%s(%s)\n}\n\n", ret, recv, m.field, m.name, strings.Join(call, ", "))
+}
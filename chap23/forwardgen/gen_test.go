@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "用生成结果覆盖 testdata 下的黄金文件")
+
+// 与 testdata 中各个 //go:generate 行的参数一致。
+var goldenCases = []struct {
+	golden string
+	cfg    config
+}{
+	{"robot/robot_forward.golden", config{typeName: "Robot"}},
+	{"computer/computer_forward.golden", config{
+		typeName: "Computer", iface: "Device", before: "beforeCall", after: "afterCall", guard: "poweredOn",
+	}},
+	{"hybrid/hybridcar_forward.golden", config{
+		typeName: "HybridCar", resolve: map[string]string{"Start": "ElectricMotor"},
+	}},
+	{"hybrid/carwithforwarding_forward.golden", config{
+		typeName: "CarWithForwarding", fields: []string{"engine", "wheels"},
+	}},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.cfg.typeName, func(t *testing.T) {
+			golden := filepath.Join("testdata", tc.golden)
+			cfg := tc.cfg
+			cfg.dir = filepath.Dir(golden)
+			cfg.output = golden
+			got, err := generate(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				var diff strings.Builder
+				printDiff(&diff, string(want), string(got))
+				t.Errorf("%s is out of date (go test ./chap23/forwardgen -update):\n%s", golden, diff.String())
+			}
+		})
+	}
+}
+
+func TestAmbiguousSelector(t *testing.T) {
+	cfg := config{dir: filepath.Join("testdata", "hybrid"), typeName: "HybridCar"}
+	_, err := generate(cfg)
+	if err == nil {
+		t.Fatal("HybridCar without -resolve: no error")
+	}
+	for _, want := range []string{
+		"HybridCar.Start: ambiguous selector",
+		"HybridCar.Engine.Start and HybridCar.ElectricMotor.Start",
+		"-resolve Start=Engine",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+
+	cfg.resolve = map[string]string{"Start": "Battery"}
+	if _, err := generate(cfg); err == nil || !strings.Contains(err.Error(), "does not provide Start") {
+		t.Errorf("-resolve with a field that has no Start: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  config
+		want string
+	}{
+		{config{dir: "testdata/robot", typeName: "Missing"}, "type Missing not found"},
+		{config{dir: "testdata/robot", typeName: "Robot", fields: []string{"Nope"}}, "Nope"},
+		{config{dir: "testdata/computer", typeName: "Computer", iface: "Nope"}, "Nope"},
+		{config{dir: "testdata/computer", typeName: "Computer", guard: "nope"}, "nope"},
+	} {
+		if _, err := generate(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: error %v, want it to mention %q", tc.cfg, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// forwardgen 为结构体生成转发方法（第23章手写的 Robot.Walk、Computer.Save 的自动版本）。
+//
+// 用法（写在类型所在的文件里，然后执行 go generate）：
+//
+//	//go:generate go run books/chap23/forwardgen -type Robot
+//	//go:generate go run books/chap23/forwardgen -type Computer -iface Device -before beforeCall -after afterCall
+//	//go:generate go run books/chap23/forwardgen -type CarWithForwarding -field engine,wheels -guard canRun
+//
+// 默认转发所有嵌入字段的导出方法；-field 指定字段（可以是未嵌入的小写字段），
+// -iface 只生成某个接口要求的方法。类型上已经手写的方法不会重复生成。
+//
+// 两个字段在同一深度提供同名方法时（例如 HybridCar 的 Engine.Start 和 ElectricMotor.Start），
+// Go 会把 c.Start() 报告为 ambiguous selector；forwardgen 同样报错并列出来源，
+// 可以用 -resolve Start=Engine 指定转发给谁，或者手写这个方法。
+//
+// 钩子都是类型自己的方法：
+//
+//	before(method string)       // 转发前调用，用于日志、指标
+//	after(method string)        // 转发后调用（defer），用于日志、指标
+//	guard(method string) bool   // 返回 false 时不转发，直接返回零值，用于状态保护
+//
+// -check 不写文件，只比较生成结果和 -output 的现有内容，不同则打印差异并以状态 1 退出，
+// 可用于 CI；testdata 下的黄金文件由 gen_test.go 校验（go test -update 重新生成）。
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type config struct {
+	dir      string
+	typeName string
+	fields   []string
+	iface    string
+	before   string
+	after    string
+	guard    string
+	resolve  map[string]string
+	output   string
+	args     []string
+}
+
+func main() {
+	var (
+		cfg     config
+		fields  = flag.String("field", "", "逗号分隔的转发来源字段，默认所有嵌入字段")
+		resolve = flag.String("resolve", "", "逗号分隔的 方法=字段，解决同名方法冲突")
+		check   = flag.Bool("check", false, "只比较生成结果和 -output 的现有内容")
+	)
+	flag.StringVar(&cfg.dir, "dir", ".", "包所在目录")
+	flag.StringVar(&cfg.typeName, "type", "", "要生成转发方法的结构体类型（必填）")
+	flag.StringVar(&cfg.iface, "iface", "", "只生成该接口（同一包内）要求的方法")
+	flag.StringVar(&cfg.before, "before", "", "转发前调用的钩子方法名")
+	flag.StringVar(&cfg.after, "after", "", "转发后调用的钩子方法名")
+	flag.StringVar(&cfg.guard, "guard", "", "返回 bool 的状态保护钩子方法名")
+	flag.StringVar(&cfg.output, "output", "", "输出文件，默认 <dir>/<type>_forward.go")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: forwardgen -type T [-field a,b] [-iface I] [-before f] [-after f] [-guard f] [-resolve M=field] [-output file] [-check]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if cfg.typeName == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg.args = os.Args[1:]
+	if *fields != "" {
+		cfg.fields = strings.Split(*fields, ",")
+	}
+	cfg.resolve = make(map[string]string)
+	if *resolve != "" {
+		for _, kv := range strings.Split(*resolve, ",") {
+			m, f, ok := strings.Cut(kv, "=")
+			if !ok || m == "" || f == "" {
+				fmt.Fprintf(os.Stderr, "forwardgen: bad -resolve entry %q, want Method=field\n", kv)
+				os.Exit(2)
+			}
+			cfg.resolve[m] = f
+		}
+	}
+	if cfg.output == "" {
+		cfg.output = filepath.Join(cfg.dir, strings.ToLower(cfg.typeName)+"_forward.go")
+	}
+
+	src, err := generate(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "forwardgen:", err)
+		os.Exit(1)
+	}
+
+	if *check {
+		old, err := os.ReadFile(cfg.output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "forwardgen:", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(old, src) {
+			fmt.Fprintf(os.Stderr, "forwardgen: %s is out of date\n", cfg.output)
+			printDiff(os.Stderr, string(old), string(src))
+			os.Exit(1)
+		}
+		return
+	}
+	if err := os.WriteFile(cfg.output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "forwardgen:", err)
+		os.Exit(1)
+	}
+}
+
+// printDiff 逐行比较，输出第一处不同附近的内容；生成的文件很短，不需要完整的 diff 算法。
+func printDiff(w io.Writer, old, new string) {
+	a, b := strings.Split(old, "\n"), strings.Split(new, "\n")
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	fmt.Fprintf(w, "first difference at line %d:\n", i+1)
+	for j := i; j < i+5; j++ {
+		if j < len(a) {
+			fmt.Fprintf(w, "-%s\n", a[j])
+		}
+	}
+	for j := i; j < i+5; j++ {
+		if j < len(b) {
+			fmt.Fprintf(w, "+%s\n", b[j])
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package computer 对应 chap23 的 Computer：只转发 Device 接口要求的方法，
+// 并用 before/after 钩子记录调用、用 guard 钩子保证开机后才能使用。
+package computer
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+//go:generate go run books/chap23/forwardgen -type Computer -iface Device -before beforeCall -after afterCall -guard poweredOn -output computer_forward.golden
+
+// Device 是 Computer 对外提供的能力。
+type Device interface {
+	Process(ctx context.Context, job string) error
+	Store(keys ...string)
+	Save(w io.Writer, data []byte) (n int, err error)
+}
+
+// CPU CPU结构体
+type CPU struct{}
+
+func (c *CPU) Process(ctx context.Context, job string) error {
+	fmt.Println("    CPU processing", job)
+	return ctx.Err()
+}
+
+// Reset 不在 Device 中，不会被转发。
+func (c *CPU) Reset() {}
+
+// Memory 内存结构体
+type Memory struct{}
+
+func (m *Memory) Store(keys ...string) {
+	fmt.Println("    Memory storing", keys)
+}
+
+// Storage 存储结构体
+type Storage struct{}
+
+func (s *Storage) Save(w io.Writer, data []byte) (int, error) {
+	return w.Write(data)
+}
+
+// Computer 电脑结构体（组合多个组件）
+type Computer struct {
+	CPU
+	Memory
+	Storage
+	on    bool
+	calls []string
+}
+
+func (c *Computer) beforeCall(method string) { c.calls = append(c.calls, "enter "+method) }
+
+func (c *Computer) afterCall(method string) { c.calls = append(c.calls, "leave "+method) }
+
+func (c *Computer) poweredOn(method string) bool { return c.on }
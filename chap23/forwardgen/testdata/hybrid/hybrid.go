@@ -0,0 +1,54 @@
+// Package hybrid 对应 chap23 的 HybridCar 和 CarWithForwarding。
+//
+// HybridCar 同时嵌入 Engine 和 ElectricMotor，二者都有 Start，c.Start() 是 ambiguous selector。
+// 不加 -resolve 时 forwardgen 会报告：
+//
+//	forwardgen: HybridCar.Start: ambiguous selector: provided by HybridCar.Engine.Start and HybridCar.ElectricMotor.Start at the same depth
+//		x.Start() would not compile; add -resolve Start=Engine or write Start by hand
+package hybrid
+
+import "fmt"
+
+//go:generate go run books/chap23/forwardgen -type HybridCar -resolve Start=ElectricMotor -output hybridcar_forward.golden
+//go:generate go run books/chap23/forwardgen -type CarWithForwarding -field engine,wheels -output carwithforwarding_forward.golden
+
+// Engine 引擎结构体
+type Engine struct{}
+
+func (e *Engine) Start() {
+	fmt.Println("    Engine started")
+}
+
+func (e *Engine) Stop() {
+	fmt.Println("    Engine stopped")
+}
+
+// ElectricMotor 电机结构体
+type ElectricMotor struct{}
+
+func (e *ElectricMotor) Start() {
+	fmt.Println("    Electric motor started")
+}
+
+func (e *ElectricMotor) Charge(kwh float64) {
+	fmt.Printf("    Charging %.1f kWh\n", kwh)
+}
+
+// Wheels 车轮结构体
+type Wheels struct{}
+
+func (w *Wheels) Rotate() {
+	fmt.Println("    Wheels rotating")
+}
+
+// HybridCar 混合动力汽车
+type HybridCar struct {
+	Engine
+	ElectricMotor
+}
+
+// CarWithForwarding 带手动转发的汽车：字段不嵌入，方法不会自动提升
+type CarWithForwarding struct {
+	engine Engine
+	wheels Wheels
+}
@@ -0,0 +1,46 @@
+// Package robot 对应 chap23 的 Robot：四个组件嵌入，转发方法由 forwardgen 生成。
+package robot
+
+import "fmt"
+
+//go:generate go run books/chap23/forwardgen -type Robot -output robot_forward.golden
+
+// Body 身体结构体
+type Body struct{}
+
+func (b *Body) Move() {
+	fmt.Println("    Body moving")
+}
+
+// Brain 大脑结构体
+type Brain struct{}
+
+func (b *Brain) Think() {
+	fmt.Println("    Brain thinking")
+}
+
+// Arms 手臂结构体
+type Arms struct{}
+
+func (a *Arms) Grab() {
+	fmt.Println("    Arms grabbing")
+}
+
+// Legs 腿部结构体
+type Legs struct {
+	steps int
+}
+
+func (l *Legs) Walk(steps int) int {
+	l.steps += steps
+	fmt.Println("    Legs walking")
+	return l.steps
+}
+
+// Robot 机器人结构体（组合多个组件）
+type Robot struct {
+	Body
+	Brain
+	Arms
+	Legs
+}
@@ -0,0 +1,295 @@
+// Package fsm 是一个泛型有限状态机（第23章 SmartCar 的 started bool 的一般化）。
+//
+// SmartCar 只用一个布尔值记录“是否已启动”，HybridCar.Start 不论什么情况都同时启动
+// 引擎和电机。状态一多，这种写法就会散落成大量 if。这里把规则集中声明：
+//
+//	m := fsm.New[State, Event](Off)
+//	m.Permit(Off, PowerOn, Idle)
+//	m.Permit(Idle, Drive, Electric).When("电量≥20%", func() error { ... })
+//	m.OnEntry(Electric, func(t fsm.Record[State, Event]) { motor.Start() })
+//	err := m.Fire(Drive)
+//
+// 同一状态、同一事件可以有多条规则，按声明顺序取第一条守卫通过的规则。
+// 非法转换返回 *TransitionError，可以用 errors.Is 区分 ErrInvalidTransition 和 ErrGuardRejected。
+//
+// Machine 不是并发安全的，多个 goroutine 共用时需要自行加锁。
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidTransition 表示当前状态下没有为该事件声明任何规则。
+	ErrInvalidTransition = errors.New("fsm: invalid transition")
+	// ErrGuardRejected 表示有规则，但所有规则的守卫都拒绝了。
+	ErrGuardRejected = errors.New("fsm: guard rejected transition")
+	// ErrReentrant 表示在动作（entry/exit/Do）执行期间又调用了 Fire。
+	ErrReentrant = errors.New("fsm: Fire called from inside an action")
+)
+
+// TransitionError 描述一次失败的 Fire。
+type TransitionError[S, E comparable] struct {
+	State S
+	Event E
+	Err   error // 包装了 ErrInvalidTransition、ErrGuardRejected 或 ErrReentrant
+}
+
+func (e *TransitionError[S, E]) Error() string {
+	return fmt.Sprintf("fsm: event %v in state %v: %v", e.Event, e.State, e.Err)
+}
+
+func (e *TransitionError[S, E]) Unwrap() error { return e.Err }
+
+// Record 是一次成功的转换。
+type Record[S, E comparable] struct {
+	From  S
+	Event E
+	To    S
+	At    time.Time
+}
+
+func (r Record[S, E]) String() string {
+	return fmt.Sprintf("%v --%v--> %v", r.From, r.Event, r.To)
+}
+
+// Rule 是一条转换规则，由 Permit 创建，可以链式添加守卫和动作。
+type Rule[S, E comparable] struct {
+	From   S
+	Event  E
+	To     S
+	guards []guard
+	action func(Record[S, E])
+}
+
+type guard struct {
+	name  string
+	check func() error
+}
+
+// When 添加守卫。check 返回非 nil 时拒绝该规则，name 用于错误信息和 DOT 输出。
+func (r *Rule[S, E]) When(name string, check func() error) *Rule[S, E] {
+	r.guards = append(r.guards, guard{name, check})
+	return r
+}
+
+// Do 设置转换动作，在离开旧状态（exit）之后、进入新状态（entry）之前执行。
+func (r *Rule[S, E]) Do(action func(Record[S, E])) *Rule[S, E] {
+	r.action = action
+	return r
+}
+
+// check 依次执行守卫，返回第一个拒绝原因。
+func (r *Rule[S, E]) check() error {
+	for _, g := range r.guards {
+		if err := g.check(); err != nil {
+			return fmt.Errorf("%s: %w", g.name, err)
+		}
+	}
+	return nil
+}
+
+// Machine 是状态机。
+type Machine[S, E comparable] struct {
+	initial S
+	state   S
+	rules   []*Rule[S, E]
+	states  []S // 按首次出现的顺序，供 States 和 DOT 使用
+	events  []E
+	entry   map[S][]func(Record[S, E])
+	exit    map[S][]func(Record[S, E])
+	history []Record[S, E]
+	limit   int
+	firing  bool
+
+	// Now 为历史记录提供时间，默认 time.Now；测试或回放时可以替换。
+	Now func() time.Time
+}
+
+// New 创建初始状态为 initial 的状态机。
+func New[S, E comparable](initial S) *Machine[S, E] {
+	m := &Machine[S, E]{
+		initial: initial,
+		state:   initial,
+		entry:   make(map[S][]func(Record[S, E])),
+		exit:    make(map[S][]func(Record[S, E])),
+		Now:     time.Now,
+	}
+	m.addState(initial)
+	return m
+}
+
+func (m *Machine[S, E]) addState(s S) {
+	for _, x := range m.states {
+		if x == s {
+			return
+		}
+	}
+	m.states = append(m.states, s)
+}
+
+func (m *Machine[S, E]) addEvent(e E) {
+	for _, x := range m.events {
+		if x == e {
+			return
+		}
+	}
+	m.events = append(m.events, e)
+}
+
+// Permit 声明：在状态 from 收到事件 event 时转到 to。from == to 表示自环，同样会执行 exit 和 entry。
+func (m *Machine[S, E]) Permit(from S, event E, to S) *Rule[S, E] {
+	r := &Rule[S, E]{From: from, Event: event, To: to}
+	m.rules = append(m.rules, r)
+	m.addState(from)
+	m.addState(to)
+	m.addEvent(event)
+	return r
+}
+
+// OnEntry 注册进入状态 s 时执行的动作。
+func (m *Machine[S, E]) OnEntry(s S, fn func(Record[S, E])) {
+	m.addState(s)
+	m.entry[s] = append(m.entry[s], fn)
+}
+
+// OnExit 注册离开状态 s 时执行的动作。
+func (m *Machine[S, E]) OnExit(s S, fn func(Record[S, E])) {
+	m.addState(s)
+	m.exit[s] = append(m.exit[s], fn)
+}
+
+// SetHistoryLimit 限制保留的历史记录条数，0（默认）表示不限制。
+func (m *Machine[S, E]) SetHistoryLimit(n int) {
+	m.limit = n
+	m.trim()
+}
+
+func (m *Machine[S, E]) trim() {
+	if m.limit > 0 && len(m.history) > m.limit {
+		m.history = append(m.history[:0], m.history[len(m.history)-m.limit:]...)
+	}
+}
+
+// State 返回当前状态。
+func (m *Machine[S, E]) State() S { return m.state }
+
+// Is 报告当前状态是否为 s。
+func (m *Machine[S, E]) Is(s S) bool { return m.state == s }
+
+// States 返回所有出现过的状态，按首次声明的顺序。
+func (m *Machine[S, E]) States() []S { return append([]S(nil), m.states...) }
+
+// Events 返回所有出现过的事件，按首次声明的顺序。
+func (m *Machine[S, E]) Events() []E { return append([]E(nil), m.events...) }
+
+// History 返回转换历史的副本，最早的在前。
+func (m *Machine[S, E]) History() []Record[S, E] {
+	return append([]Record[S, E](nil), m.history...)
+}
+
+// Reset 直接把状态设为 s 并清空历史，不执行任何动作。用于恢复持久化的状态或测试。
+func (m *Machine[S, E]) Reset(s S) {
+	m.addState(s)
+	m.state = s
+	m.history = nil
+}
+
+// find 返回当前状态下事件 e 应当采用的规则。
+func (m *Machine[S, E]) find(e E) (*Rule[S, E], error) {
+	var rejected []error
+	for _, r := range m.rules {
+		if r.From != m.state || r.Event != e {
+			continue
+		}
+		err := r.check()
+		if err == nil {
+			return r, nil
+		}
+		rejected = append(rejected, err)
+	}
+	if rejected == nil {
+		return nil, ErrInvalidTransition
+	}
+	return nil, fmt.Errorf("%w: %w", ErrGuardRejected, errors.Join(rejected...))
+}
+
+// Can 报告在当前状态下 e 能否成功触发（守卫会被执行）。
+func (m *Machine[S, E]) Can(e E) bool {
+	_, err := m.find(e)
+	return err == nil
+}
+
+// Permitted 返回在当前状态下能够成功触发的事件。
+func (m *Machine[S, E]) Permitted() []E {
+	var out []E
+	for _, e := range m.events {
+		if m.Can(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Fire 触发事件 e：依次执行旧状态的 exit、规则的 Do、新状态的 entry，并记录历史。
+// 失败时状态不变，返回 *TransitionError。
+func (m *Machine[S, E]) Fire(e E) error {
+	if m.firing {
+		return &TransitionError[S, E]{State: m.state, Event: e, Err: ErrReentrant}
+	}
+	r, err := m.find(e)
+	if err != nil {
+		return &TransitionError[S, E]{State: m.state, Event: e, Err: err}
+	}
+
+	m.firing = true
+	defer func() { m.firing = false }()
+	rec := Record[S, E]{From: m.state, Event: e, To: r.To, At: m.Now()}
+	for _, fn := range m.exit[rec.From] {
+		fn(rec)
+	}
+	if r.action != nil {
+		r.action(rec)
+	}
+	m.state = rec.To
+	for _, fn := range m.entry[rec.To] {
+		fn(rec)
+	}
+	m.history = append(m.history, rec)
+	m.trim()
+	return nil
+}
+
+// DOT 以 Graphviz DOT 格式输出状态图：初始状态由一个点指入，当前状态加粗，
+// 守卫写在边的标签里，例如 Drive [电量≥20%]。
+func (m *Machine[S, E]) DOT(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", name)
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=ellipse];\n")
+	b.WriteString("\t__start [shape=point, label=\"\"];\n")
+	for _, s := range m.states {
+		attrs := ""
+		if s == m.state {
+			attrs = " [style=bold, penwidth=2]"
+		}
+		fmt.Fprintf(&b, "\t%q%s;\n", fmt.Sprint(s), attrs)
+	}
+	fmt.Fprintf(&b, "\t__start -> %q;\n", fmt.Sprint(m.initial))
+	for _, r := range m.rules {
+		label := fmt.Sprint(r.Event)
+		if len(r.guards) > 0 {
+			names := make([]string, len(r.guards))
+			for i, g := range r.guards {
+				names[i] = g.name
+			}
+			label += " [" + strings.Join(names, ", ") + "]"
+		}
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", fmt.Sprint(r.From), fmt.Sprint(r.To), label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
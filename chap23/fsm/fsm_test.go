@@ -0,0 +1,247 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试用一扇带锁的门：Closed ⇄ Open，Closed ⇄ Locked。
+const (
+	closed = "Closed"
+	open   = "Open"
+	locked = "Locked"
+)
+
+var errNoKey = errors.New("no key")
+
+func door() *Machine[string, string] {
+	m := New[string, string](closed)
+	m.Permit(closed, "open", open)
+	m.Permit(open, "close", closed)
+	m.Permit(closed, "lock", locked)
+	m.Permit(locked, "unlock", closed)
+	return m
+}
+
+func TestFire(t *testing.T) {
+	m := door()
+	if !m.Is(closed) || m.State() != closed {
+		t.Fatalf("initial state = %s", m.State())
+	}
+	for _, e := range []string{"open", "close", "lock", "unlock"} {
+		if err := m.Fire(e); err != nil {
+			t.Fatalf("%s: %v", e, err)
+		}
+	}
+	if m.State() != closed || len(m.History()) != 4 {
+		t.Errorf("after a round trip: %s, %d records", m.State(), len(m.History()))
+	}
+
+	err := m.Fire("unlock")
+	var te *TransitionError[string, string]
+	if !errors.As(err, &te) || te.State != closed || te.Event != "unlock" || !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("unlock when closed: %v", err)
+	}
+	if err.Error() != "fsm: event unlock in state Closed: fsm: invalid transition" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if m.State() != closed || len(m.History()) != 4 {
+		t.Error("failed Fire changed the machine")
+	}
+
+	if fmt.Sprint(m.States()) != "[Closed Open Locked]" || fmt.Sprint(m.Events()) != "[open close lock unlock]" {
+		t.Errorf("States = %v, Events = %v", m.States(), m.Events())
+	}
+	if fmt.Sprint(m.Permitted()) != "[open lock]" || !m.Can("open") || m.Can("close") {
+		t.Errorf("Permitted in Closed = %v", m.Permitted())
+	}
+}
+
+func TestGuards(t *testing.T) {
+	hasKey, calls := false, 0
+	key := func() error {
+		calls++
+		if !hasKey {
+			return errNoKey
+		}
+		return nil
+	}
+	m := New[string, string](locked)
+	m.Permit(locked, "unlock", closed).When("has key", key)
+	m.Permit(locked, "unlock", open).When("alarm off", func() error { return errors.New("alarm on") })
+
+	err := m.Fire("unlock")
+	if !errors.Is(err, ErrGuardRejected) || !errors.Is(err, errNoKey) || errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("all guards reject: %v", err)
+	}
+	// 两条规则的拒绝原因都在错误里，带守卫的名字
+	for _, want := range []string{"has key: no key", "alarm off: alarm on"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q: %v", want, err)
+		}
+	}
+	if m.Can("unlock") || m.Permitted() != nil || m.State() != locked {
+		t.Error("rejected transition is still permitted")
+	}
+
+	// 按声明顺序取第一条守卫通过的规则
+	hasKey = true
+	if err := m.Fire("unlock"); err != nil || m.State() != closed {
+		t.Errorf("guard passes: %v, state %s", err, m.State())
+	}
+
+	// 同一条规则上的多个守卫依次执行，第一个拒绝后不再执行后面的
+	calls = 0
+	second := 0
+	m = New[string, string](closed)
+	m.Permit(closed, "open", open).
+		When("has key", key).
+		When("counted", func() error { second++; return nil })
+	hasKey = false
+	m.Fire("open")
+	if calls != 1 || second != 0 {
+		t.Errorf("after the first guard rejects: %d, %d calls", calls, second)
+	}
+	hasKey = true
+	if err := m.Fire("open"); err != nil || second != 1 {
+		t.Errorf("both guards pass: %v, %d calls", err, second)
+	}
+}
+
+func TestHooksOrder(t *testing.T) {
+	var calls []string
+	log := func(what string) func(Record[string, string]) {
+		return func(r Record[string, string]) {
+			calls = append(calls, fmt.Sprintf("%s(%s)", what, r))
+		}
+	}
+	m := door()
+	m.OnExit(closed, log("exit Closed"))
+	m.OnExit(closed, log("exit Closed again"))
+	m.OnEntry(open, log("entry Open"))
+	m.OnEntry(closed, log("entry Closed"))
+	m.Permit(open, "slam", open).Do(log("do slam"))
+	m.Permit(open, "bang", closed).Do(func(r Record[string, string]) {
+		// Do 执行时已经离开了旧状态，还没有进入新状态
+		calls = append(calls, "do bang in "+m.State())
+	})
+
+	m.Fire("open")
+	m.Fire("slam") // 自环同样执行 exit 和 entry
+	m.Fire("bang")
+	want := []string{
+		"exit Closed(Closed --open--> Open)",
+		"exit Closed again(Closed --open--> Open)",
+		"entry Open(Closed --open--> Open)",
+		"do slam(Open --slam--> Open)",
+		"entry Open(Open --slam--> Open)",
+		"do bang in Open",
+		"entry Closed(Open --bang--> Closed)",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hooks ran in order:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEntrySeesNewState(t *testing.T) {
+	m := door()
+	var seen []string
+	m.OnEntry(open, func(Record[string, string]) { seen = append(seen, "entry "+m.State()) })
+	m.OnExit(closed, func(Record[string, string]) { seen = append(seen, "exit "+m.State()) })
+	m.Fire("open")
+	if fmt.Sprint(seen) != "[exit Closed entry Open]" {
+		t.Errorf("state seen by the hooks: %v", seen)
+	}
+}
+
+func TestReentrant(t *testing.T) {
+	m := door()
+	var inner error
+	m.OnEntry(open, func(Record[string, string]) { inner = m.Fire("close") })
+	if err := m.Fire("open"); err != nil {
+		t.Fatal(err)
+	}
+	var te *TransitionError[string, string]
+	if !errors.Is(inner, ErrReentrant) || !errors.As(inner, &te) || te.Event != "close" {
+		t.Fatalf("Fire from an entry hook = %v", inner)
+	}
+	if m.State() != open || len(m.History()) != 1 {
+		t.Errorf("re-entrant Fire changed the machine: %s, %v", m.State(), m.History())
+	}
+	// 动作结束后可以正常触发
+	if err := m.Fire("close"); err != nil {
+		t.Errorf("Fire after the hook returned: %v", err)
+	}
+
+	m.Permit(closed, "knock", closed).Do(func(Record[string, string]) { inner = m.Fire("open") })
+	m.Fire("knock")
+	if !errors.Is(inner, ErrReentrant) {
+		t.Errorf("Fire from Do = %v", inner)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	m := door()
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	tick := 0
+	m.Now = func() time.Time {
+		tick++
+		return start.Add(time.Duration(tick) * time.Minute)
+	}
+	for _, e := range []string{"open", "close", "lock", "unlock", "open"} {
+		m.Fire(e)
+	}
+	h := m.History()
+	if len(h) != 5 || h[0].String() != "Closed --open--> Open" || !h[4].At.Equal(start.Add(5*time.Minute)) {
+		t.Fatalf("history = %v", h)
+	}
+	h[0].To = "changed"
+	if m.History()[0].To != open {
+		t.Error("History returned the internal slice")
+	}
+
+	m.SetHistoryLimit(2)
+	if h := m.History(); len(h) != 2 || h[0].Event != "unlock" || h[1].Event != "open" {
+		t.Errorf("after SetHistoryLimit(2): %v", h)
+	}
+	m.Fire("close")
+	if h := m.History(); len(h) != 2 || h[0].Event != "open" || h[1].Event != "close" {
+		t.Errorf("limit not kept on Fire: %v", h)
+	}
+
+	m.Reset(locked)
+	if m.State() != locked || len(m.History()) != 0 {
+		t.Errorf("Reset: %s, %v", m.State(), m.History())
+	}
+	m.Reset("Broken") // 未声明的状态也会加入 States
+	if fmt.Sprint(m.States()) != "[Closed Open Locked Broken]" {
+		t.Errorf("States after Reset = %v", m.States())
+	}
+}
+
+func TestDOT(t *testing.T) {
+	m := New[string, string](closed)
+	m.Permit(closed, "open", open)
+	m.Permit(open, "close", closed)
+	m.Permit(closed, "lock", locked).When("has key", func() error { return nil }).When("door shut", func() error { return nil })
+	m.Fire("open")
+	want := `digraph "door" {
+	rankdir=LR;
+	node [shape=ellipse];
+	__start [shape=point, label=""];
+	"Closed";
+	"Open" [style=bold, penwidth=2];
+	"Locked";
+	__start -> "Closed";
+	"Closed" -> "Open" [label="open"];
+	"Open" -> "Closed" [label="close"];
+	"Closed" -> "Locked" [label="lock [has key, door shut]"];
+}
+`
+	if got := m.DOT("door"); got != want {
+		t.Errorf("DOT =\n%s\nwant:\n%s", got, want)
+	}
+}
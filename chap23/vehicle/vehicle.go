@@ -0,0 +1,197 @@
+// Package vehicle 用 fsm 重写第23章的 SmartCar 和 HybridCar。
+//
+// 车辆有五个状态：
+//
+//	Off       熄火
+//	Idle      通电但静止，引擎和电机都不转
+//	Electric  纯电行驶，只有电机运转
+//	Hybrid    混合动力，引擎和电机都运转
+//	Fault     故障，所有动力停止，只能 Repair 回到 Off
+//
+// 与 HybridCar.Start 不同，进入哪个行驶状态由电量和油量决定：
+// 电量不低于 MinBattery 时优先纯电，否则只要有油就进入混合动力。
+package vehicle
+
+import (
+	"errors"
+	"fmt"
+
+	"books/chap23/fsm"
+)
+
+// State 是车辆状态。
+type State int
+
+const (
+	Off State = iota
+	Idle
+	Electric
+	Hybrid
+	Fault
+)
+
+var stateNames = [...]string{"Off", "Idle", "Electric", "Hybrid", "Fault"}
+
+func (s State) String() string {
+	if s >= 0 && int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Event 是驾驶员或车辆自身产生的事件。
+type Event int
+
+const (
+	PowerOn  Event = iota // 通电
+	PowerOff              // 断电
+	Drive                 // 起步
+	Boost                 // 需要大功率（急加速、爬坡）
+	Cruise                // 功率需求降低
+	Stop                  // 停车
+	Fail                  // 检测到故障
+	Repair                // 维修完成
+)
+
+var eventNames = [...]string{"PowerOn", "PowerOff", "Drive", "Boost", "Cruise", "Stop", "Fail", "Repair"}
+
+func (e Event) String() string {
+	if e >= 0 && int(e) < len(eventNames) {
+		return eventNames[e]
+	}
+	return fmt.Sprintf("Event(%d)", int(e))
+}
+
+// States 和 Events 列出全部状态和事件，便于枚举。
+var (
+	States = []State{Off, Idle, Electric, Hybrid, Fault}
+	Events = []Event{PowerOn, PowerOff, Drive, Boost, Cruise, Stop, Fail, Repair}
+)
+
+// MinBattery 是纯电行驶所需的最低电量（百分比）。
+const MinBattery = 20
+
+var (
+	// ErrLowBattery 是纯电守卫的拒绝原因。
+	ErrLowBattery = errors.New("battery too low")
+	// ErrNoFuel 是混合动力守卫的拒绝原因。
+	ErrNoFuel = errors.New("out of fuel")
+)
+
+// Error 是车辆事件失败时返回的错误类型，等同于 fsm.TransitionError[State, Event]。
+type Error = fsm.TransitionError[State, Event]
+
+// Engine 对应 chap23 的 Engine，记录是否在运转。
+type Engine struct{ Running bool }
+
+// ElectricMotor 对应 chap23 的 ElectricMotor。
+type ElectricMotor struct{ Running bool }
+
+// Vehicle 是一辆混合动力车。
+type Vehicle struct {
+	Engine
+	ElectricMotor
+	Battery float64 // 电量百分比 0～100
+	Fuel    float64 // 油量（升）
+
+	m   *fsm.Machine[State, Event]
+	log []string
+}
+
+// New 创建一辆熄火状态的车。
+func New(battery, fuel float64) *Vehicle {
+	v := &Vehicle{Battery: battery, Fuel: fuel, m: fsm.New[State, Event](Off)}
+	m := v.m
+
+	hasCharge := func() error {
+		if v.Battery < MinBattery {
+			return ErrLowBattery
+		}
+		return nil
+	}
+	hasFuel := func() error {
+		if v.Fuel <= 0 {
+			return ErrNoFuel
+		}
+		return nil
+	}
+
+	m.Permit(Off, PowerOn, Idle)
+	m.Permit(Idle, PowerOff, Off)
+	m.Permit(Idle, Drive, Electric).When("battery≥20%", hasCharge)
+	m.Permit(Idle, Drive, Hybrid).When("fuel>0", hasFuel)
+	m.Permit(Electric, Boost, Hybrid).When("fuel>0", hasFuel)
+	m.Permit(Hybrid, Cruise, Electric).When("battery≥20%", hasCharge)
+	m.Permit(Electric, Stop, Idle)
+	m.Permit(Hybrid, Stop, Idle)
+	for _, s := range []State{Idle, Electric, Hybrid} {
+		m.Permit(s, Fail, Fault)
+	}
+	m.Permit(Fault, Repair, Off)
+
+	m.OnEntry(Electric, func(fsm.Record[State, Event]) { v.setPower(false, true) })
+	m.OnEntry(Hybrid, func(fsm.Record[State, Event]) { v.setPower(true, true) })
+	for _, s := range []State{Off, Idle, Fault} {
+		m.OnEntry(s, func(fsm.Record[State, Event]) { v.setPower(false, false) })
+	}
+	m.OnEntry(Fault, func(r fsm.Record[State, Event]) {
+		v.logf("故障：在 %v 状态下停止所有动力", r.From)
+	})
+	return v
+}
+
+// setPower 只在运转状态变化时启动或停止部件，与 chap23 的 Engine.Start 打印风格一致。
+func (v *Vehicle) setPower(engine, motor bool) {
+	if v.Engine.Running != engine {
+		v.Engine.Running = engine
+		v.logf("Engine %s", onOff(engine))
+	}
+	if v.ElectricMotor.Running != motor {
+		v.ElectricMotor.Running = motor
+		v.logf("Electric motor %s", onOff(motor))
+	}
+}
+
+func onOff(on bool) string {
+	if on {
+		return "started"
+	}
+	return "stopped"
+}
+
+func (v *Vehicle) logf(format string, args ...any) {
+	v.log = append(v.log, fmt.Sprintf(format, args...))
+}
+
+// Fire 触发事件。失败时返回 *Error，可用 errors.Is 检查 fsm.ErrInvalidTransition、
+// fsm.ErrGuardRejected、ErrLowBattery、ErrNoFuel。
+func (v *Vehicle) Fire(e Event) error {
+	return v.m.Fire(e)
+}
+
+// State 返回当前状态。
+func (v *Vehicle) State() State { return v.m.State() }
+
+// Permitted 返回当前可以触发的事件。
+func (v *Vehicle) Permitted() []Event { return v.m.Permitted() }
+
+// History 返回转换历史。
+func (v *Vehicle) History() []fsm.Record[State, Event] { return v.m.History() }
+
+// Log 返回并清空部件动作日志。
+func (v *Vehicle) Log() []string {
+	out := v.log
+	v.log = nil
+	return out
+}
+
+// DOT 以 Graphviz DOT 格式输出状态图。
+func (v *Vehicle) DOT() string { return v.m.DOT("vehicle") }
+
+// Reset 把车辆直接置于状态 s，并让部件与之匹配，不记录历史。用于枚举测试。
+func (v *Vehicle) Reset(s State) {
+	v.m.Reset(s)
+	v.Engine.Running = s == Hybrid
+	v.ElectricMotor.Running = s == Electric || s == Hybrid
+	v.log = nil
+}
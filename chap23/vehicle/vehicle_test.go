@@ -0,0 +1,174 @@
+package vehicle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"books/chap23/fsm"
+)
+
+// outcome 是一次 Fire 的结果：目标状态，或者错误类别。
+type outcome string
+
+const (
+	invalid  outcome = "✗"  // fsm.ErrInvalidTransition
+	rejected outcome = "守卫" // fsm.ErrGuardRejected
+)
+
+func fire(v *Vehicle, e Event) outcome {
+	err := v.Fire(e)
+	switch {
+	case err == nil:
+		return outcome(v.State().String())
+	case errors.Is(err, fsm.ErrInvalidTransition):
+		return invalid
+	case errors.Is(err, fsm.ErrGuardRejected):
+		return rejected
+	}
+	return outcome("?" + err.Error())
+}
+
+// TestMatrix 枚举三种电量/油量下的每个 (状态, 事件) 组合。
+func TestMatrix(t *testing.T) {
+	X, G := invalid, rejected
+	for _, sc := range []struct {
+		name          string
+		battery, fuel float64
+		want          map[State][]outcome // 按 Events 的顺序
+	}{
+		{"charged", 80, 40, map[State][]outcome{
+			// 列：PowerOn PowerOff Drive Boost Cruise Stop Fail Repair
+			Off:      {"Idle", X, X, X, X, X, X, X},
+			Idle:     {X, "Off", "Electric", X, X, X, "Fault", X},
+			Electric: {X, X, X, "Hybrid", X, "Idle", "Fault", X},
+			Hybrid:   {X, X, X, X, "Electric", "Idle", "Fault", X},
+			Fault:    {X, X, X, X, X, X, X, "Off"},
+		}},
+		{"low battery", 10, 40, map[State][]outcome{
+			Off:      {"Idle", X, X, X, X, X, X, X},
+			Idle:     {X, "Off", "Hybrid", X, X, X, "Fault", X},
+			Electric: {X, X, X, "Hybrid", X, "Idle", "Fault", X},
+			Hybrid:   {X, X, X, X, G, "Idle", "Fault", X},
+			Fault:    {X, X, X, X, X, X, X, "Off"},
+		}},
+		{"low battery, no fuel", 10, 0, map[State][]outcome{
+			Off:      {"Idle", X, X, X, X, X, X, X},
+			Idle:     {X, "Off", G, X, X, X, "Fault", X},
+			Electric: {X, X, X, G, X, "Idle", "Fault", X},
+			Hybrid:   {X, X, X, X, G, "Idle", "Fault", X},
+			Fault:    {X, X, X, X, X, X, X, "Off"},
+		}},
+	} {
+		if len(sc.want) != len(States) {
+			t.Fatalf("%s: table covers %d states, want %d", sc.name, len(sc.want), len(States))
+		}
+		for _, s := range States {
+			if len(sc.want[s]) != len(Events) {
+				t.Fatalf("%s: row %v covers %d events, want %d", sc.name, s, len(sc.want[s]), len(Events))
+			}
+			for i, e := range Events {
+				v := New(sc.battery, sc.fuel)
+				v.Reset(s)
+				got := fire(v, e)
+				if got != sc.want[s][i] {
+					t.Errorf("%s: %v --%v--> %s, want %s", sc.name, s, e, got, sc.want[s][i])
+				}
+				failed := got == invalid || got == rejected
+				if failed && (v.State() != s || len(v.History()) != 0) {
+					t.Errorf("%s: failed %v in %v changed the state to %v", sc.name, e, s, v.State())
+				}
+				// 部件的运转状态总是与所在状态一致
+				if v.Engine.Running != (v.State() == Hybrid) || v.ElectricMotor.Running != (v.State() == Electric || v.State() == Hybrid) {
+					t.Errorf("%s: after %v in %v: state %v, engine %v, motor %v",
+						sc.name, e, s, v.State(), v.Engine.Running, v.ElectricMotor.Running)
+				}
+			}
+		}
+	}
+}
+
+func TestTrip(t *testing.T) {
+	car := New(80, 40)
+	var logs []string
+	for _, e := range []Event{PowerOn, Drive, Boost, Cruise, Stop, PowerOff} {
+		if err := car.Fire(e); err != nil {
+			t.Fatalf("%v: %v", e, err)
+		}
+		logs = append(logs, fmt.Sprintf("%v: %s", e, strings.Join(car.Log(), "; ")))
+	}
+	want := []string{
+		"PowerOn: ",
+		"Drive: Electric motor started",
+		"Boost: Engine started",
+		"Cruise: Engine stopped",
+		"Stop: Electric motor stopped",
+		"PowerOff: ",
+	}
+	if strings.Join(logs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("component log:\n%s", strings.Join(logs, "\n"))
+	}
+
+	var path []string
+	for _, r := range car.History() {
+		path = append(path, r.String())
+	}
+	if got := strings.Join(path, ", "); got != "Off --PowerOn--> Idle, Idle --Drive--> Electric, Electric --Boost--> Hybrid, "+
+		"Hybrid --Cruise--> Electric, Electric --Stop--> Idle, Idle --PowerOff--> Off" {
+		t.Errorf("history = %s", got)
+	}
+}
+
+func TestGuardErrors(t *testing.T) {
+	low := New(10, 40)
+	low.Fire(PowerOn)
+	low.Fire(Drive)
+	if fmt.Sprint(low.Permitted()) != "[Stop Fail]" {
+		t.Errorf("Permitted in Hybrid with a low battery = %v", low.Permitted())
+	}
+	err := low.Fire(Cruise)
+	var ve *Error
+	if !errors.As(err, &ve) || ve.State != Hybrid || ve.Event != Cruise {
+		t.Fatalf("Cruise with a low battery: %v", err)
+	}
+	if !errors.Is(err, fsm.ErrGuardRejected) || !errors.Is(err, ErrLowBattery) || errors.Is(err, ErrNoFuel) {
+		t.Errorf("Cruise error does not wrap the guard's reason: %v", err)
+	}
+
+	empty := New(10, 0)
+	empty.Fire(PowerOn)
+	err = empty.Fire(Drive)
+	if !errors.Is(err, ErrLowBattery) || !errors.Is(err, ErrNoFuel) {
+		t.Errorf("Drive without charge or fuel should report both guards: %v", err)
+	}
+	if err := empty.Fire(Repair); !errors.Is(err, fsm.ErrInvalidTransition) {
+		t.Errorf("Repair in Idle: %v", err)
+	}
+}
+
+func TestFault(t *testing.T) {
+	v := New(80, 40)
+	v.Reset(Hybrid)
+	if err := v.Fire(Fail); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(v.Log(), "; "); got != "Engine stopped; Electric motor stopped; 故障：在 Hybrid 状态下停止所有动力" {
+		t.Errorf("log after Fail = %s", got)
+	}
+	if fmt.Sprint(v.Permitted()) != "[Repair]" {
+		t.Errorf("Permitted in Fault = %v", v.Permitted())
+	}
+}
+
+func TestNames(t *testing.T) {
+	if State(9).String() != "State(9)" || Event(-1).String() != "Event(-1)" {
+		t.Error("out-of-range names")
+	}
+	dot := New(80, 40).DOT()
+	for _, want := range []string{`"Off" [style=bold, penwidth=2];`, `"Idle" -> "Electric" [label="Drive [battery≥20%]"];`, `"Fault" -> "Off" [label="Repair"];`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT does not contain %s:\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// 独立运行：go run ./chap23/vehicle_demo
+// 演示：用 fsm 状态机驱动混合动力车；枚举每个 (状态, 事件) 组合打印结果表；输出 Graphviz DOT。
+// 断言见 go test ./chap23/fsm ./chap23/vehicle。
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"books/chap23/fsm"
+	"books/chap23/vehicle"
+)
+
+// outcome 是一次 Fire 的结果：目标状态，或者错误类别。
+type outcome string
+
+const (
+	invalid  outcome = "✗"  // fsm.ErrInvalidTransition
+	rejected outcome = "守卫" // fsm.ErrGuardRejected
+)
+
+func fire(v *vehicle.Vehicle, e vehicle.Event) outcome {
+	err := v.Fire(e)
+	switch {
+	case err == nil:
+		return outcome(v.State().String())
+	case errors.Is(err, fsm.ErrInvalidTransition):
+		return invalid
+	case errors.Is(err, fsm.ErrGuardRejected):
+		return rejected
+	}
+	return outcome("?" + err.Error())
+}
+
+// width 返回终端显示宽度：中日韩字符占两列。
+func width(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func main() {
+	fmt.Println("=== 1. 一次正常的行程 ===")
+	car := vehicle.New(80, 40)
+	for _, e := range []vehicle.Event{vehicle.PowerOn, vehicle.Drive, vehicle.Boost, vehicle.Cruise, vehicle.Stop, vehicle.PowerOff} {
+		if err := car.Fire(e); err != nil {
+			fmt.Println("  错误:", err)
+			continue
+		}
+		fmt.Printf("  %-8v → %-8v %s\n", e, car.State(), strings.Join(car.Log(), "；"))
+	}
+	fmt.Println("  历史:")
+	for _, r := range car.History() {
+		fmt.Println("   ", r)
+	}
+
+	fmt.Println("\n=== 2. 电量不足时起步直接进入混合动力 ===")
+	low := vehicle.New(10, 40)
+	low.Fire(vehicle.PowerOn)
+	low.Fire(vehicle.Drive)
+	fmt.Println("  状态:", low.State(), " 可触发事件:", low.Permitted())
+	err := low.Fire(vehicle.Cruise)
+	fmt.Println("  Cruise:", err)
+	var ve *vehicle.Error
+	if errors.As(err, &ve) {
+		fmt.Printf("  errors.As → State=%v Event=%v; Is(ErrGuardRejected)=%v Is(ErrLowBattery)=%v\n",
+			ve.State, ve.Event, errors.Is(err, fsm.ErrGuardRejected), errors.Is(err, vehicle.ErrLowBattery))
+	}
+	fmt.Println("  Repair:", low.Fire(vehicle.Repair))
+
+	fmt.Println("\n=== 3. 枚举所有 (状态, 事件) 组合 ===")
+	scenarios := []struct {
+		name          string
+		battery, fuel float64
+	}{
+		{"电量充足、有油", 80, 40},
+		{"电量不足、有油", 10, 40},
+		{"电量不足、没油", 10, 0},
+	}
+	for _, sc := range scenarios {
+		fmt.Printf("\n  [%s] battery=%.0f%% fuel=%.0fL\n", sc.name, sc.battery, sc.fuel)
+		fmt.Printf("  %-9s", "")
+		for _, e := range vehicle.Events {
+			fmt.Printf("%-10v", e)
+		}
+		fmt.Println()
+		for _, s := range vehicle.States {
+			fmt.Printf("  %-9v", s)
+			for _, e := range vehicle.Events {
+				v := vehicle.New(sc.battery, sc.fuel)
+				v.Reset(s)
+				cell := string(fire(v, e))
+				fmt.Printf("%s%s", cell, strings.Repeat(" ", max(1, 10-width(cell))))
+			}
+			fmt.Println()
+		}
+	}
+	fmt.Println("\n  ✗ = ErrInvalidTransition，守卫 = ErrGuardRejected；失败的事件不改变状态")
+
+	fmt.Println("\n=== 4. Graphviz DOT（可用 dot -Tpng 渲染）===")
+	fmt.Print(vehicle.New(80, 40).DOT())
+}
@@ -0,0 +1,271 @@
+package kv
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cached 在 Store 前面加一层 LRU 读缓存，最多缓存 size 个条目。
+//
+// 写入和删除先写下层再清掉缓存里的旧值；缓存的条目带着下层返回的过期时间，过期后不会再被读到。
+// 未命中的读取期间如果同一个键被写入或删除，读到的结果不放入缓存，以免把旧值缓存下来。
+// maxAge 大于 0 时缓存条目最多保留这么久，用于下层可能被其他进程修改的情况。
+// 不存在的键不缓存。
+type Cached[K ~string, V any] struct {
+	Store[K, V]
+	size   int
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	lru    *list.List // 元素是 *cacheItem，最近使用的在前
+	items  map[K]*list.Element
+	fills  map[K]*fill // 正在从下层读取的键
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cacheItem[K ~string, V any] struct {
+	entry Entry[K, V]
+	added time.Time
+}
+
+// fill 记录一个键上正在进行的下层读取。Put 或 Delete 清缓存时 gen 加一，
+// 读取开始之后 gen 变过的结果可能是旧值，不放入缓存。
+type fill struct {
+	gen     uint64
+	pending int
+}
+
+// NewCached 用大小为 size 的缓存包装 s。
+func NewCached[K ~string, V any](s Store[K, V], size int, maxAge time.Duration, opts ...Option) *Cached[K, V] {
+	o := buildOptions(opts)
+	return &Cached[K, V]{
+		Store: s, size: max(1, size), maxAge: maxAge, now: o.now,
+		lru: list.New(), items: make(map[K]*list.Element), fills: make(map[K]*fill),
+	}
+}
+
+// Get 实现 Store：先查缓存，未命中时读下层并放入缓存。
+func (c *Cached[K, V]) Get(ctx context.Context, key K) (Entry[K, V], error) {
+	if err := ctx.Err(); err != nil {
+		return Entry[K, V]{}, err
+	}
+	e, gen, ok := c.lookup(key)
+	if ok {
+		c.hits.Add(1)
+		return e, nil
+	}
+	c.misses.Add(1)
+	e, err := c.Store.Get(ctx, key)
+	c.add(key, gen, e, err == nil)
+	return e, err
+}
+
+// lookup 查缓存；未命中时登记一次下层读取，返回读取开始时的代数，之后必须调用 add。
+func (c *Cached[K, V]) lookup(key K) (Entry[K, V], uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*cacheItem[K, V])
+		now := c.now()
+		if !item.entry.Expired(now) && (c.maxAge <= 0 || now.Sub(item.added) < c.maxAge) {
+			c.lru.MoveToFront(el)
+			return item.entry, 0, true
+		}
+		c.lru.Remove(el)
+		delete(c.items, key)
+	}
+	f := c.fills[key]
+	if f == nil {
+		f = &fill{}
+		c.fills[key] = f
+	}
+	f.pending++
+	return Entry[K, V]{}, f.gen, false
+}
+
+// add 结束 lookup 登记的读取。ok 为 true 且读取期间键没有被 evict 时把 e 放入缓存。
+func (c *Cached[K, V]) add(key K, gen uint64, e Entry[K, V], ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f := c.fills[key]
+	if f.pending--; f.pending == 0 {
+		delete(c.fills, key)
+	}
+	if !ok || f.gen != gen {
+		return
+	}
+	item := &cacheItem[K, V]{entry: e, added: c.now()}
+	if el, ok := c.items[e.Key]; ok {
+		el.Value = item
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.items[e.Key] = c.lru.PushFront(item)
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem[K, V]).entry.Key)
+	}
+}
+
+func (c *Cached[K, V]) evict(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.lru.Remove(el)
+		delete(c.items, key)
+	}
+	if f := c.fills[key]; f != nil {
+		f.gen++
+	}
+}
+
+// Put 实现 Store。下层写入失败时也会清掉缓存，避免读到可能已经过时的值。
+func (c *Cached[K, V]) Put(ctx context.Context, key K, value V, ttl time.Duration) error {
+	err := c.Store.Put(ctx, key, value, ttl)
+	c.evict(key)
+	return err
+}
+
+// Delete 实现 Store。
+func (c *Cached[K, V]) Delete(ctx context.Context, key K) error {
+	err := c.Store.Delete(ctx, key)
+	c.evict(key)
+	return err
+}
+
+// Close 清空缓存并关闭下层。
+func (c *Cached[K, V]) Close() error {
+	c.mu.Lock()
+	c.lru.Init()
+	clear(c.items)
+	for _, f := range c.fills {
+		f.gen++
+	}
+	c.mu.Unlock()
+	return c.Store.Close()
+}
+
+// Stats 返回缓存命中和未命中次数。
+func (c *Cached[K, V]) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// OpStats 是一种操作的统计。
+type OpStats struct {
+	Calls  int64
+	Errors int64 // 不含 ErrNotFound
+	Total  time.Duration
+}
+
+// Metrics 是 Metered 的统计快照。
+type Metrics struct {
+	Get, Put, Delete, List OpStats
+	Hits, Misses           int64 // Get 找到和没找到（ErrNotFound）的次数
+}
+
+type opCounter struct {
+	calls, errors, nanos atomic.Int64
+}
+
+func (c *opCounter) stats() OpStats {
+	return OpStats{Calls: c.calls.Load(), Errors: c.errors.Load(), Total: time.Duration(c.nanos.Load())}
+}
+
+// Metered 统计每种操作的次数、错误数和耗时。
+type Metered[K ~string, V any] struct {
+	Store[K, V]
+	get, put, del, list opCounter
+	hits, misses        atomic.Int64
+}
+
+// NewMetered 包装 s。
+func NewMetered[K ~string, V any](s Store[K, V]) *Metered[K, V] {
+	return &Metered[K, V]{Store: s}
+}
+
+func (m *Metered[K, V]) observe(c *opCounter, start time.Time, err error) {
+	c.calls.Add(1)
+	c.nanos.Add(int64(time.Since(start)))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.errors.Add(1)
+	}
+}
+
+// Get 实现 Store。
+func (m *Metered[K, V]) Get(ctx context.Context, key K) (Entry[K, V], error) {
+	start := time.Now()
+	e, err := m.Store.Get(ctx, key)
+	m.observe(&m.get, start, err)
+	switch {
+	case err == nil:
+		m.hits.Add(1)
+	case errors.Is(err, ErrNotFound):
+		m.misses.Add(1)
+	}
+	return e, err
+}
+
+// Put 实现 Store。
+func (m *Metered[K, V]) Put(ctx context.Context, key K, value V, ttl time.Duration) error {
+	start := time.Now()
+	err := m.Store.Put(ctx, key, value, ttl)
+	m.observe(&m.put, start, err)
+	return err
+}
+
+// Delete 实现 Store。
+func (m *Metered[K, V]) Delete(ctx context.Context, key K) error {
+	start := time.Now()
+	err := m.Store.Delete(ctx, key)
+	m.observe(&m.del, start, err)
+	return err
+}
+
+// List 实现 Store。
+func (m *Metered[K, V]) List(ctx context.Context, prefix K) ([]Entry[K, V], error) {
+	start := time.Now()
+	es, err := m.Store.List(ctx, prefix)
+	m.observe(&m.list, start, err)
+	return es, err
+}
+
+// Metrics 返回当前统计。
+func (m *Metered[K, V]) Metrics() Metrics {
+	return Metrics{
+		Get: m.get.stats(), Put: m.put.stats(), Delete: m.del.stats(), List: m.list.stats(),
+		Hits: m.hits.Load(), Misses: m.misses.Load(),
+	}
+}
+
+// ReadOnly 是 Store 的只读视图：Put 和 Delete 返回 ErrReadOnly，其余操作转给下层。
+type ReadOnly[K ~string, V any] struct {
+	Store[K, V]
+}
+
+// NewReadOnly 包装 s。
+func NewReadOnly[K ~string, V any](s Store[K, V]) ReadOnly[K, V] {
+	return ReadOnly[K, V]{Store: s}
+}
+
+// Put 总是返回 ErrReadOnly。
+func (r ReadOnly[K, V]) Put(ctx context.Context, key K, value V, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrReadOnly
+}
+
+// Delete 总是返回 ErrReadOnly。
+func (r ReadOnly[K, V]) Delete(ctx context.Context, key K) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrReadOnly
+}
@@ -0,0 +1,110 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowGet 在 Get 读到结果之后、返回之前停下，等 release 关闭，用来制造读与写的交错。
+type slowGet struct {
+	Store[string, int]
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (s *slowGet) Get(ctx context.Context, key string) (Entry[string, int], error) {
+	e, err := s.Store.Get(ctx, key)
+	if s.read != nil {
+		close(s.read)
+		<-s.release
+		s.read = nil
+	}
+	return e, err
+}
+
+func TestCachedDropsStaleFill(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name  string
+		write func(c *Cached[string, int]) error
+		want  error // 写入之后 Get 的结果：nil 表示读到 2
+	}{
+		{"put", func(c *Cached[string, int]) error { return c.Put(ctx, "k", 2, 0) }, nil},
+		{"delete", func(c *Cached[string, int]) error { return c.Delete(ctx, "k") }, ErrNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := NewMemory[string, int]()
+			if err := mem.Put(ctx, "k", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			slow := &slowGet{Store: mem, read: make(chan struct{}), release: make(chan struct{})}
+			c := NewCached[string, int](slow, 8, 0)
+
+			done := make(chan Entry[string, int])
+			go func() {
+				e, _ := c.Get(ctx, "k")
+				done <- e
+			}()
+			<-slow.read // 未命中的 Get 已经从下层读到旧值 1
+			if err := tc.write(c); err != nil {
+				t.Fatal(err)
+			}
+			close(slow.release)
+			if e := <-done; e.Value != 1 {
+				t.Fatalf("concurrent Get = %d, want the value it read (1)", e.Value)
+			}
+
+			e, err := c.Get(ctx, "k")
+			if !errors.Is(err, tc.want) || (err == nil && e.Value != 2) {
+				t.Errorf("Get after %s = %v, %v; stale fill was cached", tc.name, e.Value, err)
+			}
+			if hits, misses := c.Stats(); hits != 0 || misses != 2 {
+				t.Errorf("hits=%d misses=%d, want 0 and 2", hits, misses)
+			}
+			if n := len(c.fills); n != 0 {
+				t.Errorf("%d fills left after all reads finished", n)
+			}
+		})
+	}
+}
+
+func TestCachedHitsAndEviction(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	mem := NewMemory[string, int](WithClock(clock))
+	c := NewCached[string, int](mem, 2, time.Minute, WithClock(clock))
+	for i, k := range []string{"a", "b", "c"} {
+		if err := c.Put(ctx, k, i, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, k := range []string{"a", "b", "a", "c", "a"} { // c 挤掉最久未用的 b
+		if _, err := c.Get(ctx, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits, misses := c.Stats(); hits != 2 || misses != 3 {
+		t.Errorf("hits=%d misses=%d, want 2 and 3", hits, misses)
+	}
+	if _, err := c.Get(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, misses := c.Stats(); misses != 4 {
+		t.Errorf("b should have been evicted, misses=%d", misses)
+	}
+
+	// maxAge 到期后重新读下层，能看到绕过缓存的写入
+	if err := mem.Put(ctx, "b", 42, 0); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := c.Get(ctx, "b"); e.Value != 1 {
+		t.Errorf("within maxAge: b = %d, want cached 1", e.Value)
+	}
+	now = now.Add(time.Minute)
+	if e, _ := c.Get(ctx, "b"); e.Value != 42 {
+		t.Errorf("after maxAge: b = %d, want 42", e.Value)
+	}
+}
@@ -0,0 +1,70 @@
+package kv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// record 是 Log 的一条日志，也是 Snapshot 里的一个条目。
+// Value 用指针，删除记录里就没有 value 字段；Exp 是 Unix 纳秒，0 表示永不过期。
+type record[K ~string, V any] struct {
+	Op    string `json:"op,omitempty"`
+	Key   K      `json:"key"`
+	Value *V     `json:"value,omitempty"`
+	Exp   int64  `json:"exp,omitempty"`
+}
+
+func toRecord[K ~string, V any](op string, e Entry[K, V]) record[K, V] {
+	r := record[K, V]{Op: op, Key: e.Key, Value: &e.Value}
+	if !e.Expires.IsZero() {
+		r.Exp = e.Expires.UnixNano()
+	}
+	return r
+}
+
+func (r record[K, V]) entry() (Entry[K, V], error) {
+	if r.Value == nil {
+		return Entry[K, V]{}, fmt.Errorf("missing value for key %q", r.Key)
+	}
+	e := Entry[K, V]{Key: r.Key, Value: *r.Value}
+	if r.Exp != 0 {
+		e.Expires = time.Unix(0, r.Exp)
+	}
+	return e, nil
+}
+
+// writeFileAtomic 先写临时文件再改名，崩溃时 path 要么是旧内容要么是新内容。
+func writeFileAtomic(path string, data []byte, sync bool) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil && sync {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if sync {
+		syncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+// syncDir 让改名落盘。有的平台不支持对目录 fsync，错误忽略。
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
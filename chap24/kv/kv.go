@@ -0,0 +1,124 @@
+// Package kv 把 chap24/interfaces.go 里的 Storage 接口扩展成一个可插拔的键值存储：
+// 泛型的 Store[K,V] 接口支持 context、前缀列举和 TTL，后端有内存（Memory）、
+// 追加日志文件（Log，支持压缩和崩溃恢复）和 JSON 快照文件（Snapshot），
+// 装饰器（Cached、Metered、ReadOnly）可以叠加在任意后端上。
+//
+// 所有后端都应通过 kv/kvtest 中的一致性测试。
+package kv
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound 表示键不存在或已过期。
+	ErrNotFound = errors.New("kv: key not found")
+	// ErrClosed 表示存储已经关闭。
+	ErrClosed = errors.New("kv: store closed")
+	// ErrReadOnly 表示在只读存储上写入。
+	ErrReadOnly = errors.New("kv: store is read-only")
+	// ErrCorrupt 表示数据文件损坏且无法自动恢复。
+	ErrCorrupt = errors.New("kv: corrupt data file")
+)
+
+// Entry 是一个键值对；Expires 为零值表示永不过期。
+type Entry[K ~string, V any] struct {
+	Key     K
+	Value   V
+	Expires time.Time
+}
+
+// Expired 报告 e 在 now 时是否已经过期。
+func (e Entry[K, V]) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Store 是键值存储。实现必须并发安全，并在 ctx 已取消时返回 ctx.Err()。
+type Store[K ~string, V any] interface {
+	// Get 返回 key 的条目，不存在或已过期时返回 ErrNotFound。
+	Get(ctx context.Context, key K) (Entry[K, V], error)
+	// Put 写入 key；ttl 大于 0 时条目在 ttl 之后过期，为 0 表示永不过期。
+	Put(ctx context.Context, key K, value V, ttl time.Duration) error
+	// Delete 删除 key，不存在或已过期时返回 ErrNotFound。
+	Delete(ctx context.Context, key K) error
+	// List 按键排序返回所有以 prefix 开头且未过期的条目，prefix 为空时返回全部。
+	List(ctx context.Context, prefix K) ([]Entry[K, V], error)
+	// Close 释放资源；之后的调用返回 ErrClosed。
+	Close() error
+}
+
+// Option 配置后端。
+type Option func(*options)
+
+type options struct {
+	now         func() time.Time
+	compactMin  int
+	syncOnWrite bool
+}
+
+func buildOptions(opts []Option) options {
+	o := options{now: time.Now, compactMin: 1000}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithClock 替换时间来源，用于测试 TTL。
+func WithClock(now func() time.Time) Option {
+	return func(o *options) { o.now = now }
+}
+
+// WithAutoCompact 设置 Log 自动压缩的阈值：日志记录数不少于 n 且超过存活键数的两倍时压缩。
+// n 小于等于 0 时关闭自动压缩。默认 1000。
+func WithAutoCompact(n int) Option {
+	return func(o *options) { o.compactMin = n }
+}
+
+// WithSync 让文件后端每次写入后调用 fsync。更安全，也更慢。
+func WithSync(sync bool) Option {
+	return func(o *options) { o.syncOnWrite = sync }
+}
+
+// table 是各后端共用的内存索引，不加锁，由调用方负责同步。
+type table[K ~string, V any] map[K]Entry[K, V]
+
+func (t table[K, V]) get(key K, now time.Time) (Entry[K, V], error) {
+	e, ok := t[key]
+	if !ok || e.Expired(now) {
+		return Entry[K, V]{}, ErrNotFound
+	}
+	return e, nil
+}
+
+func (t table[K, V]) list(prefix K, now time.Time) []Entry[K, V] {
+	var out []Entry[K, V]
+	for k, e := range t {
+		if strings.HasPrefix(string(k), string(prefix)) && !e.Expired(now) {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// sweep 删除已过期的条目，返回剩下的条目数。
+func (t table[K, V]) sweep(now time.Time) int {
+	for k, e := range t {
+		if e.Expired(now) {
+			delete(t, k)
+		}
+	}
+	return len(t)
+}
+
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
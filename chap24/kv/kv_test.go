@@ -0,0 +1,72 @@
+package kv_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"books/chap24/kv"
+	"books/chap24/kv/kvtest"
+)
+
+// Item 与 kv_demo 相同，覆盖字符串、数字和切片的 JSON 往返。
+type Item struct {
+	Name  string
+	Qty   int
+	Price float64
+	Tags  []string
+}
+
+func sample(i int) Item {
+	return Item{Name: fmt.Sprintf("item-%d", i), Qty: i, Price: float64(i) * 1.25, Tags: []string{"t", fmt.Sprint(i % 3)}}
+}
+
+type store = kv.Store[string, Item]
+
+func memory(_ string, now func() time.Time) (store, error) {
+	return kv.NewMemory[string, Item](kv.WithClock(now)), nil
+}
+
+func logFile(dir string, now func() time.Time) (store, error) {
+	return kv.OpenLog[string, Item](filepath.Join(dir, "data.log"), kv.WithClock(now))
+}
+
+func snapshot(dir string, now func() time.Time) (store, error) {
+	return kv.OpenSnapshot[string, Item](filepath.Join(dir, "data.json"), kv.WithClock(now))
+}
+
+func compacting(dir string, now func() time.Time) (store, error) {
+	return kv.OpenLog[string, Item](filepath.Join(dir, "data.log"), kv.WithClock(now), kv.WithAutoCompact(16))
+}
+
+func wrap(open func(string, func() time.Time) (store, error), deco func(store, func() time.Time) store) func(string, func() time.Time) (store, error) {
+	return func(dir string, now func() time.Time) (store, error) {
+		s, err := open(dir, now)
+		if err != nil {
+			return nil, err
+		}
+		return deco(s, now), nil
+	}
+}
+
+func cached(s store, now func() time.Time) store {
+	return kv.NewCached(s, 8, 0, kv.WithClock(now))
+}
+
+func metered(s store, _ func() time.Time) store { return kv.NewMetered(s) }
+
+func TestConformance(t *testing.T) {
+	for _, b := range []kvtest.Backend[Item]{
+		{Name: "Memory", Open: memory},
+		{Name: "Log", Open: logFile, Persistent: true},
+		{Name: "Log(auto-compact 16)", Open: compacting, Persistent: true},
+		{Name: "Snapshot", Open: snapshot, Persistent: true},
+		{Name: "Cached(Memory)", Open: wrap(memory, cached)},
+		{Name: "Cached(Log)", Open: wrap(logFile, cached), Persistent: true},
+		{Name: "Metered(Snapshot)", Open: wrap(snapshot, metered), Persistent: true},
+		{Name: "Metered(Cached(Log))", Open: wrap(wrap(compacting, cached), metered), Persistent: true},
+	} {
+		t.Run(b.Name, func(t *testing.T) { kvtest.Test(t, b, sample) })
+	}
+}
@@ -0,0 +1,398 @@
+// Package kvtest 是 kv.Store 的一致性测试：每个后端和装饰器都应该通过同一组用例。
+//
+// 各后端在自己的测试里调用 Test（chap24/kv 的 kv_test.go 覆盖了全部后端和装饰器组合），
+// chap24/kv_demo 用 Run 把同一组用例的结果打印出来。
+package kvtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"books/chap24/kv"
+)
+
+// Clock 是手动拨动的时钟，用于测试 TTL。
+type Clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewClock 返回从固定时间开始的时钟。
+func NewClock() *Clock {
+	return &Clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now 返回当前时间。
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Advance 把时钟拨快 d。
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// Backend 描述被测的后端。
+type Backend[V any] struct {
+	Name string
+	// Open 在目录 dir 中打开存储，时间取自 now。用同一个 dir 再次打开时，
+	// Persistent 的后端应该看到关闭前写入的数据。
+	Open       func(dir string, now func() time.Time) (kv.Store[string, V], error)
+	Persistent bool
+}
+
+// Result 是一个用例的结果，Err 为 nil 表示通过。
+type Result struct {
+	Case string
+	Err  error
+}
+
+type testCase[V any] struct {
+	name string
+	run  func(t *tester[V]) error
+}
+
+// Test 把每个用例作为 t 的子测试运行。sample 的含义同 Run。
+func Test[V any](t *testing.T, b Backend[V], sample func(i int) V) {
+	for _, c := range cases(b) {
+		t.Run(c.name, func(t *testing.T) {
+			if err := runCase(b, sample, c); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Run 对 b 运行全部用例。sample(i) 为不同的 i 返回不同的值，用于检查值能原样往返。
+func Run[V any](b Backend[V], sample func(i int) V) []Result {
+	cs := cases(b)
+	results := make([]Result, 0, len(cs))
+	for _, c := range cs {
+		results = append(results, Result{Case: c.name, Err: runCase(b, sample, c)})
+	}
+	return results
+}
+
+func cases[V any](b Backend[V]) []testCase[V] {
+	cases := []testCase[V]{
+		{"missing", testMissing[V]},
+		{"put-get-overwrite", testPutGet[V]},
+		{"delete", testDelete[V]},
+		{"list-prefix", testList[V]},
+		{"ttl", testTTL[V]},
+		{"context", testContext[V]},
+		{"concurrent", testConcurrent[V]},
+		{"close", testClose[V]},
+	}
+	if b.Persistent {
+		cases = append(cases, testCase[V]{"reopen", testReopen[V]})
+	}
+	return cases
+}
+
+func runCase[V any](b Backend[V], sample func(int) V, c testCase[V]) (err error) {
+	dir, err := os.MkdirTemp("", "kvtest-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	t := &tester[V]{ctx: context.Background(), backend: b, dir: dir, clock: NewClock(), sample: sample}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if t.store != nil {
+			t.store.Close()
+		}
+	}()
+	if err := t.open(); err != nil {
+		return err
+	}
+	return c.run(t)
+}
+
+// tester 保存一个用例的环境。
+type tester[V any] struct {
+	ctx     context.Context
+	backend Backend[V]
+	dir     string
+	clock   *Clock
+	sample  func(int) V
+	store   kv.Store[string, V]
+}
+
+func (t *tester[V]) open() error {
+	s, err := t.backend.Open(t.dir, t.clock.Now)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	t.store = s
+	return nil
+}
+
+func (t *tester[V]) reopen() error {
+	s := t.store
+	t.store = nil
+	if err := s.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	return t.open()
+}
+
+func (t *tester[V]) put(key string, i int, ttl time.Duration) error {
+	if err := t.store.Put(t.ctx, key, t.sample(i), ttl); err != nil {
+		return fmt.Errorf("Put(%q): %w", key, err)
+	}
+	return nil
+}
+
+// expect 检查 key 的值是 sample(i)。
+func (t *tester[V]) expect(key string, i int) error {
+	e, err := t.store.Get(t.ctx, key)
+	if err != nil {
+		return fmt.Errorf("Get(%q): %w", key, err)
+	}
+	if e.Key != key || !reflect.DeepEqual(e.Value, t.sample(i)) {
+		return fmt.Errorf("Get(%q) = %q %+v, want %+v", key, e.Key, e.Value, t.sample(i))
+	}
+	return nil
+}
+
+func (t *tester[V]) expectMissing(key string) error {
+	if _, err := t.store.Get(t.ctx, key); !errors.Is(err, kv.ErrNotFound) {
+		return fmt.Errorf("Get(%q) error = %v, want ErrNotFound", key, err)
+	}
+	return nil
+}
+
+func (t *tester[V]) expectKeys(prefix string, want ...string) error {
+	es, err := t.store.List(t.ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("List(%q): %w", prefix, err)
+	}
+	got := make([]string, len(es))
+	for i, e := range es {
+		got[i] = e.Key
+	}
+	if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+		return fmt.Errorf("List(%q) = %q, want %q", prefix, got, want)
+	}
+	return nil
+}
+
+// all 依次执行检查，返回第一个错误。
+func all(checks ...func() error) error {
+	for _, c := range checks {
+		if err := c(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testMissing[V any](t *tester[V]) error {
+	if err := t.expectMissing("nope"); err != nil {
+		return err
+	}
+	if err := t.store.Delete(t.ctx, "nope"); !errors.Is(err, kv.ErrNotFound) {
+		return fmt.Errorf("Delete(missing) error = %v, want ErrNotFound", err)
+	}
+	return t.expectKeys("")
+}
+
+func testPutGet[V any](t *tester[V]) error {
+	return all(
+		func() error { return t.put("k", 1, 0) },
+		func() error { return t.expect("k", 1) },
+		func() error { return t.put("k", 2, 0) },
+		func() error { return t.expect("k", 2) },
+		func() error { return t.put("", 3, 0) }, // 空键也是合法的键
+		func() error { return t.expect("", 3) },
+		func() error { return t.expectKeys("", "", "k") },
+	)
+}
+
+func testDelete[V any](t *tester[V]) error {
+	return all(
+		func() error { return t.put("a", 1, 0) },
+		func() error { return t.put("b", 2, 0) },
+		func() error { return t.store.Delete(t.ctx, "a") },
+		func() error { return t.expectMissing("a") },
+		func() error { return t.expect("b", 2) },
+		func() error {
+			if err := t.store.Delete(t.ctx, "a"); !errors.Is(err, kv.ErrNotFound) {
+				return fmt.Errorf("second Delete error = %v, want ErrNotFound", err)
+			}
+			return nil
+		},
+		func() error { return t.put("a", 3, 0) }, // 删除后可以重新写入
+		func() error { return t.expect("a", 3) },
+	)
+}
+
+func testList[V any](t *tester[V]) error {
+	keys := []string{"user/2", "user/10", "users", "order/1", "user/1", "u"}
+	for i, k := range keys {
+		if err := t.put(k, i, 0); err != nil {
+			return err
+		}
+	}
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	return all(
+		func() error { return t.expectKeys("", sorted...) },
+		func() error { return t.expectKeys("user/", "user/1", "user/10", "user/2") },
+		func() error { return t.expectKeys("user", "user/1", "user/10", "user/2", "users") },
+		func() error { return t.expectKeys("order/1", "order/1") },
+		func() error { return t.expectKeys("x") },
+		func() error {
+			es, _ := t.store.List(t.ctx, "order/")
+			if len(es) != 1 || !reflect.DeepEqual(es[0].Value, t.sample(3)) {
+				return fmt.Errorf("List(order/) values = %+v", es)
+			}
+			return nil
+		},
+	)
+}
+
+func testTTL[V any](t *tester[V]) error {
+	return all(
+		func() error { return t.put("short", 1, 10*time.Second) },
+		func() error { return t.put("long", 2, time.Hour) },
+		func() error { return t.put("forever", 3, 0) },
+		func() error {
+			e, err := t.store.Get(t.ctx, "short")
+			if err != nil {
+				return err
+			}
+			if want := t.clock.Now().Add(10 * time.Second); !e.Expires.Equal(want) {
+				return fmt.Errorf("Expires = %v, want %v", e.Expires, want)
+			}
+			return nil
+		},
+		func() error { t.clock.Advance(9 * time.Second); return t.expect("short", 1) },
+		func() error { t.clock.Advance(time.Second); return t.expectMissing("short") },
+		func() error { return t.expectKeys("", "forever", "long") },
+		func() error {
+			if err := t.store.Delete(t.ctx, "short"); !errors.Is(err, kv.ErrNotFound) {
+				return fmt.Errorf("Delete(expired) error = %v, want ErrNotFound", err)
+			}
+			return nil
+		},
+		func() error { return t.put("long", 4, 0) }, // 覆盖会去掉 TTL
+		func() error { t.clock.Advance(2 * time.Hour); return t.expect("long", 4) },
+		func() error { return t.put("short", 5, 0) },
+		func() error { return t.expect("short", 5) },
+	)
+}
+
+func testContext[V any](t *tester[V]) error {
+	if err := t.put("k", 1, 0); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(t.ctx)
+	cancel()
+	_, gerr := t.store.Get(ctx, "k")
+	perr := t.store.Put(ctx, "k", t.sample(2), 0)
+	derr := t.store.Delete(ctx, "k")
+	_, lerr := t.store.List(ctx, "")
+	for op, err := range map[string]error{"Get": gerr, "Put": perr, "Delete": derr, "List": lerr} {
+		if !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("%s with canceled ctx: error = %v, want context.Canceled", op, err)
+		}
+	}
+	return t.expect("k", 1) // 取消的写入不能生效
+}
+
+func testConcurrent[V any](t *tester[V]) error {
+	const workers, n = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				key := fmt.Sprintf("w%d/%03d", w, i)
+				if err := all(
+					func() error { return t.put(key, i, 0) },
+					func() error { return t.expect(key, i) },
+					func() error { _, err := t.store.List(t.ctx, fmt.Sprintf("w%d/", w)); return err },
+				); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	es, err := t.store.List(t.ctx, "w")
+	if err != nil {
+		return err
+	}
+	if len(es) != workers*n {
+		return fmt.Errorf("List after concurrent writes: %d entries, want %d", len(es), workers*n)
+	}
+	return nil
+}
+
+func testClose[V any](t *tester[V]) error {
+	if err := t.put("k", 1, 0); err != nil {
+		return err
+	}
+	s := t.store
+	t.store = nil
+	if err := s.Close(); err != nil {
+		return fmt.Errorf("Close: %w", err)
+	}
+	if _, err := s.Get(t.ctx, "k"); !errors.Is(err, kv.ErrClosed) {
+		return fmt.Errorf("Get after Close: error = %v, want ErrClosed", err)
+	}
+	if err := s.Put(t.ctx, "k", t.sample(2), 0); !errors.Is(err, kv.ErrClosed) {
+		return fmt.Errorf("Put after Close: error = %v, want ErrClosed", err)
+	}
+	return nil
+}
+
+func testReopen[V any](t *tester[V]) error {
+	for i := 0; i < 20; i++ {
+		if err := t.put(fmt.Sprintf("k%02d", i), i, 0); err != nil {
+			return err
+		}
+	}
+	return all(
+		func() error { return t.put("k00", 100, 0) },
+		func() error { return t.store.Delete(t.ctx, "k01") },
+		func() error { return t.put("ttl", 7, time.Minute) },
+		t.reopen,
+		func() error { return t.expect("k00", 100) },
+		func() error { return t.expectMissing("k01") },
+		func() error { return t.expect("k19", 19) },
+		func() error { return t.expect("ttl", 7) },
+		func() error { t.clock.Advance(time.Minute); return t.expectMissing("ttl") },
+		t.reopen, // 过期条目重新打开后仍然是过期的
+		func() error { return t.expectMissing("ttl") },
+		func() error {
+			es, err := t.store.List(t.ctx, "k")
+			if err != nil || len(es) != 19 {
+				return fmt.Errorf("List after reopen: %d entries, %v; want 19", len(es), err)
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,255 @@
+package kv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+)
+
+// Log 是追加日志后端：每次写入在文件末尾追加一行 "<crc32> <json>"，
+// 打开时重放日志重建内存索引。
+//
+// 进程在写到一半时崩溃只会留下不完整或校验失败的最后一行，OpenLog 会把它截掉（见 Recovered）；
+// 中间某一行损坏则说明文件被破坏，返回 ErrCorrupt。
+// 覆盖和删除会让日志越来越长，Compact 把存活的条目重写成新文件后原子地替换旧文件。
+type Log[K ~string, V any] struct {
+	Memory[K, V]
+	path       string
+	f          *os.File
+	sync       bool
+	compactMin int
+	records    int   // 当前文件中的记录数
+	recovered  int64 // 打开时截掉的字节数
+}
+
+// OpenLog 打开或创建 path 上的日志文件。
+func OpenLog[K ~string, V any](path string, opts ...Option) (*Log[K, V], error) {
+	o := buildOptions(opts)
+	l := &Log[K, V]{
+		Memory: Memory[K, V]{now: o.now, data: make(table[K, V])},
+		path:   path, sync: o.syncOnWrite, compactMin: o.compactMin,
+	}
+	os.Remove(path + ".tmp") // 上次压缩到一半留下的临时文件
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.replay(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.f = f
+	return l, nil
+}
+
+// replay 逐行重放日志，截掉末尾的残缺记录，最后把文件偏移移到末尾。
+func (l *Log[K, V]) replay(f *os.File) error {
+	r := bufio.NewReader(f)
+	var off int64
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		var rec record[K, V]
+		perr := io.ErrUnexpectedEOF // 没有换行符：最后一行没写完
+		if err == nil {
+			perr = decodeLine(line, &rec)
+		}
+		if perr == nil {
+			perr = l.apply(rec)
+		}
+		if perr != nil {
+			if rest, _ := r.Peek(1); len(rest) > 0 {
+				return fmt.Errorf("%w: %s:%d: %v", ErrCorrupt, l.path, n, perr)
+			}
+			if err := f.Truncate(off); err != nil {
+				return err
+			}
+			l.recovered = int64(len(line))
+			break
+		}
+		off += int64(len(line))
+		l.records++
+	}
+	_, err := f.Seek(off, io.SeekStart)
+	return err
+}
+
+func (l *Log[K, V]) apply(rec record[K, V]) error {
+	switch rec.Op {
+	case "put":
+		e, err := rec.entry()
+		if err != nil {
+			return err
+		}
+		l.data[e.Key] = e
+	case "del":
+		delete(l.data, rec.Key)
+	default:
+		return fmt.Errorf("unknown op %q", rec.Op)
+	}
+	return nil
+}
+
+func encodeLine(rec any) ([]byte, error) {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "%08x %s\n", crc32.ChecksumIEEE(body), body), nil
+}
+
+func decodeLine(line []byte, rec any) error {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	var sum uint32
+	if len(line) < 10 || line[8] != ' ' {
+		return errors.New("malformed record")
+	}
+	if _, err := fmt.Sscanf(string(line[:8]), "%08x", &sum); err != nil {
+		return fmt.Errorf("malformed checksum: %v", err)
+	}
+	body := line[9:]
+	if crc32.ChecksumIEEE(body) != sum {
+		return errors.New("checksum mismatch")
+	}
+	return json.Unmarshal(body, rec)
+}
+
+// Recovered 返回打开时因崩溃恢复而截掉的字节数，0 表示日志完好。
+func (l *Log[K, V]) Recovered() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.recovered
+}
+
+// Records 返回日志文件中的记录数和存活的条目数。
+func (l *Log[K, V]) Records() (records, live int) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.records, len(l.data)
+}
+
+// Put 实现 Store。
+func (l *Log[K, V]) Put(ctx context.Context, key K, value V, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+	e := Entry[K, V]{Key: key, Value: value, Expires: expiry(l.now(), ttl)}
+	if err := l.append(toRecord("put", e)); err != nil {
+		return err
+	}
+	l.data[key] = e
+	return l.maybeCompact()
+}
+
+// Delete 实现 Store。
+func (l *Log[K, V]) Delete(ctx context.Context, key K) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+	if _, err := l.data.get(key, l.now()); err != nil {
+		return err
+	}
+	if err := l.append(record[K, V]{Op: "del", Key: key}); err != nil {
+		return err
+	}
+	delete(l.data, key)
+	return l.maybeCompact()
+}
+
+// append 一次 Write 写入整行，失败时把文件截回写入前的长度。
+func (l *Log[K, V]) append(rec record[K, V]) error {
+	line, err := encodeLine(rec)
+	if err != nil {
+		return err
+	}
+	off, err := l.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := l.f.Write(line); err != nil {
+		l.f.Truncate(off)
+		l.f.Seek(off, io.SeekStart)
+		return fmt.Errorf("kv: append %s: %w", l.path, err)
+	}
+	if l.sync {
+		if err := l.f.Sync(); err != nil {
+			return fmt.Errorf("kv: sync %s: %w", l.path, err)
+		}
+	}
+	l.records++
+	return nil
+}
+
+func (l *Log[K, V]) maybeCompact() error {
+	if l.compactMin <= 0 || l.records < l.compactMin || l.records <= 2*len(l.data) {
+		return nil
+	}
+	return l.compact()
+}
+
+// Compact 丢弃被覆盖、删除和已过期的记录，重写日志文件。
+func (l *Log[K, V]) Compact(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.check(ctx); err != nil {
+		return err
+	}
+	return l.compact()
+}
+
+func (l *Log[K, V]) compact() error {
+	l.data.sweep(l.now())
+	var buf bytes.Buffer
+	for _, e := range l.data.list("", l.now()) {
+		line, err := encodeLine(toRecord("put", e))
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+	}
+	if err := writeFileAtomic(l.path, buf.Bytes(), l.sync); err != nil {
+		return fmt.Errorf("kv: compact %s: %w", l.path, err)
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("kv: compact %s: %w", l.path, err)
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return err
+	}
+	l.f.Close()
+	l.f, l.records = f, len(l.data)
+	return nil
+}
+
+// Close 实现 Store，关闭前把日志刷到磁盘。
+func (l *Log[K, V]) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	l.closed, l.data = true, nil
+	err := l.f.Sync()
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package kv
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory 是内存后端，相当于加了锁、TTL 和前缀列举的 MemoryStorage。
+type Memory[K ~string, V any] struct {
+	mu     sync.RWMutex
+	now    func() time.Time
+	data   table[K, V]
+	closed bool
+}
+
+// NewMemory 创建空的内存存储。
+func NewMemory[K ~string, V any](opts ...Option) *Memory[K, V] {
+	o := buildOptions(opts)
+	return &Memory[K, V]{now: o.now, data: make(table[K, V])}
+}
+
+// check 在持有锁时调用。
+func (m *Memory[K, V]) check(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Get 实现 Store。
+func (m *Memory[K, V]) Get(ctx context.Context, key K) (Entry[K, V], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(ctx); err != nil {
+		return Entry[K, V]{}, err
+	}
+	return m.data.get(key, m.now())
+}
+
+// Put 实现 Store。
+func (m *Memory[K, V]) Put(ctx context.Context, key K, value V, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.check(ctx); err != nil {
+		return err
+	}
+	m.data[key] = Entry[K, V]{Key: key, Value: value, Expires: expiry(m.now(), ttl)}
+	return nil
+}
+
+// Delete 实现 Store。
+func (m *Memory[K, V]) Delete(ctx context.Context, key K) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.check(ctx); err != nil {
+		return err
+	}
+	if _, err := m.data.get(key, m.now()); err != nil {
+		return err
+	}
+	delete(m.data, key)
+	return nil
+}
+
+// List 实现 Store。
+func (m *Memory[K, V]) List(ctx context.Context, prefix K) ([]Entry[K, V], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(ctx); err != nil {
+		return nil, err
+	}
+	return m.data.list(prefix, m.now()), nil
+}
+
+// Sweep 删除已过期的条目，返回剩下的条目数。过期条目本来就读不到，
+// 只是会一直占用内存，长期运行时可以定期调用。
+func (m *Memory[K, V]) Sweep() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data.sweep(m.now())
+}
+
+// Close 实现 Store。
+func (m *Memory[K, V]) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	m.closed, m.data = true, nil
+	return nil
+}
@@ -0,0 +1,101 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Snapshot 把全部数据保存在一个 JSON 文件里，每次写入都原子地重写整个文件。
+// 适合数据量小、读多写少的场景，文件可以直接用编辑器查看。
+type Snapshot[K ~string, V any] struct {
+	Memory[K, V]
+	path string
+	sync bool
+}
+
+type snapshotFile[K ~string, V any] struct {
+	Version int            `json:"version"`
+	Entries []record[K, V] `json:"entries"`
+}
+
+// OpenSnapshot 打开 path 上的快照文件，文件不存在时从空存储开始。
+func OpenSnapshot[K ~string, V any](path string, opts ...Option) (*Snapshot[K, V], error) {
+	o := buildOptions(opts)
+	s := &Snapshot[K, V]{Memory: Memory[K, V]{now: o.now, data: make(table[K, V])}, path: path, sync: o.syncOnWrite}
+	os.Remove(path + ".tmp") // 上次写到一半的临时文件
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file snapshotFile[K, V]
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, path, err)
+	}
+	for i, r := range file.Entries {
+		e, err := r.entry()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: entry %d: %v", ErrCorrupt, path, i, err)
+		}
+		s.data[e.Key] = e
+	}
+	return s, nil
+}
+
+// Put 实现 Store。
+func (s *Snapshot[K, V]) Put(ctx context.Context, key K, value V, ttl time.Duration) error {
+	return s.mutate(ctx, func() error {
+		s.data[key] = Entry[K, V]{Key: key, Value: value, Expires: expiry(s.now(), ttl)}
+		return nil
+	})
+}
+
+// Delete 实现 Store。
+func (s *Snapshot[K, V]) Delete(ctx context.Context, key K) error {
+	return s.mutate(ctx, func() error {
+		if _, err := s.data.get(key, s.now()); err != nil {
+			return err
+		}
+		delete(s.data, key)
+		return nil
+	})
+}
+
+// mutate 修改内存中的数据并写盘；写盘失败时恢复修改前的数据。
+func (s *Snapshot[K, V]) mutate(ctx context.Context, change func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(ctx); err != nil {
+		return err
+	}
+	old := make(table[K, V], len(s.data))
+	for k, e := range s.data {
+		old[k] = e
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		s.data = old
+		return err
+	}
+	return nil
+}
+
+func (s *Snapshot[K, V]) save() error {
+	s.data.sweep(s.now())
+	file := snapshotFile[K, V]{Version: 1, Entries: make([]record[K, V], 0, len(s.data))}
+	for _, e := range s.data.list("", s.now()) {
+		file.Entries = append(file.Entries, toRecord("", e))
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, append(data, '\n'), s.sync)
+}
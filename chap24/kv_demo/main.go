@@ -0,0 +1,220 @@
+// 独立运行：go run ./chap24/kv_demo
+// 演示：对所有 kv 后端和装饰器组合运行 kvtest 一致性测试，然后演示日志压缩、崩溃恢复、只读视图和统计。
+// 有用例失败时以状态码 1 退出。
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"books/chap24/kv"
+	"books/chap24/kv/kvtest"
+)
+
+// Item 是测试用的值类型，覆盖字符串、数字和切片的 JSON 往返。
+type Item struct {
+	Name  string
+	Qty   int
+	Price float64
+	Tags  []string
+}
+
+func sample(i int) Item {
+	return Item{Name: fmt.Sprintf("item-%d", i), Qty: i, Price: float64(i) * 1.25, Tags: []string{"t", fmt.Sprint(i % 3)}}
+}
+
+type store = kv.Store[string, Item]
+
+func memory(_ string, now func() time.Time) (store, error) {
+	return kv.NewMemory[string, Item](kv.WithClock(now)), nil
+}
+
+func logFile(dir string, now func() time.Time) (store, error) {
+	return kv.OpenLog[string, Item](filepath.Join(dir, "data.log"), kv.WithClock(now))
+}
+
+func snapshot(dir string, now func() time.Time) (store, error) {
+	return kv.OpenSnapshot[string, Item](filepath.Join(dir, "data.json"), kv.WithClock(now))
+}
+
+// compacting 每写 16 条就可能压缩一次，让一致性测试覆盖压缩后的读写。
+func compacting(dir string, now func() time.Time) (store, error) {
+	return kv.OpenLog[string, Item](filepath.Join(dir, "data.log"), kv.WithClock(now), kv.WithAutoCompact(16))
+}
+
+// wrap 把装饰器套在 open 返回的存储上。
+func wrap(open func(string, func() time.Time) (store, error), deco func(store, func() time.Time) store) func(string, func() time.Time) (store, error) {
+	return func(dir string, now func() time.Time) (store, error) {
+		s, err := open(dir, now)
+		if err != nil {
+			return nil, err
+		}
+		return deco(s, now), nil
+	}
+}
+
+func cached(s store, now func() time.Time) store {
+	return kv.NewCached(s, 8, 0, kv.WithClock(now))
+}
+
+func metered(s store, _ func() time.Time) store { return kv.NewMetered(s) }
+
+var ctx = context.Background()
+
+func main() {
+	backends := []kvtest.Backend[Item]{
+		{Name: "Memory", Open: memory},
+		{Name: "Log", Open: logFile, Persistent: true},
+		{Name: "Log(auto-compact 16)", Open: compacting, Persistent: true},
+		{Name: "Snapshot", Open: snapshot, Persistent: true},
+		{Name: "Cached(Memory)", Open: wrap(memory, cached)},
+		{Name: "Cached(Log)", Open: wrap(logFile, cached), Persistent: true},
+		{Name: "Metered(Snapshot)", Open: wrap(snapshot, metered), Persistent: true},
+		{Name: "Metered(Cached(Log))", Open: wrap(wrap(compacting, cached), metered), Persistent: true},
+	}
+
+	fmt.Println("=== 1. 一致性测试 ===")
+	failed := 0
+	for _, b := range backends {
+		var bad []string
+		results := kvtest.Run(b, sample)
+		for _, r := range results {
+			if r.Err != nil {
+				bad = append(bad, fmt.Sprintf("%s: %v", r.Case, r.Err))
+			}
+		}
+		if len(bad) == 0 {
+			fmt.Printf("  ✅ %-22s %d/%d\n", b.Name, len(results), len(results))
+			continue
+		}
+		failed += len(bad)
+		fmt.Printf("  ❌ %-22s %d/%d\n", b.Name, len(results)-len(bad), len(results))
+		for _, s := range bad {
+			fmt.Println("       ", s)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "kv-demo-")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+	check := func(ok bool, format string, args ...any) {
+		mark := "✅"
+		if !ok {
+			mark, failed = "❌", failed+1
+		}
+		fmt.Printf("  %s "+format+"\n", append([]any{mark}, args...)...)
+	}
+
+	fmt.Println("\n=== 2. 日志压缩 ===")
+	path := filepath.Join(dir, "counter.log")
+	l, _ := kv.OpenLog[string, int](path, kv.WithAutoCompact(0))
+	for i := 1; i <= 500; i++ {
+		l.Put(ctx, fmt.Sprintf("counter/%d", i%5), i, 0)
+	}
+	records, live := l.Records()
+	before := fileSize(path)
+	err = l.Compact(ctx)
+	after := fileSize(path)
+	records2, _ := l.Records()
+	check(err == nil && records == 500 && live == 5 && records2 == 5,
+		"500 次写入 5 个键：%d 条记录 %d 字节 → 压缩后 %d 条 %d 字节", records, before, records2, after)
+	e, _ := l.Get(ctx, "counter/0")
+	check(e.Value == 500, "压缩后 counter/0 = %d", e.Value)
+	l.Put(ctx, "counter/0", 501, 0)
+	l.Close()
+
+	fmt.Println("\n=== 3. 崩溃恢复 ===")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`1234abcd {"op":"put","key":"counter/0","val`) // 进程在写到一半时被杀掉
+	f.Close()
+	l, err = kv.OpenLog[string, int](path)
+	check(err == nil && l.Recovered() > 0, "残缺的最后一行被截掉 %d 字节，err=%v", l.Recovered(), err)
+	e, _ = l.Get(ctx, "counter/0")
+	check(e.Value == 501, "截断前最后一次完整写入仍在：counter/0 = %d", e.Value)
+	l.Put(ctx, "counter/9", 9, 0)
+	l.Close()
+	l, _ = kv.OpenLog[string, int](path)
+	records, live = l.Records()
+	check(l.Recovered() == 0 && live == 6, "恢复后继续写入，再次打开完好：%d 条记录 %d 个键", records, live)
+	l.Close()
+
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[2] = strings.Replace(lines[2], "counter", "cOunter", 1) // 中间一行被破坏
+	os.WriteFile(path, []byte(strings.Join(lines, "")), 0o644)
+	_, err = kv.OpenLog[string, int](path)
+	check(errors.Is(err, kv.ErrCorrupt), "中间的记录校验失败不能自动恢复：%v", err)
+
+	fmt.Println("\n=== 4. 只读视图与装饰器统计 ===")
+	snap, _ := kv.OpenSnapshot[string, Item](filepath.Join(dir, "catalog.json"))
+	m := kv.NewMetered[string, Item](snap)
+	c := kv.NewCached[string, Item](m, 2, time.Minute)
+	for i := 1; i <= 3; i++ {
+		c.Put(ctx, fmt.Sprintf("sku/%d", i), sample(i), 0)
+	}
+	for _, k := range []string{"sku/1", "sku/1", "sku/2", "sku/3", "sku/1", "sku/4"} {
+		c.Get(ctx, k)
+	}
+	hits, misses := c.Stats()
+	check(hits == 1 && misses == 5, "缓存（容量 2）命中 %d 次，未命中 %d 次", hits, misses)
+	mt := m.Metrics()
+	check(mt.Get.Calls == 5 && mt.Hits == 4 && mt.Misses == 1 && mt.Put.Calls == 3,
+		"下层统计：Get %d 次（找到 %d，没找到 %d），Put %d 次", mt.Get.Calls, mt.Hits, mt.Misses, mt.Put.Calls)
+
+	ro := kv.NewReadOnly[string, Item](c)
+	err = ro.Put(ctx, "sku/9", sample(9), 0)
+	check(errors.Is(err, kv.ErrReadOnly), "只读视图写入：%v", err)
+	es, _ := ro.List(ctx, "sku/")
+	check(len(es) == 3, "只读视图可以列举：%d 个 sku", len(es))
+	ro.Close()
+
+	fmt.Println("\n=== 5. 旧的 Storage 接口 ===")
+	var s Storage = storageAdapter{s: kv.NewMemory[string, any]()}
+	s.Store("name", "Alice")
+	s.Store("age", 25)
+	name, ok := s.Retrieve("name")
+	_, missing := s.Retrieve("email")
+	check(ok && name == "Alice" && !missing, "Retrieve(name) = %v，Retrieve(email) 不存在", name)
+
+	if failed > 0 {
+		fmt.Printf("\n%d 项失败\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("\n全部通过")
+}
+
+// Storage 同 chap24/interfaces.go。
+type Storage interface {
+	Store(key string, value interface{})
+	Retrieve(key string) (interface{}, bool)
+}
+
+// storageAdapter 让 kv.Store 满足旧的 Storage 接口。
+type storageAdapter struct {
+	s kv.Store[string, any]
+}
+
+func (a storageAdapter) Store(key string, value interface{}) {
+	a.s.Put(context.Background(), key, value, 0)
+}
+
+func (a storageAdapter) Retrieve(key string) (interface{}, bool) {
+	e, err := a.s.Get(context.Background(), key)
+	return e.Value, err == nil
+}
+
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return -1
+	}
+	return fi.Size()
+}
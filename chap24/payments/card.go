@@ -0,0 +1,160 @@
+// Package payments 是围绕第24章 PaymentMethod 接口的支付处理子系统。
+//
+// chap24 的 CreditCard、PayPal 只打印一行就返回 nil。真实的支付至少还需要：
+//   - 校验卡号（Luhn）并且在日志和返回值里只出现掩码后的卡号；
+//   - 幂等键：同一笔请求重试多次也只扣一次款；
+//   - 预授权 → 请款 → 退款的生命周期，每一步都检查当前状态；
+//   - 按支付方式选择网关的注册表；
+//   - 对超时、网关不可用、可重试的拒付按 errors.Is 重试。
+//
+// FakeGateway 在进程内模拟网关，可以注入拒付、超时和“已执行但响应丢失”的部分失败，
+// 不需要任何外部服务。金额一律以最小货币单位（分）的 int64 表示，避免浮点误差。
+package payments
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Method 是一种支付方式。
+type Method interface {
+	// Kind 是注册表中的键，例如 "card"、"paypal"。
+	Kind() string
+	// Validate 在发往网关之前做本地校验，now 用于检查有效期。
+	Validate(now time.Time) error
+	// Masked 返回可以安全打印和存储的描述。
+	Masked() string
+}
+
+// Card 是银行卡，对应 chap24 的 CreditCard。
+type Card struct {
+	Number   string // 可以包含空格或连字符
+	ExpMonth int    // 1～12
+	ExpYear  int    // 四位年份
+	Holder   string
+}
+
+func (c Card) Kind() string { return "card" }
+
+// Validate 检查卡号长度、Luhn 校验位和有效期（有效期当月最后一天仍然可用）。
+func (c Card) Validate(now time.Time) error {
+	n := NormalizeCardNumber(c.Number)
+	if len(n) < 12 || len(n) > 19 || strings.Trim(n, "0123456789") != "" {
+		return fmt.Errorf("%w: %s has bad length or characters", ErrInvalidCard, MaskCardNumber(c.Number))
+	}
+	if !Luhn(n) {
+		return fmt.Errorf("%w: %s fails the Luhn check", ErrInvalidCard, MaskCardNumber(c.Number))
+	}
+	if c.ExpMonth < 1 || c.ExpMonth > 12 {
+		return fmt.Errorf("%w: expiry month %d", ErrInvalidCard, c.ExpMonth)
+	}
+	// 有效期到 ExpYear 年 ExpMonth 月的月底
+	end := time.Date(c.ExpYear, time.Month(c.ExpMonth)+1, 1, 0, 0, 0, 0, now.Location())
+	if !now.Before(end) {
+		return fmt.Errorf("%w: expired %02d/%d", ErrInvalidCard, c.ExpMonth, c.ExpYear)
+	}
+	return nil
+}
+
+func (c Card) Masked() string {
+	return Brand(c.Number) + " " + MaskCardNumber(c.Number)
+}
+
+// PayPal 对应 chap24 的 PayPal。
+type PayPal struct {
+	Email string
+}
+
+func (p PayPal) Kind() string { return "paypal" }
+
+func (p PayPal) Validate(time.Time) error {
+	at := strings.IndexByte(p.Email, '@')
+	if at <= 0 || at == len(p.Email)-1 || strings.Count(p.Email, "@") != 1 {
+		return fmt.Errorf("%w: bad PayPal email", ErrInvalidMethod)
+	}
+	return nil
+}
+
+// Masked 只保留邮箱首字母和域名，例如 u***@example.com。
+func (p PayPal) Masked() string {
+	at := strings.IndexByte(p.Email, '@')
+	if at <= 0 {
+		return "PayPal ***"
+	}
+	return "PayPal " + p.Email[:1] + "***" + p.Email[at:]
+}
+
+// NormalizeCardNumber 去掉卡号中的空格和连字符。
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// Luhn 报告 number（只含数字）是否通过 Luhn 校验：从右往左，偶数位乘 2（大于 9 减 9），总和能被 10 整除。
+func Luhn(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// MaskCardNumber 只保留最后四位，每四位一组，例如 **** **** **** 4242。
+// 位数不足 8 位时全部遮住，避免短号码泄露太多。
+func MaskCardNumber(number string) string {
+	n := NormalizeCardNumber(number)
+	if len(n) < 8 {
+		return strings.Repeat("*", len(n))
+	}
+	masked := strings.Repeat("*", len(n)-4) + n[len(n)-4:]
+	var b strings.Builder
+	for i, c := range masked {
+		if i > 0 && (len(masked)-i)%4 == 0 { // 从右往左每 4 位一组，末 4 位总在一起
+			b.WriteByte(' ')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Brand 按卡号前缀判断卡组织，无法识别时返回 "Card"。
+func Brand(number string) string {
+	n := NormalizeCardNumber(number)
+	switch {
+	case strings.HasPrefix(n, "4"):
+		return "Visa"
+	case len(n) >= 2 && n[0] == '5' && n[1] >= '1' && n[1] <= '5',
+		len(n) >= 4 && n[:4] >= "2221" && n[:4] <= "2720":
+		return "Mastercard"
+	case strings.HasPrefix(n, "34"), strings.HasPrefix(n, "37"):
+		return "Amex"
+	case strings.HasPrefix(n, "62"):
+		return "UnionPay"
+	}
+	return "Card"
+}
+
+// FormatAmount 把以分为单位的金额格式化为 12.34 CNY。
+func FormatAmount(cents int64, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, cents/100, cents%100, currency)
+}
@@ -0,0 +1,79 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidCard 表示卡号、校验位或有效期不合法。
+	ErrInvalidCard = errors.New("payments: invalid card")
+	// ErrInvalidMethod 表示其他支付方式的本地校验失败。
+	ErrInvalidMethod = errors.New("payments: invalid payment method")
+	// ErrInvalidAmount 表示金额不是正数，或者超过可请款、可退款的余额。
+	ErrInvalidAmount = errors.New("payments: invalid amount")
+	// ErrUnknownMethod 表示注册表里没有该支付方式的网关。
+	ErrUnknownMethod = errors.New("payments: no gateway registered for method")
+	// ErrNotFound 表示支付 ID 不存在。
+	ErrNotFound = errors.New("payments: payment not found")
+	// ErrIdempotencyConflict 表示同一个幂等键被用于参数不同的请求。
+	ErrIdempotencyConflict = errors.New("payments: idempotency key reused with different parameters")
+	// ErrInvalidState 表示当前状态不允许该操作，例如对未请款的支付退款。
+	ErrInvalidState = errors.New("payments: operation not allowed in current state")
+
+	// ErrDeclined 匹配所有 *DeclineError。
+	ErrDeclined = errors.New("payments: declined")
+	// ErrSoftDecline 只匹配可重试的拒付（发卡行暂时无法处理）。
+	ErrSoftDecline = errors.New("payments: soft decline")
+	// ErrTimeout 表示网关在截止时间内没有响应。操作可能已经执行，必须用同一个幂等键重试。
+	ErrTimeout = errors.New("payments: gateway timeout")
+	// ErrUnavailable 表示网关暂时不可用（连接失败、5xx）。
+	ErrUnavailable = errors.New("payments: gateway unavailable")
+)
+
+// DeclineCode 是发卡行或网关给出的拒付原因。
+type DeclineCode string
+
+const (
+	InsufficientFunds DeclineCode = "insufficient_funds"
+	CardExpired       DeclineCode = "expired_card"
+	SuspectedFraud    DeclineCode = "fraudulent"
+	DoNotHonor        DeclineCode = "do_not_honor"
+	TryAgainLater     DeclineCode = "try_again_later" // 软拒付，可以重试
+)
+
+// DeclineError 是拒付。errors.Is(err, ErrDeclined) 对所有拒付成立，
+// errors.Is(err, ErrSoftDecline) 只对可重试的拒付成立，
+// errors.Is(err, &DeclineError{Code: InsufficientFunds}) 可以匹配具体原因。
+type DeclineError struct {
+	Code    DeclineCode
+	Message string
+}
+
+func (e *DeclineError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("payments: declined (%s)", e.Code)
+	}
+	return fmt.Sprintf("payments: declined (%s): %s", e.Code, e.Message)
+}
+
+func (e *DeclineError) Is(target error) bool {
+	switch t := target.(type) {
+	case *DeclineError:
+		return t.Code == e.Code
+	}
+	return target == ErrDeclined || (target == ErrSoftDecline && e.Code == TryAgainLater)
+}
+
+// StateError 表示对处于 Status 状态的支付执行 Op 不被允许。
+type StateError struct {
+	ID     string
+	Op     string
+	Status Status
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("payments: cannot %s payment %s in state %s", e.Op, e.ID, e.Status)
+}
+
+func (e *StateError) Unwrap() error { return ErrInvalidState }
@@ -0,0 +1,197 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 这些卡号在 FakeGateway 上总是得到固定的结果（都能通过 Luhn 校验），用法类似各家网关的测试卡号。
+const (
+	TestCardOK                = "4242 4242 4242 4242"
+	TestCardDeclined          = "4000 0000 0000 0002" // DoNotHonor
+	TestCardInsufficientFunds = "4000 0000 0000 9995"
+	TestCardFraud             = "4100 0000 0000 0019"
+)
+
+var testCardDeclines = map[string]DeclineCode{
+	NormalizeCardNumber(TestCardDeclined):          DoNotHonor,
+	NormalizeCardNumber(TestCardInsufficientFunds): InsufficientFunds,
+	NormalizeCardNumber(TestCardFraud):             SuspectedFraud,
+}
+
+// Fault 是注入到 FakeGateway 的一次故障，按注入顺序被匹配的调用消耗。
+type Fault struct {
+	// Op 限定故障作用的操作："authorize"、"capture"、"refund"，为空表示任意操作。
+	Op string
+	// Err 是返回的错误，例如 ErrTimeout、ErrUnavailable、&DeclineError{Code: TryAgainLater}。
+	Err error
+	// Delay 让调用先等待这么久；ctx 先到期时返回 ErrTimeout。
+	Delay time.Duration
+	// Commit 为 true 时操作照常执行，只是仍然返回 Err，模拟“已扣款但响应丢失”的部分失败。
+	Commit bool
+}
+
+// FakeGateway 是进程内的网关实现，按幂等键去重，记录每次调用，可以注入故障。
+type FakeGateway struct {
+	mu     sync.Mutex
+	seq    int
+	txns   map[string]*fakeTxn
+	seen   map[string]fakeResult // 幂等键 → 第一次成功执行的结果
+	faults []Fault
+	calls  []string
+}
+
+type fakeTxn struct {
+	authorized, captured, refunded int64
+}
+
+type fakeResult struct {
+	op     string
+	txnID  string
+	amount int64
+}
+
+// NewFakeGateway 创建空的模拟网关。
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{txns: make(map[string]*fakeTxn), seen: make(map[string]fakeResult)}
+}
+
+// Inject 追加故障。
+func (g *FakeGateway) Inject(faults ...Fault) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.faults = append(g.faults, faults...)
+}
+
+// Calls 返回调用日志，例如 "authorize key=k1 → txn_0001"。
+func (g *FakeGateway) Calls() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.calls...)
+}
+
+// Balance 返回交易的授权、请款、退款金额，用于核对没有重复扣款。
+func (g *FakeGateway) Balance(txnID string) (authorized, captured, refunded int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if t := g.txns[txnID]; t != nil {
+		return t.authorized, t.captured, t.refunded
+	}
+	return 0, 0, 0
+}
+
+// fault 取出第一个匹配 op 的故障。
+func (g *FakeGateway) fault(op string) (Fault, bool) {
+	for i, f := range g.faults {
+		if f.Op == "" || f.Op == op {
+			g.faults = append(g.faults[:i], g.faults[i+1:]...)
+			return f, true
+		}
+	}
+	return Fault{}, false
+}
+
+// call 是三个操作的公共流程：故障注入、延迟、幂等去重、执行、记录日志。
+func (g *FakeGateway) call(ctx context.Context, op, key string, amount int64, exec func() (string, error)) (string, error) {
+	g.mu.Lock()
+	f, faulty := g.fault(op)
+	g.mu.Unlock()
+
+	if faulty && f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			g.log("%s key=%s → %v", op, key, ctx.Err())
+			return "", fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if faulty && !f.Commit && f.Err != nil {
+		g.calls = append(g.calls, fmt.Sprintf("%s key=%s → %v", op, key, f.Err))
+		return "", f.Err
+	}
+
+	var (
+		txn string
+		err error
+	)
+	if r, dup := g.seen[key]; dup {
+		if r.op != op || r.amount != amount {
+			err = fmt.Errorf("%w: gateway key %s", ErrIdempotencyConflict, key)
+		} else {
+			txn = r.txnID
+			g.calls = append(g.calls, fmt.Sprintf("%s key=%s → replay %s", op, key, txn))
+		}
+	} else if txn, err = exec(); err == nil {
+		g.seen[key] = fakeResult{op: op, txnID: txn, amount: amount}
+		g.calls = append(g.calls, fmt.Sprintf("%s key=%s amount=%d → %s", op, key, amount, txn))
+	}
+	if err != nil {
+		g.calls = append(g.calls, fmt.Sprintf("%s key=%s → %v", op, key, err))
+		return "", err
+	}
+	if faulty && f.Err != nil { // Commit：已执行，但调用方看到的是错误
+		g.calls = append(g.calls, fmt.Sprintf("%s key=%s → committed, response lost: %v", op, key, f.Err))
+		return "", f.Err
+	}
+	return txn, nil
+}
+
+func (g *FakeGateway) log(format string, args ...any) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls = append(g.calls, fmt.Sprintf(format, args...))
+}
+
+// Authorize 实现 Gateway。测试卡号按 testCardDeclines 拒付。
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthRequest) (string, error) {
+	return g.call(ctx, "authorize", req.IdempotencyKey, req.Amount, func() (string, error) {
+		if c, ok := req.Method.(Card); ok {
+			if code, ok := testCardDeclines[NormalizeCardNumber(c.Number)]; ok {
+				return "", &DeclineError{Code: code}
+			}
+		}
+		g.seq++
+		id := fmt.Sprintf("txn_%04d", g.seq)
+		g.txns[id] = &fakeTxn{authorized: req.Amount}
+		return id, nil
+	})
+}
+
+// Capture 实现 Gateway。
+func (g *FakeGateway) Capture(ctx context.Context, req OpRequest) error {
+	_, err := g.call(ctx, "capture", req.IdempotencyKey, req.Amount, func() (string, error) {
+		t := g.txns[req.TxnID]
+		if t == nil {
+			return "", fmt.Errorf("payments: fake gateway: unknown transaction %s", req.TxnID)
+		}
+		if t.captured > 0 || req.Amount > t.authorized {
+			return "", fmt.Errorf("%w: fake gateway: capture %d of %d", ErrInvalidAmount, req.Amount, t.authorized)
+		}
+		t.captured = req.Amount
+		return req.TxnID, nil
+	})
+	return err
+}
+
+// Refund 实现 Gateway。
+func (g *FakeGateway) Refund(ctx context.Context, req OpRequest) error {
+	_, err := g.call(ctx, "refund", req.IdempotencyKey, req.Amount, func() (string, error) {
+		t := g.txns[req.TxnID]
+		if t == nil {
+			return "", fmt.Errorf("payments: fake gateway: unknown transaction %s", req.TxnID)
+		}
+		if t.refunded+req.Amount > t.captured {
+			return "", fmt.Errorf("%w: fake gateway: refund %d exceeds captured %d", ErrInvalidAmount, req.Amount, t.captured-t.refunded)
+		}
+		t.refunded += req.Amount
+		return req.TxnID, nil
+	})
+	return err
+}
@@ -0,0 +1,89 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// AuthRequest 是一次预授权请求。
+type AuthRequest struct {
+	IdempotencyKey string
+	Method         Method
+	Amount         int64
+	Currency       string
+}
+
+// OpRequest 是对已授权交易的请款或退款。
+type OpRequest struct {
+	IdempotencyKey string
+	TxnID          string
+	Amount         int64
+}
+
+// Gateway 是支付网关。实现必须按 IdempotencyKey 去重：同一个键再次到达时
+// 返回第一次的结果而不是重复执行，Processor 依赖这一点安全地重试超时的请求。
+type Gateway interface {
+	// Authorize 冻结资金，返回网关交易号。
+	Authorize(ctx context.Context, req AuthRequest) (txnID string, err error)
+	// Capture 从已冻结的资金中请款。
+	Capture(ctx context.Context, req OpRequest) error
+	// Refund 退还已请款的资金。
+	Refund(ctx context.Context, req OpRequest) error
+}
+
+// Registry 按支付方式（Method.Kind）查找网关，可以在运行时增删，并发安全。
+type Registry struct {
+	mu       sync.RWMutex
+	gateways map[string]Gateway
+}
+
+// NewRegistry 创建空注册表。
+func NewRegistry() *Registry {
+	return &Registry{gateways: make(map[string]Gateway)}
+}
+
+// Register 为 kind 注册网关；kind 已经注册过时返回错误。
+func (r *Registry) Register(kind string, g Gateway) error {
+	if kind == "" || g == nil {
+		return fmt.Errorf("payments: register %q: empty kind or nil gateway", kind)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.gateways[kind]; dup {
+		return fmt.Errorf("payments: gateway for %q already registered", kind)
+	}
+	r.gateways[kind] = g
+	return nil
+}
+
+// Unregister 删除 kind 的网关。
+func (r *Registry) Unregister(kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.gateways, kind)
+}
+
+// Lookup 返回 kind 的网关，没有时返回 ErrUnknownMethod。
+func (r *Registry) Lookup(kind string) (Gateway, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.gateways[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMethod, kind)
+	}
+	return g, nil
+}
+
+// Kinds 返回已注册的支付方式，按字母排序。
+func (r *Registry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	kinds := make([]string, 0, len(r.gateways))
+	for k := range r.gateways {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,304 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var ctx = context.Background()
+
+func card(number string) Card {
+	return Card{Number: number, ExpMonth: 12, ExpYear: 2030, Holder: "ZHANG SAN"}
+}
+
+// noSleep 让重试立即进行。
+func noSleep(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+
+// setup 返回使用 FakeGateway 的处理器，重试策略与 DefaultRetry 相同但不等待。
+func setup(t *testing.T) (*Processor, *FakeGateway) {
+	t.Helper()
+	g := NewFakeGateway()
+	r := NewRegistry()
+	if err := r.Register("card", g); err != nil {
+		t.Fatal(err)
+	}
+	retry := DefaultRetry
+	retry.Sleep = noSleep
+	retry.PerAttempt = 50 * time.Millisecond
+	p := NewProcessor(r, retry)
+	p.Now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return p, g
+}
+
+func TestRetryDecisions(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		number   string
+		faults   []Fault
+		attempts int
+		status   Status
+		is       error
+	}{
+		{"ok", TestCardOK, nil, 1, Authorized, nil},
+		{"hard decline", TestCardDeclined, nil, 1, Declined, &DeclineError{Code: DoNotHonor}},
+		{"insufficient funds", TestCardInsufficientFunds, nil, 1, Declined, &DeclineError{Code: InsufficientFunds}},
+		{"soft decline retried", TestCardOK, []Fault{{Err: &DeclineError{Code: TryAgainLater}}}, 2, Authorized, nil},
+		{"unavailable retried", TestCardOK, []Fault{{Err: ErrUnavailable}, {Err: ErrUnavailable}}, 3, Authorized, nil},
+		{"timeout retried", TestCardOK, []Fault{{Delay: time.Second}}, 2, Authorized, nil},
+		{"attempts exhausted", TestCardOK, []Fault{{Err: ErrUnavailable}, {Err: ErrUnavailable}, {Err: ErrUnavailable}}, 3, Failed, ErrUnavailable},
+		// 软拒付重试耗尽不是终态的拒付，可以用同一个幂等键再试
+		{"soft decline exhausted", TestCardOK, []Fault{{Err: &DeclineError{Code: TryAgainLater}}, {Err: &DeclineError{Code: TryAgainLater}}, {Err: &DeclineError{Code: TryAgainLater}}}, 3, Failed, ErrSoftDecline},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, g := setup(t)
+			g.Inject(tc.faults...)
+			pay, err := p.Authorize(ctx, "k1", card(tc.number), 1999, "CNY")
+			if tc.is == nil && err != nil || tc.is != nil && !errors.Is(err, tc.is) {
+				t.Fatalf("err = %v, want %v", err, tc.is)
+			}
+			if pay.Attempts != tc.attempts || pay.Status != tc.status {
+				t.Errorf("attempts=%d status=%v, want %d %v\n%s", pay.Attempts, pay.Status, tc.attempts, tc.status, strings.Join(g.Calls(), "\n"))
+			}
+			if tc.status == Declined && Retryable(err) {
+				t.Errorf("hard decline %v reported as retryable", err)
+			}
+			if tc.status == Failed {
+				again, err := p.Authorize(ctx, "k1", card(tc.number), 1999, "CNY")
+				if err != nil || again.Status != Authorized || again.ID != pay.ID {
+					t.Errorf("retry after Failed = %v, %v", again, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	p, g := setup(t)
+	first, err := p.Authorize(ctx, "order-1", card(TestCardOK), 1999, "CNY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := p.Authorize(ctx, "order-1", card(TestCardOK), 1999, "CNY")
+	if err != nil || again.ID != first.ID || again.TxnID != first.TxnID {
+		t.Errorf("replay = %v, %v; want %v", again, err, first)
+	}
+	if n := len(g.Calls()); n != 1 {
+		t.Errorf("replay reached the gateway: %v", g.Calls())
+	}
+
+	// 拒付的结果同样被重放，不会再次请求网关
+	_, err = p.Authorize(ctx, "order-2", card(TestCardFraud), 500, "CNY")
+	replayed, err2 := p.Authorize(ctx, "order-2", card(TestCardFraud), 500, "CNY")
+	if !errors.Is(err, ErrDeclined) || err2 != err || replayed.Status != Declined {
+		t.Errorf("declined replay: %v / %v (%v)", err, err2, replayed.Status)
+	}
+
+	// 同一个键、不同参数
+	if _, err := p.Authorize(ctx, "order-1", card(TestCardOK), 2999, "CNY"); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("different amount: err = %v, want ErrIdempotencyConflict", err)
+	}
+	if _, err := p.Authorize(ctx, "order-1", card(TestCardDeclined), 1999, "CNY"); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("different card: err = %v, want ErrIdempotencyConflict", err)
+	}
+	if len(g.Calls()) != 2 {
+		t.Errorf("gateway calls:\n%s", strings.Join(g.Calls(), "\n"))
+	}
+}
+
+func TestPartialFailure(t *testing.T) {
+	p, g := setup(t)
+
+	// 预授权已执行但响应丢失：重试用同一个键，网关重放，只冻结一次
+	g.Inject(Fault{Op: "authorize", Err: ErrTimeout, Commit: true})
+	pay, err := p.Authorize(ctx, "order-1", card(TestCardOK), 1999, "CNY")
+	if err != nil || pay.Attempts != 2 || pay.Status != Authorized {
+		t.Fatalf("authorize = %v, %v (attempts %d)", pay, err, pay.Attempts)
+	}
+
+	// 请款重试全部失败，但第一次其实已经扣款：状态不变，再次调用时网关重放而不是重复请款
+	g.Inject(
+		Fault{Op: "capture", Err: ErrTimeout, Commit: true},
+		Fault{Op: "capture", Err: ErrUnavailable},
+		Fault{Op: "capture", Err: ErrUnavailable},
+	)
+	pay, err = p.Capture(ctx, pay.ID, 0)
+	if !errors.Is(err, ErrUnavailable) || pay.Status != Authorized || pay.Attempts != 3 {
+		t.Fatalf("failed capture = %v, %v (attempts %d)", pay, err, pay.Attempts)
+	}
+	if _, captured, _ := g.Balance(pay.TxnID); captured != 1999 {
+		t.Fatalf("gateway captured %d, want 1999 (committed before the response was lost)", captured)
+	}
+	pay, err = p.Capture(ctx, pay.ID, 0)
+	if err != nil || pay.Status != Captured || pay.Captured != 1999 {
+		t.Fatalf("capture retry = %v, %v", pay, err)
+	}
+	if auth, captured, _ := g.Balance(pay.TxnID); auth != 1999 || captured != 1999 {
+		t.Errorf("gateway balance authorized=%d captured=%d, want 1999 each", auth, captured)
+	}
+	var replays int
+	for _, c := range g.Calls() {
+		if strings.Contains(c, "replay") {
+			replays++
+		}
+	}
+	if replays != 2 {
+		t.Errorf("%d replays, want 2 (authorize and capture):\n%s", replays, strings.Join(g.Calls(), "\n"))
+	}
+
+	// Failed 的预授权可以用同一个键再试
+	g.Inject(Fault{Err: ErrUnavailable}, Fault{Err: ErrUnavailable}, Fault{Err: ErrUnavailable})
+	failed, err := p.Authorize(ctx, "order-2", card(TestCardOK), 100, "CNY")
+	if failed.Status != Failed || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("exhausted = %v, %v", failed, err)
+	}
+	retried, err := p.Authorize(ctx, "order-2", card(TestCardOK), 100, "CNY")
+	if err != nil || retried.ID != failed.ID || retried.Status != Authorized {
+		t.Errorf("retry after Failed = %v, %v", retried, err)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	p, _ := setup(t)
+	pay, err := p.Authorize(ctx, "k", card(TestCardOK), 10000, "CNY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Refund(ctx, pay.ID, 0); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("refund before capture: %v", err)
+	}
+	if _, err := p.Capture(ctx, pay.ID, 20000); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("over-capture: %v", err)
+	}
+	if pay, err = p.Capture(ctx, pay.ID, 8000); err != nil {
+		t.Fatal(err)
+	}
+	if pay, err = p.Refund(ctx, pay.ID, 3000); err != nil || pay.Status != PartiallyRefunded {
+		t.Fatalf("partial refund = %v, %v", pay, err)
+	}
+	if _, err := p.Refund(ctx, pay.ID, 6000); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("over-refund: %v", err)
+	}
+	if pay, err = p.Refund(ctx, pay.ID, 0); err != nil || pay.Status != Refunded || pay.Refunded != 8000 {
+		t.Errorf("full refund = %v, %v", pay, err)
+	}
+	if _, err := p.Get("pay_9999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get unknown: %v", err)
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	for _, tc := range []struct {
+		number string
+		want   bool
+	}{
+		{"4242424242424242", true},
+		{"378282246310005", true}, // Amex，15 位
+		{"6200000000000005", true},
+		{"79927398713", true},
+		{"0", true},
+		{"4242424242424241", false}, // 校验位错误
+		{"79927398710", false},
+		{"2424242424242424", false}, // 数字对调
+		{"", false},
+		{"4242 4242 4242 4242", false}, // 调用方应先 NormalizeCardNumber
+		{"4242x24242424242", false},
+	} {
+		if got := Luhn(tc.number); got != tc.want {
+			t.Errorf("Luhn(%q) = %v, want %v", tc.number, got, tc.want)
+		}
+	}
+}
+
+func TestMaskCardNumber(t *testing.T) {
+	for _, tc := range []struct {
+		number, want string
+	}{
+		{"4242424242424242", "**** **** **** 4242"},
+		{"4242 4242-4242 4242", "**** **** **** 4242"},
+		{"378282246310005", "*** **** **** 0005"},
+		{"6200000000000000005", "*** **** **** **** 0005"}, // 19 位
+		{"12345678", "**** 5678"},
+		{"1234567", "*******"}, // 不足 8 位全部遮住
+		{"42", "**"},
+		{"", ""},
+	} {
+		if got := MaskCardNumber(tc.number); got != tc.want {
+			t.Errorf("MaskCardNumber(%q) = %q, want %q", tc.number, got, tc.want)
+		}
+	}
+}
+
+func TestCardValidate(t *testing.T) {
+	now := time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name  string
+		card  Card
+		error string // 为空表示校验通过
+	}{
+		{"valid", card(TestCardOK), ""},
+		{"spaces and dashes", Card{Number: "4242 4242-4242 4242", ExpMonth: 12, ExpYear: 2030}, ""},
+		{"expires this month", Card{Number: TestCardOK, ExpMonth: 1, ExpYear: 2026}, ""},
+		{"expired last month", Card{Number: TestCardOK, ExpMonth: 12, ExpYear: 2025}, "expired 12/2025"},
+		{"expired last year", Card{Number: TestCardOK, ExpMonth: 6, ExpYear: 2020}, "expired 06/2020"},
+		{"month 0", Card{Number: TestCardOK, ExpMonth: 0, ExpYear: 2030}, "expiry month 0"},
+		{"month 13", Card{Number: TestCardOK, ExpMonth: 13, ExpYear: 2030}, "expiry month 13"},
+		{"too short", Card{Number: "42424242424", ExpMonth: 12, ExpYear: 2030}, "bad length"},
+		{"too long", Card{Number: "42424242424242424242", ExpMonth: 12, ExpYear: 2030}, "bad length"},
+		{"letters", Card{Number: "4242x24242424242", ExpMonth: 12, ExpYear: 2030}, "bad length or characters"},
+		{"Luhn", Card{Number: "4242424242424241", ExpMonth: 12, ExpYear: 2030}, "fails the Luhn check"},
+	} {
+		err := tc.card.Validate(now)
+		switch {
+		case tc.error == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.error != "" && (!errors.Is(err, ErrInvalidCard) || !strings.Contains(err.Error(), tc.error)):
+			t.Errorf("%s: err = %v, want ErrInvalidCard mentioning %q", tc.name, err, tc.error)
+		case err != nil && strings.Contains(err.Error(), NormalizeCardNumber(tc.card.Number)):
+			t.Errorf("%s: error leaks the card number: %v", tc.name, err)
+		}
+	}
+	// 过了有效期当月的月底就失效
+	if err := (Card{Number: TestCardOK, ExpMonth: 1, ExpYear: 2026}).Validate(now.Add(time.Minute)); err == nil {
+		t.Error("card expiring 01/2026 accepted on 2026-02-01")
+	}
+}
+
+func TestCheckoutRounding(t *testing.T) {
+	p, g := setup(t)
+	c := &Checkout{Processor: p, Method: card(TestCardOK), Currency: "CNY", Prefix: "cart"}
+	for _, amount := range []float64{19.99, 0.295, 1.005, 100} {
+		if err := c.Pay(amount); err != nil {
+			t.Fatalf("Pay(%v): %v", amount, err)
+		}
+	}
+	var captured []string
+	for i := 1; i <= 4; i++ {
+		pay, err := p.Get(fmt.Sprintf("pay_%04d", i))
+		if err != nil || pay.Status != Captured {
+			t.Fatalf("payment %d: %v, %v", i, pay, err)
+		}
+		captured = append(captured, FormatAmount(pay.Captured, pay.Currency))
+	}
+	// 1.005 在二进制里略小于字面值，乘 100 后是 100.499…，所以舍去
+	if got := strings.Join(captured, ", "); got != "19.99 CNY, 0.30 CNY, 1.00 CNY, 100.00 CNY" {
+		t.Errorf("captured %s\n%s", got, strings.Join(g.Calls(), "\n"))
+	}
+
+	// 负数金额换算成负的分，而不是被 +0.5 截断成 0
+	for _, tc := range []struct {
+		amount float64
+		want   string
+	}{
+		{-0.01, "invalid amount: -1"},
+		{-19.99, "invalid amount: -1999"},
+		{0.004, "invalid amount: 0"},
+	} {
+		if err := c.Pay(tc.amount); !errors.Is(err, ErrInvalidAmount) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Pay(%v) = %v, want %q", tc.amount, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Status 是支付的生命周期状态。
+type Status int
+
+const (
+	// Authorized：资金已冻结，等待请款。
+	Authorized Status = iota + 1
+	// Captured：已全额或部分请款。
+	Captured
+	// PartiallyRefunded：已请款的金额部分退回。
+	PartiallyRefunded
+	// Refunded：已请款的金额全部退回。
+	Refunded
+	// Declined：预授权被拒付，终态。
+	Declined
+	// Failed：重试耗尽仍然失败（超时、网关不可用、软拒付），可用同一个幂等键再次 Authorize。
+	Failed
+)
+
+var statusNames = map[Status]string{
+	Authorized:        "authorized",
+	Captured:          "captured",
+	PartiallyRefunded: "partially_refunded",
+	Refunded:          "refunded",
+	Declined:          "declined",
+	Failed:            "failed",
+}
+
+func (s Status) String() string {
+	if n, ok := statusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Payment 是一笔支付的快照。Processor 返回的都是副本，修改它不会影响内部状态。
+type Payment struct {
+	ID             string
+	IdempotencyKey string
+	Kind           string // 支付方式，例如 card
+	Method         string // 掩码后的支付方式描述
+	Currency       string
+	Amount         int64 // 预授权金额（分）
+	Captured       int64
+	Refunded       int64
+	Status         Status
+	TxnID          string // 网关交易号
+	Attempts       int    // 最近一次操作的网关调用次数
+	Err            error  // 最近一次失败的原因
+	Updated        time.Time
+}
+
+func (p Payment) String() string {
+	s := fmt.Sprintf("%s %s %s %s", p.ID, p.Status, FormatAmount(p.Amount, p.Currency), p.Method)
+	if p.Captured > 0 {
+		s += " captured=" + FormatAmount(p.Captured, p.Currency)
+	}
+	if p.Refunded > 0 {
+		s += " refunded=" + FormatAmount(p.Refunded, p.Currency)
+	}
+	return s
+}
+
+// Processor 管理支付的生命周期。并发安全；同一笔支付上的操作会串行执行。
+type Processor struct {
+	registry *Registry
+	retry    RetryPolicy
+	// Now 提供时间，默认 time.Now。
+	Now func() time.Time
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*record
+	byKey    map[string]*record
+}
+
+// record 是内部保存的支付，op 串行化同一笔支付上的网关调用。
+type record struct {
+	op      sync.Mutex
+	p       Payment
+	request AuthRequest // 用于检查幂等键是否被挪用
+	ops     int         // 已执行的请款、退款次数，用于生成网关幂等键
+}
+
+// NewProcessor 创建使用 registry 和 retry 的处理器。
+func NewProcessor(registry *Registry, retry RetryPolicy) *Processor {
+	return &Processor{
+		registry: registry,
+		retry:    retry,
+		Now:      time.Now,
+		payments: make(map[string]*record),
+		byKey:    make(map[string]*record),
+	}
+}
+
+// Authorize 用支付方式 m 预授权 amount（分）。
+//
+// 同一个 key 再次调用时：参数相同则直接返回第一次的结果（包括拒付错误），
+// 参数不同则返回 ErrIdempotencyConflict；上次以 Failed 结束的会用同一个键重新请求网关，
+// 网关按键去重，因此不会重复冻结资金。
+func (p *Processor) Authorize(ctx context.Context, key string, m Method, amount int64, currency string) (Payment, error) {
+	if key == "" {
+		return Payment{}, errors.New("payments: empty idempotency key")
+	}
+	if amount <= 0 {
+		return Payment{}, fmt.Errorf("%w: %d", ErrInvalidAmount, amount)
+	}
+	if err := m.Validate(p.Now()); err != nil {
+		return Payment{}, err
+	}
+	g, err := p.registry.Lookup(m.Kind())
+	if err != nil {
+		return Payment{}, err
+	}
+	req := AuthRequest{IdempotencyKey: key, Method: m, Amount: amount, Currency: currency}
+
+	p.mu.Lock()
+	rec, seen := p.byKey[key]
+	if !seen {
+		p.seq++
+		rec = &record{
+			p: Payment{
+				ID: fmt.Sprintf("pay_%04d", p.seq), IdempotencyKey: key, Kind: m.Kind(),
+				Method: m.Masked(), Currency: currency, Amount: amount,
+			},
+			request: req,
+		}
+		p.byKey[key] = rec
+		p.payments[rec.p.ID] = rec
+		rec.op.Lock() // 新记录在发布前加锁，并发的同键请求会等待第一次的结果
+		p.mu.Unlock()
+	} else {
+		p.mu.Unlock()
+		rec.op.Lock()
+	}
+	defer rec.op.Unlock()
+	if seen {
+		if !sameRequest(rec.request, req) {
+			return rec.p, fmt.Errorf("%w: %s", ErrIdempotencyConflict, key)
+		}
+		if rec.p.Status != Failed {
+			return rec.p, rec.p.Err
+		}
+	}
+
+	var txn string
+	attempts, err := p.retry.Do(ctx, func(ctx context.Context) error {
+		var err error
+		txn, err = g.Authorize(ctx, req)
+		return err
+	})
+	rec.p.Attempts, rec.p.Err, rec.p.Updated = attempts, err, p.Now()
+	switch {
+	case err == nil:
+		rec.p.Status, rec.p.TxnID = Authorized, txn
+	case errors.Is(err, ErrDeclined) && !p.retry.retryable(err):
+		// 软拒付也匹配 ErrDeclined，但它可以重试：重试耗尽时按 Failed 处理
+		rec.p.Status = Declined
+	default:
+		rec.p.Status = Failed
+	}
+	return rec.p, err
+}
+
+func sameRequest(a, b AuthRequest) bool {
+	return a.Amount == b.Amount && a.Currency == b.Currency &&
+		a.Method.Kind() == b.Method.Kind() && a.Method.Masked() == b.Method.Masked()
+}
+
+// Capture 请款 amount（分），0 表示预授权的全部金额。只能请款一次，未请的部分自动释放。
+func (p *Processor) Capture(ctx context.Context, id string, amount int64) (Payment, error) {
+	return p.operate(ctx, id, "capture", func(rec *record) (int64, error) {
+		if rec.p.Status != Authorized {
+			return 0, &StateError{ID: id, Op: "capture", Status: rec.p.Status}
+		}
+		if amount == 0 {
+			amount = rec.p.Amount
+		}
+		if amount < 0 || amount > rec.p.Amount {
+			return 0, fmt.Errorf("%w: capture %s of %s authorized", ErrInvalidAmount,
+				FormatAmount(amount, rec.p.Currency), FormatAmount(rec.p.Amount, rec.p.Currency))
+		}
+		return amount, nil
+	}, Gateway.Capture, func(rec *record, amount int64) {
+		rec.p.Captured = amount
+		rec.p.Status = Captured
+	})
+}
+
+// Refund 退款 amount（分），0 表示全部剩余可退金额。可以多次部分退款。
+func (p *Processor) Refund(ctx context.Context, id string, amount int64) (Payment, error) {
+	return p.operate(ctx, id, "refund", func(rec *record) (int64, error) {
+		if rec.p.Status != Captured && rec.p.Status != PartiallyRefunded {
+			return 0, &StateError{ID: id, Op: "refund", Status: rec.p.Status}
+		}
+		left := rec.p.Captured - rec.p.Refunded
+		if amount == 0 {
+			amount = left
+		}
+		if amount < 0 || amount > left {
+			return 0, fmt.Errorf("%w: refund %s of %s refundable", ErrInvalidAmount,
+				FormatAmount(amount, rec.p.Currency), FormatAmount(left, rec.p.Currency))
+		}
+		return amount, nil
+	}, Gateway.Refund, func(rec *record, amount int64) {
+		rec.p.Refunded += amount
+		rec.p.Status = PartiallyRefunded
+		if rec.p.Refunded == rec.p.Captured {
+			rec.p.Status = Refunded
+		}
+	})
+}
+
+// operate 是 Capture 和 Refund 的公共流程：检查状态和金额、带重试调用网关、更新状态。
+// 网关幂等键由支付 ID、操作名和序号组成，同一次操作的重试使用同一个键。
+func (p *Processor) operate(ctx context.Context, id, op string,
+	check func(*record) (int64, error),
+	call func(Gateway, context.Context, OpRequest) error,
+	apply func(*record, int64),
+) (Payment, error) {
+	p.mu.Lock()
+	rec, ok := p.payments[id]
+	p.mu.Unlock()
+	if !ok {
+		return Payment{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	rec.op.Lock()
+	defer rec.op.Unlock()
+
+	amount, err := check(rec)
+	if err != nil {
+		return rec.p, err
+	}
+	g, err := p.registry.Lookup(rec.p.Kind)
+	if err != nil {
+		return rec.p, err
+	}
+	req := OpRequest{
+		IdempotencyKey: fmt.Sprintf("%s:%s:%d", rec.p.ID, op, rec.ops+1),
+		TxnID:          rec.p.TxnID,
+		Amount:         amount,
+	}
+	attempts, err := p.retry.Do(ctx, func(ctx context.Context) error { return call(g, ctx, req) })
+	rec.p.Attempts, rec.p.Err, rec.p.Updated = attempts, err, p.Now()
+	if err != nil {
+		return rec.p, err // 状态不变；调用方可以再次调用，网关按幂等键去重
+	}
+	rec.ops++
+	apply(rec, amount)
+	return rec.p, nil
+}
+
+// Get 返回支付的快照。
+func (p *Processor) Get(id string) (Payment, error) {
+	p.mu.Lock()
+	rec, ok := p.payments[id]
+	p.mu.Unlock()
+	if !ok {
+		return Payment{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	rec.op.Lock()
+	defer rec.op.Unlock()
+	return rec.p, nil
+}
+
+// Checkout 把 Processor 适配成 chap24 的 PaymentMethod 接口（Pay(amount float64) error）：
+// 每次 Pay 都预授权并立即全额请款，幂等键由 Prefix 和调用序号组成。
+type Checkout struct {
+	Processor *Processor
+	Method    Method
+	Currency  string
+	Prefix    string
+
+	mu sync.Mutex
+	n  int
+}
+
+// Pay 以元为单位支付 amount（四舍五入到分，负数同样远离零取整）。
+// 参数是 float64 是为了满足 PaymentMethod 接口，内部一律换算成分。
+func (c *Checkout) Pay(amount float64) error {
+	c.mu.Lock()
+	c.n++
+	key := fmt.Sprintf("%s-%d", c.Prefix, c.n)
+	c.mu.Unlock()
+
+	cents := int64(math.Round(amount * 100))
+	pay, err := c.Processor.Authorize(context.Background(), key, c.Method, cents, c.Currency)
+	if err != nil {
+		return err
+	}
+	_, err = c.Processor.Capture(context.Background(), pay.ID, 0)
+	return err
+}
@@ -0,0 +1,85 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy 决定网关调用失败后是否以及何时重试。
+type RetryPolicy struct {
+	// MaxAttempts 是包括第一次在内的最多尝试次数，小于 1 时按 1 处理。
+	MaxAttempts int
+	// Backoff 是第一次重试前的等待时间，之后每次翻倍，不超过 MaxBackoff（为 0 时不设上限）。
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// PerAttempt 限制每次尝试的时长（context.WithTimeout），为 0 时只受外层 ctx 限制。
+	PerAttempt time.Duration
+	// Retryable 判断错误能否重试，为 nil 时使用 Retryable 函数。
+	Retryable func(error) bool
+	// Sleep 用于等待，为 nil 时使用可被 ctx 取消的 time.Timer；测试时可以替换成不等待的函数。
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// DefaultRetry 最多尝试 3 次，每次最多 5 秒，间隔 100ms、200ms。
+var DefaultRetry = RetryPolicy{MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second, PerAttempt: 5 * time.Second}
+
+// Retryable 报告 err 是否值得重试：超时、网关不可用和软拒付可以重试；
+// 其他拒付（余额不足、疑似欺诈……）以及本地校验错误重试也不会成功。
+func Retryable(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrUnavailable) || errors.Is(err, ErrSoftDecline)
+}
+
+// Do 按策略执行 op，返回最后一次的错误和尝试次数。ctx 取消时立即停止。
+func (p RetryPolicy) Do(ctx context.Context, op func(ctx context.Context) error) (attempts int, err error) {
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = sleepCtx
+	}
+	wait := p.Backoff
+	for {
+		attempts++
+		err = p.attempt(ctx, op)
+		if err == nil || attempts >= max(1, p.MaxAttempts) || !p.retryable(err) {
+			return attempts, err
+		}
+		if serr := sleep(ctx, wait); serr != nil {
+			return attempts, errors.Join(err, serr)
+		}
+		wait *= 2
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+	}
+}
+
+// retryable 用策略的 Retryable 判断 err 能否重试。
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable == nil {
+		return Retryable(err)
+	}
+	return p.Retryable(err)
+}
+
+func (p RetryPolicy) attempt(ctx context.Context, op func(ctx context.Context) error) error {
+	if p.PerAttempt <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.PerAttempt)
+	defer cancel()
+	return op(ctx)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
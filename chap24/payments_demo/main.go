@@ -0,0 +1,142 @@
+// 独立运行：go run ./chap24/payments_demo
+// 演示：卡号校验与掩码、预授权/请款/退款、幂等键、拒付与重试、部分失败，全部跑在进程内的 FakeGateway 上。
+// 重试决策和幂等重放的断言见 go test ./chap24/payments。
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"books/chap24/payments"
+)
+
+// PaymentMethod 同 chap24/interfaces.go。
+type PaymentMethod interface {
+	Pay(amount float64) error
+}
+
+var ctx = context.Background()
+
+func card(number string) payments.Card {
+	return payments.Card{Number: number, ExpMonth: 12, ExpYear: 2030, Holder: "ZHANG SAN"}
+}
+
+// pad 把 label 补齐到 18 列，中日韩字符按两列计算。
+func pad(label string) string {
+	w := 0
+	for _, r := range label {
+		if r >= 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return label + strings.Repeat(" ", max(1, 18-w))
+}
+
+func show(label string, p payments.Payment, err error) {
+	if err != nil {
+		fmt.Printf("  %s%v\n  %s↳ %v\n", pad(label), p, pad(""), err)
+		return
+	}
+	fmt.Printf("  %s%v (attempts=%d)\n", pad(label), p, p.Attempts)
+}
+
+func main() {
+	fmt.Println("=== 1. Luhn 校验与掩码 ===")
+	for _, n := range []string{payments.TestCardOK, "4242 4242 4242 4241", "5555-5555-5555-4444", "3782 822463 10005"} {
+		fmt.Printf("  %-22s Luhn=%-5v %-10s %s\n", n, payments.Luhn(payments.NormalizeCardNumber(n)),
+			payments.Brand(n), payments.MaskCardNumber(n))
+	}
+	expired := payments.Card{Number: payments.TestCardOK, ExpMonth: 1, ExpYear: 2020}
+	fmt.Println("  过期卡:", expired.Validate(time.Now()))
+
+	gw := payments.NewFakeGateway()
+	paypal := payments.NewFakeGateway()
+	reg := payments.NewRegistry()
+	reg.Register("card", gw)
+	reg.Register("paypal", paypal)
+	policy := payments.RetryPolicy{MaxAttempts: 3, Backoff: 5 * time.Millisecond, PerAttempt: 20 * time.Millisecond}
+	proc := payments.NewProcessor(reg, policy)
+	fmt.Println("  已注册的支付方式:", reg.Kinds())
+
+	fmt.Println("\n=== 2. 预授权 → 部分请款 → 两次部分退款 ===")
+	p, err := proc.Authorize(ctx, "order-1", card(payments.TestCardOK), 10000, "CNY")
+	show("Authorize 100.00", p, err)
+	p, err = proc.Capture(ctx, p.ID, 8000)
+	show("Capture 80.00", p, err)
+	p, err = proc.Refund(ctx, p.ID, 3000)
+	show("Refund 30.00", p, err)
+	p, err = proc.Refund(ctx, p.ID, 6000)
+	show("Refund 60.00", p, err)
+	fmt.Println("  errors.Is(err, ErrInvalidAmount):", errors.Is(err, payments.ErrInvalidAmount))
+	p, err = proc.Refund(ctx, p.ID, 0)
+	show("Refund 剩余", p, err)
+	_, err = proc.Capture(ctx, p.ID, 0)
+	var se *payments.StateError
+	fmt.Println("  再次 Capture:", err, "| errors.As StateError:", errors.As(err, &se), "| Is(ErrInvalidState):", errors.Is(err, payments.ErrInvalidState))
+
+	fmt.Println("\n=== 3. 幂等键 ===")
+	a, _ := proc.Authorize(ctx, "order-2", card(payments.TestCardOK), 5000, "CNY")
+	b, _ := proc.Authorize(ctx, "order-2", card(payments.TestCardOK), 5000, "CNY")
+	fmt.Printf("  同一个键两次: %s == %s，网关交易号 %s == %s\n", a.ID, b.ID, a.TxnID, b.TxnID)
+	_, err = proc.Authorize(ctx, "order-2", card(payments.TestCardOK), 9900, "CNY")
+	fmt.Println("  同一个键不同金额:", err)
+
+	fmt.Println("\n=== 4. 拒付：硬拒付不重试，软拒付重试 ===")
+	p, err = proc.Authorize(ctx, "order-3", card(payments.TestCardInsufficientFunds), 5000, "CNY")
+	show("余额不足", p, err)
+	var de *payments.DeclineError
+	if errors.As(err, &de) {
+		fmt.Printf("  Code=%s Is(ErrDeclined)=%v Is(ErrSoftDecline)=%v Is(&DeclineError{InsufficientFunds})=%v\n",
+			de.Code, errors.Is(err, payments.ErrDeclined), errors.Is(err, payments.ErrSoftDecline),
+			errors.Is(err, &payments.DeclineError{Code: payments.InsufficientFunds}))
+	}
+	gw.Inject(payments.Fault{Op: "authorize", Err: &payments.DeclineError{Code: payments.TryAgainLater}})
+	p, err = proc.Authorize(ctx, "order-4", card(payments.TestCardOK), 5000, "CNY")
+	show("软拒付后重试", p, err)
+
+	fmt.Println("\n=== 5. 超时与部分失败 ===")
+	gw.Inject(payments.Fault{Op: "authorize", Delay: 200 * time.Millisecond})
+	p, err = proc.Authorize(ctx, "order-5", card(payments.TestCardOK), 2000, "CNY")
+	show("第一次超时", p, err)
+
+	gw.Inject(payments.Fault{Op: "capture", Err: payments.ErrTimeout, Commit: true})
+	p, err = proc.Capture(ctx, p.ID, 0)
+	show("请款响应丢失", p, err)
+	auth, captured, _ := gw.Balance(p.TxnID)
+	fmt.Printf("  网关核对: authorized=%d captured=%d（只请款一次）\n", auth, captured)
+
+	gw.Inject(
+		payments.Fault{Op: "authorize", Err: payments.ErrUnavailable},
+		payments.Fault{Op: "authorize", Err: payments.ErrUnavailable},
+		payments.Fault{Op: "authorize", Err: payments.ErrUnavailable},
+	)
+	p, err = proc.Authorize(ctx, "order-6", card(payments.TestCardOK), 3000, "CNY")
+	show("三次都不可用", p, err)
+	p, err = proc.Authorize(ctx, "order-6", card(payments.TestCardOK), 3000, "CNY")
+	show("稍后用同一个键", p, err)
+
+	fmt.Println("\n=== 6. 注册表与 chap24 的 PaymentMethod 接口 ===")
+	_, err = proc.Authorize(ctx, "order-7", payments.PayPal{Email: "user@example.com"}, 1000, "USD")
+	fmt.Println("  PayPal 走另一个网关:", err == nil, paypal.Calls())
+	reg.Unregister("paypal")
+	_, err = proc.Authorize(ctx, "order-8", payments.PayPal{Email: "user@example.com"}, 1000, "USD")
+	fmt.Println("  注销后:", err)
+
+	var methods = []PaymentMethod{
+		&payments.Checkout{Processor: proc, Method: card(payments.TestCardOK), Currency: "CNY", Prefix: "cart"},
+		&payments.Checkout{Processor: proc, Method: card(payments.TestCardDeclined), Currency: "CNY", Prefix: "cart-bad"},
+	}
+	for _, m := range methods {
+		fmt.Println("  Pay(100.0):", m.Pay(100.0))
+	}
+
+	fmt.Println("\n=== 7. 网关调用日志（卡网关）===")
+	for _, c := range gw.Calls() {
+		fmt.Println("  ", strings.TrimSpace(c))
+	}
+}
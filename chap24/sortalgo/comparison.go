@@ -0,0 +1,228 @@
+package sortalgo
+
+import "math/bits"
+
+// InsertionSort 插入排序：稳定，O(n²)，但对几乎有序或很短的数据最快。
+func InsertionSort(data Sortable) {
+	insertionSort(data, 0, data.Len())
+}
+
+func insertionSort(data Sortable, a, b int) {
+	for i := a + 1; i < b; i++ {
+		for j := i; j > a && data.Less(j, j-1); j-- {
+			data.Swap(j, j-1)
+		}
+	}
+}
+
+// HeapSort 堆排序：不稳定，最坏 O(n log n)，不需要额外空间。
+func HeapSort(data Sortable) {
+	heapSort(data, 0, data.Len())
+}
+
+// heapSort 对 [a, b) 排序，快速排序递归过深时也用它。
+func heapSort(data Sortable, a, b int) {
+	n := b - a
+	for i := n/2 - 1; i >= 0; i-- {
+		siftDown(data, i, n, a)
+	}
+	for i := n - 1; i > 0; i-- {
+		data.Swap(a, a+i)
+		siftDown(data, 0, i, a)
+	}
+}
+
+// siftDown 在以 offset 为起点、长度为 n 的大顶堆中把节点 root 下沉到合适位置。
+func siftDown(data Sortable, root, n, offset int) {
+	for {
+		child := 2*root + 1
+		if child >= n {
+			return
+		}
+		if child+1 < n && data.Less(offset+child, offset+child+1) {
+			child++
+		}
+		if !data.Less(offset+root, offset+child) {
+			return
+		}
+		data.Swap(offset+root, offset+child)
+		root = child
+	}
+}
+
+// smallSort 以下的区间改用插入排序。
+const smallSort = 12
+
+// QuickSort 快速排序：三数取中选枢轴，先递归较短的一边；
+// 递归深度超过 2·⌈log₂ n⌉ 时说明枢轴一直选得很差，剩下的区间改用堆排序（即 introsort），
+// 所以最坏也是 O(n log n)。不稳定。
+func QuickSort(data Sortable) {
+	n := data.Len()
+	quickSort(data, 0, n, 2*bits.Len(uint(n)))
+}
+
+func quickSort(data Sortable, a, b, depth int) {
+	for b-a > smallSort {
+		if depth == 0 {
+			heapSort(data, a, b)
+			return
+		}
+		depth--
+		p := partition(data, a, b)
+		if p-a < b-p {
+			quickSort(data, a, p, depth)
+			a = p + 1
+		} else {
+			quickSort(data, p+1, b, depth)
+			b = p
+		}
+	}
+	insertionSort(data, a, b)
+}
+
+// partition 把枢轴放到最终位置 p 并返回 p：[a, p) 都不大于枢轴，(p, b) 都不小于枢轴。
+// 与枢轴相等的元素会分到两边，大量重复元素时两边仍然均衡。
+func partition(data Sortable, a, b int) int {
+	medianOfThree(data, a, a+(b-a)/2, b-1)
+	i, j := a+1, b-1
+	for {
+		for i <= j && data.Less(i, a) {
+			i++
+		}
+		for i <= j && data.Less(a, j) {
+			j--
+		}
+		if i >= j {
+			break
+		}
+		data.Swap(i, j)
+		i++
+		j--
+	}
+	if j != a {
+		data.Swap(a, j)
+	}
+	return j
+}
+
+// medianOfThree 把 data[a]、data[m]、data[c] 的中位数换到 a。
+func medianOfThree(data Sortable, a, m, c int) {
+	if data.Less(m, a) {
+		data.Swap(m, a)
+	}
+	if data.Less(c, m) {
+		data.Swap(c, m)
+		if data.Less(m, a) {
+			data.Swap(m, a)
+		}
+	}
+	data.Swap(a, m)
+}
+
+// mergeBlock 是 MergeSort 先用插入排序处理的块长度。
+const mergeBlock = 20
+
+// MergeSort 归并排序：稳定。只能通过 Swap 移动元素，所以用 SymMerge 原地归并
+// （Kim & Kutzner 2004），比较 O(n log n) 次、交换 O(n log² n) 次，不需要额外空间。
+func MergeSort(data Sortable) {
+	n := data.Len()
+	a, b := 0, mergeBlock
+	for b <= n {
+		insertionSort(data, a, b)
+		a, b = b, b+mergeBlock
+	}
+	insertionSort(data, a, n)
+	for size := mergeBlock; size < n; size *= 2 {
+		a, b = 0, 2*size
+		for b <= n {
+			symMerge(data, a, a+size, b)
+			a, b = b, b+2*size
+		}
+		if m := a + size; m < n {
+			symMerge(data, a, m, n)
+		}
+	}
+}
+
+// symMerge 稳定地合并相邻的有序区间 [a, m) 和 [m, b)。
+func symMerge(data Sortable, a, m, b int) {
+	if m-a == 1 { // 左边只有一个元素：二分查找位置后冒泡过去
+		i, j := m, b
+		for i < j {
+			h := int(uint(i+j) >> 1)
+			if data.Less(h, a) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := a; k < i-1; k++ {
+			data.Swap(k, k+1)
+		}
+		return
+	}
+	if b-m == 1 { // 右边只有一个元素
+		i, j := a, m
+		for i < j {
+			h := int(uint(i+j) >> 1)
+			if !data.Less(m, h) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := m; k > i; k-- {
+			data.Swap(k, k-1)
+		}
+		return
+	}
+
+	mid := int(uint(a+b) >> 1)
+	n := mid + m
+	var start, r int
+	if m > mid {
+		start, r = n-b, mid
+	} else {
+		start, r = a, m
+	}
+	p := n - 1
+	for start < r {
+		c := int(uint(start+r) >> 1)
+		if !data.Less(p-c, c) {
+			start = c + 1
+		} else {
+			r = c
+		}
+	}
+	end := n - start
+	if start < m && m < end {
+		rotate(data, start, m, end)
+	}
+	if a < start && start < mid {
+		symMerge(data, a, start, mid)
+	}
+	if mid < end && end < b {
+		symMerge(data, mid, end, b)
+	}
+}
+
+// rotate 交换相邻的两段 [a, m) 和 [m, b)。
+func rotate(data Sortable, a, m, b int) {
+	i, j := m-a, b-m
+	for i != j {
+		if i > j {
+			swapRange(data, m-i, m, j)
+			i -= j
+		} else {
+			swapRange(data, m-i, m+j-i, i)
+			j -= i
+		}
+	}
+	swapRange(data, m-i, m, i)
+}
+
+func swapRange(data Sortable, a, b, n int) {
+	for i := 0; i < n; i++ {
+		data.Swap(a+i, b+i)
+	}
+}
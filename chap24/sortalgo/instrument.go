@@ -0,0 +1,44 @@
+package sortalgo
+
+// Instrumented 包装一个 Sortable，统计 Less 和 Swap 的调用次数。
+//
+//	c := sortalgo.Count(data)
+//	sortalgo.QuickSort(c)
+//	fmt.Println(c.Comparisons, c.Swaps)
+//
+// 不是并发安全的，排序本来也不会并发调用 Less 和 Swap。
+type Instrumented struct {
+	Data        Sortable
+	Comparisons int
+	Swaps       int
+	// Trace 不为 nil 时在每次 Swap 之后调用，可以用来打印排序过程。
+	Trace func(i, j int)
+}
+
+// Count 返回包装 data 的 Instrumented。
+func Count(data Sortable) *Instrumented {
+	return &Instrumented{Data: data}
+}
+
+// Len 实现 Sortable。
+func (c *Instrumented) Len() int { return c.Data.Len() }
+
+// Less 实现 Sortable。
+func (c *Instrumented) Less(i, j int) bool {
+	c.Comparisons++
+	return c.Data.Less(i, j)
+}
+
+// Swap 实现 Sortable。
+func (c *Instrumented) Swap(i, j int) {
+	c.Swaps++
+	c.Data.Swap(i, j)
+	if c.Trace != nil {
+		c.Trace(i, j)
+	}
+}
+
+// Reset 把计数清零。
+func (c *Instrumented) Reset() {
+	c.Comparisons, c.Swaps = 0, 0
+}
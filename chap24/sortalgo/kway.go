@@ -0,0 +1,93 @@
+package sortalgo
+
+import (
+	"cmp"
+	"container/heap"
+)
+
+// MergeRuns 把 data 中若干相邻的有序段合并成一个有序序列。starts 是每段的起始下标（升序，
+// 第一段可以省略 0），最后一段到 data.Len() 为止。段两两合并，共 ⌈log₂ k⌉ 轮，稳定。
+func MergeRuns(data Sortable, starts []int) {
+	bounds := make([]int, 0, len(starts)+2)
+	if len(starts) == 0 || starts[0] != 0 {
+		bounds = append(bounds, 0)
+	}
+	bounds = append(bounds, starts...)
+	bounds = append(bounds, data.Len())
+	for len(bounds) > 2 {
+		merged := bounds[:1]
+		for i := 0; i+2 < len(bounds); i += 2 {
+			a, m, b := bounds[i], bounds[i+1], bounds[i+2]
+			if a < m && m < b {
+				symMerge(data, a, m, b)
+			}
+			merged = append(merged, b)
+		}
+		if len(bounds)%2 == 0 { // 奇数个段，最后一段原样保留到下一轮
+			merged = append(merged, bounds[len(bounds)-1])
+		}
+		bounds = merged
+	}
+}
+
+// MergeK 合并多个升序切片，返回新切片。
+func MergeK[T cmp.Ordered](lists ...[]T) []T {
+	return MergeKFunc(cmp.Compare[T], lists...)
+}
+
+// MergeKFunc 用大小为 k 的最小堆合并多个按 cmp 排好序的切片，O(n log k)。
+// 相等的元素按所在切片的顺序输出，因此是稳定的。
+func MergeKFunc[T any](cmp func(a, b T) int, lists ...[]T) []T {
+	n := 0
+	h := &cursors[T]{cmp: cmp}
+	for i, l := range lists {
+		n += len(l)
+		if len(l) > 0 {
+			h.items = append(h.items, cursor{list: i})
+		}
+	}
+	h.lists = lists
+	heap.Init(h)
+	out := make([]T, 0, n)
+	for h.Len() > 0 {
+		c := &h.items[0]
+		out = append(out, lists[c.list][c.pos])
+		if c.pos++; c.pos < len(lists[c.list]) {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return out
+}
+
+// cursor 指向第 list 个切片中下一个要输出的元素。
+type cursor struct {
+	list, pos int
+}
+
+// cursors 是按当前元素排序的最小堆，实现 heap.Interface。
+type cursors[T any] struct {
+	lists [][]T
+	items []cursor
+	cmp   func(a, b T) int
+}
+
+func (h *cursors[T]) Len() int { return len(h.items) }
+
+func (h *cursors[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if c := h.cmp(h.lists[a.list][a.pos], h.lists[b.list][b.pos]); c != 0 {
+		return c < 0
+	}
+	return a.list < b.list
+}
+
+func (h *cursors[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *cursors[T]) Push(x any)    { h.items = append(h.items, x.(cursor)) }
+
+func (h *cursors[T]) Pop() any {
+	x := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return x
+}
@@ -0,0 +1,108 @@
+package sortalgo
+
+// Integer 是所有整数类型。
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntKey 把有符号整数映射成保持顺序的无符号键：翻转符号位后负数排在正数前面。
+func IntKey(v int64) uint64 {
+	return uint64(v) ^ 1<<63
+}
+
+// RadixSort 按 key(i) 的大小对 data 排序，不调用 Less。key 必须读取 data 当前的内容
+// （例如闭包引用同一个切片），因为元素会被 Swap 移动。有符号整数用 IntKey 转换。
+//
+// 实现是 MSD 的 American flag sort：按最高字节把元素原地分到 256 个桶，再对每个桶递归下一个字节，
+// 只通过 Swap 移动元素，不需要额外的数组。不稳定。
+func RadixSort(data Sortable, key func(i int) uint64) {
+	radixSort(data, key, 0, data.Len(), 56)
+}
+
+func radixSort(data Sortable, key func(int) uint64, a, b int, shift uint) {
+	if b-a <= smallSort {
+		for i := a + 1; i < b; i++ {
+			for j := i; j > a && key(j) < key(j-1); j-- {
+				data.Swap(j, j-1)
+			}
+		}
+		return
+	}
+	var count [256]int
+	for i := a; i < b; i++ {
+		count[byte(key(i)>>shift)]++
+	}
+	var next, end [256]int
+	pos := a
+	for d := range count {
+		next[d] = pos
+		pos += count[d]
+		end[d] = pos
+	}
+	for d := range count {
+		for next[d] < end[d] {
+			// 把 next[d] 处的元素换到它所属的桶，直到换来一个属于桶 d 的元素。
+			if e := byte(key(next[d]) >> shift); e == byte(d) {
+				next[d]++
+			} else {
+				data.Swap(next[d], next[e])
+				next[e]++
+			}
+		}
+	}
+	if shift == 0 {
+		return
+	}
+	start := a
+	for d := range count {
+		if count[d] > 1 {
+			radixSort(data, key, start, start+count[d], shift-8)
+		}
+		start += count[d]
+	}
+}
+
+// Radix 用 LSD 基数排序对整数切片升序排序：每轮按一个字节做计数排序，
+// 所有元素这个字节都相同的轮次直接跳过。需要与 s 等长的缓冲区，稳定。
+func Radix[T Integer](s []T) {
+	signed := ^T(0) < 0
+	RadixFunc(s, func(v T) uint64 {
+		if signed {
+			return IntKey(int64(v))
+		}
+		return uint64(v)
+	})
+}
+
+// RadixFunc 按 key(v) 对 s 稳定排序。
+func RadixFunc[T any](s []T, key func(T) uint64) {
+	if len(s) < 2 {
+		return
+	}
+	keys := make([]uint64, len(s))
+	for i, v := range s {
+		keys[i] = key(v)
+	}
+	buf := make([]T, len(s))
+	kbuf := make([]uint64, len(s))
+	for shift := uint(0); shift < 64; shift += 8 {
+		var count [257]int
+		for _, k := range keys {
+			count[int(byte(k>>shift))+1]++
+		}
+		if count[int(byte(keys[0]>>shift))+1] == len(s) {
+			continue
+		}
+		for d := 1; d < len(count); d++ {
+			count[d] += count[d-1]
+		}
+		for i, k := range keys {
+			d := byte(k >> shift)
+			buf[count[d]], kbuf[count[d]] = s[i], k
+			count[d]++
+		}
+		copy(s, buf)
+		copy(keys, kbuf)
+	}
+}
@@ -0,0 +1,65 @@
+// Package sortalgo 在 chap24 的 Sortable 接口（Len/Less/Swap）之上实现几种经典排序算法：
+// 插入排序、归并排序（稳定）、堆排序、快速排序（三数取中，递归过深时退化为堆排序）、
+// 整数基数排序和 k 路归并。
+//
+// 每种算法都有两种形式：XxxSort(data Sortable) 直接作用于 Sortable；
+// Xxx[T cmp.Ordered](s []T) 和 XxxFunc(s, cmp) 作用于切片。切片形式把切片包装成 Sortable
+// 再调用同一份实现，所以两种形式的比较和交换次数完全相同，Instrumented 统计出来的数字对两者都成立。
+package sortalgo
+
+import "cmp"
+
+// Sortable 同 chap24/interfaces.go，也与 sort.Interface 相同。
+type Sortable interface {
+	Len() int
+	Less(i, j int) bool
+	Swap(i, j int)
+}
+
+// IsSorted 报告 data 是否已经按升序排列。
+func IsSorted(data Sortable) bool {
+	for i := data.Len() - 1; i > 0; i-- {
+		if data.Less(i, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// funcSlice 把切片和比较函数包装成 Sortable。
+type funcSlice[T any] struct {
+	s   []T
+	cmp func(a, b T) int
+}
+
+func (f funcSlice[T]) Len() int           { return len(f.s) }
+func (f funcSlice[T]) Less(i, j int) bool { return f.cmp(f.s[i], f.s[j]) < 0 }
+func (f funcSlice[T]) Swap(i, j int)      { f.s[i], f.s[j] = f.s[j], f.s[i] }
+
+func ordered[T cmp.Ordered](s []T) funcSlice[T] {
+	return funcSlice[T]{s, cmp.Compare[T]}
+}
+
+// Insertion 用插入排序对 s 升序排序。
+func Insertion[T cmp.Ordered](s []T) { InsertionSort(ordered(s)) }
+
+// InsertionFunc 用插入排序按 cmp 排序，cmp(a, b) 小于 0 表示 a 排在 b 前面。
+func InsertionFunc[T any](s []T, cmp func(a, b T) int) { InsertionSort(funcSlice[T]{s, cmp}) }
+
+// Merge 用归并排序对 s 升序排序，相等的元素保持原来的顺序。
+func Merge[T cmp.Ordered](s []T) { MergeSort(ordered(s)) }
+
+// MergeFunc 用归并排序按 cmp 稳定排序。
+func MergeFunc[T any](s []T, cmp func(a, b T) int) { MergeSort(funcSlice[T]{s, cmp}) }
+
+// Heap 用堆排序对 s 升序排序。
+func Heap[T cmp.Ordered](s []T) { HeapSort(ordered(s)) }
+
+// HeapFunc 用堆排序按 cmp 排序。
+func HeapFunc[T any](s []T, cmp func(a, b T) int) { HeapSort(funcSlice[T]{s, cmp}) }
+
+// Quick 用快速排序对 s 升序排序。
+func Quick[T cmp.Ordered](s []T) { QuickSort(ordered(s)) }
+
+// QuickFunc 用快速排序按 cmp 排序。
+func QuickFunc[T any](s []T, cmp func(a, b T) int) { QuickSort(funcSlice[T]{s, cmp}) }
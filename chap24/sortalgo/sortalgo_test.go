@@ -0,0 +1,371 @@
+package sortalgo
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// IntSlice 同 chap24/interfaces.go 和 chap17/slice_methods.go。
+type IntSlice []int
+
+func (s IntSlice) Len() int           { return len(s) }
+func (s IntSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s IntSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// algo 对 data 排序；s 是 data 背后的切片，RadixSort 的 key 需要读取它。
+type algo struct {
+	name    string
+	sort    func(data Sortable, s IntSlice)
+	generic func(s []int)
+	fn      func(s []int, cmp func(a, b int) int) // XxxFunc 形式，没有时为 nil
+}
+
+var algos = []algo{
+	{"insertion", func(d Sortable, _ IntSlice) { InsertionSort(d) }, Insertion[int], InsertionFunc[int]},
+	{"merge", func(d Sortable, _ IntSlice) { MergeSort(d) }, Merge[int], MergeFunc[int]},
+	{"heap", func(d Sortable, _ IntSlice) { HeapSort(d) }, Heap[int], HeapFunc[int]},
+	{"quick", func(d Sortable, _ IntSlice) { QuickSort(d) }, Quick[int], QuickFunc[int]},
+	{"radix", func(d Sortable, s IntSlice) {
+		RadixSort(d, func(i int) uint64 { return IntKey(int64(s[i])) })
+	}, Radix[int], nil},
+}
+
+// inputs 生成 n 个元素的测试输入。
+var inputs = []struct {
+	name string
+	gen  func(r *rand.Rand, n int) []int
+}{
+	{"random", func(r *rand.Rand, n int) []int { return r.Perm(n) }},
+	{"sorted", func(_ *rand.Rand, n int) []int { return seq(n, func(i int) int { return i }) }},
+	{"reversed", func(_ *rand.Rand, n int) []int { return seq(n, func(i int) int { return n - i }) }},
+	{"all-equal", func(_ *rand.Rand, n int) []int { return seq(n, func(int) int { return 7 }) }},
+	{"few-unique", func(r *rand.Rand, n int) []int { return seq(n, func(int) int { return r.Intn(4) }) }},
+	{"negative", func(r *rand.Rand, n int) []int { return seq(n, func(int) int { return r.Intn(2001) - 1000 }) }},
+	{"organ-pipe", func(_ *rand.Rand, n int) []int { return seq(n, func(i int) int { return min(i, n-i) }) }},
+}
+
+// sizes 覆盖空切片、单个元素、插入排序阈值（smallSort、mergeBlock）两侧和较大的输入。
+var sizes = []int{0, 1, 2, 3, 5, 12, 13, 20, 21, 41, 100, 257, 1000}
+
+func seq(n int, f func(i int) int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = f(i)
+	}
+	return s
+}
+
+func head[T any](s []T) string {
+	if len(s) > 12 {
+		return fmt.Sprint(s[:12]) + "..."
+	}
+	return fmt.Sprint(s)
+}
+
+func TestSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	desc := func(a, b int) int { return cmp.Compare(b, a) }
+	for _, n := range sizes {
+		for _, in := range inputs {
+			input := in.gen(r, n)
+			want := slices.Clone(input)
+			slices.Sort(want)
+			wantDesc := slices.Clone(want)
+			slices.Reverse(wantDesc)
+			for _, a := range algos {
+				s := IntSlice(slices.Clone(input))
+				a.sort(s, s)
+				if !slices.Equal(s, want) {
+					t.Errorf("%s(Sortable) n=%d %s: %v", a.name, n, in.name, head(s))
+				}
+				g := slices.Clone(input)
+				a.generic(g)
+				if !slices.Equal(g, want) {
+					t.Errorf("%s(generic) n=%d %s: %v", a.name, n, in.name, head(g))
+				}
+				if a.fn != nil {
+					f := slices.Clone(input)
+					a.fn(f, desc)
+					if !slices.Equal(f, wantDesc) {
+						t.Errorf("%sFunc(desc) n=%d %s: %v", a.name, n, in.name, head(f))
+					}
+				}
+				if !IsSorted(s) {
+					t.Errorf("%s n=%d %s: IsSorted = false", a.name, n, in.name)
+				}
+			}
+		}
+	}
+}
+
+func TestIsSorted(t *testing.T) {
+	for _, tc := range []struct {
+		s    []int
+		want bool
+	}{
+		{nil, true}, {[]int{1}, true}, {[]int{1, 1, 2}, true}, {[]int{2, 1}, false}, {[]int{1, 3, 2, 4}, false},
+	} {
+		if got := IsSorted(IntSlice(tc.s)); got != tc.want {
+			t.Errorf("IsSorted(%v) = %v", tc.s, got)
+		}
+	}
+}
+
+func TestFuncStrings(t *testing.T) {
+	words := strings.Fields("pear apple fig kiwi banana cherry date grape lemon mango melon olive peach plum")
+	rand.New(rand.NewSource(2)).Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+	desc := func(a, b string) int { return cmp.Compare(b, a) }
+	want := slices.Clone(words)
+	slices.SortFunc(want, desc)
+	for name, f := range map[string]func([]string, func(a, b string) int){
+		"InsertionFunc": InsertionFunc[string],
+		"MergeFunc":     MergeFunc[string],
+		"HeapFunc":      HeapFunc[string],
+		"QuickFunc":     QuickFunc[string],
+	} {
+		got := slices.Clone(words)
+		f(got, desc)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s descending: %v", name, got)
+		}
+	}
+}
+
+type pair struct{ key, seq int }
+
+// pairs 按 key 比较，实现 Sortable。
+type pairs struct{ s []pair }
+
+func (p pairs) Len() int           { return len(p.s) }
+func (p pairs) Less(i, j int) bool { return p.s[i].key < p.s[j].key }
+func (p pairs) Swap(i, j int)      { p.s[i], p.s[j] = p.s[j], p.s[i] }
+
+// TestStable 按 key 排序带序号的元素，相同 key 的序号必须保持递增。
+func TestStable(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	byKey := func(a, b pair) int { return cmp.Compare(a.key, b.key) }
+	stable := func(got []pair) bool {
+		return slices.IsSortedFunc(got, func(a, b pair) int {
+			if c := byKey(a, b); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.seq, b.seq)
+		})
+	}
+	for _, n := range []int{0, 1, 10, 20, 21, 41, 100, 1000} {
+		for _, keys := range []int{1, 2, 5, 1000} {
+			input := make([]pair, n)
+			for i := range input {
+				input[i] = pair{r.Intn(keys), i}
+			}
+			for name, f := range map[string]func([]pair, func(a, b pair) int){
+				"InsertionFunc": InsertionFunc[pair],
+				"MergeFunc":     MergeFunc[pair],
+			} {
+				got := slices.Clone(input)
+				f(got, byKey)
+				if !stable(got) {
+					t.Errorf("%s n=%d keys=%d is not stable: %v", name, n, keys, head(got))
+				}
+			}
+			got := slices.Clone(input)
+			MergeSort(pairs{got})
+			if !stable(got) {
+				t.Errorf("MergeSort n=%d keys=%d is not stable", n, keys)
+			}
+			got = slices.Clone(input)
+			RadixFunc(got, func(p pair) uint64 { return uint64(p.key) })
+			if !stable(got) {
+				t.Errorf("RadixFunc n=%d keys=%d is not stable", n, keys)
+			}
+		}
+	}
+}
+
+func TestMergeRunsAndMergeK(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	byKey := func(a, b pair) int { return cmp.Compare(a.key, b.key) }
+	for _, n := range []int{4, 21, 100, 1000} {
+		for _, k := range []int{1, 2, 3, 4, 7} {
+			input := make([]pair, n)
+			for i := range input {
+				input[i] = pair{r.Intn(5), i}
+			}
+			// 切成 k 段分别稳定排序，再合并
+			var runs [][]pair
+			var starts []int
+			step := (n + k - 1) / k
+			for i := 0; i < n; i += step {
+				run := slices.Clone(input[i:min(n, i+step)])
+				slices.SortStableFunc(run, byKey)
+				runs = append(runs, run)
+				starts = append(starts, i)
+			}
+			want := slices.Clone(input)
+			slices.SortStableFunc(want, byKey)
+
+			if got := MergeKFunc(byKey, runs...); !slices.Equal(got, want) {
+				t.Errorf("MergeKFunc n=%d k=%d: %v", n, k, head(got))
+			}
+			flat := concat(runs)
+			MergeRuns(pairs{flat}, starts)
+			if !slices.Equal(flat, want) {
+				t.Errorf("MergeRuns n=%d k=%d: %v", n, k, head(flat))
+			}
+			flat = concat(runs)
+			MergeRuns(pairs{flat}, starts[1:]) // 省略第一段的 0
+			if !slices.Equal(flat, want) {
+				t.Errorf("MergeRuns without 0, n=%d k=%d: %v", n, k, head(flat))
+			}
+		}
+	}
+
+	lists := [][]int{{1, 4, 9}, nil, {2, 2, 3}, {}, {0, 10}}
+	if got := MergeK(lists...); fmt.Sprint(got) != "[0 1 2 2 3 4 9 10]" {
+		t.Errorf("MergeK = %v", got)
+	}
+	if got := MergeK[int](); len(got) != 0 {
+		t.Errorf("MergeK() = %v", got)
+	}
+}
+
+func TestRadixExtremes(t *testing.T) {
+	i8 := []int8{127, -128, 0, -1, 1, 100, -100, -128, 127}
+	Radix(i8)
+	if !slices.IsSorted(i8) {
+		t.Errorf("Radix int8: %v", i8)
+	}
+	i64 := []int64{math.MaxInt64, math.MinInt64, 0, -1, 1, 1 << 40, -(1 << 40), math.MinInt64 + 1}
+	Radix(i64)
+	if !slices.IsSorted(i64) {
+		t.Errorf("Radix int64: %v", i64)
+	}
+	u64 := []uint64{math.MaxUint64, 0, 1 << 63, 0xff, 0xff00, math.MaxUint64 - 1, 1}
+	Radix(u64)
+	if !slices.IsSorted(u64) {
+		t.Errorf("Radix uint64: %v", u64)
+	}
+	type celsius int16
+	temps := []celsius{30, -40, 0, 15, -5}
+	Radix(temps)
+	if fmt.Sprint(temps) != "[-40 -5 0 15 30]" {
+		t.Errorf("Radix on a named type: %v", temps)
+	}
+
+	// 高字节全部相同，MSD 要一直递归到低字节
+	big := make([]uint64, 300)
+	for i := range big {
+		big[i] = math.MaxUint64 - uint64(i*i)
+	}
+	RadixSort(uint64s(big), func(i int) uint64 { return big[i] })
+	if !slices.IsSorted(big) {
+		t.Errorf("RadixSort with equal high bytes: %v", head(big))
+	}
+	if IntKey(-1) >= IntKey(0) || IntKey(math.MinInt64) != 0 || IntKey(math.MaxInt64) != math.MaxUint64 {
+		t.Error("IntKey does not preserve order")
+	}
+}
+
+type uint64s []uint64
+
+func (s uint64s) Len() int           { return len(s) }
+func (s uint64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// TestInstrumentedCounts 用 Instrumented 核对各算法的比较和交换次数。
+func TestInstrumentedCounts(t *testing.T) {
+	const n = 1000
+	nlogn := n * bits.Len(n) // n·⌈log₂ n⌉
+	count := func(a algo, input []int) *Instrumented {
+		s := IntSlice(slices.Clone(input))
+		c := Count(s)
+		a.sort(c, s)
+		return c
+	}
+	sorted := seq(n, func(i int) int { return i })
+	reversed := seq(n, func(i int) int { return n - i })
+	equal := seq(n, func(int) int { return 7 })
+	random := rand.New(rand.NewSource(5)).Perm(n)
+
+	// 插入排序：有序输入只比较 n-1 次；逆序输入每一对都要比较、交换一次
+	ins := algos[0]
+	if c := count(ins, sorted); c.Comparisons != n-1 || c.Swaps != 0 {
+		t.Errorf("insertion on sorted input: %d / %d", c.Comparisons, c.Swaps)
+	}
+	if c := count(ins, reversed); c.Comparisons != n*(n-1)/2 || c.Swaps != n*(n-1)/2 {
+		t.Errorf("insertion on reversed input: %d / %d", c.Comparisons, c.Swaps)
+	}
+	if c := count(ins, equal); c.Comparisons != n-1 || c.Swaps != 0 {
+		t.Errorf("insertion on equal input: %d / %d", c.Comparisons, c.Swaps)
+	}
+
+	// 基数排序不调用 Less；每个字节一轮，每次交换都把一个元素放进它的桶，
+	// 再加上小桶里插入排序的交换
+	for _, input := range [][]int{sorted, reversed, random} {
+		if c := count(algos[4], input); c.Comparisons != 0 || c.Swaps > 8*n+smallSort*n/2 {
+			t.Errorf("radix: %d comparisons, %d swaps", c.Comparisons, c.Swaps)
+		}
+	}
+
+	// O(n log n) 的算法在任何输入上都不能退化成 O(n²)
+	for _, a := range algos[1:4] {
+		for name, input := range map[string][]int{"sorted": sorted, "reversed": reversed, "equal": equal, "random": random} {
+			c := count(a, input)
+			if c.Comparisons > 3*nlogn {
+				t.Errorf("%s on %s input: %d comparisons, want ≤ %d", a.name, name, c.Comparisons, 3*nlogn)
+			}
+			if a.name != "merge" && c.Swaps > 2*nlogn {
+				t.Errorf("%s on %s input: %d swaps, want ≤ %d", a.name, name, c.Swaps, 2*nlogn)
+			}
+		}
+	}
+	// 归并排序对已经有序的输入只需要检查块边界，不做交换
+	if c := count(algos[1], sorted); c.Swaps != 0 {
+		t.Errorf("merge on sorted input: %d swaps", c.Swaps)
+	}
+
+	// 包装成 Sortable 的泛型形式和直接作用于 Sortable 的形式次数相同
+	for _, a := range algos[:4] {
+		direct := count(a, random)
+		g := slices.Clone(random)
+		wrapped := Count(ordered(g))
+		a.sort(wrapped, nil)
+		if wrapped.Comparisons != direct.Comparisons || wrapped.Swaps != direct.Swaps {
+			t.Errorf("%s: generic %d / %d, Sortable %d / %d",
+				a.name, wrapped.Comparisons, wrapped.Swaps, direct.Comparisons, direct.Swaps)
+		}
+	}
+}
+
+func TestInstrumentedTrace(t *testing.T) {
+	s := IntSlice{3, 1, 2}
+	c := Count(s)
+	var swaps []string
+	c.Trace = func(i, j int) { swaps = append(swaps, fmt.Sprintf("%d↔%d %v", i, j, s)) }
+	InsertionSort(c)
+	if c.Len() != 3 || c.Comparisons != 3 || c.Swaps != 2 || len(swaps) != 2 {
+		t.Errorf("counts %d / %d, trace %v", c.Comparisons, c.Swaps, swaps)
+	}
+	if strings.Join(swaps, ", ") != "1↔0 [1 3 2], 2↔1 [1 2 3]" {
+		t.Errorf("trace = %v", swaps)
+	}
+	c.Reset()
+	if c.Comparisons != 0 || c.Swaps != 0 {
+		t.Error("Reset did not clear the counters")
+	}
+}
+
+// concat 把各段首尾相接（slices.Concat 需要 go1.22）。
+func concat[T any](runs [][]T) []T {
+	var out []T
+	for _, r := range runs {
+		out = append(out, r...)
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+// 独立运行：go run ./chap24/sortalgo_demo [-n 1000] [-seed 1] [-viz quick]
+// 演示：统计 sortalgo 每种算法在随机、有序、逆序输入上的比较和交换次数以及耗时；
+// -viz 逐步打印一个小数组的排序过程。断言见 go test ./chap24/sortalgo。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"books/chap24/sortalgo"
+)
+
+// IntSlice 同 chap24/interfaces.go 和 chap17/slice_methods.go。
+type IntSlice []int
+
+func (s IntSlice) Len() int           { return len(s) }
+func (s IntSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s IntSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// bubbleSort 同 chap24/interfaces.go，作为对照。
+func bubbleSort(data sortalgo.Sortable) {
+	n := data.Len()
+	for i := 0; i < n-1; i++ {
+		for j := 0; j < n-1-i; j++ {
+			if data.Less(j+1, j) {
+				data.Swap(j, j+1)
+			}
+		}
+	}
+}
+
+// algo 对 data 排序；s 是 data 背后的切片，RadixSort 的 key 需要读取它。
+type algo struct {
+	name    string
+	sort    func(data sortalgo.Sortable, s IntSlice)
+	generic func(s []int)
+}
+
+var algos = []algo{
+	{"bubble (chap24)", func(d sortalgo.Sortable, _ IntSlice) { bubbleSort(d) }, nil},
+	{"insertion", func(d sortalgo.Sortable, _ IntSlice) { sortalgo.InsertionSort(d) }, sortalgo.Insertion[int]},
+	{"merge", func(d sortalgo.Sortable, _ IntSlice) { sortalgo.MergeSort(d) }, sortalgo.Merge[int]},
+	{"heap", func(d sortalgo.Sortable, _ IntSlice) { sortalgo.HeapSort(d) }, sortalgo.Heap[int]},
+	{"quick", func(d sortalgo.Sortable, _ IntSlice) { sortalgo.QuickSort(d) }, sortalgo.Quick[int]},
+	{"radix", func(d sortalgo.Sortable, s IntSlice) {
+		sortalgo.RadixSort(d, func(i int) uint64 { return sortalgo.IntKey(int64(s[i])) })
+	}, sortalgo.Radix[int]},
+	{"sort.Sort (标准库)", func(d sortalgo.Sortable, _ IntSlice) { sort.Sort(d) }, slices.Sort[[]int]},
+}
+
+// inputs 生成 n 个元素的输入。
+var inputs = []struct {
+	name string
+	gen  func(r *rand.Rand, n int) []int
+}{
+	{"random", func(r *rand.Rand, n int) []int { return r.Perm(n) }},
+	{"sorted", func(_ *rand.Rand, n int) []int { return seq(n, func(i int) int { return i }) }},
+	{"reversed", func(_ *rand.Rand, n int) []int { return seq(n, func(i int) int { return n - i }) }},
+}
+
+func seq(n int, f func(i int) int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = f(i)
+	}
+	return s
+}
+
+func main() {
+	n := flag.Int("n", 1000, "统计用的输入长度")
+	seed := flag.Int64("seed", 1, "随机种子")
+	viz := flag.String("viz", "", "逐步打印某个算法排序 12 个数的过程：insertion、merge、heap、quick、radix、bubble")
+	flag.Parse()
+	r := rand.New(rand.NewSource(*seed))
+
+	if *viz != "" {
+		visualize(*viz, r)
+		return
+	}
+
+	fmt.Printf("=== 比较 / 交换次数（n = %d）===\n", *n)
+	fmt.Printf("  %-18s", "")
+	for _, in := range inputs {
+		fmt.Printf("%24s", in.name)
+	}
+	fmt.Printf("%12s\n", "ns/元素")
+	for _, a := range algos {
+		if a.name == "bubble (chap24)" && *n > 5000 {
+			continue
+		}
+		fmt.Print("  ", pad(a.name, 18))
+		for _, in := range inputs {
+			s := IntSlice(in.gen(r, *n))
+			c := sortalgo.Count(s)
+			a.sort(c, s)
+			fmt.Printf("%24s", fmt.Sprintf("%d / %d", c.Comparisons, c.Swaps))
+		}
+		if a.generic != nil {
+			fmt.Printf("%12.1f", timing(a.generic, inputs[0].gen(r, *n)))
+		}
+		fmt.Println()
+	}
+	lg := math.Log2(float64(*n))
+	fmt.Printf("  参考：n·log₂n ≈ %.0f，n²/2 ≈ %.0f；基数排序不做比较\n", float64(*n)*lg, float64(*n)*float64(*n)/2)
+}
+
+// timing 返回泛型形式对随机输入排序的耗时中位数（每个元素的纳秒数）。
+func timing(sortFn func([]int), input []int) float64 {
+	var runs []time.Duration
+	buf := make([]int, len(input))
+	for i := 0; i < 5; i++ {
+		copy(buf, input)
+		start := time.Now()
+		sortFn(buf)
+		runs = append(runs, time.Since(start))
+	}
+	slices.Sort(runs)
+	return float64(runs[len(runs)/2].Nanoseconds()) / float64(max(1, len(input)))
+}
+
+// pad 按显示宽度补齐，中日韩字符占两列。
+func pad(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return s + strings.Repeat(" ", max(1, width-w))
+}
+
+// visualize 打印每次交换之后的数组，被交换的两个位置用方括号标出。
+func visualize(name string, r *rand.Rand) {
+	var a *algo
+	for i := range algos {
+		if strings.HasPrefix(algos[i].name, name) {
+			a = &algos[i]
+			break
+		}
+	}
+	if a == nil {
+		fmt.Fprintf(os.Stderr, "未知算法 %q\n", name)
+		os.Exit(2)
+	}
+	s := IntSlice(seq(12, func(int) int { return r.Intn(10) }))
+	c := sortalgo.Count(s)
+	row := func(prefix string, i, j int) {
+		var b strings.Builder
+		for k, v := range s {
+			if k == i || k == j {
+				fmt.Fprintf(&b, "[%d]", v)
+			} else {
+				fmt.Fprintf(&b, " %d ", v)
+			}
+		}
+		fmt.Printf("%5s %s\n", prefix, b.String())
+	}
+	row("", -1, -1)
+	c.Trace = func(i, j int) { row(fmt.Sprint(c.Swaps), i, j) }
+	a.sort(c, s)
+	fmt.Printf("%s：%d 次比较，%d 次交换\n", a.name, c.Comparisons, c.Swaps)
+}
@@ -0,0 +1,48 @@
+// Package sanctuary 是第 25 章的综合练习：火星动物保护区。
+//
+// 每种动物实现 Animal 接口（移动、进食、叫、睡觉）。Sanctuary 用火星日（sol）的时钟驱动所有动物：
+// 白天醒着的动物随机选择一个行为，夜里睡觉；实现了 Nocturnal 的动物反过来。
+// 物种通过 Registry 注册，核心循环只认识接口，新增物种不需要修改本包。
+// 所有随机性来自同一个带种子的 rand.Rand，同一个种子总是得到同样的事件日志。
+package sanctuary
+
+import "math/rand"
+
+// Animal 是保护区里的动物。方法返回一句描述，写进事件日志。
+type Animal interface {
+	// Name 是动物的名字，在保护区内唯一。
+	Name() string
+	// Move 返回这一小时的位移和描述，保护区会把位置限制在围栏以内。
+	Move(r *rand.Rand) (dx, dy int, how string)
+	// Eat 返回吃了什么。
+	Eat(r *rand.Rand) string
+	// Speak 返回叫声。
+	Speak(r *rand.Rand) string
+	// Sleep 返回入睡时的描述。
+	Sleep() string
+}
+
+// Nocturnal 是可选接口：Nocturnal() 返回 true 的动物白天睡觉、夜里活动。
+type Nocturnal interface {
+	Nocturnal() bool
+}
+
+// Hungry 是可选接口：返回动物醒着多少小时后会饿，默认 DefaultAppetite。
+type Hungry interface {
+	Appetite() int
+}
+
+// DefaultAppetite 是没有实现 Hungry 的动物变饿所需的清醒小时数。
+const DefaultAppetite = 6
+
+func isNocturnal(a Animal) bool {
+	n, ok := a.(Nocturnal)
+	return ok && n.Nocturnal()
+}
+
+func appetite(a Animal) int {
+	if h, ok := a.(Hungry); ok && h.Appetite() > 0 {
+		return h.Appetite()
+	}
+	return DefaultAppetite
+}
@@ -0,0 +1,75 @@
+package sanctuary
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownSpecies 表示物种没有注册。
+var ErrUnknownSpecies = errors.New("sanctuary: unknown species")
+
+// Factory 用名字创建一只动物。
+type Factory func(name string) Animal
+
+// Registry 保存物种名到 Factory 的映射，类似 database/sql 的驱动注册：
+// 物种包在 init 中调用 Register，使用方用空白导入引入它们。
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry 创建空注册表。
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register 注册物种；重名或 f 为 nil 时 panic，与 database/sql.Register 一样，
+// 因为这只会发生在 init 里的编程错误。
+func (r *Registry) Register(species string, f Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f == nil {
+		panic("sanctuary: Register factory is nil for " + species)
+	}
+	if _, dup := r.factories[species]; dup {
+		panic("sanctuary: Register called twice for species " + species)
+	}
+	r.factories[species] = f
+}
+
+// New 创建 species 的一只名为 name 的动物。
+func (r *Registry) New(species, name string) (Animal, error) {
+	r.mu.RLock()
+	f, ok := r.factories[species]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSpecies, species)
+	}
+	return f(name), nil
+}
+
+// Species 返回已注册的物种，按字母排序。
+func (r *Registry) Species() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default 是包级函数使用的注册表。
+var Default = NewRegistry()
+
+// Register 在 Default 中注册物种。
+func Register(species string, f Factory) { Default.Register(species, f) }
+
+// New 用 Default 创建动物。
+func New(species, name string) (Animal, error) { return Default.New(species, name) }
+
+// Species 返回 Default 中的物种。
+func Species() []string { return Default.Species() }
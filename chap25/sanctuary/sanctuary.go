@@ -0,0 +1,244 @@
+package sanctuary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// 火星日的时间划分。真实的 sol 约 24 小时 39 分钟，这里取整为 24 小时。
+const (
+	HoursPerSol = 24
+	Sunrise     = 6  // 6:00 天亮
+	Sunset      = 18 // 18:00 天黑
+)
+
+// Clock 是保护区时钟，Sol 从 1 开始。
+type Clock struct {
+	Sol, Hour int
+}
+
+// IsDay 报告当前是否是白天。
+func (c Clock) IsDay() bool {
+	return c.Hour >= Sunrise && c.Hour < Sunset
+}
+
+// Tick 前进一小时。
+func (c *Clock) Tick() {
+	if c.Hour++; c.Hour == HoursPerSol {
+		c.Sol, c.Hour = c.Sol+1, 0
+	}
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("Sol %d %02d:00", c.Sol, c.Hour)
+}
+
+// Action 是事件的类型。
+type Action string
+
+// 事件类型。
+const (
+	ActionWake  Action = "wake"
+	ActionSleep Action = "sleep"
+	ActionMove  Action = "move"
+	ActionEat   Action = "eat"
+	ActionSpeak Action = "speak"
+)
+
+// Actions 按固定顺序列出所有事件类型，用于打印统计。
+var Actions = []Action{ActionWake, ActionMove, ActionEat, ActionSpeak, ActionSleep}
+
+// Event 是事件日志中的一行（JSON Lines）。X、Y 是事件发生后动物的位置。
+type Event struct {
+	Sol     int    `json:"sol"`
+	Hour    int    `json:"hour"`
+	Animal  string `json:"animal"`
+	Species string `json:"species"`
+	Action  Action `json:"action"`
+	Detail  string `json:"detail"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+}
+
+// Config 配置保护区。
+type Config struct {
+	// Seed 决定全部随机行为。
+	Seed int64
+	// Width、Height 是围栏大小，默认 10×10。
+	Width, Height int
+	// Log 接收 JSON Lines 事件日志，为 nil 时不写日志。
+	Log io.Writer
+	// Registry 用于 Admit，为 nil 时使用 Default。
+	Registry *Registry
+}
+
+// Sanctuary 是保护区。不是并发安全的，模拟本身是单线程的。
+type Sanctuary struct {
+	Clock Clock
+	// OnEvent 不为 nil 时对每个事件调用，在写日志之后。
+	OnEvent func(Event)
+
+	cfg       Config
+	rng       *rand.Rand
+	enc       *json.Encoder
+	residents []*resident
+	byName    map[string]*resident
+}
+
+// resident 是动物在保护区中的状态。
+type resident struct {
+	Animal
+	species string
+	x, y    int
+	awake   bool
+	hunger  int // 上次进食后醒着的小时数
+	counts  map[Action]int
+}
+
+// NewSanctuary 创建保护区，时钟从 Sol 1 的午夜开始。
+func NewSanctuary(cfg Config) *Sanctuary {
+	if cfg.Width <= 0 {
+		cfg.Width = 10
+	}
+	if cfg.Height <= 0 {
+		cfg.Height = 10
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = Default
+	}
+	s := &Sanctuary{
+		Clock:  Clock{Sol: 1},
+		cfg:    cfg,
+		rng:    rand.New(rand.NewSource(cfg.Seed)),
+		byName: make(map[string]*resident),
+	}
+	if cfg.Log != nil {
+		s.enc = json.NewEncoder(cfg.Log)
+		s.enc.SetEscapeHTML(false)
+	}
+	return s
+}
+
+// Admit 用注册表创建 species 的动物并放进保护区。
+func (s *Sanctuary) Admit(species, name string) error {
+	a, err := s.cfg.Registry.New(species, name)
+	if err != nil {
+		return err
+	}
+	return s.Add(species, a)
+}
+
+// Add 把已经创建好的动物放进保护区的随机位置。名字必须唯一。
+func (s *Sanctuary) Add(species string, a Animal) error {
+	if _, dup := s.byName[a.Name()]; dup {
+		return fmt.Errorf("sanctuary: duplicate animal name %q", a.Name())
+	}
+	r := &resident{
+		Animal:  a,
+		species: species,
+		x:       s.rng.Intn(s.cfg.Width),
+		y:       s.rng.Intn(s.cfg.Height),
+		awake:   s.Clock.IsDay() != isNocturnal(a),
+		counts:  make(map[Action]int),
+	}
+	s.residents = append(s.residents, r)
+	s.byName[a.Name()] = r
+	return nil
+}
+
+// Run 模拟 sols 个完整的火星日。
+func (s *Sanctuary) Run(sols int) error {
+	for i := 0; i < sols*HoursPerSol; i++ {
+		if err := s.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step 模拟一小时：每只动物按加入顺序行动一次，然后时钟前进。
+func (s *Sanctuary) Step() error {
+	for _, r := range s.residents {
+		if err := s.act(r); err != nil {
+			return err
+		}
+	}
+	s.Clock.Tick()
+	return nil
+}
+
+// act 决定动物这一小时做什么：该睡时入睡，该醒时醒来；醒着时饿了多半去吃东西，
+// 否则随机移动、叫或者休息（休息不记录事件）。
+func (s *Sanctuary) act(r *resident) error {
+	awake := s.Clock.IsDay() != isNocturnal(r.Animal)
+	switch {
+	case !awake && r.awake:
+		r.awake = false
+		return s.emit(r, ActionSleep, r.Sleep())
+	case !awake:
+		return nil
+	case !r.awake:
+		r.awake = true
+		return s.emit(r, ActionWake, "醒了")
+	}
+
+	r.hunger++
+	roll := s.rng.Intn(100)
+	switch {
+	case r.hunger >= appetite(r.Animal) && roll < 70:
+		r.hunger = 0
+		return s.emit(r, ActionEat, r.Eat(s.rng))
+	case roll < 45:
+		dx, dy, how := r.Move(s.rng)
+		r.x = clamp(r.x+dx, s.cfg.Width)
+		r.y = clamp(r.y+dy, s.cfg.Height)
+		return s.emit(r, ActionMove, how)
+	case roll < 65:
+		return s.emit(r, ActionSpeak, r.Speak(s.rng))
+	}
+	return nil
+}
+
+func clamp(v, n int) int {
+	return min(max(v, 0), n-1)
+}
+
+func (s *Sanctuary) emit(r *resident, action Action, detail string) error {
+	r.counts[action]++
+	e := Event{
+		Sol: s.Clock.Sol, Hour: s.Clock.Hour,
+		Animal: r.Name(), Species: r.species,
+		Action: action, Detail: detail, X: r.x, Y: r.y,
+	}
+	if s.enc != nil {
+		if err := s.enc.Encode(e); err != nil {
+			return fmt.Errorf("sanctuary: write event log: %w", err)
+		}
+	}
+	if s.OnEvent != nil {
+		s.OnEvent(e)
+	}
+	return nil
+}
+
+// Tally 是一只动物的事件统计。
+type Tally struct {
+	Animal, Species string
+	X, Y            int
+	Counts          map[Action]int
+}
+
+// Tallies 按加入顺序返回每只动物的统计和当前位置。
+func (s *Sanctuary) Tallies() []Tally {
+	out := make([]Tally, 0, len(s.residents))
+	for _, r := range s.residents {
+		counts := make(map[Action]int, len(r.counts))
+		for k, v := range r.counts {
+			counts[k] = v
+		}
+		out = append(out, Tally{Animal: r.Name(), Species: r.species, X: r.x, Y: r.y, Counts: counts})
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package sanctuary_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"books/chap25/sanctuary"
+	_ "books/chap25/sanctuary/species"
+)
+
+// Penguin 与 sanctuary_demo 相同：在 sanctuary 包之外新增的物种。
+type Penguin struct{ name string }
+
+func (p Penguin) Name() string { return p.name }
+
+func (p Penguin) Move(r *rand.Rand) (int, int, string) {
+	if r.Intn(2) == 0 {
+		return 1, 0, "肚皮贴地向东滑了 1 格"
+	}
+	return -1, 0, "摇摇摆摆向西走了 1 格"
+}
+
+func (p Penguin) Eat(*rand.Rand) string { return "吃了一条冷冻磷虾" }
+
+func (p Penguin) Speak(*rand.Rand) string { return "嘎嘎！" }
+
+func (p Penguin) Sleep() string { return "站着睡着了" }
+
+func init() {
+	sanctuary.Register("penguin", func(name string) sanctuary.Animal { return Penguin{name} })
+}
+
+// roster 与 sanctuary_demo 相同，顺序决定随机数的消耗顺序。
+var roster = []struct{ species, name string }{
+	{"dog", "旺财"},
+	{"cat", "咪咪"},
+	{"owl", "夜猫子"},
+	{"tortoise", "慢慢"},
+	{"penguin", "波波"},
+}
+
+// 用种子 42 模拟 7 个 sol 的事件日志摘要。修改模拟规则或内置物种后需要更新。
+const (
+	goldenSeed   = 42
+	goldenSols   = 7
+	goldenEvents = 314
+	goldenSHA256 = "a5f5efc008f8878d863ae33cb68081c7d6bc4f2c3789f3c1229e3e1c7f682399"
+)
+
+// simulate 模拟 sols 个 sol，返回 JSON Lines 日志和 OnEvent 收到的事件。
+func simulate(t *testing.T, seed int64, sols int) ([]byte, []sanctuary.Event) {
+	t.Helper()
+	var log bytes.Buffer
+	var events []sanctuary.Event
+	s := sanctuary.NewSanctuary(sanctuary.Config{Seed: seed, Log: &log})
+	s.OnEvent = func(e sanctuary.Event) { events = append(events, e) }
+	for _, r := range roster {
+		if err := s.Admit(r.species, r.name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Run(sols); err != nil {
+		t.Fatal(err)
+	}
+	return log.Bytes(), events
+}
+
+func TestGolden(t *testing.T) {
+	log, events := simulate(t, goldenSeed, goldenSols)
+	if len(events) != goldenEvents {
+		t.Errorf("%d events, want %d", len(events), goldenEvents)
+	}
+	if sum := fmt.Sprintf("%x", sha256.Sum256(log)); sum != goldenSHA256 {
+		t.Errorf("log SHA-256 = %s, want %s", sum, goldenSHA256)
+	}
+
+	// 日志的每一行就是 OnEvent 收到的事件
+	dec := json.NewDecoder(bytes.NewReader(log))
+	for i := 0; ; i++ {
+		var e sanctuary.Event
+		if err := dec.Decode(&e); err == io.EOF {
+			if i != len(events) {
+				t.Errorf("log has %d lines, OnEvent saw %d events", i, len(events))
+			}
+			break
+		} else if err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		if i >= len(events) || e != events[i] {
+			t.Fatalf("line %d = %+v, OnEvent saw %+v", i+1, e, events[min(i, len(events)-1)])
+		}
+	}
+	if last := events[len(events)-1]; last.Sol != goldenSols {
+		t.Errorf("last event in sol %d, want %d", last.Sol, goldenSols)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	for _, seed := range []int64{goldenSeed, 1, 7} {
+		first, _ := simulate(t, seed, 3)
+		second, _ := simulate(t, seed, 3)
+		if !bytes.Equal(first, second) {
+			t.Errorf("seed %d: two runs produced different logs", seed)
+		}
+	}
+	a, _ := simulate(t, 1, 3)
+	b, _ := simulate(t, 2, 3)
+	if bytes.Equal(a, b) {
+		t.Error("seeds 1 and 2 produced the same log")
+	}
+}
+
+func TestAdmitUnknownSpecies(t *testing.T) {
+	s := sanctuary.NewSanctuary(sanctuary.Config{Seed: goldenSeed})
+	if err := s.Admit("dragon", "x"); !errors.Is(err, sanctuary.ErrUnknownSpecies) {
+		t.Errorf("Admit(dragon) = %v", err)
+	}
+	if _, err := sanctuary.New("dragon", "x"); !errors.Is(err, sanctuary.ErrUnknownSpecies) {
+		t.Errorf("New(dragon) = %v", err)
+	}
+	if fmt.Sprint(sanctuary.Species()) != "[cat dog owl penguin tortoise]" {
+		t.Errorf("Species = %v", sanctuary.Species())
+	}
+}
@@ -0,0 +1,113 @@
+// Package species 提供保护区的内置物种：dog、cat、owl、tortoise。
+// 用空白导入注册它们：
+//
+//	import _ "books/chap25/sanctuary/species"
+package species
+
+import (
+	"fmt"
+	"math/rand"
+
+	"books/chap25/sanctuary"
+)
+
+func init() {
+	sanctuary.Register("dog", func(name string) sanctuary.Animal { return Dog{name} })
+	sanctuary.Register("cat", func(name string) sanctuary.Animal { return Cat{name} })
+	sanctuary.Register("owl", func(name string) sanctuary.Animal { return Owl{name} })
+	sanctuary.Register("tortoise", func(name string) sanctuary.Animal { return Tortoise{name} })
+}
+
+func pick(r *rand.Rand, options ...string) string {
+	return options[r.Intn(len(options))]
+}
+
+var directions = []struct {
+	dx, dy int
+	name   string
+}{
+	{0, -1, "北"}, {1, 0, "东"}, {0, 1, "南"}, {-1, 0, "西"},
+}
+
+// wander 随机选一个方向走 1～maxSteps 格。
+func wander(r *rand.Rand, maxSteps int, verb string) (dx, dy int, how string) {
+	d := directions[r.Intn(len(directions))]
+	n := 1 + r.Intn(maxSteps)
+	return d.dx * n, d.dy * n, fmt.Sprintf("向%s%s %d 格", d.name, verb, n)
+}
+
+// Dog 同 chap24 的 Dog，多了保护区需要的行为。
+type Dog struct{ name string }
+
+func (d Dog) Name() string { return d.name }
+
+func (d Dog) Move(r *rand.Rand) (int, int, string) { return wander(r, 3, "跑") }
+
+func (d Dog) Eat(r *rand.Rand) string {
+	return "吃了" + pick(r, "一碗狗粮", "一根骨头", "温室里掉下的土豆")
+}
+
+func (d Dog) Speak(r *rand.Rand) string {
+	return pick(r, "Woof!", "汪汪！", "对着火卫一叫了两声")
+}
+
+func (d Dog) Sleep() string { return "趴在气闸门边睡着了" }
+
+// Cat 同 chap24 的 Cat。
+type Cat struct{ name string }
+
+func (c Cat) Name() string { return c.name }
+
+func (c Cat) Move(r *rand.Rand) (int, int, string) {
+	if r.Intn(3) == 0 {
+		return 0, 0, "跳上太阳能板晒太阳"
+	}
+	return wander(r, 2, "溜达")
+}
+
+func (c Cat) Eat(r *rand.Rand) string {
+	return "吃了" + pick(r, "一条鱼", "一罐猫粮", "狗碗里的狗粮")
+}
+
+func (c Cat) Speak(r *rand.Rand) string { return pick(r, "Meow!", "喵～", "呼噜呼噜") }
+
+func (c Cat) Sleep() string { return "蜷在暖气管上睡着了" }
+
+// Appetite 猫饿得快一些。
+func (c Cat) Appetite() int { return 4 }
+
+// Owl 是夜行动物，实现 sanctuary.Nocturnal。
+type Owl struct{ name string }
+
+func (o Owl) Name() string { return o.name }
+
+func (o Owl) Move(r *rand.Rand) (int, int, string) { return wander(r, 4, "飞") }
+
+func (o Owl) Eat(r *rand.Rand) string {
+	return "抓到了" + pick(r, "一只火星鼠", "一只甲虫")
+}
+
+func (o Owl) Speak(r *rand.Rand) string { return pick(r, "咕——咕——", "Hoo hoo") }
+
+func (o Owl) Sleep() string { return "天亮了，缩回天线塔上的窝" }
+
+// Nocturnal 实现 sanctuary.Nocturnal。
+func (o Owl) Nocturnal() bool { return true }
+
+// Tortoise 走得慢、不出声，也很耐饿。
+type Tortoise struct{ name string }
+
+func (t Tortoise) Name() string { return t.name }
+
+func (t Tortoise) Move(r *rand.Rand) (int, int, string) { return wander(r, 1, "爬") }
+
+func (t Tortoise) Eat(r *rand.Rand) string {
+	return "慢慢嚼着" + pick(r, "生菜", "苜蓿", "一朵蒲公英")
+}
+
+func (t Tortoise) Speak(*rand.Rand) string { return "……（乌龟不出声）" }
+
+func (t Tortoise) Sleep() string { return "把头缩进壳里" }
+
+// Appetite 实现 sanctuary.Hungry。
+func (t Tortoise) Appetite() int { return 12 }
@@ -0,0 +1,128 @@
+// 独立运行：go run ./chap25/sanctuary_demo [-seed 42] [-sols 7] [-log week.jsonl] [-show 20]
+// 演示：火星动物保护区模拟一周，打印前几条事件和每只动物的统计。
+// 同一个种子总是得到同样的日志，黄金摘要和确定性的断言见 go test ./chap25/sanctuary。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+
+	"books/chap25/sanctuary"
+	_ "books/chap25/sanctuary/species"
+)
+
+// Penguin 演示在核心循环之外新增物种：实现接口、注册，不改 sanctuary 包。
+type Penguin struct{ name string }
+
+func (p Penguin) Name() string { return p.name }
+
+func (p Penguin) Move(r *rand.Rand) (int, int, string) {
+	if r.Intn(2) == 0 {
+		return 1, 0, "肚皮贴地向东滑了 1 格"
+	}
+	return -1, 0, "摇摇摆摆向西走了 1 格"
+}
+
+func (p Penguin) Eat(*rand.Rand) string { return "吃了一条冷冻磷虾" }
+
+func (p Penguin) Speak(*rand.Rand) string { return "嘎嘎！" }
+
+func (p Penguin) Sleep() string { return "站着睡着了" }
+
+func init() {
+	sanctuary.Register("penguin", func(name string) sanctuary.Animal { return Penguin{name} })
+}
+
+// roster 是保护区的居民，顺序决定随机数的消耗顺序，也就影响日志内容。
+var roster = []struct{ species, name string }{
+	{"dog", "旺财"},
+	{"cat", "咪咪"},
+	{"owl", "夜猫子"},
+	{"tortoise", "慢慢"},
+	{"penguin", "波波"},
+}
+
+// pad 按显示宽度补齐，中日韩字符占两列。
+func pad(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return s + strings.Repeat(" ", max(1, width-w))
+}
+
+func simulate(seed int64, sols int, log io.Writer, onEvent func(sanctuary.Event)) (*sanctuary.Sanctuary, error) {
+	s := sanctuary.NewSanctuary(sanctuary.Config{Seed: seed, Log: log})
+	s.OnEvent = onEvent
+	for _, r := range roster {
+		if err := s.Admit(r.species, r.name); err != nil {
+			return nil, err
+		}
+	}
+	return s, s.Run(sols)
+}
+
+func main() {
+	seed := flag.Int64("seed", 42, "随机种子")
+	sols := flag.Int("sols", 7, "模拟的火星日数")
+	logPath := flag.String("log", "", "把 JSON Lines 事件日志写到文件，- 表示标准输出")
+	show := flag.Int("show", 20, "打印前几条事件")
+	flag.Parse()
+
+	fmt.Println("已注册的物种:", sanctuary.Species())
+	if _, err := sanctuary.New("dragon", "x"); err != nil {
+		fmt.Println("未注册的物种:", err)
+	}
+
+	var log io.Writer = io.Discard
+	switch *logPath {
+	case "":
+	case "-":
+		log = os.Stdout
+	default:
+		f, err := os.Create(*logPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		log = f
+	}
+
+	fmt.Printf("\n=== 种子 %d，模拟 %d 个 sol ===\n", *seed, *sols)
+	n := 0
+	s, err := simulate(*seed, *sols, log, func(e sanctuary.Event) {
+		if n++; n <= *show {
+			fmt.Printf("  Sol %d %02d:00  %s%-6s %s (%d,%d)\n",
+				e.Sol, e.Hour, pad(e.Animal, 8), e.Action, e.Detail, e.X, e.Y)
+		}
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if n > *show {
+		fmt.Printf("  ……共 %d 条事件\n", n)
+	}
+
+	fmt.Printf("\n%s%s", pad("动物", 10), pad("物种", 10))
+	for _, a := range sanctuary.Actions {
+		fmt.Printf("%7s", a)
+	}
+	fmt.Println("   位置")
+	for _, t := range s.Tallies() {
+		fmt.Printf("%s%-10s", pad(t.Animal, 10), t.Species)
+		for _, a := range sanctuary.Actions {
+			fmt.Printf("%7d", t.Counts[a])
+		}
+		fmt.Printf("   (%d,%d)\n", t.X, t.Y)
+	}
+}
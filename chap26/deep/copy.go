@@ -0,0 +1,242 @@
+// Package deep 用反射做深拷贝、深比较和差异对比（第26章“值传递只复制一层”的延伸）。
+//
+// chap26/pointers.go 和 chap21/structs.go 演示了：结构体赋值会复制所有字段，
+// 但字段里的指针、切片和 map 仍然指向同一份数据。Copy 会一直复制到最底层：
+//   - 指针、切片、map 都分配新的副本，原来共享的部分在副本中依然共享；
+//   - 共用同一个底层数组的切片（例如 s、s[1:] 和 s[:2]）在副本中共用同一个新数组，
+//     副本数组包含原数组从这些切片中最靠前的起点到容量末尾的所有元素；
+//   - 循环引用（例如首尾相连的 Node 链表）只复制一次，不会无限递归；
+//   - 不可导出字段按 UnexportedPolicy 处理；
+//   - 可以用 WithCopier 为某个类型指定自定义拷贝函数。
+//
+// chan、func 和 unsafe.Pointer 无法复制，副本与原值共享。
+package deep
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// UnexportedPolicy 决定 Copy 如何处理不可导出字段。
+type UnexportedPolicy int
+
+const (
+	// DeepUnexported 像可导出字段一样深拷贝（默认）。
+	DeepUnexported UnexportedPolicy = iota
+	// ShallowUnexported 直接赋值，副本与原值共享其中的指针、切片和 map。
+	ShallowUnexported
+	// ZeroUnexported 不复制，副本中保持零值。
+	ZeroUnexported
+)
+
+// Option 配置 Copy。
+type Option func(*copier)
+
+// WithUnexported 设置不可导出字段的处理方式。
+func WithUnexported(p UnexportedPolicy) Option {
+	return func(c *copier) { c.policy = p }
+}
+
+// WithCopier 注册类型 T 的自定义拷贝函数。Copy 遇到 T 类型的值时直接使用 fn 的结果，
+// 不再进入其内部。默认已为 time.Time 注册了按值复制（共享 *time.Location）。
+// 少数情况下 Copy 要重新复制一遍（见 copier.retry），fn 可能被多次调用，应当没有副作用。
+func WithCopier[T any](fn func(T) T) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(c *copier) {
+		c.hooks[t] = func(v reflect.Value) reflect.Value {
+			out := reflect.New(t).Elem()
+			if r := reflect.ValueOf(any(fn(v.Interface().(T)))); r.IsValid() {
+				out.Set(r)
+			}
+			return out
+		}
+	}
+}
+
+// Copy 返回 src 的深拷贝。
+func Copy[T any](src T, opts ...Option) T {
+	c := &copier{
+		hooks: make(map[reflect.Type]func(reflect.Value) reflect.Value),
+		first: make(map[array]unsafe.Pointer),
+	}
+	WithCopier(func(t time.Time) time.Time { return t })(c)
+	for _, opt := range opts {
+		opt(c)
+	}
+	in := reflect.ValueOf(&src).Elem()
+	for {
+		c.seen = make(map[visit]reflect.Value)
+		c.arrays = make(map[array]*backing)
+		c.retry = false
+		out := reflect.New(in.Type()).Elem()
+		c.copy(out, in)
+		if !c.retry {
+			return out.Interface().(T)
+		}
+	}
+}
+
+// visit 标识一个已经复制过的指针或 map。
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// array 标识切片的底层数组。从同一个数组切出的切片，容量末尾的地址都相同
+// （三索引切片 s[i:j:k] 会缩短容量，被当作另一个数组）。
+type array struct {
+	end uintptr
+	typ reflect.Type
+}
+
+// backing 是一个底层数组的副本：elems 覆盖原数组中从 start 到容量末尾的元素。
+type backing struct {
+	start unsafe.Pointer
+	elems reflect.Value
+}
+
+type copier struct {
+	policy UnexportedPolicy
+	hooks  map[reflect.Type]func(reflect.Value) reflect.Value
+	seen   map[visit]reflect.Value
+	arrays map[array]*backing
+	// first 记录每个底层数组上见过的最靠前的切片起点，跨多遍复制保留。
+	// 如果某个切片的起点在已经复制的范围之前，本遍的结果作废（retry），
+	// 下一遍从 first 开始分配，保证所有切片都能落在同一个副本数组里。
+	first map[array]unsafe.Pointer
+	retry bool
+}
+
+// copy 把 src 深拷贝到 dst。dst 必须可设置，src 必须可读（见 unlock）。
+func (c *copier) copy(dst, src reflect.Value) {
+	if hook, ok := c.hooks[src.Type()]; ok {
+		dst.Set(hook(src))
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		key := visit{ptr: src.Pointer(), typ: src.Type()}
+		if p, ok := c.seen[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		c.seen[key] = p // 先登记再递归，循环引用会拿到这个新指针
+		c.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(c.value(src.Elem()))
+
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			df, sf := dst.Field(i), src.Field(i)
+			if !src.Type().Field(i).IsExported() {
+				df, sf = unlock(df), unlock(sf)
+				switch c.policy {
+				case ZeroUnexported:
+					continue
+				case ShallowUnexported:
+					df.Set(sf)
+					continue
+				}
+			}
+			c.copy(df, sf)
+		}
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(c.slice(src))
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := visit{ptr: src.Pointer(), typ: src.Type()}
+		if m, ok := c.seen[key]; ok {
+			dst.Set(m)
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.seen[key] = m
+		iter := src.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(c.value(iter.Key()), c.value(iter.Value()))
+		}
+		dst.Set(m)
+
+	default:
+		// 基本类型按值复制；chan、func、unsafe.Pointer 只能共享
+		dst.Set(src)
+	}
+}
+
+// slice 复制 src 的底层数组（同一数组只复制一次），返回副本上对应位置的切片。
+func (c *copier) slice(src reflect.Value) reflect.Value {
+	size := src.Type().Elem().Size()
+	if src.Cap() == 0 || size == 0 {
+		// 没有元素，或者元素不占内存：没有可以共享的数据
+		return reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+	}
+	start := src.UnsafePointer()
+	key := array{end: uintptr(start) + uintptr(src.Cap())*size, typ: src.Type()}
+	if f, ok := c.first[key]; !ok || uintptr(start) < uintptr(f) {
+		c.first[key] = start
+	}
+
+	b, ok := c.arrays[key]
+	if !ok {
+		b = &backing{start: c.first[key]}
+		n := int((key.end - uintptr(b.start)) / size)
+		b.elems = reflect.MakeSlice(src.Type(), n, n)
+		c.arrays[key] = b // 先登记再递归，元素引用回这个数组时会拿到同一个副本
+		orig := reflect.NewAt(reflect.ArrayOf(n, src.Type().Elem()), b.start).Elem()
+		for i := 0; i < n; i++ {
+			c.copy(b.elems.Index(i), orig.Index(i))
+		}
+	}
+	if uintptr(start) < uintptr(b.start) {
+		// 副本数组不包含这个切片开头的元素，下一遍从更靠前的起点分配
+		c.retry = true
+		return reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+	}
+	off := int((uintptr(start) - uintptr(b.start)) / size)
+	return b.elems.Slice3(off, off+src.Len(), off+src.Cap())
+}
+
+// value 深拷贝一个不可寻址的值（map 的键和值、接口里的动态值）。
+func (c *copier) value(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	c.copy(out, addressable(v))
+	return out
+}
+
+// addressable 返回 v 的一个可寻址副本，这样才能用 unlock 读取其中的不可导出字段。
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	a := reflect.New(v.Type()).Elem()
+	a.Set(v)
+	return a
+}
+
+// unlock 去掉不可导出字段的只读标记，使其可以 Interface 和 Set。v 必须可寻址。
+func unlock(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,305 @@
+package deep
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 以下类型与 deep_demo 相同，对应 chap22/no_classes_composition.go 和 chap26/pointers.go。
+
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+type Employee struct {
+	Person
+	EmployeeID int
+	Department string
+	Salary     float64
+}
+
+type Address struct {
+	Street, City, Zip string
+}
+
+type Contact struct {
+	Email   string
+	Phone   string
+	Address *Address
+}
+
+type Manager struct {
+	Employee
+	Contact
+	TeamSize int
+	Reports  []*Employee
+	Tags     map[string]string
+	Hired    time.Time
+	notes    []string
+}
+
+func newManager() Manager {
+	alice := &Employee{Person: Person{"Alice", 28}, EmployeeID: 11, Department: "研发", Salary: 20000}
+	return Manager{
+		Employee: Employee{Person: Person{"张三", 40}, EmployeeID: 7, Department: "研发", Salary: 30000},
+		Contact:  Contact{Email: "zhangsan@example.com", Address: &Address{"中关村大街1号", "北京", "100080"}},
+		TeamSize: 2,
+		Reports:  []*Employee{alice, alice},
+		Tags:     map[string]string{"level": "M2"},
+		Hired:    time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC),
+		notes:    []string{"年度优秀"},
+	}
+}
+
+func ring(n int) *Node {
+	head := &Node{Value: 1}
+	cur := head
+	for i := 2; i <= n; i++ {
+		cur.Next = &Node{Value: i}
+		cur = cur.Next
+	}
+	cur.Next = head
+	return head
+}
+
+// references 收集 v 中所有指针、切片底层数组和 map 的地址（包括不可导出字段），值为第一次遇到时的路径。
+// time.Time 按值复制、共享 *time.Location，不进入。
+func references(path string, v reflect.Value, out map[uintptr]string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		if _, ok := out[v.Pointer()]; ok {
+			return
+		}
+		out[v.Pointer()] = path
+		if v.Kind() == reflect.Pointer {
+			references(path, v.Elem(), out)
+			return
+		}
+		for _, k := range v.MapKeys() {
+			references(path+"[key]", k, out)
+			references(path+"[value]", v.MapIndex(k), out)
+		}
+	case reflect.Slice:
+		if v.Cap() == 0 {
+			return
+		}
+		out[v.Pointer()] = path
+		for i := 0; i < v.Len(); i++ {
+			references(path+"[i]", v.Index(i), out)
+		}
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			references(path+"."+v.Type().Field(i).Name, v.Field(i), out)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			references(path, v.Elem(), out)
+		}
+	}
+}
+
+// shared 返回 a 和 b 共用的引用的路径。
+func shared(a, b any) []string {
+	ra, rb := map[uintptr]string{}, map[uintptr]string{}
+	references("", reflect.ValueOf(a), ra)
+	references("", reflect.ValueOf(b), rb)
+	var out []string
+	for p, path := range ra {
+		if _, ok := rb[p]; ok {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+func TestCopyManager(t *testing.T) {
+	orig := newManager()
+	cp := Copy(orig)
+
+	if !Equal(cp, orig) || !reflect.DeepEqual(cp, orig) {
+		t.Fatalf("copy differs from original:\n%v", Diff(orig, cp))
+	}
+	if s := shared(&orig, &cp); len(s) != 0 {
+		t.Fatalf("copy shares references with the original at %v", s)
+	}
+	// 原值中共享的部分在副本中依然共享
+	if cp.Reports[0] != cp.Reports[1] {
+		t.Error("Reports[0] and Reports[1] should still be the same pointer")
+	}
+
+	cp.Contact.Address.City = "上海"
+	cp.Reports[0].Salary = 25000
+	cp.Tags["level"] = "M3"
+	cp.notes[0] = "已修改"
+	if orig.Contact.Address.City != "北京" || orig.Reports[1].Salary != 20000 || orig.Tags["level"] != "M2" || orig.notes[0] != "年度优秀" {
+		t.Errorf("modifying the copy changed the original: %+v", orig)
+	}
+
+	// 对比：普通赋值共享地址、切片和 map
+	shallow := orig
+	if len(shared(&orig, &shallow)) == 0 {
+		t.Error("plain assignment should share references")
+	}
+}
+
+func TestCopyCycles(t *testing.T) {
+	r := ring(4)
+	cp := Copy(r)
+	if cp == r || cp.Next.Next.Next.Next != cp {
+		t.Fatal("copy of a ring should be a new ring of the same length")
+	}
+	for a, b := r, cp; ; a, b = a.Next, b.Next {
+		if a == b || a.Value != b.Value {
+			t.Fatalf("node %d shared or different", a.Value)
+		}
+		if a.Next == r {
+			break
+		}
+	}
+	if !Equal(r, cp) || len(Diff(r, cp)) != 0 {
+		t.Errorf("Equal(ring, copy) = false: %v", Diff(r, cp))
+	}
+	cp.Next.Next.Value = 30
+	if d := Diff(r, cp); d.String() != "~ Node.Next.Next.Value: 3 → 30\n" {
+		t.Errorf("Diff after change:\n%v", d)
+	}
+
+	// 自引用的切片元素
+	type Self struct {
+		Peers []*Self
+	}
+	s := &Self{}
+	s.Peers = []*Self{s, s}
+	sc := Copy(s)
+	if sc == s || sc.Peers[0] != sc || sc.Peers[1] != sc {
+		t.Error("self-referencing slice not preserved")
+	}
+}
+
+func TestCopySubslices(t *testing.T) {
+	type Views struct {
+		All, Tail, Head, Limited []int
+	}
+	base := []int{0, 1, 2, 3, 4, 5}
+	orig := Views{All: base, Tail: base[1:], Head: base[:2], Limited: base[3:4:4]}
+	cp := Copy(orig)
+	if !reflect.DeepEqual(cp, orig) {
+		t.Fatalf("copy = %+v", cp)
+	}
+	if &cp.All[0] == &base[0] {
+		t.Fatal("copy shares the original backing array")
+	}
+	// 和原值一样，s、s[1:] 和 s[:2] 指向同一个数组
+	if &cp.Tail[0] != &cp.All[1] || &cp.Head[1] != &cp.All[1] || cap(cp.Head) != 6 || cap(cp.Tail) != 5 {
+		t.Errorf("subslices do not share the copied array: cap(Head) %d, cap(Tail) %d", cap(cp.Head), cap(cp.Tail))
+	}
+	// 在容量之内 append 会写到原数组后面的元素上，副本里也一样
+	cp.Head = append(cp.Head, 20)
+	if cp.All[2] != 20 || cp.Tail[1] != 20 || base[2] != 2 {
+		t.Errorf("append within capacity: All %v, Tail %v, original %v", cp.All, cp.Tail, base)
+	}
+	// 三索引切片缩短了容量，被当作另一个数组复制
+	if cap(cp.Limited) != 1 || &cp.Limited[0] == &cp.All[3] {
+		t.Error("three-index slice should get its own array")
+	}
+
+	// 先遇到靠后的切片、再遇到从更前面开始的切片，也能共享同一个数组
+	ptrs := []*int{new(int), new(int), new(int)}
+	type Reversed struct {
+		Tail, All []*int
+	}
+	rc := Copy(Reversed{Tail: ptrs[2:], All: ptrs})
+	if &rc.Tail[0] != &rc.All[2] || rc.All[0] == ptrs[0] || rc.All[2] == ptrs[2] {
+		t.Error("slice visited after its tail should still share the copied array")
+	}
+
+	// len 之外、cap 之内的元素同样深拷贝，重新切出来之后不会与原值共享
+	hidden := ptrs[:1]
+	hc := Copy(hidden)
+	if len(hc) != 1 || cap(hc) != 3 || hc[:3][2] == nil || hc[:3][2] == ptrs[2] {
+		t.Error("elements beyond len should be deep copied")
+	}
+}
+
+func TestUnexportedAndCopiers(t *testing.T) {
+	orig := newManager()
+	if zero := Copy(orig, WithUnexported(ZeroUnexported)); zero.notes != nil {
+		t.Errorf("ZeroUnexported: notes = %v", zero.notes)
+	}
+	if sh := Copy(orig, WithUnexported(ShallowUnexported)); &sh.notes[0] != &orig.notes[0] {
+		t.Error("ShallowUnexported should share the notes backing array")
+	}
+	anon := Copy(orig, WithCopier(func(p Person) Person { return Person{Name: "***", Age: p.Age} }))
+	if anon.Name != "***" || anon.Reports[0].Name != "***" || anon.Age != 40 || orig.Name != "张三" {
+		t.Errorf("custom Person copier: %+v", anon.Person)
+	}
+	if cp := Copy(orig); cp.Hired.Location() != orig.Hired.Location() || !cp.Hired.Equal(orig.Hired) {
+		t.Error("time.Time should be copied by value")
+	}
+}
+
+func TestDiffPaths(t *testing.T) {
+	before := newManager()
+	after := Copy(before)
+	after.Age++
+	after.Contact.Address.Zip = "100081"
+	after.Reports = append(after.Reports, &Employee{Person: Person{"Bob", 30}, EmployeeID: 12})
+	after.Tags["level"] = "M3"
+	after.Tags["remote"] = "yes"
+	after.notes = nil
+
+	want := []struct {
+		path string
+		kind ChangeKind
+	}{
+		{"Manager.Employee.Person.Age", Modified},
+		{"Manager.Contact.Address.Zip", Modified},
+		{"Manager.Reports[2]", Added},
+		{"Manager.Tags[level]", Modified},
+		{"Manager.Tags[remote]", Added},
+		{"Manager.notes[0]", Removed},
+	}
+	got := Diff(before, after)
+	if len(got) != len(want) {
+		t.Fatalf("Diff =\n%v", got)
+	}
+	for i, w := range want {
+		if got[i].Path != w.path || got[i].Kind != w.kind {
+			t.Errorf("change %d = %s %v, want %s %v", i, got[i].Path, got[i].Kind, w.path, w.kind)
+		}
+	}
+	if got[1].Old != "100080" || got[1].New != "100081" {
+		t.Errorf("Zip change = %v", got[1])
+	}
+	if !strings.HasPrefix(got.String(), "~ Manager.Employee.Person.Age: 40 → 41\n~ Manager.Contact.Address.Zip: \"100080\" → \"100081\"\n") {
+		t.Errorf("text:\n%v", got)
+	}
+	if Equal(before, after) {
+		t.Error("Equal(before, after) = true")
+	}
+
+	// nil 与空切片、空 map 视为相同；类型不同时只报告根路径
+	a, b := newManager(), newManager()
+	a.Tags, b.Tags = nil, map[string]string{}
+	a.Reports, b.Reports = nil, []*Employee{}
+	if !Equal(a, b) {
+		t.Errorf("nil vs empty: %v", Diff(a, b))
+	}
+	if d := Diff(1, "1"); len(d) != 1 || d[0].Kind != Modified {
+		t.Errorf("Diff(int, string) = %v", d)
+	}
+}
@@ -0,0 +1,253 @@
+package deep
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind 表示一处差异的类型。
+type ChangeKind int
+
+const (
+	// Modified 表示两边都有该路径，但值不同。
+	Modified ChangeKind = iota
+	// Added 表示只有 b 有该路径（切片变长、map 新增键）。
+	Added
+	// Removed 表示只有 a 有该路径。
+	Removed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Modified:
+		return "modified"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// symbol 是文本输出中每行的前缀。
+func (k ChangeKind) symbol() string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	}
+	return "~"
+}
+
+// Change 是一处差异。Added 时 Old 为 nil，Removed 时 New 为 nil。
+type Change struct {
+	Path string // 例如 Manager.Employee.Person.Age、Manager.Reports[1]、Manager.Tags[go]
+	Kind ChangeKind
+	Old  any
+	New  any
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, format(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, format(c.Old))
+	}
+	return fmt.Sprintf("%s %s: %s → %s", c.Kind.symbol(), c.Path, format(c.Old), format(c.New))
+}
+
+// Changes 是 Diff 的结果，按遍历顺序排列（结构体字段顺序、切片下标、map 键排序）。
+type Changes []Change
+
+// String 每行输出一处差异，例如：
+//
+//	~ Manager.Employee.Person.Age: 40 → 41
+//	+ Manager.Reports[1]: {...}
+//	- Manager.Tags[go]: "x"
+func (cs Changes) String() string {
+	var b strings.Builder
+	for _, c := range cs {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// Diff 逐字段比较 a 和 b，返回所有差异；完全相同时返回 nil。
+//
+// 指针比较指向的值（路径不变），nil 切片和空切片、nil map 和空 map 视为相同，
+// 不可导出字段也参与比较。a 和 b 类型不同时返回一处根路径上的 Modified。
+func Diff(a, b any) Changes {
+	d := &differ{seen: make(map[[2]uintptr]bool)}
+	d.diff(rootName(a, b), reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.changes
+}
+
+// Equal 报告 a 和 b 是否深度相等，规则与 Diff 相同，但遇到第一处差异就返回。
+func Equal(a, b any) bool {
+	d := &differ{seen: make(map[[2]uintptr]bool), first: true}
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return len(d.changes) == 0
+}
+
+func rootName(a, b any) string {
+	v := a
+	if v == nil {
+		v = b
+	}
+	if v == nil {
+		return ""
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+type differ struct {
+	changes Changes
+	seen    map[[2]uintptr]bool // 已比较过的指针对，避免循环引用无限递归
+	first   bool                // 只需要知道是否相等
+}
+
+func (d *differ) add(path string, kind ChangeKind, a, b reflect.Value) {
+	c := Change{Path: path, Kind: kind}
+	if a.IsValid() {
+		c.Old = a.Interface()
+	}
+	if b.IsValid() {
+		c.New = b.Interface()
+	}
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) done() bool { return d.first && len(d.changes) > 0 }
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if d.done() {
+		return
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path, Modified, a, b)
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		d.add(path, Modified, a, b)
+		return
+	}
+	a, b = addressable(a), addressable(b)
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return
+		}
+		key := [2]uintptr{a.Pointer(), b.Pointer()}
+		if key[0] == key[1] || d.seen[key] {
+			return
+		}
+		d.seen[key] = true
+		d.diff(path, a.Elem(), b.Elem())
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return
+		}
+		d.diff(path, a.Elem(), b.Elem())
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			fa, fb := a.Field(i), b.Field(i)
+			if !t.Field(i).IsExported() {
+				fa, fb = unlock(fa), unlock(fb)
+			}
+			d.diff(path+"."+t.Field(i).Name, fa, fb)
+		}
+
+	case reflect.Slice, reflect.Array:
+		n := min(a.Len(), b.Len())
+		for i := 0; i < n; i++ {
+			d.diff(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i))
+		}
+		for i := n; i < a.Len(); i++ {
+			d.add(fmt.Sprintf("%s[%d]", path, i), Removed, a.Index(i), reflect.Value{})
+		}
+		for i := n; i < b.Len(); i++ {
+			d.add(fmt.Sprintf("%s[%d]", path, i), Added, reflect.Value{}, b.Index(i))
+		}
+
+	case reflect.Map:
+		for _, k := range sortedKeys(a, b) {
+			kpath := fmt.Sprintf("%s[%v]", path, k.Interface())
+			va, vb := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !vb.IsValid():
+				d.add(kpath, Removed, va, reflect.Value{})
+			case !va.IsValid():
+				d.add(kpath, Added, reflect.Value{}, vb)
+			default:
+				d.diff(kpath, va, vb)
+			}
+		}
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			d.add(path, Modified, a, b)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if x, y := a.Float(), b.Float(); x != y && !(x != x && y != y) { // NaN 视为相等
+			d.add(path, Modified, a, b)
+		}
+
+	default:
+		if a.Interface() != b.Interface() {
+			d.add(path, Modified, a, b)
+		}
+	}
+}
+
+// sortedKeys 返回 a 和 b 的键的并集，按 fmt 输出排序，使结果稳定。
+func sortedKeys(a, b reflect.Value) []reflect.Value {
+	seen := make(map[any]bool)
+	var keys []reflect.Value
+	for _, m := range [2]reflect.Value{a, b} {
+		for _, k := range m.MapKeys() {
+			if !seen[k.Interface()] {
+				seen[k.Interface()] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
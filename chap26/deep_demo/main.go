@@ -0,0 +1,143 @@
+// 独立运行：go run ./chap26/deep_demo
+// 演示：结构体赋值是浅拷贝；deep.Copy 深拷贝（含循环链表、不可导出字段、自定义拷贝函数）；deep.Diff 列出差异。
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"books/chap26/deep"
+)
+
+// Node 同 chap26/pointers.go 的链表节点。
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+// LargeStruct 同 chap26/pointers.go，数组按值复制，本身就是深拷贝。
+type LargeStruct struct {
+	Data [1000]int
+}
+
+// 以下类型对应 chap22/no_classes_composition.go，Manager 嵌入了 Employee（内含 Person）和 Contact。
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+type Employee struct {
+	Person
+	EmployeeID int
+	Department string
+	Salary     float64
+}
+
+type Address struct {
+	Street, City, Zip string
+}
+
+type Contact struct {
+	Email   string
+	Phone   string
+	Address *Address
+}
+
+type Manager struct {
+	Employee
+	Contact
+	TeamSize int
+	Reports  []*Employee
+	Tags     map[string]string
+	Hired    time.Time
+	notes    []string
+}
+
+// ring 返回首尾相连的 n 个节点。
+func ring(n int) *Node {
+	head := &Node{Value: 1}
+	cur := head
+	for i := 2; i <= n; i++ {
+		cur.Next = &Node{Value: i}
+		cur = cur.Next
+	}
+	cur.Next = head
+	return head
+}
+
+func (n *Node) String() string {
+	var parts []string
+	for cur := n; cur != nil; cur = cur.Next {
+		parts = append(parts, fmt.Sprint(cur.Value))
+		if cur.Next == n {
+			parts = append(parts, "…")
+			break
+		}
+	}
+	return strings.Join(parts, "→")
+}
+
+func newManager() Manager {
+	alice := &Employee{Person: Person{"Alice", 28}, EmployeeID: 11, Department: "研发", Salary: 20000}
+	return Manager{
+		Employee: Employee{Person: Person{"张三", 40}, EmployeeID: 7, Department: "研发", Salary: 30000},
+		Contact:  Contact{Email: "zhangsan@example.com", Address: &Address{"中关村大街1号", "北京", "100080"}},
+		TeamSize: 2,
+		Reports:  []*Employee{alice, alice}, // 同一个人出现两次：副本中也应是同一个指针
+		Tags:     map[string]string{"level": "M2"},
+		Hired:    time.Date(2020, 3, 1, 9, 0, 0, 0, time.Local),
+		notes:    []string{"年度优秀"},
+	}
+}
+
+func main() {
+	fmt.Println("=== 1. 赋值只复制一层 ===")
+	m1 := newManager()
+	shallow := m1
+	shallow.Contact.Address.City = "上海"
+	shallow.Reports[0].Salary = 99999
+	fmt.Println("修改浅拷贝后，原值的城市:", m1.Contact.Address.City, "下属工资:", m1.Reports[0].Salary)
+
+	fmt.Println("\n=== 2. deep.Copy 复制到底 ===")
+	m2 := newManager()
+	cp := deep.Copy(m2)
+	cp.Contact.Address.City = "上海"
+	cp.Reports[0].Salary = 25000
+	cp.notes[0] = "已修改"
+	fmt.Println("修改深拷贝后，原值的城市:", m2.Contact.Address.City, "下属工资:", m2.Reports[0].Salary, "备注:", m2.notes[0])
+	fmt.Println("副本中 Reports[0] 和 Reports[1] 仍是同一个指针:", cp.Reports[0] == cp.Reports[1])
+	fmt.Println("time.Time 按值复制，Location 共享:", cp.Hired.Location() == time.Local)
+
+	fmt.Println("\n=== 3. 循环链表和大数组 ===")
+	r := ring(4)
+	rc := deep.Copy(r)
+	rc.Next.Value = 20
+	fmt.Println("原链表:", r, " 副本:", rc, " 副本仍然成环:", rc.Next.Next.Next.Next == rc)
+	var big LargeStruct
+	big.Data[999] = 1
+	bc := deep.Copy(&big)
+	bc.Data[999] = 2
+	fmt.Println("原数组末元素:", big.Data[999], " 副本:", bc.Data[999])
+
+	fmt.Println("\n=== 4. 不可导出字段策略和自定义拷贝函数 ===")
+	zero := deep.Copy(m2, deep.WithUnexported(deep.ZeroUnexported))
+	share := deep.Copy(m2, deep.WithUnexported(deep.ShallowUnexported))
+	fmt.Println("ZeroUnexported notes:", zero.notes, " ShallowUnexported 共享底层数组:", &share.notes[0] == &m2.notes[0])
+	anon := deep.Copy(m2, deep.WithCopier(func(p Person) Person { return Person{Name: "***", Age: p.Age} }))
+	fmt.Println("自定义 Person 拷贝:", anon.Name, anon.Reports[0].Name)
+
+	fmt.Println("\n=== 5. deep.Diff ===")
+	before := newManager()
+	after := deep.Copy(before)
+	after.Age++
+	after.Contact.Address.Zip = "100081"
+	after.Reports = append(after.Reports, &Employee{Person: Person{"Bob", 30}, EmployeeID: 12})
+	after.Tags["level"] = "M3"
+	after.Tags["remote"] = "yes"
+	after.notes = nil
+	fmt.Print(deep.Diff(before, after))
+	fmt.Println("Equal(before, Copy(before)) =", deep.Equal(before, deep.Copy(before)))
+	fmt.Println("Equal(ring, Copy(ring)) =", deep.Equal(r, deep.Copy(r)))
+}
@@ -0,0 +1,130 @@
+package list
+
+// Deque 是基于环形缓冲区的双端队列：两端的压入和弹出都是均摊 O(1)，At 是 O(1)。
+// 缓冲区满时容量翻倍，元素少于容量的 1/4 时减半。零值是空队列。
+type Deque[T any] struct {
+	buf  []T
+	head int // 第一个元素在 buf 中的下标
+	n    int
+}
+
+// minDequeCap 是缓冲区的最小容量。
+const minDequeCap = 8
+
+// Len 返回元素个数，d 为 nil 时返回 0。
+func (d *Deque[T]) Len() int {
+	if d == nil {
+		return 0
+	}
+	return d.n
+}
+
+// Cap 返回当前缓冲区的容量。
+func (d *Deque[T]) Cap() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.buf)
+}
+
+// index 把逻辑下标 i 映射到 buf 中的位置。
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+func (d *Deque[T]) resize(size int) {
+	buf := make([]T, size)
+	if d.n > 0 {
+		if end := d.head + d.n; end <= len(d.buf) {
+			copy(buf, d.buf[d.head:end])
+		} else {
+			k := copy(buf, d.buf[d.head:])
+			copy(buf[k:], d.buf[:d.n-k])
+		}
+	}
+	d.buf, d.head = buf, 0
+}
+
+func (d *Deque[T]) grow() {
+	if d.n == len(d.buf) {
+		d.resize(max(minDequeCap, 2*len(d.buf)))
+	}
+}
+
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minDequeCap && d.n <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+// PushBack 在尾部压入 v。
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[d.index(d.n)] = v
+	d.n++
+}
+
+// PushFront 在头部压入 v。
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = v
+	d.n++
+}
+
+// PopFront 弹出并返回第一个元素，空队列返回 false。
+// 弹出的位置会清零，避免缓冲区继续引用已经弹出的指针（否则它们不会被 GC 回收）。
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.Len() == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = d.index(1)
+	d.n--
+	d.shrink()
+	return v, true
+}
+
+// PopBack 弹出并返回最后一个元素，空队列返回 false。
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.Len() == 0 {
+		return zero, false
+	}
+	i := d.index(d.n - 1)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	d.shrink()
+	return v, true
+}
+
+// Front 返回第一个元素但不弹出。
+func (d *Deque[T]) Front() (T, bool) {
+	return d.At(0)
+}
+
+// Back 返回最后一个元素但不弹出。
+func (d *Deque[T]) Back() (T, bool) {
+	return d.At(d.Len() - 1)
+}
+
+// At 返回第 i 个元素，越界时返回 false。
+func (d *Deque[T]) At(i int) (T, bool) {
+	if i < 0 || i >= d.Len() {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.index(i)], true
+}
+
+// Values 按从头到尾的顺序返回所有元素。
+func (d *Deque[T]) Values() []T {
+	out := make([]T, d.Len())
+	for i := range out {
+		out[i] = d.buf[d.index(i)]
+	}
+	return out
+}
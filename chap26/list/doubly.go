@@ -0,0 +1,172 @@
+package list
+
+// Element 是 List 中的元素。
+type Element[T any] struct {
+	Value      T
+	next, prev *Element[T]
+	list       *List[T]
+}
+
+// Next 返回下一个元素，没有时返回 nil。e 为 nil 或已被删除时也返回 nil。
+func (e *Element[T]) Next() *Element[T] {
+	if e == nil || e.list == nil || e.next == &e.list.root {
+		return nil
+	}
+	return e.next
+}
+
+// Prev 返回上一个元素。
+func (e *Element[T]) Prev() *Element[T] {
+	if e == nil || e.list == nil || e.prev == &e.list.root {
+		return nil
+	}
+	return e.prev
+}
+
+// List 是带哨兵节点的双向循环链表：root.next 是第一个元素，root.prev 是最后一个，
+// 插入和删除不需要判断头尾。零值是空链表；List 包含哨兵本身，创建后不要复制。
+type List[T any] struct {
+	root Element[T]
+	len  int
+}
+
+// New 返回包含 values 的链表。
+func New[T any](values ...T) *List[T] {
+	l := new(List[T])
+	for _, v := range values {
+		l.PushBack(v)
+	}
+	return l
+}
+
+// lazyInit 让零值 List 在第一次写入时初始化哨兵。
+func (l *List[T]) lazyInit() {
+	if l.root.next == nil {
+		l.root.next, l.root.prev = &l.root, &l.root
+	}
+}
+
+// Len 返回元素个数，l 为 nil 时返回 0。
+func (l *List[T]) Len() int {
+	if l == nil {
+		return 0
+	}
+	return l.len
+}
+
+// Front 返回第一个元素，空链表或 l 为 nil 时返回 nil。
+func (l *List[T]) Front() *Element[T] {
+	if l.Len() == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back 返回最后一个元素。
+func (l *List[T]) Back() *Element[T] {
+	if l.Len() == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// insert 把 e 插到 at 之后。
+func (l *List[T]) insert(e, at *Element[T]) *Element[T] {
+	e.prev, e.next = at, at.next
+	e.prev.next, e.next.prev = e, e
+	e.list = l
+	l.len++
+	return e
+}
+
+// unlink 把 e 从链表中摘下，但不清空 e.list。
+func (l *List[T]) unlink(e *Element[T]) {
+	e.prev.next, e.next.prev = e.next, e.prev
+	l.len--
+}
+
+// owns 报告 e 是否是 l 中的元素。
+func (l *List[T]) owns(e *Element[T]) bool {
+	return l != nil && e != nil && e.list == l
+}
+
+// PushFront 在头部插入 v。
+func (l *List[T]) PushFront(v T) *Element[T] {
+	l.lazyInit()
+	return l.insert(&Element[T]{Value: v}, &l.root)
+}
+
+// PushBack 在尾部插入 v。
+func (l *List[T]) PushBack(v T) *Element[T] {
+	l.lazyInit()
+	return l.insert(&Element[T]{Value: v}, l.root.prev)
+}
+
+// InsertBefore 在 mark 之前插入 v。mark 为 nil 或不属于 l 时返回 nil。
+func (l *List[T]) InsertBefore(v T, mark *Element[T]) *Element[T] {
+	if !l.owns(mark) {
+		return nil
+	}
+	return l.insert(&Element[T]{Value: v}, mark.prev)
+}
+
+// InsertAfter 在 mark 之后插入 v。
+func (l *List[T]) InsertAfter(v T, mark *Element[T]) *Element[T] {
+	if !l.owns(mark) {
+		return nil
+	}
+	return l.insert(&Element[T]{Value: v}, mark)
+}
+
+// Remove 删除 e 并返回它的值，O(1)。e 为 nil 或不属于 l（包括已经删除过）时返回 false。
+// 删除后 e 的指针被清空，Next、Prev 返回 nil，旧句柄不会再把别的元素“带”回来。
+func (l *List[T]) Remove(e *Element[T]) (T, bool) {
+	if !l.owns(e) {
+		var zero T
+		return zero, false
+	}
+	l.unlink(e)
+	e.next, e.prev, e.list = nil, nil, nil
+	return e.Value, true
+}
+
+// MoveToFront 把 e 移到头部。
+func (l *List[T]) MoveToFront(e *Element[T]) {
+	if !l.owns(e) || l.root.next == e {
+		return
+	}
+	l.unlink(e)
+	l.insert(e, &l.root)
+}
+
+// MoveToBack 把 e 移到尾部。
+func (l *List[T]) MoveToBack(e *Element[T]) {
+	if !l.owns(e) || l.root.prev == e {
+		return
+	}
+	l.unlink(e)
+	l.insert(e, l.root.prev)
+}
+
+// Reverse 原地反转链表：交换每个节点（包括哨兵）的 next 和 prev，O(n)，句柄仍然有效。
+func (l *List[T]) Reverse() {
+	if l.Len() < 2 {
+		return
+	}
+	e := &l.root
+	for {
+		e.next, e.prev = e.prev, e.next
+		if e = e.prev; e == &l.root { // e.prev 是交换前的 next
+			return
+		}
+	}
+}
+
+// Values 按顺序返回所有值。
+func (l *List[T]) Values() []T {
+	out := make([]T, 0, l.Len())
+	for e := l.Front(); e != nil; e = e.Next() {
+		out = append(out, e.Value)
+	}
+	return out
+}
@@ -0,0 +1,348 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSingly(t *testing.T) {
+	l := NewSingly(1, 2, 4)
+	two := l.Front().Next()
+	l.InsertAfter(two, 3)
+	l.PushFront(0)
+	tail := l.PushBack(5)
+	if !slices.Equal(l.Values(), []int{0, 1, 2, 3, 4, 5}) {
+		t.Fatalf("after inserts: %v", l.Values())
+	}
+	l.Reverse()
+	if !slices.Equal(l.Values(), []int{5, 4, 3, 2, 1, 0}) || l.Front() != tail {
+		t.Fatalf("after Reverse: %v, handle moved", l.Values())
+	}
+	if v, ok := l.RemoveAfter(l.Back()); ok || v != 0 {
+		t.Errorf("RemoveAfter(last) = %v, %v", v, ok)
+	}
+	l.Remove(two)
+	l.PopFront()
+	if !slices.Equal(l.Values(), []int{4, 3, 1, 0}) || l.Len() != 4 {
+		t.Errorf("after removing 2 and the head: %v (len %d)", l.Values(), l.Len())
+	}
+	l.PushBack(9)
+	if l.Back().Value != 9 || !slices.Equal(l.Values(), []int{4, 3, 1, 0, 9}) {
+		t.Errorf("tail not maintained after removals: %v", l.Values())
+	}
+}
+
+func TestSinglyNil(t *testing.T) {
+	var nilList *Singly[int]
+	if nilList.Len() != 0 || nilList.Front() != nil || len(nilList.Values()) != 0 {
+		t.Error("nil *Singly read methods")
+	}
+	var zero Singly[string]
+	zero.PushBack("a")
+	if zero.Len() != 1 {
+		t.Error("zero Singly is not usable")
+	}
+
+	l := NewSingly(1, 2, 3)
+	two := l.Front().Next()
+	if _, ok := l.Remove(two); !ok {
+		t.Fatal("first Remove failed")
+	}
+	if _, ok := l.Remove(two); ok {
+		t.Error("removing the same handle twice succeeded")
+	}
+	if two.Next() != nil {
+		t.Error("removed element still links into the list")
+	}
+	var nilElem *SElement[int]
+	if nilElem.Next() != nil || l.InsertAfter(nil, 1) != nil {
+		t.Error("nil handle not ignored")
+	}
+	other := NewSingly(7)
+	if _, ok := l.Remove(other.Front()); ok || other.Len() != 1 || l.Len() != 2 {
+		t.Error("handle from another list not ignored")
+	}
+}
+
+func TestList(t *testing.T) {
+	l := New("b", "d")
+	b, d := l.Front(), l.Back()
+	l.InsertAfter("c", b)
+	l.InsertBefore("a", b)
+	l.PushBack("e")
+	for _, step := range []struct {
+		op   func()
+		want string
+	}{
+		{func() {}, "abcde"},
+		{func() { l.MoveToFront(d); l.MoveToBack(b) }, "daceb"},
+		{l.Reverse, "becad"},
+	} {
+		step.op()
+		if got := strings.Join(l.Values(), ""); got != step.want {
+			t.Fatalf("Values = %s, want %s", got, step.want)
+		}
+	}
+	var back []string
+	for e := l.Back(); e != nil; e = e.Prev() {
+		back = append(back, e.Value)
+	}
+	if strings.Join(back, "") != "daceb" {
+		t.Errorf("Prev walk after Reverse: %v", back)
+	}
+}
+
+func TestListNil(t *testing.T) {
+	var nilList *List[int]
+	if v, ok := nilList.Remove(nil); nilList.Len() != 0 || nilList.Front() != nil || nilList.Back() != nil || ok || v != 0 {
+		t.Error("nil *List read methods")
+	}
+	var zero List[int]
+	if zero.Front() != nil {
+		t.Error("zero List not empty")
+	}
+	zero.PushFront(1)
+	if zero.Len() != 1 || zero.Front().Value != 1 {
+		t.Error("zero List not initialized on first write")
+	}
+
+	l := New("a", "b", "c", "d", "e")
+	d := l.Back().Prev()
+	if s, ok := l.Remove(d); !ok || s != "d" {
+		t.Fatalf("Remove(d) = %q, %v", s, ok)
+	}
+	if _, again := l.Remove(d); again {
+		t.Error("removing d twice succeeded")
+	}
+	if d.Next() != nil || d.Prev() != nil {
+		t.Error("removed element still linked")
+	}
+	l.MoveToFront(d)
+	if l.Len() != 4 || l.InsertAfter("x", d) != nil {
+		t.Error("removed handle was moved or used as an insert mark")
+	}
+	var nilElem *Element[string]
+	if nilElem.Next() != nil || nilElem.Prev() != nil {
+		t.Error("nil *Element Next/Prev")
+	}
+	other := New("z")
+	l.MoveToFront(other.Front())
+	if _, ok := l.Remove(other.Front()); ok || l.Len() != 4 || other.Len() != 1 {
+		t.Error("handle from another list not ignored")
+	}
+	if strings.Join(l.Values(), "") != "abce" {
+		t.Errorf("Values = %v", l.Values())
+	}
+}
+
+// TestListModel 对比随机操作后的链表和切片模型。
+func TestListModel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var model []int
+	var handles []*Element[int]
+	l := new(List[int])
+	for i := 0; i < 2000; i++ {
+		switch op := r.Intn(5); {
+		case op == 0 || len(handles) == 0:
+			handles = append(handles, l.PushBack(i))
+			model = append(model, i)
+		case op == 1:
+			handles = append(handles, l.PushFront(i))
+			model = append([]int{i}, model...)
+		case op == 2:
+			k := r.Intn(len(handles))
+			v, _ := l.Remove(handles[k])
+			model = slices.DeleteFunc(model, func(x int) bool { return x == v })
+			handles = slices.Delete(handles, k, k+1)
+		case op == 3:
+			h := handles[r.Intn(len(handles))]
+			l.MoveToFront(h)
+			model = slices.DeleteFunc(model, func(x int) bool { return x == h.Value })
+			model = append([]int{h.Value}, model...)
+		default:
+			l.Reverse()
+			slices.Reverse(model)
+		}
+		if l.Len() != len(model) {
+			t.Fatalf("step %d: Len = %d, model has %d", i, l.Len(), len(model))
+		}
+	}
+	if !slices.Equal(l.Values(), model) {
+		t.Errorf("list %v\nmodel %v", l.Values(), model)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	head := FromValues(1, 2, 3, 4, 5, 6)
+	if HasCycle(head) {
+		t.Fatal("HasCycle on a straight list")
+	}
+	tail, third := head, head.Next.Next
+	for tail.Next != nil {
+		tail = tail.Next
+	}
+	tail.Next = third // 6 → 3
+	if start, n := FindCycle(head); start != third || n != 4 {
+		t.Errorf("FindCycle = %v, %d; want node 3, length 4", start, n)
+	}
+	if vs, err := Values(head); !errors.Is(err, ErrCycle) || !slices.Equal(vs, []int{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("Values on a cycle = %v, %v", vs, err)
+	}
+	if _, err := ReverseNodes(head); !errors.Is(err, ErrCycle) {
+		t.Errorf("ReverseNodes on a cycle: %v", err)
+	}
+	tail.Next = nil
+	head, _ = ReverseNodes(head)
+	if vs, _ := Values(head); !slices.Equal(vs, []int{6, 5, 4, 3, 2, 1}) {
+		t.Errorf("reversed: %v", vs)
+	}
+
+	var empty *Node[int]
+	s, n := FindCycle(empty)
+	rev, err := ReverseNodes(empty)
+	if s != nil || n != 0 || rev != nil || err != nil || FromValues[int]() != nil {
+		t.Error("nil head")
+	}
+	self := &Node[string]{Value: "self"}
+	self.Next = self
+	if s, n := FindCycle(self); s != self || n != 1 {
+		t.Errorf("self loop: %v, %d", s, n)
+	}
+}
+
+func TestDeque(t *testing.T) {
+	var d Deque[int]
+	for i := 1; i <= 5; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	if !slices.Equal(d.Values(), []int{-5, -4, -3, -2, -1, 1, 2, 3, 4, 5}) {
+		t.Fatalf("Values = %v", d.Values())
+	}
+	f, _ := d.Front()
+	b, _ := d.Back()
+	x, _ := d.At(5)
+	if f != -5 || b != 5 || x != 1 {
+		t.Errorf("Front=%d Back=%d At(5)=%d", f, b, x)
+	}
+
+	var nilDeque *Deque[int]
+	_, ok1 := nilDeque.Front()
+	_, ok2 := nilDeque.At(0)
+	if nilDeque.Len() != 0 || ok1 || ok2 || len(nilDeque.Values()) != 0 {
+		t.Error("nil *Deque read methods")
+	}
+	var empty Deque[int]
+	_, ok := empty.PopFront()
+	_, ok3 := d.At(-1)
+	_, ok4 := d.At(d.Len())
+	if ok || ok3 || ok4 {
+		t.Error("PopFront on empty or At out of range returned true")
+	}
+}
+
+// TestDequeModel 对比随机操作后的 Deque 和切片模型，并检查缓冲区会缩回去。
+func TestDequeModel(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var model []int
+	var q Deque[int]
+	maxCap := 0
+	for i := 0; i < 5000; i++ {
+		switch op := r.Intn(6); {
+		case op < 2 || i < 2500 && op < 3:
+			q.PushBack(i)
+			model = append(model, i)
+		case op < 4:
+			q.PushFront(i)
+			model = append([]int{i}, model...)
+		case op == 4:
+			v, ok := q.PopFront()
+			if ok != (len(model) > 0) || ok && v != model[0] {
+				t.Fatalf("step %d: PopFront = %d, %v; model %v", i, v, ok, model[:min(len(model), 3)])
+			}
+			if ok {
+				model = model[1:]
+			}
+		default:
+			v, ok := q.PopBack()
+			if ok != (len(model) > 0) || ok && v != model[len(model)-1] {
+				t.Fatalf("step %d: PopBack = %d, %v", i, v, ok)
+			}
+			if ok {
+				model = model[:len(model)-1]
+			}
+		}
+		maxCap = max(maxCap, q.Cap())
+	}
+	if !slices.Equal(q.Values(), model) {
+		t.Fatalf("deque %v\nmodel %v", q.Values(), model)
+	}
+	for q.Len() > 0 {
+		q.PopBack()
+	}
+	if q.Cap() > 8 || maxCap <= 8 {
+		t.Errorf("buffer grew to %d and shrank to %d, want <= 8", maxCap, q.Cap())
+	}
+}
+
+func TestLRU(t *testing.T) {
+	var log []string
+	c := NewLRU[string, int](3, func(k string, v int, why EvictReason) {
+		log = append(log, fmt.Sprintf("%s=%d(%s)", k, v, why))
+	})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	c.Get("a")
+	if evicted := c.Put("d", 4); !evicted || strings.Join(c.Keys(), "") != "dac" {
+		t.Errorf("Put(d): evicted=%v keys=%v, want b evicted and dac", evicted, c.Keys())
+	}
+	if evicted := c.Put("a", 10); evicted {
+		t.Error("overwriting a reported an eviction")
+	}
+	if v, _ := c.Peek("c"); strings.Join(c.Keys(), "") != "adc" || v != 3 {
+		t.Errorf("Peek changed the order: %v", c.Keys())
+	}
+	c.Remove("d")
+	c.Resize(1)
+	c.Purge()
+	if got := strings.Join(log, " "); got != "b=2(capacity) d=4(removed) c=3(capacity) a=10(removed)" {
+		t.Errorf("evictions: %s", got)
+	}
+
+	// 回调里访问缓存
+	var lens []int
+	var rc *LRU[int, int]
+	rc = NewLRU[int, int](2, func(int, int, EvictReason) { lens = append(lens, rc.Len()) })
+	rc.Put(1, 1)
+	rc.Put(2, 2)
+	rc.Put(3, 3)
+	if !slices.Equal(lens, []int{2}) {
+		t.Errorf("Len seen by the callback = %v, want [2] (entry already removed)", lens)
+	}
+}
+
+func TestLRUNil(t *testing.T) {
+	var nilCache *LRU[string, int]
+	if _, ok := nilCache.Get("x"); ok || nilCache.Len() != 0 || nilCache.Remove("x") || len(nilCache.Keys()) != 0 {
+		t.Error("nil *LRU read methods")
+	}
+	type session struct{ user string }
+	sessions := NewLRU[string, *session](2, nil)
+	sessions.Put("guest", nil)
+	s, ok := sessions.Get("guest")
+	_, missing := sessions.Get("nobody")
+	if s != nil || !ok || missing {
+		t.Errorf("stored nil: Get = %v, %v; missing key ok=%v", s, ok, missing)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewLRU(0) did not panic")
+		}
+	}()
+	NewLRU[int, int](0, nil)
+}
@@ -0,0 +1,159 @@
+package list
+
+import "fmt"
+
+// EvictReason 说明条目为什么离开 LRU。
+type EvictReason int
+
+const (
+	// EvictedCapacity：容量已满，最久没用的条目被挤出。
+	EvictedCapacity EvictReason = iota + 1
+	// EvictedRemoved：调用了 Remove 或 Purge。
+	EvictedRemoved
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictedCapacity:
+		return "capacity"
+	case EvictedRemoved:
+		return "removed"
+	}
+	return fmt.Sprintf("EvictReason(%d)", int(r))
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// LRU 是固定容量的最近最少使用缓存：map 从键找到链表元素，链表按使用时间排序，
+// 最近使用的在前。Get、Put、Remove 都是 O(1)。不是并发安全的。
+type LRU[K comparable, V any] struct {
+	capacity int
+	items    map[K]*Element[lruEntry[K, V]]
+	order    List[lruEntry[K, V]]
+	onEvict  func(K, V, EvictReason)
+}
+
+// NewLRU 创建容量为 capacity 的缓存。onEvict 不为 nil 时在条目被挤出或删除后调用，
+// 此时条目已经不在缓存中，回调里可以安全地再访问缓存。capacity 小于 1 时 panic。
+func NewLRU[K comparable, V any](capacity int, onEvict func(key K, value V, reason EvictReason)) *LRU[K, V] {
+	if capacity < 1 {
+		panic(fmt.Sprintf("list: LRU capacity must be positive, got %d", capacity))
+	}
+	return &LRU[K, V]{capacity: capacity, items: make(map[K]*Element[lruEntry[K, V]]), onEvict: onEvict}
+}
+
+// Len 返回条目数，c 为 nil 时返回 0。
+func (c *LRU[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.order.Len()
+}
+
+// Cap 返回容量。
+func (c *LRU[K, V]) Cap() int {
+	if c == nil {
+		return 0
+	}
+	return c.capacity
+}
+
+// Get 返回 key 的值并把它标记为最近使用。c 为 nil 时返回零值和 false。
+// 值本身可以是 nil（例如 V 是指针类型），用第二个返回值区分“存了 nil”和“不存在”。
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	if c == nil {
+		var zero V
+		return zero, false
+	}
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.value, true
+}
+
+// Peek 返回 key 的值，但不改变使用顺序。
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	if c == nil {
+		var zero V
+		return zero, false
+	}
+	if e, ok := c.items[key]; ok {
+		return e.Value.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put 写入 key 并标记为最近使用，返回是否因此挤出了别的条目。覆盖已有的键不算挤出。
+func (c *LRU[K, V]) Put(key K, value V) (evicted bool) {
+	if e, ok := c.items[key]; ok {
+		e.Value.value = value
+		c.order.MoveToFront(e)
+		return false
+	}
+	c.items[key] = c.order.PushFront(lruEntry[K, V]{key, value})
+	if c.order.Len() > c.capacity {
+		c.evict(c.order.Back(), EvictedCapacity)
+		return true
+	}
+	return false
+}
+
+// Remove 删除 key，返回它是否存在。
+func (c *LRU[K, V]) Remove(key K) bool {
+	if c == nil {
+		return false
+	}
+	e, ok := c.items[key]
+	if ok {
+		c.evict(e, EvictedRemoved)
+	}
+	return ok
+}
+
+func (c *LRU[K, V]) evict(e *Element[lruEntry[K, V]], reason EvictReason) {
+	ent, _ := c.order.Remove(e)
+	delete(c.items, ent.key)
+	if c.onEvict != nil {
+		c.onEvict(ent.key, ent.value, reason)
+	}
+}
+
+// Resize 修改容量，变小时从最久没用的开始挤出，返回挤出的条目数。
+func (c *LRU[K, V]) Resize(capacity int) int {
+	if capacity < 1 {
+		panic(fmt.Sprintf("list: LRU capacity must be positive, got %d", capacity))
+	}
+	c.capacity = capacity
+	n := 0
+	for c.order.Len() > capacity {
+		c.evict(c.order.Back(), EvictedCapacity)
+		n++
+	}
+	return n
+}
+
+// Purge 删除所有条目，对每个条目调用 onEvict。
+func (c *LRU[K, V]) Purge() {
+	for c.Len() > 0 {
+		c.evict(c.order.Back(), EvictedRemoved)
+	}
+}
+
+// Keys 按从最近使用到最久没用的顺序返回所有键。
+func (c *LRU[K, V]) Keys() []K {
+	out := make([]K, 0, c.Len())
+	if c == nil {
+		return out
+	}
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		out = append(out, e.Value.key)
+	}
+	return out
+}
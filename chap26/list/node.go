@@ -0,0 +1,85 @@
+// Package list 把 chap26/pointers.go 里只用来演示指针的 Node 扩展成可用的数据结构：
+// 泛型单链表 Singly、双向链表 List、环形缓冲区 Deque，以及建立在 List 上的 LRU 缓存。
+//
+// 链表通过元素句柄（*SElement、*Element）做 O(1) 的插入和删除。和 container/list 一样，
+// 链表和元素的零值可以直接用，nil 链表上的只读方法返回零值，nil 句柄和不属于该链表的句柄会被忽略，
+// 不会 panic —— 这正是 chap27/nil.go 提醒要小心的地方。
+package list
+
+import "errors"
+
+// ErrCycle 表示链表中有环。
+var ErrCycle = errors.New("list: cycle detected")
+
+// Node 是 chap26 中 Node{Value int; Next *Node} 的泛型版本，字段全部导出，
+// 因此可以随意连成环。FindCycle 等函数就是为这种“裸”节点准备的。
+type Node[T any] struct {
+	Value T
+	Next  *Node[T]
+}
+
+// FromValues 用 values 依次建立节点，返回头节点；没有值时返回 nil。
+func FromValues[T any](values ...T) *Node[T] {
+	var head *Node[T]
+	for i := len(values) - 1; i >= 0; i-- {
+		head = &Node[T]{Value: values[i], Next: head}
+	}
+	return head
+}
+
+// FindCycle 用 Floyd 判圈算法（快慢指针）查找环：慢指针每次走一步，快指针走两步，
+// 有环时二者必在环内相遇；再让一个指针从头出发、两者同速前进，相遇处就是环的入口。
+// 返回环的入口和长度，无环时返回 nil, 0。只用 O(1) 额外空间。
+func FindCycle[T any](head *Node[T]) (start *Node[T], length int) {
+	slow, fast := head, head
+	for fast != nil && fast.Next != nil {
+		slow, fast = slow.Next, fast.Next.Next
+		if slow == fast {
+			start = head
+			for start != slow {
+				start, slow = start.Next, slow.Next
+			}
+			length = 1
+			for p := start.Next; p != start; p = p.Next {
+				length++
+			}
+			return start, length
+		}
+	}
+	return nil, 0
+}
+
+// HasCycle 报告从 head 出发是否有环。
+func HasCycle[T any](head *Node[T]) bool {
+	start, _ := FindCycle(head)
+	return start != nil
+}
+
+// Values 返回链表中的值；有环时返回 ErrCycle 和环入口之前加上一圈环的值。
+func Values[T any](head *Node[T]) ([]T, error) {
+	start, length := FindCycle(head)
+	var out []T
+	p := head
+	for ; p != nil && p != start; p = p.Next {
+		out = append(out, p.Value)
+	}
+	if start == nil {
+		return out, nil
+	}
+	for i := 0; i < length; i, p = i+1, p.Next {
+		out = append(out, p.Value)
+	}
+	return out, ErrCycle
+}
+
+// ReverseNodes 原地反转链表，返回新的头节点。有环时返回 ErrCycle，链表保持不变。
+func ReverseNodes[T any](head *Node[T]) (*Node[T], error) {
+	if HasCycle(head) {
+		return head, ErrCycle
+	}
+	var prev *Node[T]
+	for head != nil {
+		head.Next, prev, head = prev, head, head.Next
+	}
+	return prev, nil
+}
@@ -0,0 +1,167 @@
+package list
+
+// SElement 是 Singly 中的元素。
+type SElement[T any] struct {
+	Value T
+	next  *SElement[T]
+	list  *Singly[T]
+}
+
+// Next 返回下一个元素，没有时返回 nil。e 为 nil 时也返回 nil。
+func (e *SElement[T]) Next() *SElement[T] {
+	if e == nil {
+		return nil
+	}
+	return e.next
+}
+
+// Singly 是单链表，同时记录头尾，PushFront、PushBack、InsertAfter、RemoveAfter 都是 O(1)；
+// 没有前驱指针，所以 Remove 任意元素要从头找前驱，是 O(n)。零值是空链表。
+type Singly[T any] struct {
+	head, tail *SElement[T]
+	len        int
+}
+
+// NewSingly 返回包含 values 的单链表。
+func NewSingly[T any](values ...T) *Singly[T] {
+	l := new(Singly[T])
+	for _, v := range values {
+		l.PushBack(v)
+	}
+	return l
+}
+
+// Len 返回元素个数，l 为 nil 时返回 0。
+func (l *Singly[T]) Len() int {
+	if l == nil {
+		return 0
+	}
+	return l.len
+}
+
+// Front 返回第一个元素，空链表或 l 为 nil 时返回 nil。
+func (l *Singly[T]) Front() *SElement[T] {
+	if l == nil {
+		return nil
+	}
+	return l.head
+}
+
+// Back 返回最后一个元素。
+func (l *Singly[T]) Back() *SElement[T] {
+	if l == nil {
+		return nil
+	}
+	return l.tail
+}
+
+// PushFront 在头部插入 v。
+func (l *Singly[T]) PushFront(v T) *SElement[T] {
+	e := &SElement[T]{Value: v, next: l.head, list: l}
+	l.head = e
+	if l.tail == nil {
+		l.tail = e
+	}
+	l.len++
+	return e
+}
+
+// PushBack 在尾部插入 v。
+func (l *Singly[T]) PushBack(v T) *SElement[T] {
+	if l.tail == nil {
+		return l.PushFront(v)
+	}
+	e := &SElement[T]{Value: v, list: l}
+	l.tail.next, l.tail = e, e
+	l.len++
+	return e
+}
+
+// InsertAfter 在 mark 之后插入 v。mark 为 nil 或不属于 l 时不做任何事，返回 nil。
+func (l *Singly[T]) InsertAfter(mark *SElement[T], v T) *SElement[T] {
+	if mark == nil || mark.list != l {
+		return nil
+	}
+	e := &SElement[T]{Value: v, next: mark.next, list: l}
+	mark.next = e
+	if l.tail == mark {
+		l.tail = e
+	}
+	l.len++
+	return e
+}
+
+// RemoveAfter 删除 mark 之后的元素并返回它的值。mark 无效或是最后一个元素时返回 false。
+func (l *Singly[T]) RemoveAfter(mark *SElement[T]) (T, bool) {
+	var zero T
+	if mark == nil || mark.list != l || mark.next == nil {
+		return zero, false
+	}
+	e := mark.next
+	mark.next = e.next
+	if l.tail == e {
+		l.tail = mark
+	}
+	l.release(e)
+	return e.Value, true
+}
+
+// PopFront 删除并返回第一个元素的值，空链表返回 false。
+func (l *Singly[T]) PopFront() (T, bool) {
+	var zero T
+	if l.Len() == 0 {
+		return zero, false
+	}
+	e := l.head
+	l.head = e.next
+	if l.head == nil {
+		l.tail = nil
+	}
+	l.release(e)
+	return e.Value, true
+}
+
+// Remove 删除 e 并返回它的值，O(n)。e 为 nil 或不属于 l（包括已经删除过）时返回 false。
+func (l *Singly[T]) Remove(e *SElement[T]) (T, bool) {
+	var zero T
+	if e == nil || e.list != l {
+		return zero, false
+	}
+	if e == l.head {
+		return l.PopFront()
+	}
+	for p := l.head; p != nil; p = p.next {
+		if p.next == e {
+			return l.RemoveAfter(p)
+		}
+	}
+	return zero, false
+}
+
+// release 断开被删除元素的指针，防止通过旧句柄继续遍历链表，也让它不再被认为属于 l。
+func (l *Singly[T]) release(e *SElement[T]) {
+	e.next, e.list = nil, nil
+	l.len--
+}
+
+// Reverse 原地反转链表，O(n)，句柄仍然有效。
+func (l *Singly[T]) Reverse() {
+	if l.Len() < 2 {
+		return
+	}
+	var prev *SElement[T]
+	l.tail = l.head
+	for p := l.head; p != nil; {
+		p.next, prev, p = prev, p, p.next
+	}
+	l.head = prev
+}
+
+// Values 按顺序返回所有值。
+func (l *Singly[T]) Values() []T {
+	out := make([]T, 0, l.Len())
+	for e := l.Front(); e != nil; e = e.next {
+		out = append(out, e.Value)
+	}
+	return out
+}
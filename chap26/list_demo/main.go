@@ -0,0 +1,166 @@
+// 独立运行：go run ./chap26/list_demo
+// 演示：单链表、双向链表、Floyd 判圈、Deque 和 LRU 的用法，以及 chap27/nil.go 提到的 nil 边界情况
+// （nil 接收者、nil 句柄、重复删除、别的链表的句柄、存入 nil 值）。断言见 go test ./chap26/list。
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"books/chap26/list"
+)
+
+func main() {
+	singly()
+	doubly()
+	cycles()
+	deque()
+	lru()
+}
+
+func singly() {
+	fmt.Println("=== 1. 单链表 Singly ===")
+	l := list.NewSingly(1, 2, 4)
+	two := l.Front().Next()
+	l.InsertAfter(two, 3)
+	l.PushFront(0)
+	tail := l.PushBack(5)
+	fmt.Println("  插入后:", l.Values())
+	l.Reverse()
+	fmt.Printf("  反转后: %v，原来的尾元素现在在头部: %v\n", l.Values(), l.Front() == tail)
+	_, ok := l.RemoveAfter(l.Back())
+	fmt.Println("  RemoveAfter(最后一个元素):", ok)
+	l.Remove(two)
+	l.PopFront()
+	l.PushBack(9)
+	fmt.Println("  删除 2 和头部，再 PushBack(9):", l.Values())
+
+	fmt.Println("  -- nil 边界 --")
+	var nilList *list.Singly[int]
+	fmt.Printf("  nil *Singly: Len=%d Front=%v Values=%v\n", nilList.Len(), nilList.Front(), nilList.Values())
+	var zero list.Singly[string]
+	zero.PushBack("a")
+	fmt.Println("  零值 Singly 可以直接 PushBack:", zero.Values())
+	_, ok = l.Remove(two)
+	fmt.Printf("  同一个句柄再删一次: %v，已删除元素的 Next(): %v\n", ok, two.Next())
+	other := list.NewSingly(7)
+	_, ok = l.Remove(other.Front())
+	fmt.Printf("  删除别的链表的句柄: %v，那个链表仍有 %d 个元素\n", ok, other.Len())
+}
+
+func doubly() {
+	fmt.Println("\n=== 2. 双向链表 List ===")
+	l := list.New("b", "d")
+	b, d := l.Front(), l.Back()
+	l.InsertAfter("c", b)
+	l.InsertBefore("a", b)
+	l.PushBack("e")
+	fmt.Println("  插入后:", strings.Join(l.Values(), ""))
+	l.MoveToFront(d)
+	l.MoveToBack(b)
+	fmt.Println("  MoveToFront(d)、MoveToBack(b):", strings.Join(l.Values(), ""))
+	l.Reverse()
+	var back []string
+	for e := l.Back(); e != nil; e = e.Prev() {
+		back = append(back, e.Value)
+	}
+	fmt.Printf("  反转: %s，从尾部用 Prev 遍历: %s\n", strings.Join(l.Values(), ""), strings.Join(back, ""))
+
+	fmt.Println("  -- nil 边界 --")
+	var nilList *list.List[int]
+	_, ok := nilList.Remove(nil)
+	fmt.Printf("  nil *List: Len=%d Front=%v Remove=%v\n", nilList.Len(), nilList.Front(), ok)
+	var zero list.List[int]
+	zero.PushFront(1)
+	fmt.Println("  零值 List 第一次写入时初始化哨兵:", zero.Values())
+	s, ok := l.Remove(d)
+	_, again := l.Remove(d)
+	fmt.Printf("  删除 %s: %v，再删一次: %v，Next/Prev: %v %v\n", s, ok, again, d.Next(), d.Prev())
+	l.MoveToFront(d)
+	fmt.Println("  已删除的句柄不能移动:", strings.Join(l.Values(), ""))
+	other := list.New("z")
+	l.MoveToFront(other.Front())
+	fmt.Printf("  别的链表的句柄被忽略: %s / %s\n", strings.Join(l.Values(), ""), strings.Join(other.Values(), ""))
+}
+
+func cycles() {
+	fmt.Println("\n=== 3. Floyd 判圈（chap26 的 Node）===")
+	head := list.FromValues(1, 2, 3, 4, 5, 6)
+	fmt.Println("  1→2→3→4→5→6 有环:", list.HasCycle(head))
+	tail, third := head, head.Next.Next
+	for tail.Next != nil {
+		tail = tail.Next
+	}
+	tail.Next = third // 6 → 3
+	start, n := list.FindCycle(head)
+	fmt.Printf("  6→3 后：入口 %d，长度 %d\n", start.Value, n)
+	vs, err := list.Values(head)
+	fmt.Printf("  Values 遇到环不会死循环: %v, %v\n", vs, err)
+	_, err = list.ReverseNodes(head)
+	fmt.Println("  反转有环的链表:", errors.Is(err, list.ErrCycle))
+	tail.Next = nil
+	head, _ = list.ReverseNodes(head)
+	vs, _ = list.Values(head)
+	fmt.Println("  断开环后反转:", vs)
+
+	self := &list.Node[string]{Value: "self"}
+	self.Next = self
+	_, n = list.FindCycle(self)
+	fmt.Println("  自环的长度:", n)
+}
+
+func deque() {
+	fmt.Println("\n=== 4. 环形缓冲区 Deque ===")
+	var d list.Deque[int]
+	for i := 1; i <= 5; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	f, _ := d.Front()
+	b, _ := d.Back()
+	x, _ := d.At(5)
+	fmt.Printf("  两端压入: %v（容量 %d）Front=%d Back=%d At(5)=%d\n", d.Values(), d.Cap(), f, b, x)
+	for i := 0; i < 1000; i++ {
+		d.PushBack(i)
+	}
+	grown := d.Cap()
+	for d.Len() > 0 {
+		d.PopFront()
+	}
+	fmt.Printf("  压入 1000 个后容量 %d，清空后缩回 %d\n", grown, d.Cap())
+	var nilDeque *list.Deque[int]
+	_, ok := nilDeque.Front()
+	fmt.Printf("  nil *Deque: Len=%d Front ok=%v\n", nilDeque.Len(), ok)
+}
+
+type session struct{ user string }
+
+func lru() {
+	fmt.Println("\n=== 5. LRU 缓存 ===")
+	c := list.NewLRU[string, int](3, func(k string, v int, why list.EvictReason) {
+		fmt.Printf("  onEvict %s=%d (%s)\n", k, v, why)
+	})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	c.Get("a") // a 变成最近使用
+	c.Put("d", 4)
+	fmt.Println("  Put(d) 挤出最久没用的 b，顺序:", c.Keys())
+	c.Put("a", 10)
+	c.Peek("c")
+	fmt.Println("  覆盖 a 不算挤出，Peek 不改变顺序:", c.Keys())
+	c.Remove("d")
+	c.Resize(1)
+	c.Purge()
+
+	fmt.Println("  -- nil 边界 --")
+	var nilCache *list.LRU[string, int]
+	_, ok := nilCache.Get("x")
+	fmt.Printf("  nil *LRU: Get ok=%v Len=%d\n", ok, nilCache.Len())
+	sessions := list.NewLRU[string, *session](2, nil)
+	sessions.Put("guest", nil)
+	s, ok := sessions.Get("guest")
+	_, found := sessions.Get("nobody")
+	fmt.Printf("  存入 nil 指针: Get(guest) = (%v, %v)，Get(nobody) ok=%v\n", s, ok, found)
+}
@@ -163,7 +163,11 @@ fmt.Println(i == nil) // false，因为类型是 *int，不是空
 */
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"books/chap27/nilcheck"
+)
 
 func main() {
 	// ============================================
@@ -381,6 +385,7 @@ func main() {
 		fmt.Println("  返回的接口是nil")
 	} else {
 		fmt.Printf("  返回的接口不是nil: %T, %v\n", result, result)
+		fmt.Printf("  nilcheck.Describe: %v\n", nilcheck.Describe(result))
 	}
 	fmt.Println()
 
@@ -537,24 +542,9 @@ func safeGetValue(key string) (int, error) {
 }
 
 // isNil 检查值是否为nil（通用方法）
+// 用反射覆盖所有能为 nil 的类型，接口里装着带类型的 nil 时也返回 true，见 chap27/nilcheck
 func isNil(v interface{}) bool {
-	if v == nil {
-		return true
-	}
-	switch val := v.(type) {
-	case *int:
-		return val == nil
-	case []int:
-		return val == nil
-	case map[string]int:
-		return val == nil
-	case chan int:
-		return val == nil
-	case func():
-		return val == nil
-	default:
-		return false
-	}
+	return nilcheck.IsNil(v)
 }
 
 // processInterface 处理可能为nil的接口
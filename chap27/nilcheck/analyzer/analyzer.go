@@ -0,0 +1,399 @@
+// Package analyzer 提供 nilcheck 的静态检查（go/analysis），找出第27章演示的接口 nil 陷阱：
+// 函数的结果类型是 error 或其他接口，却返回了一个具体类型的 nil 值。调用方拿到的接口
+// 带着类型信息，if err != nil 永远成立。报告三种写法：
+//
+//  1. 显式的带类型 nil：return (*MyErr)(nil)。
+//  2. 返回可能为 nil 的局部变量：var p *MyErr 没有赋值，或者某处被赋值为 nil（getNilInterface 就是这样）。
+//     返回语句处于 if p != nil 的分支里，或者前面有 if p == nil { return ... } 时不报告。
+//     切片、map、chan 和函数类型的变量只在一定是 nil 时报告：var out []int 之后 append 再返回是常见写法。
+//  3. 结果类型是 error，却直接返回一个结果类型为具体指针的调用：return find()，find 返回 *MyErr，
+//     且 find 在同一个包中、函数体里有 return nil。总是返回 &T{...} 的构造函数不会报告。
+//
+// 检查按源码顺序进行，不做控制流分析：只要变量在函数中可能为 nil 就会报告，
+// 赋值发生在哪个分支、是否在返回之前都不考虑。
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Analyzer 检查通过 error 或其他接口结果返回带类型 nil 的函数。
+var Analyzer = &analysis.Analyzer{
+	Name:     "nilcheck",
+	Doc:      "report functions that return a typed nil pointer (or other nil concrete value) through an error or interface result",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nilResults := collectNilResults(pass)
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		var body *ast.BlockStmt
+		var sig *types.Signature
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			body = fn.Body
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				sig, _ = obj.Type().(*types.Signature)
+			}
+		case *ast.FuncLit:
+			body = fn.Body
+			sig, _ = pass.TypesInfo.TypeOf(fn).(*types.Signature)
+		}
+		if body != nil && sig != nil && hasInterfaceResult(sig.Results()) {
+			checkBody(pass, body, sig.Results(), nilResults)
+		}
+	})
+	return nil, nil
+}
+
+// collectNilResults 找出包中每个函数有哪些结果位置会直接 return nil 或 (T)(nil)。
+func collectNilResults(pass *analysis.Pass) map[*types.Func][]bool {
+	out := make(map[*types.Func][]bool)
+	for _, f := range pass.Files {
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			nres := fn.Type().(*types.Signature).Results().Len()
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				switch r := n.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.ReturnStmt:
+					if len(r.Results) != nres {
+						break
+					}
+					for i, e := range r.Results {
+						if isNilExpr(pass, e) {
+							if out[fn] == nil {
+								out[fn] = make([]bool, nres)
+							}
+							out[fn][i] = true
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	return out
+}
+
+// guard 表示在 [from, to) 范围内 v 已知不为 nil。
+type guard struct {
+	v        *types.Var
+	from, to token.Pos
+}
+
+// checkBody 检查一个函数体（不进入嵌套的函数字面量，它们会被单独检查）。
+func checkBody(pass *analysis.Pass, body *ast.BlockStmt, results *types.Tuple, nilResults map[*types.Func][]bool) {
+	// mayBeNil 记录在函数中某处为 nil 的局部变量（零值声明或赋值为 nil），
+	// assigned 记录被赋过非 nil 值的变量，用来区分“一定是 nil”和“可能是 nil”。
+	mayBeNil := make(map[*types.Var]bool)
+	assigned := make(map[*types.Var]bool)
+	var guards []guard
+	var returns []*ast.ReturnStmt
+
+	record := func(id *ast.Ident, isNil bool) {
+		v := localVar(pass, id)
+		if v == nil {
+			return
+		}
+		if isNil {
+			mayBeNil[v] = true
+		} else {
+			assigned[v] = true
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ValueSpec:
+			for i, id := range n.Names {
+				switch {
+				case len(n.Values) == 0:
+					record(id, true)
+				case len(n.Values) == len(n.Names):
+					record(id, isNilExpr(pass, n.Values[i]))
+				default:
+					record(id, false) // 多值调用的结果未知，按非 nil 处理
+				}
+			}
+		case *ast.AssignStmt:
+			if n.Tok != token.ASSIGN && n.Tok != token.DEFINE {
+				break
+			}
+			for i, l := range n.Lhs {
+				id, ok := l.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				record(id, len(n.Lhs) == len(n.Rhs) && isNilExpr(pass, n.Rhs[i]))
+			}
+		case *ast.IfStmt:
+			for _, v := range nilComparisons(pass, n.Cond, token.NEQ, token.LAND) {
+				guards = append(guards, guard{v, n.Body.Pos(), n.Body.End()})
+			}
+			if n.Else != nil {
+				for _, v := range nilComparisons(pass, n.Cond, token.EQL, token.LOR) {
+					guards = append(guards, guard{v, n.Else.Pos(), n.Else.End()})
+				}
+			}
+		case *ast.BlockStmt:
+			guards = append(guards, earlyReturnGuards(pass, n.List, n.End())...)
+		case *ast.CaseClause:
+			guards = append(guards, earlyReturnGuards(pass, n.Body, n.End())...)
+		case *ast.CommClause:
+			guards = append(guards, earlyReturnGuards(pass, n.Body, n.End())...)
+		case *ast.ReturnStmt:
+			returns = append(returns, n)
+		}
+		return true
+	})
+
+	guarded := func(v *types.Var, pos token.Pos) bool {
+		for _, g := range guards {
+			if g.v == v && g.from <= pos && pos < g.to {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, ret := range returns {
+		if len(ret.Results) == 1 && results.Len() > 1 {
+			checkTupleCall(pass, ret.Results[0], results, nilResults)
+			continue
+		}
+		if len(ret.Results) != results.Len() {
+			continue // 裸 return
+		}
+		for i, e := range ret.Results {
+			want := results.At(i).Type()
+			if !isInterface(want) {
+				continue
+			}
+			t := pass.TypesInfo.TypeOf(e)
+			if !isNillableConcrete(t) {
+				continue
+			}
+			e = astutil.Unparen(e)
+			switch e := e.(type) {
+			case *ast.CallExpr:
+				if isNilConversion(pass, e) {
+					pass.Reportf(e.Pos(), "returning typed nil %s as %s: the result is never == nil",
+						typeString(pass, t), typeString(pass, want))
+				} else if !isConversion(pass, e) {
+					checkCall(pass, e, 0, t, want, nilResults)
+				}
+			case *ast.Ident:
+				v := localVar(pass, e)
+				if v == nil || !mayBeNil[v] || guarded(v, ret.Pos()) {
+					continue
+				}
+				if _, ptr := t.Underlying().(*types.Pointer); !ptr && assigned[v] {
+					continue // 例如 append 过的切片：只有指针的“可能为 nil”才值得报告
+				}
+				how := "may be nil"
+				if !assigned[v] {
+					how = "is always nil"
+				}
+				pass.Reportf(e.Pos(), "%s %s %s: returning it as %s gives a non-nil interface holding a nil %s",
+					v.Name(), typeString(pass, t), how, typeString(pass, want), typeString(pass, t))
+			}
+		}
+	}
+}
+
+// checkCall 在结果类型是 error、调用的第 i 个结果是具体指针类型、
+// 且被调函数在该位置有 return nil 时报告（return find()）。
+// 被调函数不在本包或无法静态确定时不报告。
+func checkCall(pass *analysis.Pass, call *ast.CallExpr, i int, got, want types.Type, nilResults map[*types.Func][]bool) {
+	if !types.Identical(want, errorType) {
+		return
+	}
+	if _, ok := got.Underlying().(*types.Pointer); !ok {
+		return
+	}
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil {
+		return
+	}
+	if nils := nilResults[fn.Origin()]; nils == nil || !nils[i] {
+		return
+	}
+	pass.Reportf(call.Pos(), "returning the %s result of a call as error: if it is nil the error is still non-nil; make the callee return error",
+		typeString(pass, got))
+}
+
+// checkTupleCall 处理 return f() 且 f 返回多个值的情况。
+func checkTupleCall(pass *analysis.Pass, e ast.Expr, results *types.Tuple, nilResults map[*types.Func][]bool) {
+	call, ok := astutil.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	tuple, ok := pass.TypesInfo.TypeOf(call).(*types.Tuple)
+	if !ok || tuple.Len() != results.Len() {
+		return
+	}
+	for i := 0; i < tuple.Len(); i++ {
+		got, want := tuple.At(i).Type(), results.At(i).Type()
+		if isInterface(want) && isNillableConcrete(got) {
+			checkCall(pass, call, i, got, want, nilResults)
+		}
+	}
+}
+
+// earlyReturnGuards 找出语句列表中形如 if v == nil { ...; return } 的语句：
+// 从它之后到块结束，v 都不为 nil。
+func earlyReturnGuards(pass *analysis.Pass, list []ast.Stmt, end token.Pos) []guard {
+	var gs []guard
+	for _, s := range list {
+		ifs, ok := s.(*ast.IfStmt)
+		if !ok || ifs.Else != nil || !terminates(pass, ifs.Body) {
+			continue
+		}
+		for _, v := range nilComparisons(pass, ifs.Cond, token.EQL, token.LOR) {
+			gs = append(gs, guard{v, ifs.End(), end})
+		}
+	}
+	return gs
+}
+
+// terminates 报告语句块是否以 return 或 panic 结束。
+func terminates(pass *analysis.Pass, b *ast.BlockStmt) bool {
+	if len(b.List) == 0 {
+		return false
+	}
+	switch s := b.List[len(b.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := astutil.Unparen(call.Fun).(*ast.Ident)
+		if !ok {
+			return false
+		}
+		b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+		return ok && b.Name() == "panic"
+	}
+	return false
+}
+
+// nilComparisons 返回条件中用 join（&& 或 ||）连接的各项里，形如 v op nil 或 nil op v 的局部变量。
+func nilComparisons(pass *analysis.Pass, cond ast.Expr, op, join token.Token) []*types.Var {
+	be, ok := astutil.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return nil
+	}
+	if be.Op == join {
+		return append(nilComparisons(pass, be.X, op, join), nilComparisons(pass, be.Y, op, join)...)
+	}
+	if be.Op != op {
+		return nil
+	}
+	x, y := astutil.Unparen(be.X), astutil.Unparen(be.Y)
+	if isNilExpr(pass, x) {
+		x, y = y, x
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok || !isNilExpr(pass, y) {
+		return nil
+	}
+	if v := localVar(pass, id); v != nil {
+		return []*types.Var{v}
+	}
+	return nil
+}
+
+// isNilExpr 报告 e 是否为 nil 或 (T)(nil)。
+func isNilExpr(pass *analysis.Pass, e ast.Expr) bool {
+	e = astutil.Unparen(e)
+	if tv, ok := pass.TypesInfo.Types[e]; ok && tv.IsNil() {
+		return true
+	}
+	call, ok := e.(*ast.CallExpr)
+	return ok && isNilConversion(pass, call)
+}
+
+// isConversion 报告 call 是否为类型转换 T(x)。
+func isConversion(pass *analysis.Pass, call *ast.CallExpr) bool {
+	tv, ok := pass.TypesInfo.Types[call.Fun]
+	return ok && tv.IsType()
+}
+
+// isNilConversion 报告 call 是否为 (T)(nil)。
+func isNilConversion(pass *analysis.Pass, call *ast.CallExpr) bool {
+	return isConversion(pass, call) && len(call.Args) == 1 && isNilExpr(pass, call.Args[0])
+}
+
+func hasInterfaceResult(results *types.Tuple) bool {
+	for i := 0; i < results.Len(); i++ {
+		if isInterface(results.At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInterface 报告 t 是否为接口类型（不包括类型参数）。
+func isInterface(t types.Type) bool {
+	if _, ok := t.(*types.TypeParam); ok {
+		return false
+	}
+	return types.IsInterface(t)
+}
+
+// isNillableConcrete 报告 t 是否为能为 nil 的非接口类型。
+func isNillableConcrete(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if _, ok := t.(*types.TypeParam); ok {
+		return false
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return true
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer
+	}
+	return false
+}
+
+func typeString(pass *analysis.Pass, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pass.Pkg))
+}
+
+// localVar 返回标识符对应的函数内变量（包括参数），包级变量和字段返回 nil。
+func localVar(pass *analysis.Pass, id *ast.Ident) *types.Var {
+	obj := pass.TypesInfo.ObjectOf(id)
+	v, ok := obj.(*types.Var)
+	if !ok || v.IsField() || v.Parent() == nil || v.Parent() == pass.Pkg.Scope() {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,201 @@
+// Package typednil 收集第27章演示过的接口 nil 陷阱，// want 注释标出 nilcheck 应当报告的位置
+// （analysistest 约定的写法）；没有 // want 的函数不应被报告。
+package typednil
+
+import (
+	"errors"
+	"fmt"
+)
+
+type MyErr struct{ Msg string }
+
+func (e *MyErr) Error() string { return e.Msg }
+
+// nil.go 的 getNilInterface：返回的不是 nil 接口，而是装着 nil *int 的接口。
+func getNilInterface() interface{} {
+	var nilPtr *int
+	return nilPtr // want `nilPtr \*int is always nil: returning it as interface\{\} gives a non-nil interface holding a nil \*int`
+}
+
+// 显式的带类型 nil。
+func explicitTypedNil() error {
+	return (*MyErr)(nil) // want `returning typed nil \*MyErr as error: the result is never == nil`
+}
+
+// 经典写法：只有出错时才赋值，成功时返回的 err 仍然不是 nil。
+func validate(name string) error {
+	var err *MyErr
+	if name == "" {
+		err = &MyErr{Msg: "name is empty"}
+	}
+	return err // want `err \*MyErr may be nil`
+}
+
+// 多个返回值里的接口结果。
+func lookup(key string) (int, error) {
+	e := (*MyErr)(nil)
+	if key == "" {
+		e = &MyErr{Msg: "empty key"}
+	}
+	return 0, e // want `e \*MyErr may be nil`
+}
+
+// 被调用的函数返回具体类型 *MyErr。
+func find(name string) *MyErr {
+	if name == "" {
+		return &MyErr{Msg: "not found"}
+	}
+	return nil
+}
+
+func findWrapped(name string) error {
+	return find(name) // want `returning the \*MyErr result of a call as error`
+}
+
+func findBoth(name string) (*MyErr, error) {
+	return nil, nil
+}
+
+func forward(name string) (*MyErr, error) {
+	return findBoth(name) // 结果类型逐个对应，不用转换成接口
+}
+
+// 其他接口和其他可为 nil 的类型。
+type Shape interface{ Area() float64 }
+
+type Square struct{ Side float64 }
+
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+func newShape(side float64) Shape {
+	var sq *Square
+	if side > 0 {
+		sq = &Square{Side: side}
+	}
+	return sq // want `sq \*Square may be nil`
+}
+
+func untypedNil() fmt.Stringer {
+	return nil // 无类型 nil：接口就是 nil
+}
+
+func nilMap() interface{} {
+	var m map[string]int
+	return m // want `m map\[string\]int is always nil`
+}
+
+func closure() func() error {
+	return func() error {
+		var e *MyErr
+		return e // want `e \*MyErr is always nil`
+	}
+}
+
+// ---- 以下写法都是正确的，不应报告 ----
+
+func returnNil() error {
+	return nil
+}
+
+func guarded(name string) error {
+	var err *MyErr
+	if name == "" {
+		err = &MyErr{Msg: "name is empty"}
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func earlyReturn(name string) error {
+	e := find(name)
+	if e == nil {
+		return nil
+	}
+	return e
+}
+
+func elseBranch(name string) error {
+	var e *MyErr
+	if name == "" {
+		e = &MyErr{Msg: "name is empty"}
+	}
+	if e == nil {
+		return nil
+	} else {
+		return e
+	}
+}
+
+func alwaysSet() error {
+	e := &MyErr{Msg: "boom"}
+	return e
+}
+
+func wrapped(name string) error {
+	if e := find(name); e != nil {
+		return fmt.Errorf("wrapped: %w", e)
+	}
+	return errors.New("ok")
+}
+
+func concreteResult() *MyErr {
+	var e *MyErr
+	return e // 结果类型本身是 *MyErr，调用方比较的是指针
+}
+
+func generic[T any](v T) any {
+	var zero T
+	return zero // 类型参数：不知道 T 是否为指针
+}
+
+// 构造函数总是返回非 nil 指针（errs、validate 等包里的 newXxxError 写法）。
+type validationError struct{ Field string }
+
+func (e *validationError) Error() string { return e.Field + " is invalid" }
+
+func newValidationError(field string) *validationError {
+	return &validationError{Field: field}
+}
+
+func check(field string) error {
+	if field == "" {
+		return errors.New("empty field")
+	}
+	return newValidationError(field)
+}
+
+// 多值调用里的指针结果同样只在被调函数会返回 nil 时才报告。
+func parse(s string) (*validationError, int) {
+	return &validationError{Field: s}, len(s)
+}
+
+func parseAll(s string) (error, int) {
+	return parse(s)
+}
+
+func findTuple(name string) (*MyErr, int) {
+	return nil, 0
+}
+
+func findTupleWrapped(name string) (error, int) {
+	return findTuple(name) // want `returning the \*MyErr result of a call as error`
+}
+
+// 从零值切片开始 append，返回时已经不是 nil。
+func collect(n int) any {
+	var out []int
+	for i := 0; i < n; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+func collectMap(keys []string) interface{} {
+	var m map[string]bool
+	if len(keys) > 0 {
+		m = make(map[string]bool)
+	}
+	return m
+}
@@ -0,0 +1,83 @@
+// Package nilcheck 用反射回答“这个值是不是 nil”（第27章 nil 陷阱的运行时工具）。
+//
+// chap27/nil.go 里的 isNil 只认识 *int、[]int、map[string]int、chan int 和 func()，
+// 其他类型的 nil 指针一律返回 false。这里的 IsNil 覆盖所有能为 nil 的 Kind；
+// Describe 把接口变量拆成 (类型, 值) 两部分，说明是接口本身为 nil，还是接口里装着一个 nil 值
+// （getNilInterface 演示的陷阱）。静态检查见子包 analyzer。
+package nilcheck
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Nillable 报告 Kind 为 k 的值能否为 nil：指针、切片、映射、通道、函数、接口和 unsafe.Pointer。
+func Nillable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Chan,
+		reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// IsNil 报告 v 是否为 nil：接口本身为 nil，或者接口里装的是某个类型的 nil 值。
+// 不能为 nil 的类型（int、string、结构体、数组……）总是返回 false，不会像 reflect.Value.IsNil 那样 panic。
+func IsNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	return valueIsNil(reflect.ValueOf(v))
+}
+
+// valueIsNil 是 IsNil 的 reflect.Value 版本，对不能为 nil 的 Kind 返回 false。
+func valueIsNil(rv reflect.Value) bool {
+	if !rv.IsValid() {
+		return true
+	}
+	if !Nillable(rv.Kind()) {
+		return false
+	}
+	return rv.IsNil()
+}
+
+// Description 是 Describe 的结果：接口变量 (type, value) 两部分各自的情况。
+type Description struct {
+	Type         reflect.Type // 动态类型；接口本身为 nil 时是 nil
+	Kind         reflect.Kind // 动态类型的 Kind；接口本身为 nil 时是 reflect.Invalid
+	InterfaceNil bool         // v == nil：既没有类型也没有值
+	ValueNil     bool         // 动态值是 nil；InterfaceNil 时也为 true
+	Nillable     bool         // 动态类型的值能否为 nil
+}
+
+// TypedNil 报告接口不是 nil、但里面装的值是 nil：v == nil 为 false，IsNil(v) 为 true。
+func (d Description) TypedNil() bool {
+	return !d.InterfaceNil && d.ValueNil
+}
+
+// String 返回一行说明，例如 "*int (ptr): 接口非 nil，值为 nil"。
+func (d Description) String() string {
+	switch {
+	case d.InterfaceNil:
+		return "<nil>: 接口为 nil"
+	case d.ValueNil:
+		return fmt.Sprintf("%v (%v): 接口非 nil，值为 nil", d.Type, d.Kind)
+	case d.Nillable:
+		return fmt.Sprintf("%v (%v): 接口非 nil，值非 nil", d.Type, d.Kind)
+	}
+	return fmt.Sprintf("%v (%v): 接口非 nil，该类型不能为 nil", d.Type, d.Kind)
+}
+
+// Describe 拆开接口变量 v，报告它的动态类型、Kind，以及接口和值分别是否为 nil。
+func Describe(v any) Description {
+	if v == nil {
+		return Description{InterfaceNil: true, ValueNil: true}
+	}
+	rv := reflect.ValueOf(v)
+	return Description{
+		Type:     rv.Type(),
+		Kind:     rv.Kind(),
+		ValueNil: valueIsNil(rv),
+		Nillable: Nillable(rv.Kind()),
+	}
+}
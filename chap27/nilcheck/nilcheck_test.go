@@ -0,0 +1,113 @@
+package nilcheck
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+type MyErr struct{ Msg string }
+
+func (e *MyErr) Error() string { return e.Msg }
+
+type Point struct{ X, Y int }
+
+// getNilInterface 与 chap27/nil.go 中的同名函数相同。
+func getNilInterface() interface{} {
+	var nilPtr *int
+	return nilPtr
+}
+
+// validate 是经典的 error 陷阱：成功时返回的是装着 nil *MyErr 的 error。
+func validate(name string) error {
+	var err *MyErr
+	if name == "" {
+		err = &MyErr{Msg: "name is empty"}
+	}
+	return err
+}
+
+func TestIsNil(t *testing.T) {
+	var (
+		pInt   *int
+		pPoint *Point
+		pErr   *MyErr
+		sl     []string
+		m      map[int]bool
+		ch     chan<- struct{}
+		fn     func(int) error
+		up     unsafe.Pointer
+		err    error
+	)
+	for _, tc := range []struct {
+		name string
+		v    any
+		want bool
+	}{
+		{"nil interface", nil, true},
+		{"*int", pInt, true},
+		{"*Point", pPoint, true},
+		{"*MyErr", pErr, true},
+		{"[]string", sl, true},
+		{"map[int]bool", m, true},
+		{"chan<- struct{}", ch, true},
+		{"func(int) error", fn, true},
+		{"unsafe.Pointer", up, true},
+		{"nil error variable", err, true},
+		{"&Point{}", &Point{}, false},
+		{"[]string{}", []string{}, false},
+		{"make(map)", make(map[int]bool), false},
+		{"int", 0, false},
+		{"string", "", false},
+		{"Point{}", Point{}, false},
+		{"[0]int{}", [0]int{}, false},
+	} {
+		if got := IsNil(tc.v); got != tc.want {
+			t.Errorf("IsNil(%s) = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	var nilErr error
+	for _, tc := range []struct {
+		name     string
+		v        any
+		typedNil bool
+		text     string
+	}{
+		{"var err error", nilErr, false, "<nil>: 接口为 nil"},
+		{"getNilInterface()", getNilInterface(), true, "*int (ptr): 接口非 nil，值为 nil"},
+		{`validate("gopher")`, validate("gopher"), true, "*nilcheck.MyErr (ptr): 接口非 nil，值为 nil"},
+		{`validate("")`, validate(""), false, "*nilcheck.MyErr (ptr): 接口非 nil，值非 nil"},
+		{"42", 42, false, "int (int): 接口非 nil，该类型不能为 nil"},
+		{"[]int{}", []int{}, false, "[]int (slice): 接口非 nil，值非 nil"},
+	} {
+		d := Describe(tc.v)
+		if d.TypedNil() != tc.typedNil || d.String() != tc.text {
+			t.Errorf("Describe(%s) = %q (TypedNil %t), want %q (%t)", tc.name, d, d.TypedNil(), tc.text, tc.typedNil)
+		}
+	}
+
+	result := getNilInterface()
+	d := Describe(result)
+	if result == nil || d.InterfaceNil || d.Type != reflect.TypeOf((*int)(nil)) || d.Kind != reflect.Pointer || !d.ValueNil {
+		t.Errorf("getNilInterface: %+v", d)
+	}
+	if d := Describe(nil); !d.InterfaceNil || !d.ValueNil || d.Type != nil || d.Kind != reflect.Invalid {
+		t.Errorf("Describe(nil) = %+v", d)
+	}
+}
+
+func TestNillable(t *testing.T) {
+	for k := reflect.Invalid; k <= reflect.UnsafePointer; k++ {
+		want := false
+		switch k {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+			want = true
+		}
+		if Nillable(k) != want {
+			t.Errorf("Nillable(%v) = %t", k, !want)
+		}
+	}
+}
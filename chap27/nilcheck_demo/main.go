@@ -0,0 +1,154 @@
+// 独立运行：go run ./chap27/nilcheck_demo
+// 演示：nilcheck.IsNil 覆盖所有能为 nil 的类型（chap27/nil.go 原来的 isNil 只认识五种），
+// nilcheck.Describe 把接口拆成 (类型, 值) 两部分，解释 getNilInterface 的陷阱。
+// 断言见 go test ./chap27/nilcheck/...。
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"books/chap27/nilcheck"
+)
+
+// pad 把 s 补齐到 width 个显示列，中文字符按两列计算。
+func pad(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	for ; w < width; w++ {
+		s += " "
+	}
+	return s
+}
+
+type MyErr struct{ Msg string }
+
+func (e *MyErr) Error() string { return e.Msg }
+
+type Point struct{ X, Y int }
+
+// getNilInterface 与 chap27/nil.go 中的同名函数相同。
+func getNilInterface() interface{} {
+	var nilPtr *int
+	return nilPtr
+}
+
+// validate 是经典的 error 陷阱：成功时返回的是装着 nil *MyErr 的 error。
+// go vet -vettool=nilcheck 会报告这一行，见 chap27/nilcheck_vet。
+func validate(name string) error {
+	var err *MyErr
+	if name == "" {
+		err = &MyErr{Msg: "name is empty"}
+	}
+	return err
+}
+
+func main() {
+	isNil()
+	describe()
+	errorTrap()
+}
+
+func isNil() {
+	fmt.Println("=== 1. IsNil：每一种能为 nil 的类型 ===")
+	var (
+		pInt   *int
+		pPoint *Point
+		pErr   *MyErr
+		sl     []string
+		m      map[int]bool
+		ch     chan<- struct{}
+		fn     func(int) error
+		up     unsafe.Pointer
+		err    error
+	)
+	cases := []struct {
+		name string
+		v    any
+	}{
+		{"接口本身为 nil", nil},
+		{"*int", pInt},
+		{"*Point", pPoint},
+		{"*MyErr", pErr},
+		{"[]string", sl},
+		{"map[int]bool", m},
+		{"chan<- struct{}", ch},
+		{"func(int) error", fn},
+		{"unsafe.Pointer", up},
+		{"nil error 变量", err},
+		{"&Point{}", &Point{}},
+		{"[]string{}", []string{}},
+		{"make(map)", make(map[int]bool)},
+		{"0（int）", 0},
+		{"\"\"（string）", ""},
+		{"Point{}", Point{}},
+		{"[0]int{}", [0]int{}},
+	}
+	fmt.Printf("  %s %s\n", pad("值", 18), "原 isNil / nilcheck.IsNil")
+	for _, c := range cases {
+		fmt.Printf("  %s %-5t / %t\n", pad(c.name, 18), oldIsNil(c.v), nilcheck.IsNil(c.v))
+	}
+	fmt.Println("  说明：原 isNil 对 *Point、*MyErr、[]string 等都返回 false，IsNil 不依赖类型列表")
+	fmt.Println()
+}
+
+func describe() {
+	fmt.Println("=== 2. Describe：接口的 (类型, 值) ===")
+	var nilErr error
+	result := getNilInterface()
+	cases := []struct {
+		name string
+		v    any
+	}{
+		{"var err error", nilErr},
+		{"getNilInterface()", result},
+		{"validate(\"gopher\")", validate("gopher")},
+		{"validate(\"\")", validate("")},
+		{"42", 42},
+		{"[]int{}", []int{}},
+	}
+	for _, c := range cases {
+		d := nilcheck.Describe(c.v)
+		fmt.Printf("  %s %v（TypedNil=%t）\n", pad(c.name, 20), d, d.TypedNil())
+	}
+
+	d := nilcheck.Describe(result)
+	fmt.Printf("  getNilInterface() != nil 为 %t，但动态类型是 %v、值是 nil\n", result != nil, d.Type)
+	fmt.Println()
+}
+
+func errorTrap() {
+	fmt.Println("=== 3. error 陷阱 ===")
+	err := validate("gopher")
+	fmt.Printf("  validate(\"gopher\") 成功了，但 err != nil 为 %t\n", err != nil)
+	fmt.Printf("  nilcheck.IsNil(err) = %t：接口里装着 nil *MyErr\n", nilcheck.IsNil(err))
+	fmt.Println("  修复：函数签名声明 error 时，成功路径直接 return nil，不要返回具体类型的变量")
+	fmt.Println("  静态检查：go build -o nilcheck ./chap27/nilcheck_vet && go vet -vettool=$(pwd)/nilcheck ./chap27/nilcheck_demo")
+}
+
+// oldIsNil 是 chap27/nil.go 改用 nilcheck 之前的 isNil，用来对比。
+func oldIsNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := v.(type) {
+	case *int:
+		return val == nil
+	case []int:
+		return val == nil
+	case map[string]int:
+		return val == nil
+	case chan int:
+		return val == nil
+	case func():
+		return val == nil
+	default:
+		return false
+	}
+}
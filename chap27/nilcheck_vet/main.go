@@ -0,0 +1,15 @@
+// 作为 vet 工具：go build -o nilcheck ./chap27/nilcheck_vet && go vet -vettool=$(pwd)/nilcheck ./chap27
+// 检查示例陷阱：go vet -vettool=$(pwd)/nilcheck ./chap27/nilcheck/analyzer/testdata/src/typednil
+// 也可以直接 go run ./chap27/nilcheck_vet <包>，但这种方式要求 x/tools 能读取当前工具链的导出数据。
+// 演示：把 chap27/nilcheck/analyzer 包装成命令行工具，检查通过 error 或其他接口返回的带类型 nil。
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"books/chap27/nilcheck/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,476 @@
+// Package config 把 JSON、INI 文件和环境变量按固定的优先级加载到结构体中（第28章 loadConfig 的完整版本）。
+//
+// chap28 的 loadConfig 只检查文件是否存在，然后返回写死的 &Config{Host: "localhost", Port: 8080}。
+// 这里由结构体标签描述配置项：
+//
+//	type Server struct {
+//		Host    string        `config:"host" default:"localhost"`
+//		Port    int           `config:"port" default:"8080" validate:"min=1,max=65535"`
+//		Timeout time.Duration `default:"5s"`          // 键名默认为字段名的 snake_case：timeout
+//		Tags    []string      `env:"SERVER_TAGS"`     // 显式指定环境变量名
+//	}
+//
+// 嵌套结构体对应 JSON 的嵌套对象、INI 的 [section] 和环境变量名中的一段，键写作 server.port。
+// 优先级从低到高：default 标签 < Files 中的文件（按顺序，后面的覆盖前面的）< 环境变量。
+//
+// Load 收集所有问题后一起返回：语法错误、未知的键、无法转换的值，以及 validate 标签（chap28/validate）
+// 校验失败的字段；每个问题都是 *Error，带有值来自的位置，例如 app.ini:12 或 $APP_SERVER_PORT。
+// 热加载见 Watch。
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"books/chap28/validate"
+)
+
+var (
+	// ErrSyntax 表示配置文件的语法错误。
+	ErrSyntax = errors.New("config: syntax error")
+	// ErrUnknownKey 表示文件中出现了结构体里没有的键（通常是拼写错误）。
+	ErrUnknownKey = errors.New("config: unknown key")
+	// ErrInvalidValue 表示值无法转换成字段的类型。
+	ErrInvalidValue = errors.New("config: invalid value")
+	// ErrUnsupported 表示结构体中有不支持的字段类型，或者传入 Load 的不是结构体指针。
+	ErrUnsupported = errors.New("config: unsupported")
+)
+
+// Source 是一个值的来源：文件和行号、环境变量（$NAME）、default 标签，或者 unset（没有任何来源，使用零值）。
+type Source struct {
+	Name string
+	Line int // 文件中的行号，从 1 开始；不是文件时为 0
+}
+
+func (s Source) String() string {
+	if s.Line > 0 {
+		return s.Name + ":" + strconv.Itoa(s.Line)
+	}
+	return s.Name
+}
+
+// Sources 记录每个配置键的最终取值来自哪里。
+type Sources map[string]Source
+
+// Error 是加载配置时的一个问题。
+type Error struct {
+	Source Source
+	Key    string // 配置键，例如 server.port；与具体的键无关时为空
+	Err    error
+}
+
+func (e *Error) Error() string {
+	s := e.Source.String()
+	if e.Key != "" {
+		s += ": " + e.Key
+	}
+	return s + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Errors 取出 Load 返回的错误中所有的 *Error。
+func Errors(err error) []*Error {
+	var out []*Error
+	var walk func(error)
+	walk = func(err error) {
+		if e, ok := err.(*Error); ok {
+			out = append(out, e)
+			return
+		}
+		if u, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range u.Unwrap() {
+				walk(e)
+			}
+		}
+	}
+	walk(err)
+	return out
+}
+
+// Loader 描述从哪里加载配置。零值只使用 default 标签。
+type Loader struct {
+	// Files 按顺序叠加，后面的覆盖前面的。扩展名为 .json 的按 JSON 解析，其余按 INI 解析。
+	// 文件不存在时 Load 返回 *fs.PathError。
+	Files []string
+	// EnvPrefix 不为空时，每个配置键对应环境变量 前缀_键（大写，点换成下划线），
+	// 例如 APP + server.port → APP_SERVER_PORT。env 标签指定的变量名不受它影响，总是会读取。
+	EnvPrefix string
+	// Environ 返回环境变量，为 nil 时使用 os.Environ；测试中可以替换成固定的列表。
+	Environ func() []string
+}
+
+// field 是结构体中的一个配置项。
+type field struct {
+	index  []int  // reflect 的字段下标路径
+	key    string // 配置键，例如 server.port
+	goPath string // Go 字段路径，例如 Server.Port，用于对应 validate 的错误
+	env    string // env 标签指定的变量名
+	def    string
+	hasDef bool
+}
+
+// value 是从某个来源读到的原始值。
+type value struct {
+	text   string
+	list   []string
+	isList bool
+	src    Source
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Load 按优先级把配置加载到 dst（结构体指针），返回每个键的来源。
+// 有任何错误时 dst 保持不变，返回的错误由 errors.Join 合并，用 Errors 取出每一个 *Error。
+func (l *Loader) Load(dst any) (Sources, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: Load needs a non-nil struct pointer, got %T", ErrUnsupported, dst)
+	}
+	fields, err := schema(rv.Elem().Type())
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*field, len(fields))
+	for i := range fields {
+		byKey[fields[i].key] = &fields[i]
+	}
+
+	var errs []error
+	values := make(map[string]value)
+	for _, f := range fields {
+		if f.hasDef {
+			values[f.key] = value{text: f.def, src: Source{Name: "default"}}
+		}
+	}
+	for _, path := range l.Files {
+		vals, ferrs, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, ferrs...)
+		keys := make([]string, 0, len(vals))
+		for k := range vals {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return vals[keys[i]].src.Line < vals[keys[j]].src.Line })
+		for _, k := range keys {
+			if byKey[k] == nil {
+				errs = append(errs, &Error{Source: vals[k].src, Key: k, Err: ErrUnknownKey})
+				continue
+			}
+			values[k] = vals[k]
+		}
+	}
+	for k, v := range l.env(fields) {
+		values[k] = v
+	}
+
+	fresh := reflect.New(rv.Elem().Type()).Elem()
+	sources := make(Sources, len(values))
+	badKeys := make(map[string]bool)
+	for _, f := range fields {
+		v, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		sources[f.key] = v.src
+		if err := assign(fresh.FieldByIndex(f.index), v); err != nil {
+			errs = append(errs, &Error{Source: v.src, Key: f.key, Err: err})
+			badKeys[f.key] = true
+		}
+	}
+	errs = append(errs, validationErrors(fresh, fields, sources, badKeys)...)
+	if len(errs) > 0 {
+		l.sortErrors(errs)
+		return sources, errors.Join(errs...)
+	}
+	rv.Elem().Set(fresh)
+	return sources, nil
+}
+
+// sortErrors 按来源排列错误：default 标签、Files 中的文件（按顺序，同一文件内按行号）、环境变量、unset。
+func (l *Loader) sortErrors(errs []error) {
+	rank := func(err error) (int, int) {
+		src := err.(*Error).Source
+		switch {
+		case src.Name == "default":
+			return -1, 0
+		case src.Name == "unset":
+			return len(l.Files) + 1, 0
+		case strings.HasPrefix(src.Name, "$"):
+			return len(l.Files), 0
+		}
+		for i, f := range l.Files {
+			if f == src.Name {
+				return i, src.Line
+			}
+		}
+		return len(l.Files), 0
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		fi, li := rank(errs[i])
+		fj, lj := rank(errs[j])
+		return fi < fj || fi == fj && li < lj
+	})
+}
+
+// env 读取环境变量中出现的配置键。
+func (l *Loader) env(fields []field) map[string]value {
+	environ := l.Environ
+	if environ == nil {
+		environ = os.Environ
+	}
+	vars := make(map[string]string)
+	for _, kv := range environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			vars[k] = v
+		}
+	}
+	out := make(map[string]value)
+	for _, f := range fields {
+		names := []string{}
+		if l.EnvPrefix != "" {
+			names = append(names, l.EnvPrefix+"_"+strings.ToUpper(strings.ReplaceAll(f.key, ".", "_")))
+		}
+		if f.env != "" {
+			names = append(names, f.env) // 显式指定的变量名优先
+		}
+		for _, name := range names {
+			if v, ok := vars[name]; ok {
+				out[f.key] = value{text: v, src: Source{Name: "$" + name}}
+			}
+		}
+	}
+	return out
+}
+
+// validationErrors 用 chap28/validate 校验结构体，把每个违规对应到它的值的来源。
+// 已经转换失败的键（badKeys）不再重复报告。
+func validationErrors(v reflect.Value, fields []field, sources Sources, badKeys map[string]bool) []error {
+	err := validate.Struct(v.Addr().Interface())
+	if err == nil {
+		return nil
+	}
+	keyOf := make(map[string]string, len(fields))
+	for _, f := range fields {
+		keyOf[f.goPath] = f.key
+	}
+	var errs []error
+	for _, fe := range validate.Fields(err) {
+		// validate 的路径以类型名开头，并且可能带有 [i] 下标：Config.Server.Tags[2]
+		path := fe.Path
+		if _, rest, ok := strings.Cut(path, "."); ok {
+			path = rest
+		}
+		if i := strings.IndexByte(path, '['); i >= 0 {
+			path = path[:i]
+		}
+		key := keyOf[path]
+		if badKeys[key] {
+			continue
+		}
+		src, ok := sources[key]
+		if !ok {
+			src = Source{Name: "unset"} // 没有任何来源设置过，使用的是零值
+		}
+		errs = append(errs, &Error{Source: src, Key: key, Err: violation{fe}})
+	}
+	return errs
+}
+
+// violation 包装 validate 的 *FieldError：文字中不再重复字段路径（*Error 已经带有配置键），
+// errors.As 仍然可以取出 *FieldError。
+type violation struct{ fe *validate.FieldError }
+
+func (v violation) Error() string { return v.fe.Err.Error() }
+func (v violation) Unwrap() error { return v.fe }
+
+// schema 列出结构体类型 t 中的所有配置项。
+func schema(t reflect.Type) ([]field, error) {
+	var out []field
+	var walk func(t reflect.Type, index []int, keyPrefix, goPrefix string) error
+	walk = func(t reflect.Type, index []int, keyPrefix, goPrefix string) error {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, ok := sf.Tag.Lookup("config")
+			if !sf.IsExported() || name == "-" {
+				continue
+			}
+			if !ok || name == "" {
+				name = snakeCase(sf.Name)
+			}
+			f := field{
+				index:  append(append([]int(nil), index...), i),
+				key:    keyPrefix + name,
+				goPath: goPrefix + sf.Name,
+				env:    sf.Tag.Get("env"),
+			}
+			f.def, f.hasDef = sf.Tag.Lookup("default")
+			if isSection(sf.Type) {
+				if err := walk(sf.Type, f.index, f.key+".", f.goPath+"."); err != nil {
+					return err
+				}
+				continue
+			}
+			if !supported(sf.Type) {
+				return fmt.Errorf("%w: field %s has type %s", ErrUnsupported, f.goPath, sf.Type)
+			}
+			out = append(out, f)
+		}
+		return nil
+	}
+	if err := walk(t, nil, "", ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// isSection 报告 t 是否按嵌套的配置节处理：结构体，但不是 time.Time 这类自己会解析文本的类型。
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func supported(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && supported(t.Elem())
+	}
+	return false
+}
+
+// snakeCase 把 MaxConns、HTTPPort 转换成 max_conns、http_port。
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// assign 把原始值转换后写入字段。
+func assign(dst reflect.Value, v value) error {
+	if dst.Kind() == reflect.Slice && !dst.Addr().Type().Implements(textUnmarshalerType) {
+		items := v.list
+		if !v.isList {
+			items = splitList(v.text)
+		}
+		s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parseScalar(s.Index(i), item); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		dst.Set(s)
+		return nil
+	}
+	if v.isList {
+		return fmt.Errorf("%w: got a list for %s", ErrInvalidValue, dst.Type())
+	}
+	return parseScalar(dst, v.text)
+}
+
+// splitList 把 "a, b ,c" 拆成列表，空字符串得到空列表。
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func parseScalar(dst reflect.Value, text string) error {
+	if u, ok := dst.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(text)); err != nil {
+			return fmt.Errorf("%w: %q: %v", ErrInvalidValue, text, err)
+		}
+		return nil
+	}
+	invalid := func() error {
+		return fmt.Errorf("%w: %q is not a valid %s", ErrInvalidValue, text, dst.Type())
+	}
+	if dst.Type() == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return invalid()
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return invalid()
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 0, dst.Type().Bits())
+		if err != nil {
+			return invalid()
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 0, dst.Type().Bits())
+		if err != nil {
+			return invalid()
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, dst.Type().Bits())
+		if err != nil {
+			return invalid()
+		}
+		dst.SetFloat(n)
+	default:
+		return fmt.Errorf("%w: type %s", ErrUnsupported, dst.Type())
+	}
+	return nil
+}
+
+// parseFile 按扩展名解析一个文件，返回扁平的键值、语法错误，以及读取失败的错误。
+func parseFile(path string) (map[string]value, []error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		vals, errs := parseJSON(path, data)
+		return vals, errs, nil
+	}
+	vals, errs := parseINI(path, data)
+	return vals, errs, nil
+}
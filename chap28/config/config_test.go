@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"books/chap28/validate"
+)
+
+// 与 config_demo 相同的配置结构。
+
+type ServerConfig struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080" validate:"min=1,max=65535"`
+	Timeout time.Duration `default:"5s"`
+}
+
+type DBConfig struct {
+	DSN      string `config:"dsn" validate:"required"`
+	MaxConns int    `default:"10" validate:"min=1"`
+}
+
+type AppConfig struct {
+	Name   string `default:"demo" validate:"required"`
+	Debug  bool
+	Server ServerConfig
+	DB     DBConfig `config:"database"`
+	Tags   []string `env:"DEPLOY_TAGS"`
+}
+
+// env 返回固定的环境变量列表，代替 os.Environ。
+func env(kv ...string) func() []string {
+	return func() []string { return kv }
+}
+
+// write 把内容写入 dir 中的文件并返回路径。
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	var cfg ServerConfig
+	l := Loader{Environ: env()}
+	src, err := l.Load(&cfg)
+	if err != nil || cfg != (ServerConfig{Host: "localhost", Port: 8080, Timeout: 5 * time.Second}) {
+		t.Errorf("Load = %+v, %v", cfg, err)
+	}
+	if src["port"].String() != "default" {
+		t.Errorf("Sources[port] = %s", src["port"])
+	}
+
+	var app AppConfig
+	_, err = l.Load(&app)
+	if es := Errors(err); len(es) != 1 || es[0].Key != "database.dsn" || es[0].Source.Name != "unset" {
+		t.Errorf("required field without a source: %v", err)
+	}
+	if app.Name != "" {
+		t.Error("target modified on error")
+	}
+
+	if _, err := l.Load(cfg); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Load(non-pointer) = %v", err)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := write(t, dir, "base.json", `{
+  "name": "shop",
+  "server": {
+    "host": "0.0.0.0",
+    "port": 9000
+  },
+  "database": {"dsn": "postgres://db/shop", "max_conns": 20},
+  "tags": ["base", "json"]
+}
+`)
+	local := write(t, dir, "local.ini", `# 本机覆盖
+debug = true
+
+[server]
+port = 9001          ; 行尾注释
+timeout = 1m30s
+
+[database]
+dsn = "postgres://localhost/shop?sslmode=disable"
+`)
+	l := Loader{
+		Files:     []string{base, local},
+		EnvPrefix: "SHOP",
+		Environ:   env("SHOP_SERVER_PORT=9100", "DEPLOY_TAGS=blue, canary", "PATH=/usr/bin"),
+	}
+	var cfg AppConfig
+	src, err := l.Load(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AppConfig{
+		Name:   "shop",
+		Debug:  true,
+		Server: ServerConfig{Host: "0.0.0.0", Port: 9100, Timeout: 90 * time.Second},
+		DB:     DBConfig{DSN: "postgres://localhost/shop?sslmode=disable", MaxConns: 20},
+		Tags:   []string{"blue", "canary"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load =\n%+v, want\n%+v", cfg, want)
+	}
+	for key, from := range map[string]string{
+		"name":               base + ":2",
+		"server.host":        base + ":4",
+		"database.max_conns": base + ":7",
+		"debug":              local + ":2",
+		"server.timeout":     local + ":6",
+		"database.dsn":       local + ":9",
+		"server.port":        "$SHOP_SERVER_PORT",
+		"tags":               "$DEPLOY_TAGS",
+	} {
+		if got := src[key].String(); got != from {
+			t.Errorf("Sources[%s] = %s, want %s", key, got, from)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := write(t, dir, "bad.ini", `name = ""
+[server]
+port = 70000
+timeout = soon
+prot = 8080
+this line is wrong
+[database]
+max_conns = 0
+tags = [a, "b
+`)
+	var cfg AppConfig
+	_, err := (&Loader{Files: []string{bad}, Environ: env("DEPLOY_TAGS=x")}).Load(&cfg)
+	es := Errors(err)
+	where := make([]string, len(es))
+	for i, e := range es {
+		where[i] = strings.TrimPrefix(e.Source.String(), dir+string(filepath.Separator))
+	}
+	if got := strings.Join(where, " "); got != "bad.ini:1 bad.ini:3 bad.ini:4 bad.ini:5 bad.ini:6 bad.ini:8 bad.ini:9 unset" {
+		t.Errorf("error locations = %s", got)
+	}
+	for _, target := range []error{ErrSyntax, ErrUnknownKey, ErrInvalidValue} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(err, %v) = false", target)
+		}
+	}
+	var fe *validate.FieldError
+	if !errors.As(err, &fe) || fe.Rule != "required" {
+		t.Errorf("errors.As *validate.FieldError = %v", fe)
+	}
+
+	badJSON := write(t, dir, "bad.json", "{\n  \"name\": \"x\",\n  \"server\": {\"port\": 80,}\n}\n")
+	_, err = (&Loader{Files: []string{badJSON}, Environ: env()}).Load(&cfg)
+	if es := Errors(err); len(es) == 0 || es[0].Source.Line != 3 || !errors.Is(es[0], ErrSyntax) {
+		t.Errorf("JSON syntax error: %v", err)
+	}
+
+	dup := write(t, dir, "dup.json", "{\n  \"name\": \"a\",\n  \"name\": \"b\"\n}\n")
+	_, err = (&Loader{Files: []string{dup}, Environ: env()}).Load(&cfg)
+	if es := Errors(err); len(es) == 0 || !strings.HasSuffix(es[0].Error(), "dup.json:3: name: config: syntax error: duplicate key (first set at line 2)") {
+		t.Errorf("duplicate key: %v", err)
+	}
+
+	_, err = (&Loader{Files: []string{filepath.Join(dir, "missing.ini")}, Environ: env()}).Load(&cfg)
+	if !os.IsNotExist(err) {
+		t.Errorf("missing file: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseINI 解析类似 TOML 的 INI 文件：
+//
+//	# 注释，也可以用 ;
+//	name = "demo"          # 行尾注释
+//	[server]
+//	port = 8080
+//	tags = [a, "b c"]
+//	[server.tls]           # 嵌套的节用点分隔
+//	cert = /etc/app.pem
+//
+// 返回扁平的键值（server.port），每个值带有行号；语法错误会继续解析后面的行，全部收集后返回。
+func parseINI(name string, data []byte) (map[string]value, []error) {
+	vals := make(map[string]value)
+	var errs []error
+	fail := func(line int, key string, format string, args ...any) {
+		errs = append(errs, &Error{
+			Source: Source{Name: name, Line: line},
+			Key:    key,
+			Err:    fmt.Errorf("%w: "+format, append([]any{ErrSyntax}, args...)...),
+		})
+	}
+
+	section := ""
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			rest := ""
+			if end >= 0 {
+				rest = stripComment(line[end+1:])
+			}
+			if end < 0 || rest != "" {
+				fail(lineNo, "", "malformed section header %q", line)
+				continue
+			}
+			section = strings.TrimSpace(line[1:end])
+			if section == "" {
+				fail(lineNo, "", "empty section name")
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		key := strings.TrimSpace(k)
+		if !ok || key == "" {
+			fail(lineNo, "", "expected key = value, got %q", line)
+			continue
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		val, err := parseINIValue(strings.TrimSpace(v))
+		if err != nil {
+			fail(lineNo, key, "%v", err)
+			continue
+		}
+		if prev, dup := vals[key]; dup {
+			fail(lineNo, key, "duplicate key (first set at line %d)", prev.src.Line)
+			continue
+		}
+		val.src = Source{Name: name, Line: lineNo}
+		vals[key] = val
+	}
+	return vals, errs
+}
+
+// parseINIValue 解析等号右边的部分：带引号的字符串、[a, b] 列表或裸文本，并去掉行尾注释。
+func parseINIValue(s string) (value, error) {
+	if strings.HasPrefix(s, `"`) {
+		text, rest, err := unquotePrefix(s)
+		if err != nil {
+			return value{}, err
+		}
+		if stripComment(rest) != "" {
+			return value{}, fmt.Errorf("unexpected text after string: %q", rest)
+		}
+		return value{text: text}, nil
+	}
+	if strings.HasPrefix(s, "[") {
+		return parseINIList(s)
+	}
+	return value{text: stripComment(s)}, nil
+}
+
+// parseINIList 解析 [a, "b, c", d]，末尾可以有逗号。
+func parseINIList(s string) (value, error) {
+	v := value{isList: true, list: []string{}}
+	rest := strings.TrimSpace(s[1:])
+	for {
+		if strings.HasPrefix(rest, "]") {
+			if stripComment(rest[1:]) != "" {
+				return value{}, fmt.Errorf("unexpected text after list: %q", rest[1:])
+			}
+			return v, nil
+		}
+		if rest == "" {
+			return value{}, fmt.Errorf("unterminated list")
+		}
+		var item string
+		if strings.HasPrefix(rest, `"`) {
+			text, after, err := unquotePrefix(rest)
+			if err != nil {
+				return value{}, err
+			}
+			item, rest = text, strings.TrimSpace(after)
+		} else {
+			end := strings.IndexAny(rest, ",]")
+			if end < 0 {
+				return value{}, fmt.Errorf("unterminated list")
+			}
+			item, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+		v.list = append(v.list, item)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			rest = strings.TrimSpace(rest[1:])
+		case strings.HasPrefix(rest, "]"):
+		default:
+			return value{}, fmt.Errorf("expected , or ] in list, got %q", rest)
+		}
+	}
+}
+
+// unquotePrefix 解析 s 开头的 Go 风格双引号字符串，返回内容和其余部分。
+func unquotePrefix(s string) (text, rest string, err error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			text, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return text, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string %s", s)
+}
+
+// stripComment 去掉未加引号文本的行尾注释（# 或 ; 前面需要有空白）和首尾空白。
+func stripComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		if (s[i] == '#' || s[i] == ';') && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			s = s[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(s)
+}
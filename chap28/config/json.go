@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// parseJSON 把 JSON 对象展开成扁平的键值：{"server": {"port": 8080}} 得到 server.port = "8080"。
+// 数组只能包含字符串、数字和布尔值；null 表示没有设置。每个值记录它的键所在的行。
+// JSON 语法错误无法继续解析，只返回这一个错误。
+func parseJSON(name string, data []byte) (map[string]value, []error) {
+	p := &jsonParser{name: name, data: data, dec: json.NewDecoder(bytes.NewReader(data)), vals: make(map[string]value)}
+	p.dec.UseNumber()
+	if err := p.parse(); err != nil {
+		var se *json.SyntaxError
+		line := p.line(p.dec.InputOffset())
+		if errors.As(err, &se) {
+			line = p.line(se.Offset)
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			err = errors.New("unexpected end of JSON input")
+		}
+		p.errs = append(p.errs, &Error{Source: Source{Name: name, Line: line}, Err: fmt.Errorf("%w: %v", ErrSyntax, err)})
+	}
+	return p.vals, p.errs
+}
+
+type jsonParser struct {
+	name string
+	data []byte
+	dec  *json.Decoder
+	vals map[string]value
+	errs []error
+}
+
+// line 把字节偏移换算成行号。
+func (p *jsonParser) line(off int64) int {
+	off = min(max(off, 0), int64(len(p.data)))
+	return 1 + bytes.Count(p.data[:off], []byte("\n"))
+}
+
+func (p *jsonParser) parse() error {
+	tok, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("top-level value must be an object, got %v", tok)
+	}
+	if err := p.object(""); err != nil {
+		return err
+	}
+	if _, err := p.dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after top-level object")
+	}
+	return nil
+}
+
+// object 在读过 { 之后解析对象的成员，直到 }。
+func (p *jsonParser) object(prefix string) error {
+	for p.dec.More() {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + tok.(string) // More 为 true 时对象中的下一个 token 一定是键
+		line := p.line(p.dec.InputOffset())
+		if err := p.value(key, line); err != nil {
+			return err
+		}
+	}
+	_, err := p.dec.Token() // }
+	return err
+}
+
+func (p *jsonParser) value(key string, line int) error {
+	tok, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	src := Source{Name: p.name, Line: line}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return p.object(key + ".")
+		}
+		list := []string{}
+		for p.dec.More() {
+			tok, err := p.dec.Token()
+			if err != nil {
+				return err
+			}
+			s, ok := scalarText(tok)
+			if !ok {
+				return fmt.Errorf("%s: arrays may only contain strings, numbers and booleans", key)
+			}
+			list = append(list, s)
+		}
+		if _, err := p.dec.Token(); err != nil { // ]
+			return err
+		}
+		p.set(key, value{list: list, isList: true, src: src})
+	case nil:
+		// null：不设置，保留更低优先级来源的值
+	default:
+		s, _ := scalarText(tok)
+		p.set(key, value{text: s, src: src})
+	}
+	return nil
+}
+
+func (p *jsonParser) set(key string, v value) {
+	if prev, dup := p.vals[key]; dup {
+		p.errs = append(p.errs, &Error{Source: v.src, Key: key,
+			Err: fmt.Errorf("%w: duplicate key (first set at line %d)", ErrSyntax, prev.src.Line)})
+		return
+	}
+	p.vals[key] = v
+}
+
+// scalarText 把字符串、数字和布尔值 token 转换成文本。
+func scalarText(tok json.Token) (string, bool) {
+	switch t := tok.(type) {
+	case string:
+		return t, true
+	case json.Number:
+		return t.String(), true
+	case bool:
+		if t {
+			return "true", true
+		}
+		return "false", true
+	}
+	return "", false
+}
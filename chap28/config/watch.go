@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher 在配置文件变化后重新加载，并通知订阅者。
+// 通过轮询文件的修改时间和大小发现变化，不依赖 inotify 之类的系统接口。
+type Watcher[T any] struct {
+	loader  Loader
+	current atomic.Pointer[T]
+
+	notifyMu sync.Mutex // 让每次检查连同通知串行执行，订阅者按加载顺序收到结果
+	mu       sync.Mutex // 保护 stamps、subs
+	stamps   []stamp
+	subs     map[int]func(*T, error)
+	nextID   int
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// stamp 是一个文件某一时刻的状态。
+type stamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func stat(path string) stamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{exists: true, modTime: fi.ModTime(), size: fi.Size()}
+}
+
+// Watch 先加载一次配置，失败时返回错误；成功后每隔 interval 检查一次 l.Files。
+// interval <= 0 时不启动后台 goroutine，只在调用 Poll 时检查。
+// Loader 被复制，之后修改 l 不影响 Watcher。
+func Watch[T any](l *Loader, interval time.Duration) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		loader: *l,
+		subs:   make(map[int]func(*T, error)),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	w.stamps = w.statAll()
+	cfg := new(T)
+	if _, err := w.loader.Load(cfg); err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+
+	if interval <= 0 {
+		close(w.done)
+		return w, nil
+	}
+	go func() {
+		defer close(w.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-t.C:
+				w.Poll()
+			}
+		}
+	}()
+	return w, nil
+}
+
+func (w *Watcher[T]) statAll() []stamp {
+	out := make([]stamp, len(w.loader.Files))
+	for i, path := range w.loader.Files {
+		out[i] = stat(path)
+	}
+	return out
+}
+
+// Current 返回最近一次成功加载的配置。返回的值被多个 goroutine 共享，不要修改它。
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Subscribe 注册 fn，每次文件变化并重新加载后调用：成功时 cfg 为新配置、err 为 nil；
+// 失败时 cfg 为仍在使用的旧配置，err 为 Load 返回的错误。
+// fn 在检查的 goroutine 中依次调用，不会并发，应当尽快返回；fn 中可以订阅或取消订阅，
+// 但不能调用 Poll 或 Close。返回的函数取消订阅。
+func (w *Watcher[T]) Subscribe(fn func(cfg *T, err error)) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	w.subs[id] = fn
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs, id)
+	}
+}
+
+// Poll 立即检查一次文件；有变化时重新加载并通知订阅者，返回是否有变化和加载的错误。
+// 重新加载失败时 Current 保持不变，文件再次变化后会重试。
+// 并发调用的 Poll 依次执行：上一次的通知全部返回后才开始下一次检查。
+func (w *Watcher[T]) Poll() (changed bool, err error) {
+	w.notifyMu.Lock()
+	defer w.notifyMu.Unlock()
+	w.mu.Lock()
+	now := w.statAll()
+	for i := range now {
+		if now[i] != w.stamps[i] {
+			changed = true
+		}
+	}
+	if !changed {
+		w.mu.Unlock()
+		return false, nil
+	}
+	w.stamps = now
+
+	cfg := new(T)
+	if _, err = w.loader.Load(cfg); err == nil {
+		w.current.Store(cfg)
+	} else {
+		cfg = w.current.Load()
+	}
+	subs := make([]func(*T, error), 0, len(w.subs))
+	for id := 0; id < w.nextID; id++ {
+		if fn, ok := w.subs[id]; ok {
+			subs = append(subs, fn)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(cfg, err)
+	}
+	return true, err
+}
+
+// Close 停止后台检查，等待正在进行的通知结束。可以重复调用。
+func (w *Watcher[T]) Close() {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// touch 写入 app.ini 并把修改时间推后一秒，避免文件系统的时间精度太粗而看不出变化。
+type touch struct {
+	t     *testing.T
+	dir   string
+	path  string
+	mtime time.Time
+}
+
+func newTouch(t *testing.T) *touch {
+	dir := t.TempDir()
+	return &touch{t: t, dir: dir, path: write(t, dir, "app.ini", "name = v1\n[database]\ndsn = db1\n"), mtime: time.Now()}
+}
+
+func (f *touch) write(content string) {
+	write(f.t, f.dir, "app.ini", content)
+	f.mtime = f.mtime.Add(time.Second)
+	if err := os.Chtimes(f.path, f.mtime, f.mtime); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func TestWatchPoll(t *testing.T) {
+	f := newTouch(t)
+	w, err := Watch[AppConfig](&Loader{Files: []string{f.path}, Environ: env()}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Current().Name != "v1" {
+		t.Fatalf("initial Name = %s", w.Current().Name)
+	}
+
+	type event struct {
+		name string
+		err  error
+	}
+	var events []event
+	cancel := w.Subscribe(func(cfg *AppConfig, err error) { events = append(events, event{cfg.Name, err}) })
+
+	if changed, err := w.Poll(); changed || err != nil || len(events) != 0 {
+		t.Errorf("Poll without changes = %t, %v (%d events)", changed, err, len(events))
+	}
+
+	f.write("name = v2\n[database]\ndsn = db1\n")
+	if changed, err := w.Poll(); !changed || err != nil || w.Current().Name != "v2" {
+		t.Errorf("Poll after change = %t, %v, Name %s", changed, err, w.Current().Name)
+	}
+
+	old := w.Current()
+	f.write("name = v3\n[database]\ndsn = db1\nmax_conns = -1\n")
+	if _, err := w.Poll(); err == nil || w.Current() != old {
+		t.Errorf("invalid config: err=%v, Current replaced: %t", err, w.Current() != old)
+	}
+
+	f.write("name = v4\n[database]\ndsn = db1\n")
+	w.Poll()
+	if len(events) != 3 || events[1].name != "v2" || events[1].err == nil || events[2].name != "v4" {
+		t.Errorf("events = %v, want v2, v2 with the error, v4", events)
+	}
+
+	cancel()
+	f.write("name = v5\n[database]\ndsn = db1\n")
+	w.Poll()
+	if len(events) != 3 || w.Current().Name != "v5" {
+		t.Errorf("after cancel: %d events, Name %s", len(events), w.Current().Name)
+	}
+
+	if _, err := Watch[AppConfig](&Loader{Files: []string{f.path + ".missing"}, Environ: env()}, 0); err == nil {
+		t.Error("Watch with a missing file succeeded")
+	}
+}
+
+// 并发的 Poll 不能并发地通知订阅者，最后一次通知的必须是最后加载的配置。
+func TestWatchNotifySerialized(t *testing.T) {
+	f := newTouch(t)
+	w, err := Watch[AppConfig](&Loader{Files: []string{f.path}, Environ: env()}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var inFlight, overlaps atomic.Int32
+	var mu sync.Mutex
+	var last *AppConfig
+	w.Subscribe(func(cfg *AppConfig, err error) {
+		if inFlight.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		last = cfg
+		mu.Unlock()
+		// 在通知中订阅和取消订阅不会死锁
+		w.Subscribe(func(*AppConfig, error) {})()
+		inFlight.Add(-1)
+	})
+
+	var fileMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fileMu.Lock()
+			f.write(fmt.Sprintf("name = v%d\n[database]\ndsn = db1\n", i))
+			fileMu.Unlock()
+			w.Poll()
+		}(i)
+	}
+	wg.Wait()
+
+	if overlaps.Load() != 0 {
+		t.Errorf("%d notifications ran concurrently", overlaps.Load())
+	}
+	if last != w.Current() {
+		t.Errorf("last notification %s, Current %s", last.Name, w.Current().Name)
+	}
+}
+
+func TestWatchBackground(t *testing.T) {
+	f := newTouch(t)
+	w, err := Watch[AppConfig](&Loader{Files: []string{f.path}, Environ: env()}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan string, 1)
+	w.Subscribe(func(cfg *AppConfig, err error) {
+		select {
+		case got <- cfg.Name:
+		default:
+		}
+	})
+	f.write("name = background\n[database]\ndsn = db1\n")
+	select {
+	case name := <-got:
+		if name != "background" {
+			t.Errorf("notified %s", name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("background poll did not see the change")
+	}
+	w.Close()
+	w.Close()
+}
@@ -0,0 +1,224 @@
+// 独立运行：go run ./chap28/config_demo
+// 演示：用 config 从 default 标签、JSON、INI 和环境变量叠加出配置，报告每一个错误的 文件:行，
+// 并通过轮询修改时间热加载。所有文件都写在临时目录中，环境变量通过 Loader.Environ 注入。断言见 go test ./chap28/config。
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"books/chap28/config"
+	"books/chap28/validate"
+)
+
+type ServerConfig struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080" validate:"min=1,max=65535"`
+	Timeout time.Duration `default:"5s"`
+}
+
+type DBConfig struct {
+	DSN      string `config:"dsn" validate:"required"`
+	MaxConns int    `default:"10" validate:"min=1"`
+}
+
+type AppConfig struct {
+	Name   string `default:"demo" validate:"required"`
+	Debug  bool
+	Server ServerConfig
+	DB     DBConfig `config:"database"`
+	Tags   []string `env:"DEPLOY_TAGS"`
+}
+
+// env 返回固定的环境变量列表，代替 os.Environ。
+func env(kv ...string) func() []string {
+	return func() []string { return kv }
+}
+
+// write 把内容写入 dir 中的文件并返回路径，失败时直接退出。
+func write(dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		fmt.Println("写文件失败:", err)
+		os.Exit(1)
+	}
+	return path
+}
+
+func main() {
+	dir, err := os.MkdirTemp("", "config-demo-")
+	if err != nil {
+		fmt.Println("创建临时目录失败:", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+
+	defaults()
+	layers(dir)
+	errorsReport(dir)
+	reload(dir)
+}
+
+func defaults() {
+	fmt.Println("=== 1. 只有 default 标签 ===")
+	var cfg ServerConfig
+	l := config.Loader{Environ: env()}
+	src, err := l.Load(&cfg)
+	fmt.Printf("  零值 Loader：%+v, %v\n", cfg, err)
+	fmt.Println("  Sources 记录来源：port ←", src["port"])
+
+	var app AppConfig
+	_, err = l.Load(&app)
+	fmt.Println("  没有任何来源设置 required 的字段:", err)
+	fmt.Printf("  出错时目标结构体保持不变：Name = %q\n", app.Name)
+
+	_, err = l.Load(cfg)
+	fmt.Println("  传入的不是指针:", err)
+	fmt.Println()
+}
+
+func layers(dir string) {
+	fmt.Println("=== 2. 优先级：default < JSON < INI < 环境变量 ===")
+	base := write(dir, "base.json", `{
+  "name": "shop",
+  "server": {
+    "host": "0.0.0.0",
+    "port": 9000
+  },
+  "database": {"dsn": "postgres://db/shop", "max_conns": 20},
+  "tags": ["base", "json"]
+}
+`)
+	local := write(dir, "local.ini", `# 本机覆盖
+debug = true
+
+[server]
+port = 9001          ; 行尾注释
+timeout = 1m30s
+
+[database]
+dsn = "postgres://localhost/shop?sslmode=disable"
+`)
+	l := config.Loader{
+		Files:     []string{base, local},
+		EnvPrefix: "SHOP",
+		Environ:   env("SHOP_SERVER_PORT=9100", "DEPLOY_TAGS=blue, canary", "PATH=/usr/bin"),
+	}
+	var cfg AppConfig
+	src, err := l.Load(&cfg)
+	fmt.Printf("  加载结果：%+v, %v\n", cfg, err)
+	for _, key := range []string{"name", "debug", "server.host", "server.port", "server.timeout", "database.dsn", "database.max_conns", "tags"} {
+		fmt.Printf("    %-20s ← %s\n", key, src[key])
+	}
+	fmt.Printf("  env 标签指定变量名，逗号分隔成列表：%q\n", cfg.Tags)
+	fmt.Println()
+}
+
+func errorsReport(dir string) {
+	fmt.Println("=== 3. 报告每一个错误 ===")
+	bad := write(dir, "bad.ini", `name = ""
+[server]
+port = 70000
+timeout = soon
+prot = 8080
+this line is wrong
+[database]
+max_conns = 0
+tags = [a, "b
+`)
+	l := config.Loader{Files: []string{bad}, Environ: env("DEPLOY_TAGS=x")}
+	var cfg AppConfig
+	_, err := l.Load(&cfg)
+	es := config.Errors(err)
+	for _, e := range es {
+		fmt.Println("    " + e.Error())
+	}
+	fmt.Printf("  一次报告全部 %d 个问题；errors.Is 区分语法错误 %v、未知键 %v、无法转换的值 %v\n", len(es),
+		errors.Is(err, config.ErrSyntax), errors.Is(err, config.ErrUnknownKey), errors.Is(err, config.ErrInvalidValue))
+	var fe *validate.FieldError
+	if errors.As(err, &fe) {
+		fmt.Println("  errors.As 取出 validate 的 *FieldError，规则:", fe.Rule)
+	}
+
+	badJSON := write(dir, "bad.json", "{\n  \"name\": \"x\",\n  \"server\": {\"port\": 80,}\n}\n")
+	_, err = (&config.Loader{Files: []string{badJSON}, Environ: env()}).Load(&cfg)
+	fmt.Println("  JSON 语法错误也带行号:", config.Errors(err)[0])
+
+	dup := write(dir, "dup.json", "{\n  \"name\": \"a\",\n  \"name\": \"b\"\n}\n")
+	_, err = (&config.Loader{Files: []string{dup}, Environ: env()}).Load(&cfg)
+	fmt.Println("  重复的键:", config.Errors(err)[0])
+
+	_, err = (&config.Loader{Files: []string{filepath.Join(dir, "missing.ini")}, Environ: env()}).Load(&cfg)
+	fmt.Println("  文件不存在时返回 *fs.PathError:", os.IsNotExist(err))
+	fmt.Println()
+}
+
+func reload(dir string) {
+	fmt.Println("=== 4. 热加载 ===")
+	path := write(dir, "app.ini", "name = v1\n[database]\ndsn = db1\n")
+	l := &config.Loader{Files: []string{path}, Environ: env()}
+	w, err := config.Watch[AppConfig](l, 0)
+	if err != nil {
+		fmt.Println("Watch 失败:", err)
+		os.Exit(1)
+	}
+	defer w.Close()
+	fmt.Println("  初次加载：name =", w.Current().Name)
+
+	cancel := w.Subscribe(func(cfg *AppConfig, err error) {
+		fmt.Printf("    通知：name = %s, err = %v\n", cfg.Name, err)
+	})
+
+	// 依次修改文件；每次都把修改时间往后推，避免文件系统的时间精度太粗而看不出变化
+	mtime := time.Now()
+	update := func(content string) (bool, error) {
+		write(dir, "app.ini", content)
+		mtime = mtime.Add(time.Second)
+		os.Chtimes(path, mtime, mtime)
+		return w.Poll()
+	}
+
+	changed, _ := w.Poll()
+	fmt.Println("  文件没变时 Poll 不重新加载，changed =", changed)
+
+	changed, err = update("name = v2\n[database]\ndsn = db1\n")
+	fmt.Printf("  修改后 Poll：changed = %v，name = %s, %v\n", changed, w.Current().Name, err)
+
+	update("name = v3\n[database]\ndsn = db1\nmax_conns = -1\n")
+	fmt.Printf("  新内容校验失败，继续使用旧配置 name = %s\n", w.Current().Name)
+
+	update("name = v4\n[database]\ndsn = db1\n")
+	fmt.Println("  再次修改：name =", w.Current().Name)
+
+	cancel()
+	update("name = v5\n[database]\ndsn = db1\n")
+	fmt.Printf("  取消订阅后不再收到通知，name = %s\n", w.Current().Name)
+
+	// 后台轮询
+	bg, err := config.Watch[AppConfig](l, 10*time.Millisecond)
+	if err != nil {
+		fmt.Println("Watch 失败:", err)
+		os.Exit(1)
+	}
+	got := make(chan string, 1)
+	bg.Subscribe(func(cfg *AppConfig, err error) {
+		select {
+		case got <- cfg.Name:
+		default:
+		}
+	})
+	write(dir, "app.ini", "name = background\n[database]\ndsn = db1\n")
+	mtime = mtime.Add(time.Second)
+	os.Chtimes(path, mtime, mtime)
+	select {
+	case name := <-got:
+		fmt.Println("  后台 goroutine 每 10ms 检查一次，发现变化后通知:", name)
+	case <-time.After(2 * time.Second):
+		fmt.Println("  后台 goroutine 没有发现变化")
+	}
+	bg.Close()
+	bg.Close() // 可以重复调用
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+
+	"books/chap28/config"
 )
 
 func main() {
@@ -440,20 +442,23 @@ func safeDivide(a, b int) int {
 	return a / b
 }
 
-// Config 配置结构体
+// Config 配置结构体（标签的含义见 chap28/config）
 type Config struct {
-	Host string
-	Port int
+	Host string `config:"host" default:"localhost"`
+	Port int    `config:"port" default:"8080" validate:"min=1,max=65535"`
 }
 
-// loadConfig 加载配置
+// loadConfig 加载配置：default 标签 < 配置文件 < 环境变量 APP_HOST、APP_PORT
 func loadConfig(filename string) (*Config, error) {
-	_, err := os.Stat(filename)
-	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("配置文件 %s 不存在: %w", filename, err)
+	loader := config.Loader{Files: []string{filename}, EnvPrefix: "APP"}
+	var cfg Config
+	if _, err := loader.Load(&cfg); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("配置文件 %s 不存在: %w", filename, err)
+		}
+		return nil, fmt.Errorf("配置文件 %s 有误: %w", filename, err)
 	}
-	// 模拟加载配置
-	return &Config{Host: "localhost", Port: 8080}, nil
+	return &cfg, nil
 }
 
 // fetchData 获取数据（模拟网络请求）
@@ -0,0 +1,214 @@
+package errs
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Code 是错误的分类，决定对外返回的 HTTP 状态码和 gRPC 状态码。
+// Code 本身实现了 error，可以直接作为 errors.Is 的目标：errors.Is(err, errs.NotFound)。
+type Code string
+
+// 预定义的错误码，与 gRPC 的标准状态码一一对应（google.golang.org/grpc/codes）。
+const (
+	OK                 Code = "ok"
+	Canceled           Code = "canceled"
+	Unknown            Code = "unknown"
+	InvalidArgument    Code = "invalid_argument"
+	DeadlineExceeded   Code = "deadline_exceeded"
+	NotFound           Code = "not_found"
+	AlreadyExists      Code = "already_exists"
+	PermissionDenied   Code = "permission_denied"
+	ResourceExhausted  Code = "resource_exhausted"
+	FailedPrecondition Code = "failed_precondition"
+	Aborted            Code = "aborted"
+	OutOfRange         Code = "out_of_range"
+	Unimplemented      Code = "unimplemented"
+	Internal           Code = "internal"
+	Unavailable        Code = "unavailable"
+	DataLoss           Code = "data_loss"
+	Unauthenticated    Code = "unauthenticated"
+)
+
+func (c Code) Error() string  { return string(c) }
+func (c Code) String() string { return string(c) }
+
+// HTTPStatus 返回 c 对应的 HTTP 状态码，未登记的错误码返回 500。
+func (c Code) HTTPStatus() int {
+	if m, ok := lookup(c); ok {
+		return m.HTTP
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPC 返回 c 对应的 gRPC 状态码，未登记的错误码返回 GRPCUnknown。
+func (c Code) GRPC() GRPCCode {
+	if m, ok := lookup(c); ok {
+		return m.GRPC
+	}
+	return GRPCUnknown
+}
+
+// GRPCCode 是 gRPC 状态码。数值与 google.golang.org/grpc/codes 相同，
+// 用到 grpc 的代码可以直接 codes.Code(c) 转换，本包不必依赖 grpc。
+type GRPCCode uint32
+
+const (
+	GRPCOK GRPCCode = iota
+	GRPCCanceled
+	GRPCUnknown
+	GRPCInvalidArgument
+	GRPCDeadlineExceeded
+	GRPCNotFound
+	GRPCAlreadyExists
+	GRPCPermissionDenied
+	GRPCResourceExhausted
+	GRPCFailedPrecondition
+	GRPCAborted
+	GRPCOutOfRange
+	GRPCUnimplemented
+	GRPCInternal
+	GRPCUnavailable
+	GRPCDataLoss
+	GRPCUnauthenticated
+)
+
+var grpcNames = [...]string{
+	"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded", "NotFound",
+	"AlreadyExists", "PermissionDenied", "ResourceExhausted", "FailedPrecondition",
+	"Aborted", "OutOfRange", "Unimplemented", "Internal", "Unavailable", "DataLoss",
+	"Unauthenticated",
+}
+
+func (g GRPCCode) String() string {
+	if int(g) < len(grpcNames) {
+		return grpcNames[g]
+	}
+	return fmt.Sprintf("Code(%d)", uint32(g))
+}
+
+// Mapping 是一个错误码对外的表示。
+type Mapping struct {
+	Code Code
+	HTTP int
+	GRPC GRPCCode
+}
+
+var (
+	mu sync.RWMutex
+	// mappings 按登记顺序保存映射表，预定义部分按 gRPC 状态码排列；
+	// HTTP 状态码采用 grpc-gateway 的约定。
+	mappings = []Mapping{
+		{OK, http.StatusOK, GRPCOK},
+		{Canceled, 499, GRPCCanceled}, // 499 Client Closed Request（nginx 约定）
+		{Unknown, http.StatusInternalServerError, GRPCUnknown},
+		{InvalidArgument, http.StatusBadRequest, GRPCInvalidArgument},
+		{DeadlineExceeded, http.StatusGatewayTimeout, GRPCDeadlineExceeded},
+		{NotFound, http.StatusNotFound, GRPCNotFound},
+		{AlreadyExists, http.StatusConflict, GRPCAlreadyExists},
+		{PermissionDenied, http.StatusForbidden, GRPCPermissionDenied},
+		{ResourceExhausted, http.StatusTooManyRequests, GRPCResourceExhausted},
+		{FailedPrecondition, http.StatusBadRequest, GRPCFailedPrecondition},
+		{Aborted, http.StatusConflict, GRPCAborted},
+		{OutOfRange, http.StatusBadRequest, GRPCOutOfRange},
+		{Unimplemented, http.StatusNotImplemented, GRPCUnimplemented},
+		{Internal, http.StatusInternalServerError, GRPCInternal},
+		{Unavailable, http.StatusServiceUnavailable, GRPCUnavailable},
+		{DataLoss, http.StatusInternalServerError, GRPCDataLoss},
+		{Unauthenticated, http.StatusUnauthorized, GRPCUnauthenticated},
+	}
+	byCode = indexMappings(mappings)
+)
+
+func indexMappings(ms []Mapping) map[Code]int {
+	idx := make(map[Code]int, len(ms))
+	for i, m := range ms {
+		idx[m.Code] = i
+	}
+	return idx
+}
+
+func lookup(c Code) (Mapping, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	i, ok := byCode[c]
+	if !ok {
+		return Mapping{}, false
+	}
+	return mappings[i], true
+}
+
+// RegisterCode 登记一个自定义错误码的 HTTP 和 gRPC 映射。
+// 错误码为空或已经登记过时 panic（登记应当在 init 中完成，重复登记说明有冲突）。
+func RegisterCode(c Code, httpStatus int, grpc GRPCCode) {
+	if c == "" {
+		panic("errs: RegisterCode with empty code")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := byCode[c]; dup {
+		panic("errs: RegisterCode called twice for code " + string(c))
+	}
+	byCode[c] = len(mappings)
+	mappings = append(mappings, Mapping{c, httpStatus, grpc})
+}
+
+// Mappings 按登记顺序返回完整的映射表（预定义的在前）。
+func Mappings() []Mapping {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Mapping(nil), mappings...)
+}
+
+// HTTPStatus 返回 err 应当对应的 HTTP 状态码：nil 为 200，其余按 CodeOf(err) 查表。
+func HTTPStatus(err error) int {
+	return CodeOf(err).HTTPStatus()
+}
+
+// GRPCStatus 返回 err 应当对应的 gRPC 状态码：nil 为 GRPCOK，其余按 CodeOf(err) 查表。
+func GRPCStatus(err error) GRPCCode {
+	return CodeOf(err).GRPC()
+}
+
+// FromHTTPStatus 把收到的 HTTP 状态码还原成错误码（例如调用下游服务失败时）。
+// 一个状态码对应多个错误码时取最常用的那个；表中没有的 4xx 返回 FailedPrecondition，
+// 5xx 返回 Internal，其余返回 Unknown。
+func FromHTTPStatus(status int) Code {
+	switch {
+	case status >= 200 && status < 300:
+		return OK
+	case status == http.StatusBadRequest:
+		return InvalidArgument
+	case status == http.StatusConflict:
+		return AlreadyExists
+	case status == http.StatusInternalServerError:
+		return Internal
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, m := range mappings {
+		if m.HTTP == status {
+			return m.Code
+		}
+	}
+	switch {
+	case status >= 400 && status < 500:
+		return FailedPrecondition
+	case status >= 500 && status < 600:
+		return Internal
+	}
+	return Unknown
+}
+
+// FromGRPC 把 gRPC 状态码还原成错误码，未知的状态码返回 Unknown。
+func FromGRPC(g GRPCCode) Code {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, m := range mappings {
+		if m.GRPC == g {
+			return m.Code
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,182 @@
+// Package errs 提供带错误码、结构化字段、原因和调用栈的错误类型（第28章错误处理的工程化版本）。
+//
+// chap28/error_handling.go 用 fmt.Errorf("...: %w") 串起错误链，用 ErrNotFound、ErrPermissionDenied
+// 这样的哨兵错误做判断；03-error_handling/13 介绍的 pkg/errors、cockroachdb/errors 额外提供了调用栈。
+// 这里用标准库实现同样的能力：
+//
+//	err := errs.Wrap(dbErr, errs.NotFound, "load user", "user_id", 42)
+//	errors.Is(err, errs.NotFound)   // 按错误码判断
+//	errors.Is(err, sql.ErrNoRows)   // 原因链照常可用
+//	errs.HTTPStatus(err)            // 404
+//	fmt.Printf("%+v\n", err)        // 每一层的错误码、字段和调用栈
+//	slog.Error("request failed", "error", err) // 实现了 slog.LogValuer
+//
+// *Error 与 errors.Is、errors.As、errors.Join 和 fmt.Errorf("%w") 可以任意组合。
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Error 是带错误码的错误。字段都可以直接读取；调用栈由 New、Newf、Wrap、With 记录。
+type Error struct {
+	Code    Code        // 错误分类；为空时沿原因链向内查找，见 CodeOf
+	Message string      // 这一层的说明，不包含原因的文字
+	Fields  []slog.Attr // 结构化上下文，例如 user_id=42
+	Cause   error       // 被包装的原因
+	stack   Stack
+}
+
+// New 创建错误，kv 是交替出现的键和值（规则与 slog 相同，也可以直接传 slog.Attr）。
+func New(code Code, msg string, kv ...any) *Error {
+	return &Error{Code: code, Message: msg, Fields: attrs(kv), stack: callers(1)}
+}
+
+// Newf 用格式化的说明创建错误，不带字段。需要包装原因时用 Wrap。
+func Newf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), stack: callers(1)}
+}
+
+// Wrap 为 err 加上错误码、说明和字段。err 为 nil 时返回 nil。
+//
+// 返回类型是 error 而不是 *Error：返回 (*Error)(nil) 会掉进第27章的接口 nil 陷阱，
+// 调用方的 if err != nil 会永远成立。code 为空表示沿用原因的错误码。
+// 原因链中已经记录过调用栈时不再重复记录。
+func Wrap(err error, code Code, msg string, kv ...any) error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Code: code, Message: msg, Fields: attrs(kv), Cause: err}
+	if StackOf(err) == nil {
+		e.stack = callers(1)
+	}
+	return e
+}
+
+// With 返回带有额外字段的副本，并在调用处重新记录调用栈，e 本身不变。
+// 适合从包级的哨兵错误派生：return ErrUserNotFound.With("user_id", id)。
+func (e *Error) With(kv ...any) *Error {
+	c := *e
+	c.Fields = append(append([]slog.Attr(nil), e.Fields...), attrs(kv)...)
+	c.stack = callers(1)
+	return &c
+}
+
+// attrs 按 slog 的规则把键值对转换成 Attr，落单的值使用键 !BADKEY。
+func attrs(kv []any) []slog.Attr {
+	if len(kv) == 0 {
+		return nil
+	}
+	return slog.Group("", kv...).Value.Group()
+}
+
+// Error 返回 "说明: 原因"；说明为空时只返回原因，两者都为空时返回错误码。
+func (e *Error) Error() string {
+	switch {
+	case e.Cause == nil && e.Message == "":
+		if e.Code == "" {
+			return string(Unknown)
+		}
+		return string(e.Code)
+	case e.Cause == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Cause.Error()
+	}
+	return e.Message + ": " + e.Cause.Error()
+}
+
+// Unwrap 返回原因，供 errors.Is、errors.As 沿链查找。
+func (e *Error) Unwrap() error { return e.Cause }
+
+// Is 让 errors.Is 可以按错误码匹配（目标是 Code），也可以匹配由同一个哨兵 *Error
+// 派生出的错误（错误码和说明都相同，字段和调用栈不参与比较）。
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case Code:
+		return e.Code == t
+	case *Error:
+		return e.Code == t.Code && e.Message == t.Message
+	}
+	return false
+}
+
+// Stack 返回这一层记录的调用栈，没有记录时为 nil。整条链上的栈用 StackOf。
+func (e *Error) Stack() Stack { return e.stack }
+
+// walk 深度优先遍历错误链（包括 errors.Join 的每个分支），fn 返回 false 时停止。
+func walk(err error, fn func(*Error) bool) bool {
+	return walkAll(err, func(err error) bool {
+		if e, ok := err.(*Error); ok {
+			return fn(e)
+		}
+		return true
+	})
+}
+
+func walkAll(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkAll(u.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if !walkAll(e, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CodeOf 返回 err 的错误码：错误链中最外层的非空错误码（包括直接作为错误返回的 Code）。
+// 链中没有错误码时，context.DeadlineExceeded 和 context.Canceled 映射为同名错误码，
+// 其余返回 Unknown；err 为 nil 时返回 OK。
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+	code := Code("")
+	walkAll(err, func(err error) bool {
+		switch e := err.(type) {
+		case *Error:
+			code = e.Code
+		case Code:
+			code = e
+		}
+		return code == ""
+	})
+	switch {
+	case code != "":
+		return code
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	}
+	return Unknown
+}
+
+// FieldsOf 收集错误链中所有 *Error 的字段，外层在前；同名的键只保留最外层的那个。
+func FieldsOf(err error) []slog.Attr {
+	var out []slog.Attr
+	seen := make(map[string]bool)
+	walk(err, func(e *Error) bool {
+		for _, a := range e.Fields {
+			if !seen[a.Key] {
+				seen[a.Key] = true
+				out = append(out, a)
+			}
+		}
+		return true
+	})
+	return out
+}
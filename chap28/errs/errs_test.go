@@ -0,0 +1,255 @@
+package errs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 与 errs_demo 相同的三层小应用：repository → service → handler。
+
+var errNoRows = errors.New("sql: no rows in result set")
+
+var (
+	errUserNotFound     = New(NotFound, "用户不存在")
+	errPermissionDenied = New(PermissionDenied, "权限被拒绝")
+)
+
+func loadUser(id int) (string, error) {
+	if id == 42 {
+		return "", Wrap(errNoRows, NotFound, "load user", "user_id", id, "table", "users")
+	}
+	return fmt.Sprintf("user-%d", id), nil
+}
+
+func getProfile(id int) (string, error) {
+	name, err := loadUser(id)
+	if err != nil {
+		return "", fmt.Errorf("get profile: %w", err)
+	}
+	return name, nil
+}
+
+func handle(id int) error {
+	_, err := getProfile(id)
+	return Wrap(err, "", "GET /users/"+fmt.Sprint(id), "request_id", "req-7")
+}
+
+func TestSentinels(t *testing.T) {
+	err := errUserNotFound.With("user_id", 1)
+	if !errors.Is(err, errUserNotFound) || !errors.Is(err, NotFound) {
+		t.Error("error derived with With no longer matches the sentinel or its code")
+	}
+	if errors.Is(err, errPermissionDenied) {
+		t.Error("matches a different sentinel")
+	}
+	if len(errUserNotFound.Fields) != 0 || len(err.Fields) != 1 {
+		t.Errorf("With modified the sentinel: %v / %v", errUserNotFound.Fields, err.Fields)
+	}
+	if HTTPStatus(errPermissionDenied) != 403 || HTTPStatus(nil) != 200 {
+		t.Errorf("HTTPStatus: %d, %d", HTTPStatus(errPermissionDenied), HTTPStatus(nil))
+	}
+}
+
+func TestChain(t *testing.T) {
+	err := handle(42)
+	if got := err.Error(); got != "GET /users/42: get profile: load user: sql: no rows in result set" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !errors.Is(err, NotFound) || !errors.Is(err, errNoRows) {
+		t.Error("errors.Is does not see the code or the root cause through %w")
+	}
+	if CodeOf(err) != NotFound || HTTPStatus(err) != 404 || GRPCStatus(err) != GRPCNotFound {
+		t.Errorf("CodeOf = %s, HTTP %d, gRPC %v", CodeOf(err), HTTPStatus(err), GRPCStatus(err))
+	}
+
+	var e *Error
+	if !errors.As(err, &e) || e.Message != "GET /users/42" {
+		t.Fatalf("errors.As outermost *Error = %v", e)
+	}
+	var keys []string
+	for _, a := range FieldsOf(err) {
+		keys = append(keys, a.Key)
+	}
+	if strings.Join(keys, ",") != "request_id,user_id,table" {
+		t.Errorf("FieldsOf keys = %v", keys)
+	}
+	st := StackOf(err)
+	if st == nil || !strings.HasSuffix(st.Caller().Function, ".loadUser") {
+		t.Errorf("StackOf caller = %v, want loadUser", st.Caller().Function)
+	}
+	if e.Stack() != nil {
+		t.Error("outer Wrap recorded a second stack")
+	}
+
+	detail := fmt.Sprintf("%+v", e)
+	if !strings.HasPrefix(detail, "GET /users/42\n    request_id=req-7\ncaused by: get profile\ncaused by: not_found: load user\n    user_id=42 table=users\n") ||
+		!strings.Contains(detail, ".loadUser\n") || !strings.HasSuffix(detail, "caused by: sql: no rows in result set\n") {
+		t.Errorf("%%+v:\n%s", detail)
+	}
+	if q := fmt.Sprintf("%q", e); q != `"`+e.Error()+`"` {
+		t.Errorf("%%q = %s", q)
+	}
+	if s := fmt.Sprintf("%s|%v", e, e); s != e.Error()+"|"+e.Error() {
+		t.Errorf("%%s|%%v = %s", s)
+	}
+}
+
+func TestFieldsAndJoin(t *testing.T) {
+	err := New(InvalidArgument, "除数不能为零", "dividend", 10.0, "divisor", 0.0)
+	if len(err.Fields) != 2 || err.Fields[0].Key != "dividend" || err.Fields[0].Value.Float64() != 10 {
+		t.Errorf("Fields = %v", err.Fields)
+	}
+	if bad := New(Internal, "x", "lonely"); len(bad.Fields) != 1 || bad.Fields[0].Key != "!BADKEY" {
+		t.Errorf("odd key/value list: %v", bad.Fields)
+	}
+
+	joined := errors.Join(
+		New(InvalidArgument, "name 不能为空", "field", "name"),
+		New(OutOfRange, "age 超出范围", "field", "age", "value", 200),
+	)
+	if !errors.Is(joined, InvalidArgument) || !errors.Is(joined, OutOfRange) {
+		t.Error("errors.Is misses a branch of errors.Join")
+	}
+	if CodeOf(joined) != InvalidArgument {
+		t.Errorf("CodeOf(joined) = %s, want the first branch", CodeOf(joined))
+	}
+	if fs := FieldsOf(joined); len(fs) != 2 || fs[0].String() != "field=name" || fs[1].Key != "value" {
+		t.Errorf("FieldsOf(joined) = %v, want the first field=... and value", fs)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	for _, tc := range []struct {
+		err  error
+		want Code
+		http int
+	}{
+		{nil, OK, 200},
+		{fmt.Errorf("call inventory: %w", ctx.Err()), DeadlineExceeded, 504},
+		{fmt.Errorf("x: %w", context.Canceled), Canceled, 499},
+		{errors.New("plain"), Unknown, 500},
+		{fmt.Errorf("x: %w", Unavailable), Unavailable, 503},
+		{Wrap(errors.New("inner"), "", "no code"), Unknown, 500},
+		{Wrap(New(Aborted, "inner"), "", "outer"), Aborted, 409},
+	} {
+		if got := CodeOf(tc.err); got != tc.want || HTTPStatus(tc.err) != tc.http {
+			t.Errorf("CodeOf(%v) = %s (HTTP %d), want %s (%d)", tc.err, got, HTTPStatus(tc.err), tc.want, tc.http)
+		}
+	}
+}
+
+func TestMappings(t *testing.T) {
+	for _, m := range Mappings()[:GRPCUnauthenticated+1] { // 预定义的错误码，不含其他测试登记的
+		if FromGRPC(m.GRPC) != m.Code {
+			t.Errorf("FromGRPC(%v) = %s, want %s", m.GRPC, FromGRPC(m.GRPC), m.Code)
+		}
+		if m.Code.HTTPStatus() != m.HTTP || m.Code.GRPC() != m.GRPC {
+			t.Errorf("%s: methods disagree with the table", m.Code)
+		}
+	}
+	for status, want := range map[int]Code{
+		200: OK, 204: OK, 400: InvalidArgument, 401: Unauthenticated,
+		403: PermissionDenied, 404: NotFound, 409: AlreadyExists,
+		418: FailedPrecondition, 500: Internal, 502: Internal, 503: Unavailable, 302: Unknown,
+	} {
+		if got := FromHTTPStatus(status); got != want {
+			t.Errorf("FromHTTPStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+	if GRPCCode(99).String() != "Code(99)" || GRPCNotFound.String() != "NotFound" {
+		t.Error("GRPCCode.String")
+	}
+	if Code("nosuch").HTTPStatus() != 500 || Code("nosuch").GRPC() != GRPCUnknown {
+		t.Error("unregistered code should map to 500 / Unknown")
+	}
+}
+
+func TestRegisterCode(t *testing.T) {
+	const quota Code = "test_quota_exceeded"
+	RegisterCode(quota, 429, GRPCResourceExhausted)
+	if HTTPStatus(New(quota, "每日额度已用完")) != 429 || GRPCStatus(quota) != GRPCResourceExhausted {
+		t.Error("custom code not mapped")
+	}
+	if ms := Mappings(); ms[len(ms)-1].Code != quota {
+		t.Error("custom code not appended to Mappings")
+	}
+	for _, c := range []Code{quota, ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterCode(%q) did not panic", c)
+				}
+			}()
+			RegisterCode(c, 400, GRPCInvalidArgument)
+		}()
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch {
+			case len(groups) == 0 && a.Key == slog.TimeKey:
+				return slog.Attr{}
+			case a.Key == "at":
+				a.Value = slog.StringValue(filepath.Base(a.Value.String()))
+			}
+			return a
+		},
+	}))
+	logger.Error("request failed", "error", handle(42))
+	out := buf.String()
+	for _, want := range []string{
+		`"error":{"msg":"GET /users/42","code":"not_found","request_id":"req-7"`,
+		`"cause":"get profile: load user: sql: no rows in result set"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log line missing %s:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	logger.Warn("nested", "error", Wrap(errPermissionDenied.With("role", "guest"), Internal, "audit"))
+	out = buf.String()
+	if !strings.Contains(out, `"cause":{"msg":"权限被拒绝","code":"permission_denied","role":"guest","at":"errs_test.go:`) {
+		t.Errorf("nested cause not expanded:\n%s", out)
+	}
+}
+
+func TestNilAndStacks(t *testing.T) {
+	if err := Wrap(nil, Internal, "never"); err != nil {
+		t.Errorf("Wrap(nil) = %#v, want untyped nil", err)
+	}
+	prev := SetStackCapture(false)
+	e := New(Internal, "hot path")
+	SetStackCapture(prev)
+	if !prev || e.Stack() != nil {
+		t.Error("SetStackCapture(false) still records stacks")
+	}
+	if New(Internal, "x").Stack() == nil {
+		t.Error("stack capture not restored")
+	}
+	for _, tc := range []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{}, "unknown"},
+		{&Error{Code: Aborted}, "aborted"},
+		{&Error{Message: "m"}, "m"},
+		{&Error{Cause: errNoRows}, errNoRows.Error()},
+	} {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("%+v.Error() = %q, want %q", *tc.err, got, tc.want)
+		}
+	}
+}
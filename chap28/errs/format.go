@@ -0,0 +1,111 @@
+package errs
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Format 实现 fmt.Formatter：%s、%v 输出 Error()，%q 输出带引号的 Error()，
+// %+v 逐层输出错误码、说明、字段和调用栈，格式与 pkg/errors 相近：
+//
+//	GET /users/42
+//	    request_id=req-7
+//	caused by: get profile
+//	caused by: not_found: load user
+//	    user_id=42
+//	    main.loadUser
+//	    	/src/app/user.go:31
+//	caused by: sql: no rows in result set
+//
+// 中间的 fmt.Errorf("get profile: %w", err) 这类包装只输出它自己添加的前缀；
+// 文字不以原因结尾的包装（以及 errors.Join）输出完整的 Error() 后停止。
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		e.writeDetail(s)
+	case verb == 'q':
+		io.WriteString(s, strconv.Quote(e.Error()))
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+func (e *Error) writeDetail(w io.Writer) {
+	const indent = "    "
+	var err error = e
+	for prefix := ""; err != nil; prefix = "caused by: " {
+		cur, ok := err.(*Error)
+		if !ok {
+			own, inner := ownText(err)
+			fmt.Fprintf(w, "%s%s\n", prefix, own)
+			err = inner
+			continue
+		}
+		fmt.Fprintf(w, "%s%s\n", prefix, cur.header())
+		if len(cur.Fields) > 0 {
+			fmt.Fprintf(w, "%s%s\n", indent, fieldsString(cur.Fields))
+		}
+		cur.stack.writeTo(w, indent)
+		err = cur.Cause
+	}
+}
+
+// ownText 拆开一层不是 *Error 的错误：文字形如 "前缀: 原因" 时返回前缀和原因，
+// 以便继续输出原因；否则返回完整的文字和 nil。
+func ownText(err error) (string, error) {
+	text := err.Error()
+	u, ok := err.(interface{ Unwrap() error })
+	if !ok || u.Unwrap() == nil {
+		return text, nil
+	}
+	inner := u.Unwrap()
+	if own, found := strings.CutSuffix(text, ": "+inner.Error()); found {
+		return own, inner
+	}
+	return text, nil
+}
+
+// header 返回这一层的 "错误码: 说明"，缺少的部分省略。
+func (e *Error) header() string {
+	switch {
+	case e.Code == "":
+		return e.Message
+	case e.Message == "":
+		return string(e.Code)
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+// fieldsString 把字段写成 key=value，以空格分隔。
+func fieldsString(fields []slog.Attr) string {
+	parts := make([]string, len(fields))
+	for i, a := range fields {
+		parts[i] = a.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// LogValue 实现 slog.LogValuer：记录日志时展开成一组属性，
+// 包括 msg、code、各个字段、cause（原因是 *Error 时嵌套展开）以及创建位置 at。
+func (e *Error) LogValue() slog.Value {
+	as := make([]slog.Attr, 0, len(e.Fields)+4)
+	if e.Message != "" {
+		as = append(as, slog.String("msg", e.Message))
+	}
+	as = append(as, slog.String("code", string(CodeOf(e))))
+	as = append(as, e.Fields...)
+	if e.Cause != nil {
+		if c, ok := e.Cause.(*Error); ok {
+			as = append(as, slog.Any("cause", c))
+		} else {
+			as = append(as, slog.String("cause", e.Cause.Error()))
+		}
+	}
+	if f := e.stack.Caller(); f.File != "" {
+		as = append(as, slog.String("at", f.File+":"+strconv.Itoa(f.Line)))
+	}
+	return slog.GroupValue(as...)
+}
@@ -0,0 +1,79 @@
+package errs
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+// Stack 是创建错误时的调用栈（程序计数器），用 Frames 解析成文件和行号。
+type Stack []uintptr
+
+// maxDepth 是记录的最大栈深度。
+const maxDepth = 32
+
+var captureStacks atomic.Bool
+
+func init() { captureStacks.Store(true) }
+
+// SetStackCapture 打开或关闭 New、Newf、Wrap 的调用栈记录，返回之前的设置。
+// 默认打开；在错误非常频繁的热路径上（例如把 NotFound 当作正常分支）可以关掉以节省开销。
+func SetStackCapture(on bool) (prev bool) {
+	return captureStacks.Swap(on)
+}
+
+// callers 记录调用栈，skip 为 0 表示 callers 的调用者。
+func callers(skip int) Stack {
+	if !captureStacks.Load() {
+		return nil
+	}
+	var pcs [maxDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return append(Stack(nil), pcs[:n]...)
+}
+
+// Frames 把调用栈解析成帧，从最内层（创建错误的位置）开始。
+func (s Stack) Frames() []runtime.Frame {
+	if len(s) == 0 {
+		return nil
+	}
+	out := make([]runtime.Frame, 0, len(s))
+	frames := runtime.CallersFrames(s)
+	for {
+		f, more := frames.Next()
+		out = append(out, f)
+		if !more {
+			return out
+		}
+	}
+}
+
+// Caller 返回创建错误的位置（最内层的帧），没有记录栈时返回零值。
+func (s Stack) Caller() runtime.Frame {
+	if len(s) == 0 {
+		return runtime.Frame{}
+	}
+	f, _ := runtime.CallersFrames(s[:1]).Next()
+	return f
+}
+
+// writeTo 按 pkg/errors 的格式写出调用栈：每帧两行，函数名和缩进的 file:line。
+func (s Stack) writeTo(w io.Writer, indent string) {
+	for _, f := range s.Frames() {
+		fmt.Fprintf(w, "%s%s\n%s\t%s:%d\n", indent, f.Function, indent, f.File, f.Line)
+	}
+}
+
+// StackOf 返回错误链中最内层的 *Error 记录的调用栈，也就是最接近问题源头的那个；
+// 都没有记录时返回 nil。
+func StackOf(err error) Stack {
+	var st Stack
+	walk(err, func(e *Error) bool {
+		if len(e.stack) > 0 {
+			st = e.stack
+		}
+		return true
+	})
+	return st
+}
@@ -0,0 +1,249 @@
+// 独立运行：go run ./chap28/errs_demo
+// 演示：用 errs 重写 chap28/error_handling.go 的哨兵错误、错误链和 DivisionError，
+// 并展示 errors.Is/As/Join、%+v 调用栈、slog 输出和 HTTP/gRPC 映射表。断言见 go test ./chap28/errs。
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"books/chap28/errs"
+)
+
+// panics 报告 f 是否 panic。
+func panics(f func()) (p bool) {
+	defer func() { p = recover() != nil }()
+	f()
+	return false
+}
+
+// ---- 一个三层的小应用：repository → service → handler ----
+
+// errNoRows 模拟 database/sql 的 sql.ErrNoRows。
+var errNoRows = errors.New("sql: no rows in result set")
+
+// 对应 error_handling.go 中的 ErrNotFound、ErrPermissionDenied，多了错误码。
+var (
+	ErrUserNotFound     = errs.New(errs.NotFound, "用户不存在")
+	ErrPermissionDenied = errs.New(errs.PermissionDenied, "权限被拒绝")
+)
+
+func queryUser(id int) (string, error) {
+	if id == 42 {
+		return "", errNoRows
+	}
+	return fmt.Sprintf("user-%d", id), nil
+}
+
+// loadUser 是 repository 层：把驱动的错误翻译成错误码。
+func loadUser(id int) (string, error) {
+	name, err := queryUser(id)
+	if errors.Is(err, errNoRows) {
+		return "", errs.Wrap(err, errs.NotFound, "load user", "user_id", id, "table", "users")
+	}
+	return name, errs.Wrap(err, errs.Internal, "load user")
+}
+
+// getProfile 是 service 层：只用标准库的 %w 添加上下文。
+func getProfile(id int) (string, error) {
+	name, err := loadUser(id)
+	if err != nil {
+		return "", fmt.Errorf("get profile: %w", err)
+	}
+	return name, nil
+}
+
+// handle 是 handler 层：补充请求信息，错误码为空表示沿用内层的。
+func handle(id int) error {
+	_, err := getProfile(id)
+	return errs.Wrap(err, "", "GET /users/"+fmt.Sprint(id), "request_id", "req-7")
+}
+
+func main() {
+	sentinels()
+	chain()
+	division()
+	join()
+	contexts()
+	mappings()
+	logging()
+	nilAndStacks()
+}
+
+func sentinels() {
+	fmt.Println("=== 1. 带错误码的哨兵错误 ===")
+	find := func(id int) error {
+		switch id {
+		case 1:
+			return ErrUserNotFound.With("user_id", id)
+		case 2:
+			return ErrPermissionDenied
+		}
+		return nil
+	}
+	err := find(1)
+	fmt.Printf("  errors.Is(err, ErrUserNotFound) = %t：With 派生的副本仍然匹配哨兵\n", errors.Is(err, ErrUserNotFound))
+	fmt.Printf("  errors.Is(err, errs.NotFound) = %t：按错误码匹配\n", errors.Is(err, errs.NotFound))
+	fmt.Printf("  errors.Is(err, ErrPermissionDenied) = %t\n", errors.Is(err, ErrPermissionDenied))
+	fmt.Printf("  With 不修改哨兵本身：哨兵字段数 %d\n", len(ErrUserNotFound.Fields))
+	fmt.Printf("  HTTPStatus：权限错误 %d，nil %d\n", errs.HTTPStatus(find(2)), errs.HTTPStatus(find(3)))
+	fmt.Println()
+}
+
+func chain() {
+	fmt.Println("=== 2. 三层错误链 ===")
+	err := handle(42)
+	fmt.Printf("  err.Error(): %v\n", err)
+	fmt.Printf("  errors.Is(err, errs.NotFound) = %t：穿过 fmt.Errorf 的 %%w\n", errors.Is(err, errs.NotFound))
+	fmt.Printf("  errors.Is(err, errNoRows) = %t：最内层的原因仍然可见\n", errors.Is(err, errNoRows))
+	fmt.Printf("  CodeOf = %s：handler 层的错误码为空，沿用 repository 层的\n", errs.CodeOf(err))
+	fmt.Printf("  HTTP %d，gRPC %v\n", errs.HTTPStatus(err), errs.GRPCStatus(err))
+
+	var e *errs.Error
+	errors.As(err, &e)
+	fmt.Printf("  errors.As 取到最外层 *Error：%q\n", e.Message)
+
+	keys := []string{}
+	for _, a := range errs.FieldsOf(err) {
+		keys = append(keys, a.Key)
+	}
+	fmt.Printf("  FieldsOf 汇总整条链的字段：%v\n", keys)
+
+	st := errs.StackOf(err)
+	fmt.Printf("  StackOf 返回最接近源头的调用栈，创建位置是 %s\n", filepath.Base(st.Caller().Function))
+	fmt.Printf("  外层 Wrap 发现链中已有调用栈，不再重复记录：e.Stack() == nil 为 %t\n", e.Stack() == nil)
+
+	fmt.Printf("  %%+v 输出（只显示前 8 行）：\n")
+	lines := strings.Split(strings.TrimSpace(fmt.Sprintf("%+v", e)), "\n")
+	for i, l := range lines {
+		if i == 8 {
+			fmt.Printf("    | ...（共 %d 行）\n", len(lines))
+			break
+		}
+		fmt.Printf("    | %s\n", trimPath(l))
+	}
+	fmt.Printf("  %%q: %q\n", e)
+	fmt.Println()
+}
+
+// DivisionError 的 errs 版本：不需要单独定义类型，被除数作为字段记录。
+func divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errs.New(errs.InvalidArgument, "除数不能为零", "dividend", a, "divisor", b)
+	}
+	return a / b, nil
+}
+
+func division() {
+	fmt.Println("=== 3. 替代自定义错误类型 DivisionError ===")
+	_, err := divide(10, 0)
+	var e *errs.Error
+	if errors.As(err, &e) {
+		fmt.Printf("  errors.As 取出 *errs.Error：%v（code=%s），字段：%v\n", err, e.Code, e.Fields)
+	}
+	q, err := divide(10, 2)
+	fmt.Printf("  divide(10, 2) = %v, %v\n", q, err)
+	fmt.Println()
+}
+
+func join() {
+	fmt.Println("=== 4. errors.Join 合并多个错误 ===")
+	err := errors.Join(
+		errs.New(errs.InvalidArgument, "name 不能为空", "field", "name"),
+		errs.New(errs.OutOfRange, "age 超出范围", "field", "age", "value", 200),
+	)
+	fmt.Printf("  errors.Is：InvalidArgument %t，OutOfRange %t\n", errors.Is(err, errs.InvalidArgument), errors.Is(err, errs.OutOfRange))
+	fmt.Printf("  CodeOf 取第一个：%s，HTTP %d\n", errs.CodeOf(err), errs.HTTPStatus(err))
+	fmt.Printf("  FieldsOf 同名键只保留第一个：%v\n", errs.FieldsOf(err))
+	fmt.Println()
+}
+
+func contexts() {
+	fmt.Println("=== 5. context 错误 ===")
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	err := fmt.Errorf("call inventory: %w", ctx.Err())
+	for _, c := range []struct {
+		name string
+		err  error
+	}{
+		{"超时", err},
+		{"取消", fmt.Errorf("x: %w", context.Canceled)},
+		{"普通错误", errors.New("plain")},
+		{"Code 本身作为错误", fmt.Errorf("x: %w", errs.Unavailable)},
+	} {
+		fmt.Printf("  %s：CodeOf = %s，HTTP %d\n", c.name, errs.CodeOf(c.err), errs.HTTPStatus(c.err))
+	}
+	fmt.Println()
+}
+
+func mappings() {
+	fmt.Println("=== 6. 错误码映射表 ===")
+	const quota errs.Code = "quota_exceeded"
+	errs.RegisterCode(quota, 429, errs.GRPCResourceExhausted)
+	fmt.Println("  重复登记 panic:", panics(func() { errs.RegisterCode(quota, 400, errs.GRPCInvalidArgument) }))
+
+	fmt.Printf("    %-20s %-5s %s\n", "code", "HTTP", "gRPC")
+	for _, m := range errs.Mappings() {
+		fmt.Printf("    %-20s %-5d %d %v\n", m.Code, m.HTTP, m.GRPC, m.GRPC)
+	}
+
+	fmt.Printf("  自定义错误码 %s 的 HTTP 状态码：%d\n", quota, errs.HTTPStatus(errs.New(quota, "每日额度已用完")))
+	for _, status := range []int{204, 401, 409, 418, 502} {
+		fmt.Printf("  FromHTTPStatus(%d) = %s\n", status, errs.FromHTTPStatus(status))
+	}
+	fmt.Println()
+}
+
+func logging() {
+	fmt.Println("=== 7. slog 集成 ===")
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch {
+			case len(groups) == 0 && a.Key == slog.TimeKey:
+				return slog.Attr{}
+			case a.Key == "at":
+				a.Value = slog.StringValue(filepath.Base(a.Value.String()))
+			}
+			return a
+		},
+	}))
+	err := handle(42)
+	logger.Error("request failed", "error", err)
+	out := strings.TrimSpace(buf.String())
+	fmt.Printf("    %s\n", out)
+
+	buf.Reset()
+	logger.Warn("nested", "error", errs.Wrap(ErrPermissionDenied.With("role", "guest"), errs.Internal, "audit"))
+	out = strings.TrimSpace(buf.String())
+	fmt.Printf("    %s\n", out)
+	fmt.Println("  外层展开为 code 和字段；原因不是 *Error 时记录文字，是 *Error 时嵌套展开，at 记录创建位置")
+	fmt.Println()
+}
+
+func nilAndStacks() {
+	fmt.Println("=== 8. nil 与调用栈开关 ===")
+	err := errs.Wrap(nil, errs.Internal, "never")
+	fmt.Printf("  Wrap(nil, ...) == nil 为 %t：返回 error 而不是 *Error，避开第27章的接口 nil 陷阱\n", err == nil)
+
+	prev := errs.SetStackCapture(false)
+	e := errs.New(errs.Internal, "hot path")
+	errs.SetStackCapture(prev)
+	fmt.Printf("  SetStackCapture(false) 后调用栈 %d 帧，恢复后 %d 帧\n", len(e.Stack()), len(errs.New(errs.Internal, "x").Stack()))
+	fmt.Printf("  零值 Error 的文字：%q\n", (&errs.Error{}).Error())
+}
+
+// trimPath 把 %+v 输出中的绝对路径缩短成文件名，方便阅读。
+func trimPath(line string) string {
+	if i := strings.LastIndex(line, "/"); i >= 0 && strings.HasPrefix(strings.TrimSpace(line), "/") {
+		return line[:strings.Index(line, "/")] + line[i+1:]
+	}
+	return line
+}
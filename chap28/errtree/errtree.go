@@ -0,0 +1,168 @@
+// Package errtree 把错误链展开成树，便于查看和检查多层包装的错误。
+//
+// chap28 的 complexOperation → step1/step2、processData → readFile 用 fmt.Errorf("%w") 层层包装，
+// 平时只能 fmt.Println(err) 看到拼在一起的一行字。Build 沿 Unwrap() error 和
+// Unwrap() []error（errors.Join、带多个 %w 的 fmt.Errorf）把错误展开成树，
+// Render、JSON 把树输出成缩进文本或 JSON，FindAll、FindType 找出每一个匹配的节点。
+// 断言错误链形状的测试辅助见子包 errtreetest。
+package errtree
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MaxDepth 是展开的最大深度，防止自定义的 Unwrap 形成环时无限递归。
+const MaxDepth = 64
+
+// Node 是错误树中的一个节点。
+type Node struct {
+	Err      error   `json:"-"`
+	Type     string  `json:"type"`               // 动态类型，例如 *fmt.wrapError
+	Message  string  `json:"message"`            // 这一层自己的文字，见 Build
+	Text     string  `json:"error"`              // Err.Error() 的完整文字
+	Depth    int     `json:"-"`                  // 根节点为 0
+	Children []*Node `json:"children,omitempty"` // 被包装的错误，按 Unwrap 的顺序
+}
+
+// Build 把 err 展开成树，err 为 nil 时返回 nil。
+//
+// Message 是这一层自己添加的文字：叶子节点为完整文字；只有一个子节点、且完整文字形如
+// "前缀: 子节点文字" 时为前缀（fmt.Errorf("步骤1失败: %w", err) 得到 "步骤1失败"）；
+// 其余情况把子节点的文字依次替换成 %w（"导入失败: %w（另外 %w）"），
+// 文字完全由子节点拼成（errors.Join）时为空，找不到子节点的文字时保留完整文字。
+func Build(err error) *Node {
+	return build(err, 0)
+}
+
+func build(err error, depth int) *Node {
+	if err == nil {
+		return nil
+	}
+	n := &Node{Err: err, Type: fmt.Sprintf("%T", err), Text: err.Error(), Depth: depth}
+	n.Message = n.Text
+	if depth >= MaxDepth {
+		return n
+	}
+	for _, c := range unwrap(err) {
+		if c != nil {
+			n.Children = append(n.Children, build(c, depth+1))
+		}
+	}
+	n.Message = ownMessage(n)
+	return n
+}
+
+// ownMessage 从完整文字中去掉子节点的文字，得到这一层自己添加的部分。
+func ownMessage(n *Node) string {
+	if len(n.Children) == 0 {
+		return n.Text
+	}
+	if len(n.Children) == 1 {
+		if own, ok := strings.CutSuffix(n.Text, ": "+n.Children[0].Text); ok {
+			return own
+		}
+	}
+	// 按顺序把每个子节点的文字替换成 %w，还原出格式串；找不到时保留完整文字
+	var b strings.Builder
+	rest := n.Text
+	for _, c := range n.Children {
+		i := strings.Index(rest, c.Text)
+		if i < 0 {
+			return n.Text
+		}
+		b.WriteString(rest[:i])
+		b.WriteString("%w")
+		rest = rest[i+len(c.Text):]
+	}
+	b.WriteString(rest)
+	own := b.String()
+	if strings.Trim(strings.ReplaceAll(own, "%w", ""), "\n") == "" {
+		return "" // errors.Join 或直接转发：文字完全由子节点组成
+	}
+	return own
+}
+
+// unwrap 返回 err 直接包装的错误。
+func unwrap(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if c := u.Unwrap(); c != nil {
+			return []error{c}
+		}
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// Walk 先序遍历以 n 为根的树，fn 返回 false 时不再进入该节点的子节点。
+func (n *Node) Walk(fn func(*Node) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Len 返回树中的节点数。
+func (n *Node) Len() int {
+	count := 0
+	n.Walk(func(*Node) bool { count++; return true })
+	return count
+}
+
+// Leaves 返回所有叶子节点，也就是错误的根源，按从左到右的顺序。
+func (n *Node) Leaves() []*Node {
+	var out []*Node
+	n.Walk(func(n *Node) bool {
+		if len(n.Children) == 0 {
+			out = append(out, n)
+		}
+		return true
+	})
+	return out
+}
+
+// Find 按先序返回所有满足 match 的节点。
+func Find(err error, match func(*Node) bool) []*Node {
+	var out []*Node
+	Build(err).Walk(func(n *Node) bool {
+		if match(n) {
+			out = append(out, n)
+		}
+		return true
+	})
+	return out
+}
+
+// FindAll 返回每一个本身与 target 匹配的节点：节点的错误 == target，或者它的 Is 方法对 target 返回 true。
+// 与 errors.Is 不同，匹配不会向下传递，所以包装了 target 的外层节点不算；
+// 例如 syscall.ENOENT 对 fs.ErrNotExist 匹配，包装它的 *fs.PathError 不匹配。
+func FindAll(err, target error) []*Node {
+	return Find(err, func(n *Node) bool { return n.Matches(target) })
+}
+
+// Matches 报告节点本身（不看子节点）是否与 target 匹配，也就是不向下展开的 errors.Is。
+func (n *Node) Matches(target error) bool {
+	if target != nil && reflect.TypeOf(target).Comparable() && n.Err == target {
+		return true
+	}
+	x, ok := n.Err.(interface{ Is(error) bool })
+	return ok && x.Is(target)
+}
+
+// FindType 返回树中每一个动态类型为 T（或实现了接口 T）的错误，按先序排列。
+// 与 errors.As 不同，它返回所有匹配而不只是第一个，也不调用 As 方法。
+func FindType[T error](err error) []T {
+	var out []T
+	Build(err).Walk(func(n *Node) bool {
+		if t, ok := n.Err.(T); ok {
+			out = append(out, t)
+		}
+		return true
+	})
+	return out
+}
@@ -0,0 +1,185 @@
+package errtree_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"books/chap28/errs"
+	"books/chap28/errtree"
+	"books/chap28/errtree/errtreetest"
+)
+
+// 与 chap28/error_handling.go、errtree_demo 相同的函数。
+
+func readFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("读取文件 %s 失败: %w", filename, err)
+	}
+	return string(data), nil
+}
+
+func processData(filename string) error {
+	data, err := readFile(filename)
+	if err != nil {
+		return fmt.Errorf("处理数据时出错: %w", err)
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("数据为空")
+	}
+	return nil
+}
+
+var (
+	errEmptyInput = errors.New("输入不能为空")
+	errTooShort   = errors.New("输入长度不足")
+)
+
+func complexOperation(input string) (string, error) {
+	if input == "" {
+		return "", fmt.Errorf("步骤1失败: %w", errEmptyInput)
+	}
+	if len(input+"_step1") < 12 {
+		return "", fmt.Errorf("步骤2失败: %w", errTooShort)
+	}
+	return input + "_step1_step2", nil
+}
+
+func importBatch(files, inputs []string) error {
+	var all []error
+	for _, f := range files {
+		if err := processData(f); err != nil {
+			all = append(all, err)
+		}
+	}
+	for _, in := range inputs {
+		if _, err := complexOperation(in); err != nil {
+			all = append(all, err)
+		}
+	}
+	audit := errs.Wrap(errs.New(errs.PermissionDenied, "审计日志只读"), errs.Unavailable, "写审计日志", "batch", 7)
+	return fmt.Errorf("导入失败: %w（另外 %w）", errors.Join(all...), audit)
+}
+
+// missing 返回临时目录里一个不存在的文件。
+func missing(t *testing.T, name string) string {
+	return filepath.Join(t.TempDir(), name)
+}
+
+func TestBuildSingleChain(t *testing.T) {
+	_, err := complexOperation("ab")
+	errtreetest.Assert(t, err, errtreetest.Wrapf("步骤2失败", errtreetest.Leaf(errTooShort)))
+	n := errtree.Build(err)
+	if n.Len() != 2 || n.Depth != 0 || n.Children[0].Depth != 1 || n.Children[0].Message != "输入长度不足" {
+		t.Errorf("Build = %+v", n)
+	}
+	if errtree.Build(nil) != nil || errtree.Render(nil) != "" || errtree.Shape(nil) != "nil" {
+		t.Error("nil error should give an empty tree")
+	}
+}
+
+func TestFileChain(t *testing.T) {
+	file := missing(t, "data.txt")
+	err := processData(file)
+	errtreetest.Assert(t, err, errtreetest.Wrapf("处理数据时出错",
+		errtreetest.Wrapf("读取文件 "+file+" 失败",
+			errtreetest.Spec{Type: "*fs.PathError", Message: "open " + file, Children: []errtreetest.Spec{
+				errtreetest.Leaf(fs.ErrNotExist),
+			}})))
+	errtreetest.AssertShape(t, err, "*fmt.wrapError(*fmt.wrapError(*fs.PathError(syscall.Errno)))")
+
+	want := "*fmt.wrapError: 处理数据时出错\n" +
+		"└── *fmt.wrapError: 读取文件 " + file + " 失败\n" +
+		"    └── *fs.PathError: open " + file + "\n" +
+		"        └── syscall.Errno: no such file or directory\n"
+	if got := errtree.Render(err); got != want {
+		t.Errorf("Render:\n%s\nwant:\n%s", got, want)
+	}
+
+	// FindAll 只命中本身匹配的节点，包装它的 *fs.PathError 和两层 wrapError 不算
+	if hits := errtree.FindAll(err, fs.ErrNotExist); len(hits) != 1 || hits[0].Type != "syscall.Errno" || hits[0].Depth != 3 {
+		t.Errorf("FindAll(fs.ErrNotExist) = %v", hits)
+	}
+	if paths := errtree.FindType[*fs.PathError](err); len(paths) != 1 || paths[0].Op != "open" || paths[0].Path != file {
+		t.Errorf("FindType[*fs.PathError] = %v", paths)
+	}
+	if errnos := errtree.FindType[syscall.Errno](err); len(errnos) != 1 || errnos[0] != syscall.ENOENT {
+		t.Errorf("FindType[syscall.Errno] = %v", errnos)
+	}
+}
+
+func TestJoinAndMultipleW(t *testing.T) {
+	err := importBatch([]string{missing(t, "a.txt"), missing(t, "b.txt")}, []string{"", "abc"})
+	n := errtree.Build(err)
+	if n.Message != "导入失败: %w（另外 %w）" || n.Children[0].Message != "" {
+		t.Errorf("messages: %q, %q", n.Message, n.Children[0].Message)
+	}
+	errtreetest.AssertShape(t, err, "*fmt.wrapErrors(*errors.joinError("+
+		"*fmt.wrapError(*fmt.wrapError(*fs.PathError(syscall.Errno))), "+
+		"*fmt.wrapError(*fmt.wrapError(*fs.PathError(syscall.Errno))), "+
+		"*fmt.wrapError(*errors.errorString), *fmt.wrapError(*errors.errorString)), "+
+		"*errs.Error(*errs.Error))")
+
+	var texts []string
+	for _, l := range n.Leaves() {
+		texts = append(texts, l.Message)
+	}
+	if got := strings.Join(texts, " | "); got != "no such file or directory | no such file or directory | 输入不能为空 | 输入长度不足 | 审计日志只读" {
+		t.Errorf("Leaves = %s", got)
+	}
+	for target, count := range map[error]int{fs.ErrNotExist: 2, errEmptyInput: 1, errTooShort: 1, errs.PermissionDenied: 1, errs.Unavailable: 1} {
+		if got := len(errtree.FindAll(err, target)); got != count {
+			t.Errorf("FindAll(%v) = %d nodes, want %d", target, got, count)
+		}
+	}
+	var codes []string
+	for _, e := range errtree.FindType[*errs.Error](err) {
+		codes = append(codes, string(e.Code))
+	}
+	if strings.Join(codes, ",") != "unavailable,permission_denied" {
+		t.Errorf("FindType[*errs.Error] codes = %v", codes)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	_, err := complexOperation("")
+	data, jerr := errtree.JSON(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var decoded struct {
+		Type     string
+		Message  string
+		Children []struct{ Type, Error string }
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != "*fmt.wrapError" || decoded.Message != "步骤1失败" ||
+		len(decoded.Children) != 1 || decoded.Children[0].Error != "输入不能为空" {
+		t.Errorf("decoded %+v from\n%s", decoded, data)
+	}
+	if null, _ := errtree.JSON(nil); string(null) != "null" {
+		t.Errorf("JSON(nil) = %s", null)
+	}
+}
+
+// loop 的 Unwrap 返回自己，展开必须在 MaxDepth 处停下。
+type loop struct{}
+
+func (l *loop) Error() string { return "loop" }
+func (l *loop) Unwrap() error { return l }
+
+func TestMaxDepth(t *testing.T) {
+	n := errtree.Build(&loop{})
+	if got := n.Len(); got != errtree.MaxDepth+1 {
+		t.Errorf("Len = %d, want %d", got, errtree.MaxDepth+1)
+	}
+}
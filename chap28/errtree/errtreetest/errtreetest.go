@@ -0,0 +1,95 @@
+// Package errtreetest 断言错误链的形状，供测试使用。
+//
+// TB 是 testing.TB 的子集，测试中直接传入 *testing.T，用法见 chap28/errtree 的测试。
+package errtreetest
+
+import (
+	"fmt"
+	"strings"
+
+	"books/chap28/errtree"
+)
+
+// TB 是断言需要的 testing.TB 方法。
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Spec 描述期望的一个节点，零值字段表示不检查。
+type Spec struct {
+	Type     string // 动态类型（%T），例如 *fmt.wrapError
+	Message  string // 这一层自己的文字（errtree.Node.Message）
+	Is       error  // 节点本身应当与它匹配，规则同 errtree.FindAll
+	Children []Spec // 子节点，个数必须完全一致；叶子节点写 nil
+}
+
+// Wrapf 描述 fmt.Errorf("msg: %w", child) 产生的节点。
+func Wrapf(msg string, child Spec) Spec {
+	return Spec{Type: "*fmt.wrapError", Message: msg, Children: []Spec{child}}
+}
+
+// Join 描述 errors.Join(children...) 产生的节点。
+func Join(children ...Spec) Spec {
+	return Spec{Type: "*errors.joinError", Children: children}
+}
+
+// Leaf 描述一个与 target 匹配、没有包装其他错误的节点。
+func Leaf(target error) Spec {
+	return Spec{Is: target}
+}
+
+// Check 比较 err 的树和期望，返回所有不一致之处；完全一致时返回 nil。
+// 路径形如 root/1/0：根节点的第 2 个子节点的第 1 个子节点。
+func Check(err error, want Spec) []string {
+	var diffs []string
+	check(errtree.Build(err), want, "root", &diffs)
+	return diffs
+}
+
+func check(n *errtree.Node, want Spec, path string, diffs *[]string) {
+	add := func(format string, args ...any) {
+		*diffs = append(*diffs, path+": "+fmt.Sprintf(format, args...))
+	}
+	if n == nil {
+		add("got nil error")
+		return
+	}
+	if want.Type != "" && n.Type != want.Type {
+		add("type = %s, want %s", n.Type, want.Type)
+	}
+	if want.Message != "" && n.Message != want.Message {
+		add("message = %q, want %q", n.Message, want.Message)
+	}
+	if want.Is != nil && !n.Matches(want.Is) {
+		add("%s (%q) does not match %v", n.Type, n.Text, want.Is)
+	}
+	if len(n.Children) != len(want.Children) {
+		add("%d children, want %d", len(n.Children), len(want.Children))
+		return
+	}
+	for i, c := range n.Children {
+		check(c, want.Children[i], fmt.Sprintf("%s/%d", path, i), diffs)
+	}
+}
+
+// Assert 检查 err 的树与 want 完全一致，不一致时通过 t.Errorf 报告每一处差异和实际的树。
+func Assert(t TB, err error, want Spec) bool {
+	t.Helper()
+	diffs := Check(err, want)
+	if len(diffs) == 0 {
+		return true
+	}
+	t.Errorf("error tree mismatch:\n  %s\ngot:\n%s", strings.Join(diffs, "\n  "), strings.TrimRight(errtree.Render(err), "\n"))
+	return false
+}
+
+// AssertShape 检查 errtree.Shape(err) 等于 want。
+func AssertShape(t TB, err error, want string) bool {
+	t.Helper()
+	if got := errtree.Shape(err); got != want {
+		t.Errorf("error shape:\n  got  %s\n  want %s", got, want)
+		return false
+	}
+	return true
+}
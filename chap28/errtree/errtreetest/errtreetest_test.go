@@ -0,0 +1,63 @@
+package errtreetest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recorder 记录失败而不是让测试失败，用来检查断言报告的内容。
+type recorder struct{ failures []string }
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+var errTooShort = errors.New("输入长度不足")
+
+func TestAssertPasses(t *testing.T) {
+	err := fmt.Errorf("步骤2失败: %w", errTooShort)
+	if !Assert(t, err, Wrapf("步骤2失败", Leaf(errTooShort))) {
+		t.Error("Assert returned false for a matching tree")
+	}
+	if !AssertShape(t, errors.Join(err, errTooShort), "*errors.joinError(*fmt.wrapError(*errors.errorString), *errors.errorString)") {
+		t.Error("AssertShape returned false for a matching shape")
+	}
+}
+
+func TestAssertReportsDiffs(t *testing.T) {
+	err := fmt.Errorf("步骤2失败: %w", errTooShort)
+	wrong := Join(Wrapf("步骤1失败", Leaf(errors.New("输入不能为空"))))
+
+	diffs := Check(err, wrong)
+	want := []string{
+		"root: type = *fmt.wrapError, want *errors.joinError",
+		`root/0: type = *errors.errorString, want *fmt.wrapError`,
+		`root/0: message = "输入长度不足", want "步骤1失败"`,
+		"root/0: 0 children, want 1",
+	}
+	if strings.Join(diffs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Check:\n%s\nwant:\n%s", strings.Join(diffs, "\n"), strings.Join(want, "\n"))
+	}
+
+	r := &recorder{}
+	if Assert(r, err, wrong) || len(r.failures) != 1 {
+		t.Fatalf("Assert on a mismatch: %d failures", len(r.failures))
+	}
+	if f := r.failures[0]; !strings.Contains(f, want[0]) || !strings.HasSuffix(f, "got:\n*fmt.wrapError: 步骤2失败\n└── *errors.errorString: 输入长度不足") {
+		t.Errorf("report:\n%s", f)
+	}
+
+	r = &recorder{}
+	if AssertShape(r, err, "*fmt.wrapError") || len(r.failures) != 1 {
+		t.Error("AssertShape did not report a mismatch")
+	}
+	if diffs := Check(nil, Leaf(errTooShort)); len(diffs) != 1 || diffs[0] != "root: got nil error" {
+		t.Errorf("Check(nil) = %v", diffs)
+	}
+	if diffs := Check(err, Spec{Is: errTooShort, Children: []Spec{{}}}); len(diffs) != 1 || !strings.Contains(diffs[0], "does not match") {
+		t.Errorf("Is checks the node itself, not its children: %v", diffs)
+	}
+}
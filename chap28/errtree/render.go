@@ -0,0 +1,81 @@
+package errtree
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Render 把 err 的树输出成缩进文本，每个节点一行“类型: 这一层的文字”，err 为 nil 时返回空字符串：
+//
+//	*fmt.wrapError: 处理数据时出错
+//	└── *fmt.wrapError: 读取文件 missing.txt 失败
+//	    └── *fs.PathError: open missing.txt
+//	        └── syscall.Errno: no such file or directory
+func Render(err error) string {
+	var b strings.Builder
+	Fprint(&b, err)
+	return b.String()
+}
+
+// Fprint 把 Render 的结果写入 w。
+func Fprint(w io.Writer, err error) error {
+	n := Build(err)
+	if n == nil {
+		return nil
+	}
+	return n.render(w, "", "")
+}
+
+func (n *Node) render(w io.Writer, first, rest string) error {
+	line := first + n.Type
+	if n.Message != "" {
+		line += ": " + strings.ReplaceAll(n.Message, "\n", `\n`)
+	}
+	if _, err := io.WriteString(w, line+"\n"); err != nil {
+		return err
+	}
+	for i, c := range n.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if err := c.render(w, rest+branch, rest+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSON 把 err 的树编码成缩进的 JSON：每个节点包含 type、message、error 和 children。
+// err 为 nil 时返回 null。
+func JSON(err error) ([]byte, error) {
+	return json.MarshalIndent(Build(err), "", "  ")
+}
+
+// Shape 返回树的紧凑形状，只包含类型：*fmt.wrapError(*errors.joinError(*errors.errorString, *fs.PathError(syscall.Errno)))。
+// 适合在测试中整体比较错误链的结构，err 为 nil 时返回 "nil"。
+func Shape(err error) string {
+	n := Build(err)
+	if n == nil {
+		return "nil"
+	}
+	var b strings.Builder
+	n.shape(&b)
+	return b.String()
+}
+
+func (n *Node) shape(b *strings.Builder) {
+	b.WriteString(n.Type)
+	if len(n.Children) == 0 {
+		return
+	}
+	b.WriteByte('(')
+	for i, c := range n.Children {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		c.shape(b)
+	}
+	b.WriteByte(')')
+}
@@ -0,0 +1,196 @@
+// 独立运行：go run ./chap28/errtree_demo
+// 演示：把 chap28 的 complexOperation、processData 产生的多层包装错误展开成树，
+// 输出缩进文本和 JSON，找出所有匹配的节点，并展示 errtreetest 如何描述错误链的形状。
+// 断言见 go test ./chap28/errtree/...。
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+
+	"books/chap28/errs"
+	"books/chap28/errtree"
+	"books/chap28/errtree/errtreetest"
+)
+
+// printTree 缩进输出 errtree.Render 的结果。
+func printTree(err error) {
+	for _, line := range strings.Split(strings.TrimRight(errtree.Render(err), "\n"), "\n") {
+		fmt.Println("    " + line)
+	}
+}
+
+// ---- 与 chap28/error_handling.go 相同的函数 ----
+
+func readFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("读取文件 %s 失败: %w", filename, err)
+	}
+	return string(data), nil
+}
+
+func processData(filename string) error {
+	data, err := readFile(filename)
+	if err != nil {
+		return fmt.Errorf("处理数据时出错: %w", err)
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("数据为空")
+	}
+	return nil
+}
+
+var (
+	errEmptyInput = errors.New("输入不能为空")
+	errTooShort   = errors.New("输入长度不足")
+)
+
+func complexOperation(input string) (string, error) {
+	result, err := step1(input)
+	if err != nil {
+		return "", fmt.Errorf("步骤1失败: %w", err)
+	}
+	result, err = step2(result)
+	if err != nil {
+		return "", fmt.Errorf("步骤2失败: %w", err)
+	}
+	return result, nil
+}
+
+func step1(input string) (string, error) {
+	if input == "" {
+		return "", errEmptyInput
+	}
+	return input + "_step1", nil
+}
+
+func step2(input string) (string, error) {
+	if len(input) < 12 {
+		return "", errTooShort
+	}
+	return input + "_step2", nil
+}
+
+// importBatch 处理一批输入，把所有失败用 errors.Join 合并，再用带两个 %w 的 fmt.Errorf 附上审计错误。
+func importBatch(files, inputs []string) error {
+	var all []error
+	for _, f := range files {
+		if err := processData(f); err != nil {
+			all = append(all, err)
+		}
+	}
+	for _, in := range inputs {
+		if _, err := complexOperation(in); err != nil {
+			all = append(all, err)
+		}
+	}
+	batch := errors.Join(all...)
+	audit := errs.Wrap(errs.New(errs.PermissionDenied, "审计日志只读"), errs.Unavailable, "写审计日志", "batch", 7)
+	return fmt.Errorf("导入失败: %w（另外 %w）", batch, audit)
+}
+
+const missing = "missing-chap28.txt"
+
+func main() {
+	singleChain()
+	fileChain()
+	batch()
+	jsonOutput()
+	assertions()
+}
+
+func singleChain() {
+	fmt.Println("=== 1. complexOperation 的错误链 ===")
+	_, err := complexOperation("ab")
+	fmt.Printf("  fmt.Println(err)：%v\n", err)
+	printTree(err)
+	n := errtree.Build(err)
+	fmt.Printf("  %d 个节点，Message 只保留每层自己的文字：%q、%q\n", n.Len(), n.Message, n.Children[0].Message)
+	fmt.Printf("  nil 错误：Build = %v，Shape = %s\n", errtree.Build(nil), errtree.Shape(nil))
+	fmt.Println()
+}
+
+func fileChain() {
+	fmt.Println("=== 2. processData 的错误链 ===")
+	err := processData(missing)
+	printTree(err)
+
+	for _, h := range errtree.FindAll(err, fs.ErrNotExist) {
+		fmt.Printf("  FindAll(err, fs.ErrNotExist) 命中 %s（深度 %d），errors.Is 只能回答“有没有”\n", h.Type, h.Depth)
+	}
+	for _, p := range errtree.FindType[*fs.PathError](err) {
+		fmt.Printf("  FindType[*fs.PathError]：Op=%s Path=%s\n", p.Op, p.Path)
+	}
+	for _, e := range errtree.FindType[syscall.Errno](err) {
+		fmt.Printf("  FindType[syscall.Errno]：%d（ENOENT=%t）\n", uint(e), e == syscall.ENOENT)
+	}
+	fmt.Println()
+}
+
+func batch() {
+	fmt.Println("=== 3. errors.Join 与多个 %w ===")
+	err := importBatch([]string{missing, "another-" + missing}, []string{"", "abc"})
+	printTree(err)
+	n := errtree.Build(err)
+	fmt.Printf("  多个 %%w 的节点还原出格式串 %q，errors.Join 节点的文字为 %q\n", n.Message, n.Children[0].Message)
+
+	leaves := n.Leaves()
+	texts := make([]string, len(leaves))
+	for i, l := range leaves {
+		texts[i] = l.Message
+	}
+	fmt.Printf("  %d 个根源：%s\n", len(leaves), strings.Join(texts, " | "))
+	fmt.Printf("  FindAll：文件不存在 %d 处，输入为空 %d 处，长度不足 %d 处\n", len(errtree.FindAll(err, fs.ErrNotExist)),
+		len(errtree.FindAll(err, errEmptyInput)), len(errtree.FindAll(err, errTooShort)))
+
+	codes := []string{}
+	for _, e := range errtree.FindType[*errs.Error](err) {
+		codes = append(codes, string(e.Code))
+	}
+	var first *errs.Error
+	errors.As(err, &first)
+	fmt.Printf("  FindType[*errs.Error] 返回全部 %d 个：%v；errors.As 只给出第一个：%s\n", len(codes), codes, first.Code)
+	fmt.Printf("  按 errs 错误码查找：PermissionDenied %d 个节点\n", len(errtree.FindAll(err, errs.PermissionDenied)))
+	fmt.Println()
+}
+
+func jsonOutput() {
+	fmt.Println("=== 4. JSON 输出 ===")
+	_, err := complexOperation("")
+	data, jerr := errtree.JSON(err)
+	if jerr != nil {
+		fmt.Println("  ", jerr)
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Println("    " + line)
+	}
+	null, _ := errtree.JSON(nil)
+	fmt.Printf("  nil 错误编码为 %s\n", null)
+	fmt.Println()
+}
+
+func assertions() {
+	fmt.Println("=== 5. errtreetest 断言错误链的形状 ===")
+	err := processData(missing)
+	want := errtreetest.Wrapf("处理数据时出错",
+		errtreetest.Wrapf("读取文件 "+missing+" 失败",
+			errtreetest.Spec{Type: "*fs.PathError", Message: "open " + missing, Children: []errtreetest.Spec{
+				errtreetest.Leaf(fs.ErrNotExist),
+			}}))
+
+	// 测试中用 errtreetest.Assert(t, err, want)，不一致时通过 t.Errorf 报告
+	fmt.Printf("  processData 与期望的四层结构差异 %d 处；Shape：%s\n", len(errtreetest.Check(err, want)), errtree.Shape(err))
+
+	_, err = complexOperation("ab")
+	wrong := errtreetest.Join(errtreetest.Wrapf("步骤1失败", errtreetest.Leaf(errEmptyInput)))
+	fmt.Println("  形状不符时 Check 报告每一处差异：")
+	for _, d := range errtreetest.Check(err, wrong) {
+		fmt.Println("    " + d)
+	}
+}
@@ -0,0 +1,79 @@
+package safe
+
+import (
+	"context"
+	"sync"
+)
+
+// Group 相当于会 recover 的 errgroup.Group：每个 goroutine 的 panic 变成 *PanicError，
+// runtime.Goexit 变成 ErrGoexit，都和普通错误一样由 Wait 返回，不会让整个进程崩溃。
+// 零值可以直接使用，此时没有关联的 context。
+type Group struct {
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	errOnce sync.Once
+	err     error
+	cancel  context.CancelCauseFunc
+}
+
+// GroupWithRecover 返回新的 Group 和从 ctx 派生的 context：
+// 第一个 goroutine 出错（包括 panic）或 Wait 返回时，这个 context 被取消，
+// context.Cause 返回那个错误。
+func GroupWithRecover(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit 限制同时运行的 goroutine 数，n < 0 表示不限制。
+// 必须在调用 Go 之前设置。
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic("safe: modify limit while goroutines in the group are still active")
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go 在新的 goroutine 中调用 f；达到 SetLimit 的限制时阻塞，直到有 goroutine 结束。
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		guard(f, g.report)
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// report 记录第一个错误并取消 context。
+func (g *Group) report(err error) {
+	if err == nil {
+		return
+	}
+	g.errOnce.Do(func() {
+		g.err = err
+		if g.cancel != nil {
+			g.cancel(err)
+		}
+	})
+}
+
+// Wait 等待所有 goroutine 结束，返回第一个错误（可能是 *PanicError 或 ErrGoexit）。
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(g.err)
+	}
+	return g.err
+}
@@ -0,0 +1,126 @@
+// Package safe 把 panic 转换成错误（第28章 safeFunction、safeDivide 的通用版本）。
+//
+// safeFunction 和 safeDivide 各自手写 defer func() { if r := recover(); r != nil {...} }()，
+// 只打印 panic 的值，safeDivide 出错后还返回 0 且没有错误。这里统一成：
+//
+//	err := safe.Do(func() error { ... })          // panic 变成 *PanicError
+//	v, err := safe.Call(func() int { return a / b })
+//	done := safe.Go(func() { ... })                // 新 goroutine，结果从通道取
+//	defer safe.Recover(&err)                       // 在已有函数里就地使用
+//
+// 语义按 Go 1.21（go.mod 中的 go 1.21）：
+//   - panic(nil) 被 recover 为 *runtime.PanicNilError，同样转换成 *PanicError；
+//   - 延迟函数中再次 panic 时，recover 得到的是最后一个值；
+//   - 重新 panic 一个 *PanicError 时原样返回，保留最初的调用栈；
+//   - runtime.Goexit 不是 panic，无法在当前 goroutine 中拦截，Do、Call 照常让它结束 goroutine；
+//     Go 和 Group 在自己启动的 goroutine 中把它报告为 ErrGoexit。
+package safe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// ErrGoexit 表示 goroutine 调用了 runtime.Goexit（例如在测试中调用了 t.FailNow）。
+var ErrGoexit = errors.New("safe: goroutine exited via runtime.Goexit")
+
+// PanicError 是被转换成错误的 panic。
+type PanicError struct {
+	Value any    // recover() 返回的值
+	Stack []byte // recover 时的调用栈，包含 panic 发生处的帧
+}
+
+// newPanicError 在延迟函数中调用，r 是 recover() 的结果。
+func newPanicError(r any) *PanicError {
+	if pe, ok := r.(*PanicError); ok {
+		return pe
+	}
+	return &PanicError{Value: r, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	if err, ok := e.Value.(error); ok {
+		return "panic: " + err.Error()
+	}
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap 在 panic 的值是 error 时返回它，因此 errors.Is、errors.As 可以看到原始错误，
+// 例如 errors.As(err, new(runtime.Error))。
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Format 实现 fmt.Formatter：%+v 在 Error() 之后输出调用栈，其他动词输出 Error()。
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	io.WriteString(s, e.Error())
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, "\n\n")
+		s.Write(e.Stack)
+	}
+}
+
+// Recover 直接作为延迟函数使用：defer safe.Recover(&err)。
+// 发生 panic 时把 *errp 设为 *PanicError（覆盖原来的值）；没有 panic 时不修改 *errp。
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		*errp = newPanicError(r)
+	}
+}
+
+// Do 调用 f，返回它的错误；f panic 时返回 *PanicError。
+func Do(f func() error) (err error) {
+	defer Recover(&err)
+	return f()
+}
+
+// Call 调用 f 并返回结果；f panic 时返回 T 的零值和 *PanicError。
+func Call[T any](f func() T) (v T, err error) {
+	defer Recover(&err)
+	return f(), nil
+}
+
+// Try 是返回 (T, error) 的函数的 Call；f panic 时返回 T 的零值和 *PanicError。
+func Try[T any](f func() (T, error)) (v T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero T
+			v, err = zero, newPanicError(r)
+		}
+	}()
+	return f()
+}
+
+// Go 在新的 goroutine 中运行 f，返回的通道在 f 结束后收到一个值并关闭：
+// 正常返回为 nil，panic 为 *PanicError，调用 runtime.Goexit 为 ErrGoexit。
+// 通道有缓冲，不读取也不会让 goroutine 阻塞。
+func Go(f func()) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		guard(func() error { f(); return nil }, func(err error) { done <- err })
+	}()
+	return done
+}
+
+// guard 在当前 goroutine 中调用 f，把结果交给 report，且只调用一次：
+// f 的返回值、panic 转换成的 *PanicError，或者 runtime.Goexit 对应的 ErrGoexit。
+// Goexit 会继续结束当前 goroutine，所以 guard 只能用在专门为 f 启动的 goroutine 中。
+func guard(f func() error, report func(error)) {
+	normal := false
+	defer func() {
+		if r := recover(); r != nil {
+			report(newPanicError(r))
+		} else if !normal {
+			// 没有 panic 值却没有正常返回，只能是 runtime.Goexit
+			// （Go 1.21 起 panic(nil) 会被 recover 成 *runtime.PanicNilError）
+			report(ErrGoexit)
+		}
+	}()
+	err := f()
+	normal = true
+	report(err)
+}
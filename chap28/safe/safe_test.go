@@ -0,0 +1,240 @@
+package safe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// asPanic 取出 *PanicError，不是时返回 nil。
+func asPanic(err error) *PanicError {
+	var pe *PanicError
+	errors.As(err, &pe)
+	return pe
+}
+
+// safeDivide、safeFunction 对应 chap28 的同名函数。
+func safeDivide(a, b int) (q int, err error) {
+	defer Recover(&err)
+	return a / b, nil
+}
+
+func safeFunction() error {
+	return Do(func() error {
+		panic("这是一个测试 panic")
+	})
+}
+
+func TestRecover(t *testing.T) {
+	q, err := safeDivide(10, 0)
+	var re runtime.Error
+	if q != 0 || !errors.As(err, &re) {
+		t.Errorf("safeDivide(10, 0) = %d, %v; want a runtime.Error", q, err)
+	}
+	if q, err := safeDivide(10, 2); q != 5 || err != nil {
+		t.Errorf("safeDivide(10, 2) = %d, %v", q, err)
+	}
+
+	err = safeFunction()
+	pe := asPanic(err)
+	if pe == nil || pe.Value != "这是一个测试 panic" {
+		t.Fatalf("safeFunction = %v", err)
+	}
+	if !strings.Contains(string(pe.Stack), ".safeFunction") {
+		t.Errorf("Stack does not include the panicking frame:\n%s", pe.Stack)
+	}
+	if detail := fmt.Sprintf("%+v", err); !strings.HasPrefix(detail, "panic: 这是一个测试 panic\n\ngoroutine ") {
+		t.Errorf("%%+v:\n%s", detail)
+	}
+	if s := fmt.Sprintf("%v|%s", err, err); s != "panic: 这是一个测试 panic|panic: 这是一个测试 panic" {
+		t.Errorf("%%v|%%s = %s", s)
+	}
+}
+
+func TestCallTryDo(t *testing.T) {
+	if n, err := Call(func() int { return len([]int{1, 2, 3}[1:]) }); n != 2 || err != nil {
+		t.Errorf("Call = %d, %v", n, err)
+	}
+	if s, err := Call(func() string { var m map[string]*string; return *m["x"] }); s != "" || asPanic(err) == nil {
+		t.Errorf("Call with nil dereference = %q, %v", s, err)
+	}
+	if _, err := Try(func() (int, error) { return 0, io.EOF }); err != io.EOF {
+		t.Errorf("Try should return f's error unchanged: %v", err)
+	}
+	if v, err := Try(func() (int, error) { panic("boom") }); v != 0 || asPanic(err) == nil {
+		t.Errorf("Try with panic = %d, %v", v, err)
+	}
+	if err := Do(func() error { return nil }); err != nil {
+		t.Errorf("Do = %v", err)
+	}
+	if err := Do(func() error { return io.EOF }); err != io.EOF {
+		t.Errorf("Do should return f's error unchanged: %v", err)
+	}
+}
+
+func TestPanicValues(t *testing.T) {
+	err := Do(func() error { panic(fmt.Errorf("读取配置: %w", io.ErrUnexpectedEOF)) })
+	if !errors.Is(err, io.ErrUnexpectedEOF) || err.Error() != "panic: 读取配置: unexpected EOF" {
+		t.Errorf("panic(error): %v", err)
+	}
+
+	err = Do(func() error { panic(nil) })
+	var pn *runtime.PanicNilError
+	if !errors.As(err, &pn) {
+		t.Errorf("panic(nil) = %v, want *runtime.PanicNilError (Go 1.21)", err)
+	}
+
+	err = Do(func() error { panic(42) })
+	if pe := asPanic(err); pe == nil || pe.Value != 42 || pe.Unwrap() != nil {
+		t.Errorf("panic(42) = %#v", pe)
+	}
+}
+
+func TestRepanic(t *testing.T) {
+	err := Do(func() error {
+		defer func() { panic("清理时又 panic") }()
+		panic("第一次 panic")
+	})
+	if pe := asPanic(err); pe == nil || pe.Value != "清理时又 panic" {
+		t.Errorf("panic in a deferred function: %v, want the last value", err)
+	}
+
+	var inner error
+	outer := Do(func() error {
+		inner = Do(func() error { panic("内层") })
+		panic(inner)
+	})
+	if outer != inner {
+		t.Error("re-panicking a *PanicError should return the same error and stack")
+	}
+
+	err = Do(func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(fmt.Sprintf("包装后重新 panic: %v", r))
+			}
+		}()
+		panic("原始")
+	})
+	if pe := asPanic(err); pe == nil || pe.Value != "包装后重新 panic: 原始" {
+		t.Errorf("recover and panic a new value: %v", err)
+	}
+
+	var deferred bool
+	err = Do(func() error {
+		defer func() { deferred = true }()
+		panic("x")
+	})
+	if !deferred || err == nil {
+		t.Error("f's own deferred functions should still run")
+	}
+}
+
+func TestGoexit(t *testing.T) {
+	var deferred bool
+	err := <-Go(func() {
+		defer func() { deferred = true }()
+		runtime.Goexit()
+	})
+	if !errors.Is(err, ErrGoexit) || !deferred {
+		t.Errorf("Go with Goexit = %v (deferred %t)", err, deferred)
+	}
+
+	// Do 无法拦截 Goexit：Do 不返回，goroutine 直接结束
+	var returned atomic.Bool
+	err = <-Go(func() {
+		_ = Do(func() error { runtime.Goexit(); return nil })
+		returned.Store(true)
+	})
+	if !errors.Is(err, ErrGoexit) || returned.Load() {
+		t.Errorf("Do inside Go: %v, returned %t", err, returned.Load())
+	}
+
+	if err := <-Go(func() { panic(nil) }); asPanic(err) == nil {
+		t.Errorf("panic(nil) mistaken for Goexit: %v", err)
+	}
+
+	done := Go(func() {})
+	if err := <-done; err != nil {
+		t.Errorf("normal return = %v", err)
+	}
+	if _, open := <-done; open {
+		t.Error("channel not closed after the result")
+	}
+}
+
+func TestGroupWithRecover(t *testing.T) {
+	g, ctx := GroupWithRecover(context.Background())
+	var canceled atomic.Int32
+	for i := 0; i < 3; i++ {
+		g.Go(func() error {
+			select {
+			case <-ctx.Done():
+				canceled.Add(1)
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+	}
+	g.Go(func() error {
+		var s []int
+		return fmt.Errorf("不会执行到这里: %d", s[3])
+	})
+	err := g.Wait()
+	if asPanic(err) == nil || !errors.As(err, new(runtime.Error)) {
+		t.Fatalf("Wait = %v, want the recovered index panic", err)
+	}
+	if canceled.Load() != 3 {
+		t.Errorf("%d goroutines saw the cancellation, want 3", canceled.Load())
+	}
+	if context.Cause(ctx) != err {
+		t.Errorf("context.Cause = %v", context.Cause(ctx))
+	}
+
+	// Wait 返回后 context 也被取消
+	g, ctx = GroupWithRecover(context.Background())
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil || ctx.Err() == nil {
+		t.Errorf("after Wait: err=%v ctx.Err=%v", err, ctx.Err())
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var g Group
+	g.Go(func() error { runtime.Goexit(); return nil })
+	g.Go(func() error { return nil })
+	if err := g.Wait(); !errors.Is(err, ErrGoexit) {
+		t.Errorf("zero Group with Goexit: %v", err)
+	}
+
+	var limited Group
+	limited.SetLimit(2)
+	var running, peak, finished atomic.Int32
+	for i := 0; i < 8; i++ {
+		limited.Go(func() error {
+			n := running.Add(1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			finished.Add(1)
+			return nil
+		})
+	}
+	if err := limited.Wait(); err != nil || peak.Load() > 2 || finished.Load() != 8 {
+		t.Errorf("SetLimit(2): err=%v peak=%d finished=%d", err, peak.Load(), finished.Load())
+	}
+
+	var first Group
+	first.Go(func() error { return errors.New("第一个") })
+	if err := first.Wait(); err == nil || err.Error() != "第一个" {
+		t.Errorf("plain error = %v", err)
+	}
+}
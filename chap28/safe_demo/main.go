@@ -0,0 +1,190 @@
+// 独立运行：go run ./chap28/safe_demo
+// 演示：用 safe 重写 chap28 的 safeFunction、safeDivide，以及 panic 转换成错误的边界情况：
+// 重新 panic、runtime.Goexit、panic(nil)（Go 1.21 语义）以及 GroupWithRecover。断言见 go test ./chap28/safe。
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"books/chap28/safe"
+)
+
+// asPanic 取出 *safe.PanicError，不是时返回 nil。
+func asPanic(err error) *safe.PanicError {
+	var pe *safe.PanicError
+	errors.As(err, &pe)
+	return pe
+}
+
+// safeDivide 对应 chap28 的同名函数：原来除零时打印后返回 0 且没有错误，现在返回 *PanicError。
+func safeDivide(a, b int) (q int, err error) {
+	defer safe.Recover(&err)
+	return a / b, nil
+}
+
+// safeFunction 对应 chap28 的同名函数。
+func safeFunction() error {
+	return safe.Do(func() error {
+		panic("这是一个测试 panic")
+	})
+}
+
+func main() {
+	basics()
+	values()
+	repanics()
+	goexit()
+	group()
+}
+
+func basics() {
+	fmt.Println("=== 1. safeDivide、safeFunction、Call、Try ===")
+	q, err := safeDivide(10, 0)
+	var re runtime.Error
+	fmt.Printf("  safeDivide(10, 0) = %d, %v（runtime.Error: %v）\n", q, err, errors.As(err, &re))
+	q, err = safeDivide(10, 2)
+	fmt.Printf("  safeDivide(10, 2) = %d, %v\n", q, err)
+
+	err = safeFunction()
+	fmt.Println("  safeFunction:", err)
+	detail := fmt.Sprintf("%+v", err)
+	fmt.Printf("  %%+v 在错误之后输出调用栈（%d 行），包含 main.safeFunction: %v\n",
+		strings.Count(detail, "\n"), strings.Contains(detail, "main.safeFunction"))
+
+	n, err := safe.Call(func() int { return len([]int{1, 2, 3}[1:]) })
+	fmt.Printf("  Call 正常返回：%d, %v\n", n, err)
+	s, err := safe.Call(func() string { var m map[string]*string; return *m["x"] })
+	fmt.Printf("  Call 中解引用 nil 指针：%q, %v\n", s, err)
+
+	_, err = safe.Try(func() (int, error) { return 0, io.EOF })
+	fmt.Println("  Try 原样返回 f 的错误:", err)
+	v, err := safe.Try(func() (int, error) { panic("boom") })
+	fmt.Printf("  Try 中 panic：%d, %v\n", v, err)
+	fmt.Println()
+}
+
+func values() {
+	fmt.Println("=== 2. panic 的值 ===")
+	err := safe.Do(func() error { panic(fmt.Errorf("读取配置: %w", io.ErrUnexpectedEOF)) })
+	fmt.Printf("  panic 一个 error：%v，errors.Is(io.ErrUnexpectedEOF) = %v\n", err, errors.Is(err, io.ErrUnexpectedEOF))
+
+	err = safe.Do(func() error { panic(nil) })
+	var pn *runtime.PanicNilError
+	fmt.Printf("  panic(nil)：%v（*runtime.PanicNilError: %v）\n", err, errors.As(err, &pn))
+
+	err = safe.Do(func() error { panic(42) })
+	fmt.Printf("  panic 一个 int：Value = %v，Unwrap = %v\n", asPanic(err).Value, asPanic(err).Unwrap())
+	fmt.Println()
+}
+
+func repanics() {
+	fmt.Println("=== 3. 重新 panic ===")
+	err := safe.Do(func() error {
+		defer func() { panic("清理时又 panic") }()
+		panic("第一次 panic")
+	})
+	fmt.Printf("  延迟函数中再次 panic，recover 得到最后一个值：%q\n", asPanic(err).Value)
+
+	var inner error
+	outer := safe.Do(func() error {
+		inner = safe.Do(func() error { panic("内层") })
+		panic(inner) // 上层决定不处理，原样抛出
+	})
+	fmt.Println("  重新 panic 一个 *PanicError，外层得到同一个错误:", outer == inner)
+
+	err = safe.Do(func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(fmt.Sprintf("包装后重新 panic: %v", r))
+			}
+		}()
+		panic("原始")
+	})
+	fmt.Println("  recover 后 panic 新的值:", err)
+
+	var deferred bool
+	_ = safe.Do(func() error {
+		defer func() { deferred = true }()
+		panic("x")
+	})
+	fmt.Println("  panic 时 f 自己的延迟函数照常执行:", deferred)
+	fmt.Println()
+}
+
+func goexit() {
+	fmt.Println("=== 4. runtime.Goexit ===")
+	var deferred bool
+	err := <-safe.Go(func() {
+		defer func() { deferred = true }()
+		runtime.Goexit()
+	})
+	fmt.Printf("  Go 报告 Goexit：%v，延迟函数执行: %v\n", err, deferred)
+
+	var returned atomic.Bool
+	err = <-safe.Go(func() {
+		_ = safe.Do(func() error { runtime.Goexit(); return nil })
+		returned.Store(true)
+	})
+	fmt.Printf("  Do 无法拦截 Goexit：外层的 Go 报告 %v，Do 返回了: %v\n", err, returned.Load())
+
+	err = <-safe.Go(func() { panic(nil) })
+	fmt.Println("  panic(nil) 不会被误认为 Goexit:", err)
+
+	done := safe.Go(func() {})
+	err = <-done
+	_, open := <-done
+	fmt.Printf("  正常结束时收到 %v，之后通道关闭: %v\n", err, !open)
+	fmt.Println()
+}
+
+func group() {
+	fmt.Println("=== 5. GroupWithRecover ===")
+	g, ctx := safe.GroupWithRecover(context.Background())
+	var canceled atomic.Int32
+	for i := 0; i < 3; i++ {
+		g.Go(func() error {
+			select {
+			case <-ctx.Done():
+				canceled.Add(1)
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+	}
+	g.Go(func() error {
+		var s []int
+		return fmt.Errorf("不会执行到这里: %d", s[3])
+	})
+	err := g.Wait()
+	fmt.Println("  Wait 返回 panic 转换成的错误:", err)
+	fmt.Printf("  第一个错误取消 context，其余 %d 个 goroutine 提前退出，context.Cause 是同一个错误: %v\n",
+		canceled.Load(), context.Cause(ctx) == err)
+
+	var g2 safe.Group
+	g2.Go(func() error { runtime.Goexit(); return nil })
+	g2.Go(func() error { return nil })
+	fmt.Println("  零值 Group 可用，Goexit 报告为:", g2.Wait())
+
+	var g3 safe.Group
+	g3.SetLimit(2)
+	var running, peak atomic.Int32
+	for i := 0; i < 8; i++ {
+		g3.Go(func() error {
+			n := running.Add(1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	fmt.Printf("  SetLimit(2)：Wait = %v，同时运行最多 %d 个\n", g3.Wait(), peak.Load())
+}
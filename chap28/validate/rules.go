@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// builtinRules 是 New 自带的规则；required 和 omitempty 在 apply 中特殊处理。
+var builtinRules = map[string]RuleFunc{
+	"min":   minRule,
+	"max":   maxRule,
+	"len":   lenRule,
+	"email": emailRule,
+	"oneof": oneOfRule,
+}
+
+// measure 返回 min/max/len 比较用的数值和单位：
+// 数字比较数值本身，字符串比较字符（rune）个数，切片/数组/map 比较元素个数。
+func measure(v reflect.Value) (n float64, sized bool, err error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), false, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, nil
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, nil
+	}
+	return 0, false, fmt.Errorf("%w: not applicable to %s", ErrInvalidParam, v.Kind())
+}
+
+func parseBound(param string) (float64, error) {
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a number", ErrInvalidParam, param)
+	}
+	return f, nil
+}
+
+func compare(v reflect.Value, param string, ok func(n, bound float64) bool, word string) error {
+	bound, err := parseBound(param)
+	if err != nil {
+		return err
+	}
+	n, sized, err := measure(v)
+	if err != nil {
+		return err
+	}
+	if ok(n, bound) {
+		return nil
+	}
+	if sized {
+		return fmt.Errorf("length must be %s %s (got %v)", word, param, n)
+	}
+	return fmt.Errorf("must be %s %s (got %v)", word, param, v.Interface())
+}
+
+func minRule(v reflect.Value, param string) error {
+	return compare(v, param, func(n, b float64) bool { return n >= b }, "at least")
+}
+
+func maxRule(v reflect.Value, param string) error {
+	return compare(v, param, func(n, b float64) bool { return n <= b }, "at most")
+}
+
+func lenRule(v reflect.Value, param string) error {
+	want, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("%w: %q is not an integer", ErrInvalidParam, param)
+	}
+	n, sized, err := measure(v)
+	if err != nil || !sized {
+		return fmt.Errorf("%w: len not applicable to %s", ErrInvalidParam, v.Kind())
+	}
+	if int(n) != want {
+		return fmt.Errorf("length must be %d (got %d)", want, int(n))
+	}
+	return nil
+}
+
+// emailRule 只接受纯地址（user@example.com），不接受带显示名的形式。
+func emailRule(v reflect.Value, _ string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("%w: email not applicable to %s", ErrInvalidParam, v.Kind())
+	}
+	s := v.String()
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || !strings.Contains(s[strings.LastIndexByte(s, '@')+1:], ".") {
+		return errors.New("must be a valid email address")
+	}
+	return nil
+}
+
+// oneOfRule 的参数用空格分隔，例如 oneof=red green blue。
+func oneOfRule(v reflect.Value, param string) error {
+	options := strings.Fields(param)
+	if len(options) == 0 {
+		return fmt.Errorf("%w: oneof needs at least one option", ErrInvalidParam)
+	}
+	var s string
+	switch v.Kind() {
+	case reflect.String:
+		s = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = fmt.Sprint(v.Interface())
+	default:
+		return fmt.Errorf("%w: oneof not applicable to %s", ErrInvalidParam, v.Kind())
+	}
+	for _, o := range options {
+		if s == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of [%s] (got %q)", strings.Join(options, " "), s)
+}
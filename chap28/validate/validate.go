@@ -0,0 +1,293 @@
+// Package validate 根据结构体标签校验数据（第28章 validateUser/validateInput 的通用版本）。
+//
+// chap28 的 validateUser 为每个字段手写 if 判断，并且遇到第一个错误就返回。
+// 这里把规则写在标签里：
+//
+//	type Person struct {
+//		Name  string `validate:"required"`
+//		Age   int    `validate:"min=0,max=150"`
+//		Email string `validate:"omitempty,email"`
+//	}
+//
+// Struct 会递归进入嵌入字段、嵌套结构体、指针、切片和 map，一次性收集所有违规，
+// 用 errors.Join 合并返回；每个违规都是 *FieldError，路径形如 Manager.Contact.Address.Zip。
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownRule 表示标签中使用了未注册的规则。
+	ErrUnknownRule = errors.New("validate: unknown rule")
+	// ErrInvalidParam 表示规则参数无法解析或规则不适用于该字段类型。
+	ErrInvalidParam = errors.New("validate: invalid rule parameter")
+	// ErrNotStruct 表示传入 Struct 的不是结构体（或结构体指针）。
+	ErrNotStruct = errors.New("validate: not a struct")
+)
+
+// RuleFunc 校验一个字段；param 是标签中 = 后面的部分（没有时为空字符串）。
+// 返回 nil 表示通过，返回的错误会成为 FieldError.Err。
+// 规则本身配置错误（例如参数不是数字）时应返回包装了 ErrInvalidParam 的错误。
+type RuleFunc func(field reflect.Value, param string) error
+
+// FieldError 描述一个字段违反的一条规则。
+type FieldError struct {
+	Path  string // 字段路径，例如 Manager.Contact.Address.Zip、Team.Members[2].Email
+	Rule  string // 规则名，例如 max
+	Param string // 规则参数，例如 150
+	Value any    // 字段的值（不可导出时为 nil）
+	Err   error  // 规则给出的原因
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Fields 从 Struct 返回的错误中取出所有 *FieldError。
+func Fields(err error) []*FieldError {
+	var out []*FieldError
+	var walk func(error)
+	walk = func(err error) {
+		var fe *FieldError
+		switch {
+		case err == nil:
+		case errors.As(err, &fe) && fe == err:
+			out = append(out, fe)
+		default:
+			if u, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range u.Unwrap() {
+					walk(e)
+				}
+			}
+		}
+	}
+	walk(err)
+	return out
+}
+
+// Validator 持有一组规则。零值不可用，请使用 New。
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFunc
+	tag   string
+}
+
+// New 创建一个包含内置规则（required、omitempty、min、max、len、email、oneof）的校验器。
+func New() *Validator {
+	v := &Validator{rules: make(map[string]RuleFunc), tag: "validate"}
+	for name, fn := range builtinRules {
+		v.rules[name] = fn
+	}
+	return v
+}
+
+// SetTagName 修改读取的标签名（默认 validate）。
+func (v *Validator) SetTagName(name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.tag = name
+}
+
+// Register 注册或覆盖一条规则。规则名不能为空，也不能包含 , = 或空白。
+func (v *Validator) Register(name string, fn RuleFunc) error {
+	if name == "" || strings.ContainsAny(name, ",= \t") || name == "omitempty" {
+		return fmt.Errorf("validate: invalid rule name %q", name)
+	}
+	if fn == nil {
+		return fmt.Errorf("validate: nil rule %q", name)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = fn
+	return nil
+}
+
+// Struct 校验结构体（或结构体指针）s，返回所有违规合并后的错误；全部通过时返回 nil。
+func (v *Validator) Struct(s any) error {
+	root := reflect.ValueOf(s)
+	val := root
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return fmt.Errorf("%w: nil %T", ErrNotStruct, s)
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrNotStruct, s)
+	}
+	// 在锁内复制规则表，执行规则时不持有锁：自定义规则里调用 Register 不会死锁
+	v.mu.RLock()
+	w := &walker{rules: make(map[string]RuleFunc, len(v.rules)), tag: v.tag, active: make(map[visit]bool)}
+	for name, fn := range v.rules {
+		w.rules[name] = fn
+	}
+	v.mu.RUnlock()
+	w.walk(val.Type().Name(), root)
+	return errors.Join(w.errs...)
+}
+
+var std = New()
+
+// Register 在默认校验器上注册规则。
+func Register(name string, fn RuleFunc) error { return std.Register(name, fn) }
+
+// Struct 用默认校验器校验 s。
+func Struct(s any) error { return std.Struct(s) }
+
+// visit 用于识别循环引用：当前递归路径上已经进入过的同一地址、同一类型的指针。
+// 只记录路径上的指针而不是所有访问过的指针，DAG 中经由另一条路径到达的共享结构体仍会被校验。
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type walker struct {
+	rules  map[string]RuleFunc // Struct 开始时规则表的副本
+	tag    string
+	errs   []error
+	active map[visit]bool // 当前递归路径上的指针，返回时移除
+}
+
+// walk 递归进入 val 内部的结构体字段、元素和值。
+func (w *walker) walk(path string, val reflect.Value) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		if val.Kind() == reflect.Pointer {
+			key := visit{val.Pointer(), val.Type()}
+			if w.active[key] {
+				return
+			}
+			w.active[key] = true
+			defer delete(w.active, key)
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		t := val.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			tag := sf.Tag.Get(w.tag)
+			if tag == "-" {
+				continue
+			}
+			fpath := path + "." + sf.Name
+			fv := val.Field(i)
+			if tag != "" && !w.apply(fpath, fv, tag) {
+				continue
+			}
+			w.walk(fpath, fv)
+		}
+	case reflect.Slice, reflect.Array:
+		if !containsStruct(val.Type().Elem()) {
+			return
+		}
+		for i := 0; i < val.Len(); i++ {
+			w.walk(fmt.Sprintf("%s[%d]", path, i), val.Index(i))
+		}
+	case reflect.Map:
+		if !containsStruct(val.Type().Elem()) {
+			return
+		}
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			w.walk(fmt.Sprintf("%s[%v]", path, k.Interface()), val.MapIndex(k))
+		}
+	}
+}
+
+// containsStruct 报告 t（去掉指针后）是否可能包含需要递归校验的结构体。
+func containsStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return containsStruct(t.Elem())
+	}
+	return false
+}
+
+// apply 按顺序执行标签中的规则。返回 false 表示不必再递归进入该字段
+// （omitempty 且为零值，或者 required 失败）。
+func (w *walker) apply(path string, field reflect.Value, tag string) bool {
+	for _, item := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch name {
+		case "":
+			continue
+		case "omitempty":
+			if isEmpty(field) {
+				return false
+			}
+			continue
+		case "required":
+			if isEmpty(field) {
+				w.fail(path, name, param, field, errors.New("is required"))
+				return false
+			}
+			continue
+		}
+
+		rule, ok := w.rules[name]
+		if !ok {
+			w.errs = append(w.errs, fmt.Errorf("%w %q on %s", ErrUnknownRule, name, path))
+			continue
+		}
+		// 除 required 外的规则作用在指针指向的值上；nil 指针交给 required 处理
+		target := field
+		for target.Kind() == reflect.Pointer {
+			if target.IsNil() {
+				break
+			}
+			target = target.Elem()
+		}
+		if target.Kind() == reflect.Pointer {
+			continue
+		}
+		if err := rule(target, param); err != nil {
+			if errors.Is(err, ErrInvalidParam) {
+				w.errs = append(w.errs, fmt.Errorf("%s: rule %s: %w", path, name, err))
+				continue
+			}
+			w.fail(path, name, param, target, err)
+		}
+	}
+	return true
+}
+
+func (w *walker) fail(path, rule, param string, field reflect.Value, err error) {
+	fe := &FieldError{Path: path, Rule: rule, Param: param, Err: err}
+	if field.IsValid() && field.CanInterface() {
+		fe.Value = field.Interface()
+	}
+	w.errs = append(w.errs, fe)
+}
+
+// isEmpty 报告字段是否为“空”：零值，或长度为 0 的切片/map。
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,266 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 以下类型与 validate_demo 相同，对应 chap22/no_classes_composition.go。
+
+type Person struct {
+	Name string `validate:"required,max=32"`
+	Age  int    `validate:"min=0,max=150"`
+}
+
+type Employee struct {
+	Person
+	EmployeeID int     `validate:"min=1"`
+	Department string  `validate:"oneof=研发 市场 财务"`
+	Salary     float64 `validate:"min=0"`
+}
+
+type Address struct {
+	Street string `validate:"required"`
+	City   string `validate:"required"`
+	Zip    string `validate:"len=6,digits"`
+}
+
+type Contact struct {
+	Email   string `validate:"required,email"`
+	Phone   string `validate:"omitempty,min=7"`
+	Address Address
+}
+
+type Manager struct {
+	Employee
+	Contact
+	TeamSize int        `validate:"min=1"`
+	Reports  []Employee `validate:"max=10"`
+}
+
+func digits(v reflect.Value, _ string) error {
+	if strings.Trim(v.String(), "0123456789") != "" {
+		return errors.New("must contain only digits")
+	}
+	return nil
+}
+
+func newValidator(t *testing.T) *Validator {
+	t.Helper()
+	v := New()
+	if err := v.Register("digits", digits); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func goodManager() Manager {
+	return Manager{
+		Employee: Employee{Person: Person{Name: "张三", Age: 40}, EmployeeID: 7, Department: "研发", Salary: 30000},
+		Contact:  Contact{Email: "zhangsan@example.com", Address: Address{Street: "中关村大街1号", City: "北京", Zip: "100080"}},
+		TeamSize: 5,
+	}
+}
+
+// violations 把错误整理成 路径 → 规则。
+func violations(err error) map[string]string {
+	out := map[string]string{}
+	for _, fe := range Fields(err) {
+		out[fe.Path] = fe.Rule
+	}
+	return out
+}
+
+func TestManagerPaths(t *testing.T) {
+	v := newValidator(t)
+	m := goodManager()
+	if err := v.Struct(m); err != nil {
+		t.Fatalf("valid manager: %v", err)
+	}
+
+	m.Age = 200
+	m.Department = "后勤"
+	m.Email = "not-an-email"
+	m.Contact.Address.Zip = "10a08"
+	m.TeamSize = 0
+	m.Reports = []Employee{{Person: Person{Age: -1}, EmployeeID: 8, Department: "市场"}}
+	err := v.Struct(&m)
+
+	want := map[string]string{
+		"Manager.Employee.Person.Age":    "max",
+		"Manager.Employee.Department":    "oneof",
+		"Manager.Contact.Email":          "email",
+		"Manager.Contact.Address.Zip":    "digits",
+		"Manager.TeamSize":               "min",
+		"Manager.Reports[0].Person.Name": "required",
+		"Manager.Reports[0].Person.Age":  "min",
+	}
+	got := violations(err)
+	for path, rule := range want {
+		if got[path] == "" {
+			t.Errorf("missing violation %s (%s)", path, rule)
+		}
+	}
+
+	// Zip 同时违反 len 和 digits，按标签中的顺序报告
+	var zip []string
+	for _, fe := range Fields(err) {
+		if fe.Path == "Manager.Contact.Address.Zip" {
+			zip = append(zip, fe.Rule+"="+fe.Param)
+		}
+	}
+	if strings.Join(zip, " ") != "len=6 digits=" {
+		t.Errorf("Zip violations = %v, want [len=6 digits=] in tag order", zip)
+	}
+	if n := len(Fields(err)); n != 8 {
+		t.Errorf("%d violations, want 8:\n%v", n, err)
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Manager.Employee.Person.Age" || fe.Value != 200 || fe.Param != "150" {
+		t.Errorf("first FieldError = %+v", fe)
+	}
+	if !strings.Contains(err.Error(), "Manager.Contact.Email: must be a valid email address") {
+		t.Errorf("error text:\n%v", err)
+	}
+}
+
+func TestRuleCanRegister(t *testing.T) {
+	v := New()
+	type T struct {
+		A string `validate:"lazy"`
+		B string `validate:"late"`
+	}
+	// 第一次执行时才注册另一条规则：持有读锁调用规则时这里会死锁
+	err := v.Register("lazy", func(reflect.Value, string) error {
+		return v.Register("late", func(reflect.Value, string) error { return errors.New("late rule ran") })
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- v.Struct(T{}) }()
+	select {
+	case err := <-done:
+		// 规则表在 Struct 开始时复制，新规则从下一次调用起生效
+		if !errors.Is(err, ErrUnknownRule) {
+			t.Errorf("first call: %v, want unknown rule late", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Struct deadlocked when a rule called Register")
+	}
+	if got := violations(v.Struct(T{})); got["T.B"] != "late" {
+		t.Errorf("second call: %v", got)
+	}
+}
+
+func TestConfigurationErrors(t *testing.T) {
+	type T struct {
+		A int    `validate:"nosuch"`
+		B int    `validate:"min=abc"`
+		C bool   `validate:"max=1"`
+		D string `validate:"len=x"`
+	}
+	err := New().Struct(T{})
+	if !errors.Is(err, ErrUnknownRule) || !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("err = %v", err)
+	}
+	if len(Fields(err)) != 0 {
+		t.Errorf("configuration errors reported as violations: %v", Fields(err))
+	}
+	for _, s := range []any{42, nil, (*T)(nil), []T{}} {
+		if err := Struct(s); !errors.Is(err, ErrNotStruct) {
+			t.Errorf("Struct(%#v) = %v, want ErrNotStruct", s, err)
+		}
+	}
+	for _, name := range []string{"", "a,b", "a=b", "a b", "omitempty"} {
+		if New().Register(name, digits) == nil {
+			t.Errorf("Register(%q) accepted", name)
+		}
+	}
+}
+
+func TestRequiredOmitemptyAndPointers(t *testing.T) {
+	type T struct {
+		Ptr   *int           `validate:"required,min=10"`
+		Opt   string         `validate:"omitempty,email"`
+		Tags  []string       `validate:"required,max=2"`
+		Meta  map[string]int `validate:"omitempty,len=1"`
+		Skip  string         `validate:"-"`
+		inner int            `validate:"min=1"` // 不可导出，忽略
+	}
+	got := violations(New().Struct(T{}))
+	if len(got) != 2 || got["T.Ptr"] != "required" || got["T.Tags"] != "required" {
+		t.Errorf("zero value: %v", got)
+	}
+
+	five := 5
+	got = violations(New().Struct(T{Ptr: &five, Opt: "x", Tags: []string{"a", "b", "c"}, Meta: map[string]int{}}))
+	if len(got) != 3 || got["T.Ptr"] != "min" || got["T.Opt"] != "email" || got["T.Tags"] != "max" {
+		t.Errorf("invalid values: %v", got)
+	}
+}
+
+func TestNestedCollectionsAndCycles(t *testing.T) {
+	type Node struct {
+		Name     string `validate:"required"`
+		Next     *Node
+		Children map[string]*Node
+	}
+	a := &Node{Name: "a"}
+	b := &Node{Next: a}
+	a.Next = b // 循环
+	a.Children = map[string]*Node{"y": {}, "x": {Name: "ok"}}
+	got := violations(New().Struct(a))
+	want := map[string]string{"Node.Next.Name": "required", "Node.Children[y].Name": "required"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSharedPointers(t *testing.T) {
+	// 两个字段指向同一个 Address：不是循环，两条路径都要校验
+	type Order struct {
+		Billing  *Address
+		Shipping *Address
+		History  []*Address
+	}
+	shared := &Address{City: "北京", Zip: "1000"}
+	got := violations(newValidator(t).Struct(Order{Billing: shared, Shipping: shared, History: []*Address{shared}}))
+	want := map[string]string{
+		"Order.Billing.Street": "required", "Order.Billing.Zip": "len",
+		"Order.Shipping.Street": "required", "Order.Shipping.Zip": "len",
+		"Order.History[0].Street": "required", "Order.History[0].Zip": "len",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// 菱形：a → b、a → c，b 和 c 都指向 d
+	type Node struct {
+		Name        string `validate:"required"`
+		Left, Right *Node
+	}
+	d := &Node{}
+	a := &Node{Name: "a", Left: &Node{Name: "b", Right: d}, Right: &Node{Name: "c", Left: d}}
+	got = violations(New().Struct(a))
+	want = map[string]string{"Node.Left.Right.Name": "required", "Node.Right.Left.Name": "required"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diamond: got %v, want %v", got, want)
+	}
+}
+
+func TestSetTagName(t *testing.T) {
+	type T struct {
+		A int `check:"min=1" validate:"max=-1"`
+	}
+	v := New()
+	v.SetTagName("check")
+	if got := violations(v.Struct(T{})); len(got) != 1 || got["T.A"] != "min" {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,101 @@
+// 独立运行：go run ./chap28/validate_demo
+// 演示：用 validate 标签替代 validateUser 的手写判断，一次返回所有违规及字段路径。
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"books/chap21/geo"
+	"books/chap28/validate"
+)
+
+// 以下类型对应 chap22/no_classes_composition.go，只是加上了 validate 标签。
+
+type Person struct {
+	Name string `validate:"required,max=32"`
+	Age  int    `validate:"min=0,max=150"`
+}
+
+type Employee struct {
+	Person
+	EmployeeID int     `validate:"min=1"`
+	Department string  `validate:"oneof=研发 市场 财务"`
+	Salary     float64 `validate:"min=0"`
+}
+
+type Address struct {
+	Street string `validate:"required"`
+	City   string `validate:"required"`
+	Zip    string `validate:"len=6,digits"`
+}
+
+type Contact struct {
+	Email   string `validate:"required,email"`
+	Phone   string `validate:"omitempty,min=7"`
+	Address Address
+}
+
+type Manager struct {
+	Employee
+	Contact
+	TeamSize int        `validate:"min=1"`
+	Reports  []Employee `validate:"max=10"`
+}
+
+// Site 对应 chap21 的着陆点坐标。
+type Site struct {
+	Name     string         `validate:"required"`
+	Location geo.Coordinate `validate:"coordinate"`
+}
+
+func main() {
+	// 自定义规则：全部由数字组成
+	_ = validate.Register("digits", func(v reflect.Value, _ string) error {
+		if strings.Trim(v.String(), "0123456789") != "" {
+			return errors.New("must contain only digits")
+		}
+		return nil
+	})
+	// 自定义规则：坐标在合法范围内
+	_ = validate.Register("coordinate", func(v reflect.Value, _ string) error {
+		if c, ok := v.Interface().(geo.Coordinate); ok && !c.Valid() {
+			return fmt.Errorf("coordinate %v out of range", c)
+		}
+		return nil
+	})
+
+	fmt.Println("=== 1. 合法的 Manager ===")
+	good := Manager{
+		Employee: Employee{Person: Person{Name: "张三", Age: 40}, EmployeeID: 7, Department: "研发", Salary: 30000},
+		Contact:  Contact{Email: "zhangsan@example.com", Address: Address{Street: "中关村大街1号", City: "北京", Zip: "100080"}},
+		TeamSize: 5,
+	}
+	fmt.Println("err =", validate.Struct(good))
+
+	fmt.Println("\n=== 2. 一次拿到所有违规 ===")
+	bad := good
+	bad.Age = 200
+	bad.Department = "后勤"
+	bad.Email = "not-an-email"
+	bad.Contact.Address.Zip = "10a08"
+	bad.TeamSize = 0
+	bad.Reports = []Employee{{Person: Person{Age: -1}, EmployeeID: 8, Department: "市场"}}
+	err := validate.Struct(&bad)
+	fmt.Println(err)
+
+	fmt.Println("\n=== 3. 逐个检查 FieldError ===")
+	for _, fe := range validate.Fields(err) {
+		fmt.Printf("%-36s rule=%-6s param=%-10q value=%v\n", fe.Path, fe.Rule, fe.Param, fe.Value)
+	}
+	var fe *validate.FieldError
+	if errors.As(err, &fe) {
+		fmt.Println("errors.As 找到第一个违规:", fe.Path)
+	}
+
+	fmt.Println("\n=== 4. chap21 坐标 ===")
+	fmt.Println(validate.Struct(Site{Name: "Bradbury", Location: geo.Coordinate{Lat: -4.5895, Long: 137.4417}}))
+	fmt.Println(validate.Struct(Site{Location: geo.Coordinate{Lat: 95, Long: 10}}))
+}
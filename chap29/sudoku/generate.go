@@ -0,0 +1,131 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Difficulty 是题目的难度，由求解需要的技巧决定。
+type Difficulty int
+
+const (
+	Easy   Difficulty = iota // 只需要唯一候选数
+	Medium                   // 还需要隐性唯一
+	Hard                     // 需要试填，但很快就能确定（回溯不超过 HardBacktracks 次）
+	Expert                   // 需要大量试填
+)
+
+// HardBacktracks 是评为 Hard 的最大回溯次数，超过时为 Expert。
+const HardBacktracks = 3
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	case Expert:
+		return "expert"
+	}
+	return fmt.Sprintf("Difficulty(%d)", int(d))
+}
+
+// ParseDifficulty 把 easy、medium、hard、expert 转换成 Difficulty。
+func ParseDifficulty(s string) (Difficulty, error) {
+	for d := Easy; d <= Expert; d++ {
+		if d.String() == s {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("sudoku: unknown difficulty %q", s)
+}
+
+// Rating 是 Rate 的结果。
+type Rating struct {
+	Difficulty Difficulty
+	Stats      Stats
+}
+
+// Rate 求解 g 并按用到的技巧评定难度。g 有冲突或无解时返回错误。
+// 难度按本包求解器的过程评定：先唯一候选数、再隐性唯一、最后按候选数最少的格子试填。
+func Rate(g *Grid) (Rating, error) {
+	_, st, err := solve(g)
+	if err != nil {
+		return Rating{}, err
+	}
+	return Rating{Difficulty: difficulty(st), Stats: st}, nil
+}
+
+func difficulty(st Stats) Difficulty {
+	switch {
+	case st.Guesses > 0 && st.Backtracks > HardBacktracks:
+		return Expert
+	case st.Guesses > 0:
+		return Hard
+	case st.HiddenSingles > 0:
+		return Medium
+	}
+	return Easy
+}
+
+// ErrGenerate 表示在尝试次数内没有生成指定难度的题目。
+var ErrGenerate = errors.New("sudoku: could not generate puzzle")
+
+// MaxAttempts 是 Generate 最多尝试的终盘个数。
+const MaxAttempts = 100
+
+// Generate 生成一道难度为 target、有唯一解的题目，所有随机性来自 rng，同一个种子得到同样的题目。
+//
+// 做法：随机填出一个终盘，再按随机顺序成对（关于中心对称）挖空；挖空后解不唯一或难度超过 target 时放回。
+// 挖完后难度正好是 target 就返回，否则换一个终盘重试，最多 MaxAttempts 次。
+func Generate(rng *rand.Rand, target Difficulty) (*Grid, Rating, error) {
+	if target < Easy || target > Expert {
+		return nil, Rating{}, fmt.Errorf("%w: unknown difficulty %d", ErrGenerate, int(target))
+	}
+	for attempt := 0; attempt < MaxAttempts; attempt++ {
+		full := &search{limit: 1, rng: rng}
+		full.run(solver{})
+		cells := full.first.cells
+
+		for _, i := range rng.Perm(Size * Size) {
+			j := Size*Size - 1 - i
+			if cells[i] == 0 {
+				continue
+			}
+			di, dj := cells[i], cells[j]
+			cells[i], cells[j] = 0, 0
+			if r, ok := rateUnique(cells); !ok || r.Difficulty > target {
+				cells[i], cells[j] = di, dj
+			}
+		}
+		if r, _ := rateUnique(cells); r.Difficulty == target {
+			var digits [Size][Size]uint8
+			for i, d := range cells {
+				digits[i/Size][i%Size] = d
+			}
+			g, err := FromDigits(digits)
+			return g, r, err
+		}
+	}
+	return nil, Rating{}, fmt.Errorf("%w: no %s puzzle in %d attempts", ErrGenerate, target, MaxAttempts)
+}
+
+// rateUnique 在题目有唯一解时返回它的难度。
+func rateUnique(cells [Size * Size]uint8) (Rating, bool) {
+	var s solver
+	for i, d := range cells {
+		if d != 0 {
+			s.place(i, d)
+		}
+	}
+	count := &search{limit: 2}
+	if count.run(s); count.found != 1 {
+		return Rating{}, false
+	}
+	rate := &search{limit: 1}
+	rate.run(s)
+	return Rating{Difficulty: difficulty(rate.stats), Stats: rate.stats}, true
+}
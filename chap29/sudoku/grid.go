@@ -0,0 +1,309 @@
+// Package sudoku 实现第29章的综合练习：遵守规则、给定数字不可修改的数独网格，
+// 以及求解器、唯一解题目生成器和两种常见文本格式的解析。
+//
+// 行、列下标和 API 一样从 0 开始；错误消息使用数独常用的 r行c列 记法，从 1 开始，
+// 例如 Set(0, 2, 5) 出错时消息写作 r1c3。数字 0 表示空格。
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	Size    = 9 // 行数、列数
+	BoxSize = 3 // 宫的边长
+)
+
+var (
+	// ErrOutOfBounds 表示行、列不在 0..8，或者数字不在 0..9。
+	ErrOutOfBounds = errors.New("sudoku: out of bounds")
+	// ErrFixedDigit 表示试图修改题目给定的数字。
+	ErrFixedDigit = errors.New("sudoku: cannot change a fixed digit")
+	// ErrRuleViolation 表示同一行、列或宫中出现了重复的数字，具体位置见 *RuleError。
+	ErrRuleViolation = errors.New("sudoku: rule violation")
+)
+
+// Unit 是数字不能重复的区域。
+type Unit int
+
+const (
+	Row Unit = iota
+	Column
+	Box
+)
+
+func (u Unit) String() string {
+	switch u {
+	case Row:
+		return "row"
+	case Column:
+		return "column"
+	case Box:
+		return "box"
+	}
+	return fmt.Sprintf("Unit(%d)", int(u))
+}
+
+// CellError 是针对某个格子的操作错误，包装 ErrOutOfBounds 或 ErrFixedDigit。
+type CellError struct {
+	Row, Col int
+	Digit    uint8
+	Err      error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("set %d at %s: %v", e.Digit, cellName(e.Row, e.Col), e.Err)
+}
+
+func (e *CellError) Unwrap() error { return e.Err }
+
+// RuleError 描述一处冲突：(Row, Col) 上的 Digit 与同一 Unit 中 (OtherRow, OtherCol) 上的数字重复。
+// Index 是该行、列或宫的编号（宫按从左到右、从上到下编号 0..8）。
+type RuleError struct {
+	Row, Col           int
+	Digit              uint8
+	Unit               Unit
+	Index              int
+	OtherRow, OtherCol int
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("sudoku: digit %d at %s conflicts with %s in %s %d",
+		e.Digit, cellName(e.Row, e.Col), cellName(e.OtherRow, e.OtherCol), e.Unit, e.Index+1)
+}
+
+// Is 让 errors.Is(err, ErrRuleViolation) 对任何 *RuleError 成立。
+func (e *RuleError) Is(target error) bool { return target == ErrRuleViolation }
+
+func cellName(row, col int) string { return fmt.Sprintf("r%dc%d", row+1, col+1) }
+
+// BoxOf 返回 (row, col) 所在宫的编号。
+func BoxOf(row, col int) int { return row/BoxSize*BoxSize + col/BoxSize }
+
+// Grid 是一个 9×9 的数独网格。零值是没有给定数字的空网格。
+// Grid 只包含数组，可以直接赋值复制。
+type Grid struct {
+	cells [Size][Size]uint8
+	fixed [Size][Size]bool
+}
+
+// FromDigits 用 digits 创建网格，非 0 的数字都是给定数字（不可修改）。
+// 数字超出 0..9 时返回 *CellError；给定数字互相冲突时返回由 errors.Join 合并的全部 *RuleError。
+func FromDigits(digits [Size][Size]uint8) (*Grid, error) {
+	g := &Grid{}
+	for r := range digits {
+		for c, d := range digits[r] {
+			if d > Size {
+				return nil, &CellError{Row: r, Col: c, Digit: d, Err: ErrOutOfBounds}
+			}
+			g.cells[r][c] = d
+			g.fixed[r][c] = d != 0
+		}
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Get 返回 (row, col) 上的数字，空格为 0。下标越界时和数组一样 panic。
+func (g *Grid) Get(row, col int) uint8 { return g.cells[row][col] }
+
+// Fixed 报告 (row, col) 是否是给定数字。下标越界时 panic。
+func (g *Grid) Fixed(row, col int) bool { return g.fixed[row][col] }
+
+// Digits 返回所有数字的副本。
+func (g *Grid) Digits() [Size][Size]uint8 { return g.cells }
+
+// Set 在 (row, col) 上填入 digit，digit 为 0 表示清空。出错时网格不变：
+//   - 下标或数字越界：*CellError，errors.Is(err, ErrOutOfBounds)；
+//   - 格子是给定数字：*CellError，errors.Is(err, ErrFixedDigit)；
+//   - 与同一行、列、宫中的数字重复：每处冲突一个 *RuleError，由 errors.Join 合并，
+//     errors.Is(err, ErrRuleViolation)。
+func (g *Grid) Set(row, col int, digit uint8) error {
+	if row < 0 || row >= Size || col < 0 || col >= Size || digit > Size {
+		return &CellError{Row: row, Col: col, Digit: digit, Err: ErrOutOfBounds}
+	}
+	if g.fixed[row][col] {
+		return &CellError{Row: row, Col: col, Digit: digit, Err: ErrFixedDigit}
+	}
+	if digit != 0 {
+		if errs := g.conflicts(row, col, digit); len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+	g.cells[row][col] = digit
+	return nil
+}
+
+// Clear 清空 (row, col)，等价于 Set(row, col, 0)。
+func (g *Grid) Clear(row, col int) error { return g.Set(row, col, 0) }
+
+// Reset 清空所有不是给定数字的格子。
+func (g *Grid) Reset() {
+	for r := range g.cells {
+		for c := range g.cells[r] {
+			if !g.fixed[r][c] {
+				g.cells[r][c] = 0
+			}
+		}
+	}
+}
+
+// conflicts 返回在 (row, col) 填入 digit 会与哪些格子冲突，按行、列、宫的顺序，每个区域至多一个。
+func (g *Grid) conflicts(row, col int, digit uint8) []error {
+	var errs []error
+	for c := 0; c < Size; c++ {
+		if c != col && g.cells[row][c] == digit {
+			errs = append(errs, &RuleError{Row: row, Col: col, Digit: digit, Unit: Row, Index: row, OtherRow: row, OtherCol: c})
+			break
+		}
+	}
+	for r := 0; r < Size; r++ {
+		if r != row && g.cells[r][col] == digit {
+			errs = append(errs, &RuleError{Row: row, Col: col, Digit: digit, Unit: Column, Index: col, OtherRow: r, OtherCol: col})
+			break
+		}
+	}
+	r0, c0 := row/BoxSize*BoxSize, col/BoxSize*BoxSize
+	for r := r0; r < r0+BoxSize; r++ {
+		for c := c0; c < c0+BoxSize; c++ {
+			if (r != row || c != col) && g.cells[r][c] == digit {
+				errs = append(errs, &RuleError{Row: row, Col: col, Digit: digit, Unit: Box, Index: BoxOf(row, col), OtherRow: r, OtherCol: c})
+				return errs
+			}
+		}
+	}
+	return errs
+}
+
+// Validate 检查整个网格，返回由 errors.Join 合并的全部冲突；每对重复的数字在每个区域中报告一次，
+// Row、Col 是后出现的那个格子。没有冲突时返回 nil。
+func (g *Grid) Validate() error {
+	var errs []error
+	for u := Row; u <= Box; u++ {
+		for i := 0; i < Size; i++ {
+			var seen [Size + 1]*[2]int
+			for _, p := range unitCells(u, i) {
+				d := g.cells[p[0]][p[1]]
+				if d == 0 {
+					continue
+				}
+				if first := seen[d]; first != nil {
+					errs = append(errs, &RuleError{Row: p[0], Col: p[1], Digit: d, Unit: u, Index: i, OtherRow: first[0], OtherCol: first[1]})
+					continue
+				}
+				p := p
+				seen[d] = &p
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unitCells 返回区域 u 的第 i 个中 9 个格子的 (row, col)。
+func unitCells(u Unit, i int) [Size][2]int {
+	var out [Size][2]int
+	for k := 0; k < Size; k++ {
+		switch u {
+		case Row:
+			out[k] = [2]int{i, k}
+		case Column:
+			out[k] = [2]int{k, i}
+		case Box:
+			out[k] = [2]int{i/BoxSize*BoxSize + k/BoxSize, i%BoxSize*BoxSize + k%BoxSize}
+		}
+	}
+	return out
+}
+
+// Empty 返回空格数。
+func (g *Grid) Empty() int {
+	n := 0
+	for r := range g.cells {
+		for _, d := range g.cells[r] {
+			if d == 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Givens 返回给定数字的个数。
+func (g *Grid) Givens() int {
+	n := 0
+	for r := range g.fixed {
+		for _, f := range g.fixed[r] {
+			if f {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Solved 报告网格是否已经填满且没有冲突。
+func (g *Grid) Solved() bool { return g.Empty() == 0 && g.Validate() == nil }
+
+// Candidates 返回 (row, col) 上可以填入的数字，按从小到大排列；格子已有数字时返回 nil。
+func (g *Grid) Candidates(row, col int) []uint8 {
+	if g.cells[row][col] != 0 {
+		return nil
+	}
+	var out []uint8
+	for d := uint8(1); d <= Size; d++ {
+		if len(g.conflicts(row, col, d)) == 0 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Line 返回 81 个字符的单行格式，空格写作 '.'。
+func (g *Grid) Line() string {
+	var b strings.Builder
+	b.Grow(Size * Size)
+	for r := range g.cells {
+		for _, d := range g.cells[r] {
+			b.WriteByte(digitChar(d))
+		}
+	}
+	return b.String()
+}
+
+// String 返回带宫分隔线的多行格式，Parse 可以读回：
+//
+//	5 3 . | . 7 . | . . .
+//	6 . . | 1 9 5 | . . .
+//	. 9 8 | . . . | . 6 .
+//	------+-------+------
+//	...
+func (g *Grid) String() string {
+	var b strings.Builder
+	for r := range g.cells {
+		if r > 0 && r%BoxSize == 0 {
+			b.WriteString("------+-------+------\n")
+		}
+		for c, d := range g.cells[r] {
+			if c > 0 {
+				if c%BoxSize == 0 {
+					b.WriteString(" |")
+				}
+				b.WriteByte(' ')
+			}
+			b.WriteByte(digitChar(d))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func digitChar(d uint8) byte {
+	if d == 0 {
+		return '.'
+	}
+	return '0' + d
+}
@@ -0,0 +1,58 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSyntax 表示题目文本无法解析。
+var ErrSyntax = errors.New("sudoku: syntax error")
+
+// Parse 解析一道题目，文本中的数字都是给定数字。支持两种常见格式：
+//
+//   - 81 个字符的单行格式：1..9 为数字，'0' 或 '.' 为空格，例如
+//     53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79
+//   - 多行网格格式：同样的字符，另外可以用空白和 '|'、'-'、'+' 画出宫的分隔线，Grid.String 的输出就是这种格式。
+//
+// 其他字符和数字个数不是 81 时返回包装 ErrSyntax 的错误（带行、列位置）；
+// 给定数字互相冲突时返回由 errors.Join 合并的全部 *RuleError。
+func Parse(s string) (*Grid, error) {
+	var digits [Size][Size]uint8
+	n := 0
+	line, col := 1, 0
+	for _, ch := range s {
+		col++
+		var d uint8
+		switch {
+		case ch == '\n':
+			line, col = line+1, 0
+			continue
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '|' || ch == '-' || ch == '+':
+			continue
+		case ch == '.' || ch == '0':
+			d = 0
+		case ch >= '1' && ch <= '9':
+			d = uint8(ch - '0')
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at line %d, column %d", ErrSyntax, ch, line, col)
+		}
+		if n == Size*Size {
+			return nil, fmt.Errorf("%w: more than %d cells (line %d, column %d)", ErrSyntax, Size*Size, line, col)
+		}
+		digits[n/Size][n%Size] = d
+		n++
+	}
+	if n != Size*Size {
+		return nil, fmt.Errorf("%w: got %d cells, want %d", ErrSyntax, n, Size*Size)
+	}
+	return FromDigits(digits)
+}
+
+// MustParse 与 Parse 相同，但出错时 panic，用于程序中写死的题目。
+func MustParse(s string) *Grid {
+	g, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
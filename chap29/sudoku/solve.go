@@ -0,0 +1,203 @@
+package sudoku
+
+import (
+	"errors"
+	"math/bits"
+	"math/rand"
+)
+
+// ErrUnsolvable 表示题目没有解。
+var ErrUnsolvable = errors.New("sudoku: puzzle has no solution")
+
+// Stats 记录求解过程，Rate 根据它评定难度。
+type Stats struct {
+	NakedSingles  int // 只剩一个候选数的格子
+	HiddenSingles int // 某个数字在行、列或宫中只剩一个位置
+	Guesses       int // 约束传播停滞、需要试填的次数
+	Backtracks    int // 试填失败、退回的次数
+}
+
+const allDigits uint16 = 0x3fe // 第 1..9 位
+
+// units 是 27 个区域：9 行、9 列、9 宫，每个区域 9 个格子的下标。
+var units = func() (u [3 * Size][Size]int) {
+	for k := Row; k <= Box; k++ {
+		for i := 0; i < Size; i++ {
+			for j, p := range unitCells(k, i) {
+				u[int(k)*Size+i][j] = p[0]*Size + p[1]
+			}
+		}
+	}
+	return u
+}()
+
+// solver 是求解用的紧凑状态：每个区域已用数字的位图。它很小，试填时直接按值复制，不需要撤销。
+type solver struct {
+	cells             [Size * Size]uint8
+	rows, cols, boxes [Size]uint16
+}
+
+func newSolver(g *Grid) solver {
+	var s solver
+	for r := range g.cells {
+		for c, d := range g.cells[r] {
+			if d != 0 {
+				s.place(r*Size+c, d)
+			}
+		}
+	}
+	return s
+}
+
+func (s *solver) place(i int, d uint8) {
+	r, c := i/Size, i%Size
+	bit := uint16(1) << d
+	s.cells[i] = d
+	s.rows[r] |= bit
+	s.cols[c] |= bit
+	s.boxes[BoxOf(r, c)] |= bit
+}
+
+func (s *solver) candidates(i int) uint16 {
+	r, c := i/Size, i%Size
+	return allDigits &^ (s.rows[r] | s.cols[c] | s.boxes[BoxOf(r, c)])
+}
+
+// propagate 反复填入唯一候选数（naked single）；停滞时再找隐性唯一（hidden single）。
+// 返回 false 表示出现矛盾：某个空格没有候选数，或者某个区域放不下某个数字。
+func (s *solver) propagate(st *Stats) bool {
+	for {
+		progress := false
+		for i := range s.cells {
+			if s.cells[i] != 0 {
+				continue
+			}
+			cand := s.candidates(i)
+			switch bits.OnesCount16(cand) {
+			case 0:
+				return false
+			case 1:
+				s.place(i, uint8(bits.TrailingZeros16(cand)))
+				st.NakedSingles++
+				progress = true
+			}
+		}
+		if progress {
+			continue
+		}
+		for _, unit := range units {
+			for d := uint8(1); d <= Size; d++ {
+				bit := uint16(1) << d
+				pos, count := -1, 0
+				for _, i := range unit {
+					if s.cells[i] == d {
+						count = -1
+						break
+					}
+					if s.cells[i] == 0 && s.candidates(i)&bit != 0 {
+						pos = i
+						count++
+					}
+				}
+				switch count {
+				case 0:
+					return false
+				case 1:
+					s.place(pos, d)
+					st.HiddenSingles++
+					progress = true
+				}
+			}
+		}
+		if !progress {
+			return true
+		}
+	}
+}
+
+// search 是约束传播加回溯的求解过程。
+type search struct {
+	stats Stats
+	rng   *rand.Rand // 不为 nil 时按随机顺序试填，用于生成终盘
+	limit int        // 找到这么多个解后停止
+	found int
+	first solver
+}
+
+// run 返回 true 表示已经找到足够多的解，应当停止。
+func (x *search) run(s solver) bool {
+	if !s.propagate(&x.stats) {
+		return false
+	}
+	// 选择候选数最少的空格（MRV），分支最少
+	best, bestN := -1, Size+1
+	for i := range s.cells {
+		if s.cells[i] == 0 {
+			if n := bits.OnesCount16(s.candidates(i)); n < bestN {
+				best, bestN = i, n
+			}
+		}
+	}
+	if best < 0 {
+		if x.found == 0 {
+			x.first = s
+		}
+		x.found++
+		return x.found >= x.limit
+	}
+
+	var digits []uint8
+	for cand := s.candidates(best); cand != 0; cand &= cand - 1 {
+		digits = append(digits, uint8(bits.TrailingZeros16(cand)))
+	}
+	if x.rng != nil {
+		x.rng.Shuffle(len(digits), func(i, j int) { digits[i], digits[j] = digits[j], digits[i] })
+	}
+	x.stats.Guesses++
+	for _, d := range digits {
+		next := s
+		next.place(best, d)
+		if x.run(next) {
+			return true
+		}
+		x.stats.Backtracks++
+	}
+	return false
+}
+
+// solution 把求解结果填回 g 的副本，保留给定数字的标记。
+func (x *search) solution(g *Grid) *Grid {
+	out := *g
+	for i, d := range x.first.cells {
+		out.cells[i/Size][i%Size] = d
+	}
+	return &out
+}
+
+// Solve 返回 g 的一个解（g 本身不变），给定数字的标记保持不变。
+// g 有冲突时返回 Validate 的错误，无解时返回 ErrUnsolvable。有多个解时返回其中一个，用 CountSolutions 检查唯一性。
+func Solve(g *Grid) (*Grid, error) {
+	sol, _, err := solve(g)
+	return sol, err
+}
+
+func solve(g *Grid) (*Grid, Stats, error) {
+	if err := g.Validate(); err != nil {
+		return nil, Stats{}, err
+	}
+	x := &search{limit: 1}
+	if !x.run(newSolver(g)) {
+		return nil, x.stats, ErrUnsolvable
+	}
+	return x.solution(g), x.stats, nil
+}
+
+// CountSolutions 返回 g 的解的个数，最多数到 limit 为止（检查唯一解时传 2 即可）。g 有冲突时返回 0。
+func CountSolutions(g *Grid, limit int) int {
+	if limit <= 0 || g.Validate() != nil {
+		return 0
+	}
+	x := &search{limit: limit}
+	x.run(newSolver(g))
+	return x.found
+}
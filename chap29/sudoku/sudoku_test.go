@@ -0,0 +1,183 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const (
+	// 维基百科“数独”词条中的例题及其解
+	classic         = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+	classicSolution = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+	// Arto Inkala 2012 年发布的“最难数独”
+	inkala = "8..........36......7..9.2...5...7.......457.....1...3...1....68..85...1..9....4.."
+)
+
+const classicGrid = `
+5 3 . | . 7 . | . . .
+6 . . | 1 9 5 | . . .
+. 9 8 | . . . | . 6 .
+------+-------+------
+8 . . | . 6 . | . . 3
+4 . . | 8 . 3 | . . 1
+7 . . | . 2 . | . . 6
+------+-------+------
+. 6 . | . . . | 2 8 .
+. . . | 4 1 9 | . . 5
+. . . | . 8 . | . 7 9
+`
+
+// joined 拆开 errors.Join 合并的错误。
+func joined(err error) []error {
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		return u.Unwrap()
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+func TestParse(t *testing.T) {
+	a, err := Parse(classic)
+	if err != nil || a.Givens() != 30 {
+		t.Fatalf("Parse(line) = %d givens, %v", a.Givens(), err)
+	}
+	b, err := Parse(classicGrid)
+	if err != nil || a.Digits() != b.Digits() {
+		t.Errorf("grid format: %v", err)
+	}
+	c, err := Parse(a.String())
+	if err != nil || c.Line() != classic {
+		t.Errorf("String does not round-trip: %q, %v", c.Line(), err)
+	}
+
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"53..7....\n6..x95....", "line 2, column 4"},
+		{classic[:80], ""},
+	} {
+		if _, err := Parse(tc.in); !errors.Is(err, ErrSyntax) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) = %v, want ErrSyntax mentioning %q", tc.in, err, tc.want)
+		}
+	}
+
+	_, err = Parse("55" + classic[2:])
+	var re *RuleError
+	if !errors.As(err, &re) || re.Unit != Row || len(joined(err)) != 2 {
+		t.Errorf("conflicting givens: %v", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	g := MustParse(classic)
+	before := *g
+
+	if err := g.Set(9, 0, 1); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("row out of range: %v", err)
+	}
+	if err := g.Set(0, 2, 10); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("digit out of range: %v", err)
+	}
+	err := g.Set(0, 0, 4)
+	var ce *CellError
+	if !errors.Is(err, ErrFixedDigit) || !errors.As(err, &ce) || ce.Row != 0 || ce.Col != 0 {
+		t.Errorf("changing a given: %v", err)
+	}
+	if err := g.Clear(0, 1); !errors.Is(err, ErrFixedDigit) {
+		t.Errorf("clearing a given: %v", err)
+	}
+
+	// r1c3 填 5：r1c1 的 5 既在同一行，也在同一宫
+	if err := g.Set(0, 2, 5); !errors.Is(err, ErrRuleViolation) || len(joined(err)) != 2 {
+		t.Errorf("row and box conflict: %v", err)
+	}
+	// r3c1 填 6：同一行的 r3c8、同一列的 r2c1、同一宫的 r2c1
+	err = g.Set(2, 0, 6)
+	var units []string
+	for _, e := range joined(err) {
+		var re *RuleError
+		if errors.As(e, &re) {
+			units = append(units, re.Unit.String())
+		}
+	}
+	if strings.Join(units, ",") != "row,column,box" {
+		t.Errorf("Set(r3c1, 6) reported %v: %v", units, err)
+	}
+	if *g != before {
+		t.Error("failed Set modified the grid")
+	}
+
+	if err := g.Set(0, 2, 4); err != nil || g.Get(0, 2) != 4 || g.Fixed(0, 2) {
+		t.Errorf("valid Set: %v", err)
+	}
+	if got := fmt.Sprint(g.Candidates(0, 3)); got != "[2 6]" {
+		t.Errorf("Candidates(r1c4) = %s", got)
+	}
+	g.Reset()
+	if *g != before {
+		t.Error("Reset did not restore the puzzle")
+	}
+}
+
+func TestSolve(t *testing.T) {
+	g := MustParse(classic)
+	sol, err := Solve(g)
+	if err != nil || sol.Line() != classicSolution || !sol.Solved() {
+		t.Fatalf("Solve(classic) = %s, %v", sol.Line(), err)
+	}
+	if g.Empty() != 51 || !sol.Fixed(0, 0) || sol.Fixed(0, 2) {
+		t.Error("Solve modified the puzzle or lost the fixed marks")
+	}
+	if r, _ := Rate(g); r.Difficulty != Easy {
+		t.Errorf("Rate(classic) = %s %+v", r.Difficulty, r.Stats)
+	}
+
+	hard := MustParse(inkala)
+	if sol, err := Solve(hard); err != nil || !sol.Solved() || CountSolutions(hard, 2) != 1 {
+		t.Errorf("Solve(inkala): %v", err)
+	}
+
+	var empty Grid
+	if n := CountSolutions(&empty, 2); n != 2 {
+		t.Errorf("CountSolutions(empty, 2) = %d", n)
+	}
+	nosol := MustParse("12345678." + "........9" + strings.Repeat(".", 63))
+	if _, err := Solve(nosol); nosol.Validate() != nil || !errors.Is(err, ErrUnsolvable) {
+		t.Errorf("unsolvable puzzle: %v", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for d := Easy; d <= Expert; d++ {
+		g, r, err := Generate(rand.New(rand.NewSource(29)), d)
+		if err != nil {
+			t.Errorf("%s: %v", d, err)
+			continue
+		}
+		again, _, _ := Generate(rand.New(rand.NewSource(29)), d)
+		rated, _ := Rate(g)
+		if r.Difficulty != d || rated != r || CountSolutions(g, 2) != 1 || g.Givens() != 81-g.Empty() {
+			t.Errorf("%s: got %s %+v, rated %+v", d, r.Difficulty, r.Stats, rated)
+		}
+		if again.Line() != g.Line() {
+			t.Errorf("%s: same seed produced a different puzzle", d)
+		}
+	}
+	if _, _, err := Generate(rand.New(rand.NewSource(29)), Difficulty(7)); !errors.Is(err, ErrGenerate) {
+		t.Errorf("unknown difficulty: %v", err)
+	}
+	for _, s := range []string{"easy", "medium", "hard", "expert"} {
+		if d, err := ParseDifficulty(s); err != nil || d.String() != s {
+			t.Errorf("ParseDifficulty(%q) = %s, %v", s, d, err)
+		}
+	}
+	if _, err := ParseDifficulty("insane"); err == nil {
+		t.Error("ParseDifficulty accepted an unknown name")
+	}
+}
@@ -0,0 +1,187 @@
+// 独立运行：go run ./chap29/sudoku_demo
+// 演示：解析两种格式的题目，用 Set 的错误类型守住数独规则和给定数字，求解、数解的个数，
+// 并用固定的种子生成各个难度的唯一解题目。断言见 go test ./chap29/sudoku。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+
+	"books/chap29/sudoku"
+)
+
+// indent 缩进输出多行文本。
+func indent(s string) {
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		fmt.Println("    " + line)
+	}
+}
+
+// joined 拆开 errors.Join 合并的错误。
+func joined(err error) []error {
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		return u.Unwrap()
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+const (
+	// 维基百科“数独”词条中的例题及其解
+	classic         = "53..7....6..195....98....6.8...6...34..8.3..17...2...6.6....28....419..5....8..79"
+	classicSolution = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+	// Arto Inkala 2012 年发布的“最难数独”
+	inkala = "8..........36......7..9.2...5...7.......457.....1...3...1....68..85...1..9....4.."
+)
+
+const classicGrid = `
+5 3 . | . 7 . | . . .
+6 . . | 1 9 5 | . . .
+. 9 8 | . . . | . 6 .
+------+-------+------
+8 . . | . 6 . | . . 3
+4 . . | 8 . 3 | . . 1
+7 . . | . 2 . | . . 6
+------+-------+------
+. 6 . | . . . | 2 8 .
+. . . | 4 1 9 | . . 5
+. . . | . 8 . | . 7 9
+`
+
+func main() {
+	seed := flag.Int64("seed", 29, "生成题目的随机种子")
+	show := flag.String("show", "hard", "打印生成的哪个难度的题目和解：easy、medium、hard、expert")
+	flag.Parse()
+	showLevel, err := sudoku.ParseDifficulty(*show)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	parsing()
+	rules()
+	solving()
+	generating(*seed, showLevel)
+}
+
+func parsing() {
+	fmt.Println("=== 1. 解析两种格式 ===")
+	a := sudoku.MustParse(classic)
+	fmt.Printf("  81 个字符的单行格式：%d 个给定数字\n", a.Givens())
+	b := sudoku.MustParse(classicGrid)
+	fmt.Println("  带分隔线的网格格式得到同一道题:", a.Digits() == b.Digits())
+	indent(a.String())
+	c := sudoku.MustParse(a.String())
+	fmt.Println("  String 的输出可以读回，Line 输出单行格式:", c.Line())
+
+	_, err := sudoku.Parse("53..7....\n6..x95....")
+	fmt.Println("  非法字符报告行、列:", err)
+	_, err = sudoku.Parse(classic[:80])
+	fmt.Println("  格子数不对:", err)
+	_, err = sudoku.Parse("55" + classic[2:])
+	fmt.Println("  给定数字互相冲突:", strings.ReplaceAll(err.Error(), "\n", "；"))
+	fmt.Println()
+}
+
+func rules() {
+	fmt.Println("=== 2. Set 的错误 ===")
+	g := sudoku.MustParse(classic)
+	before := *g
+
+	fmt.Println("  行越界:", g.Set(9, 0, 1))
+	fmt.Println("  数字越界:", g.Set(0, 2, 10))
+	err := g.Set(0, 0, 4)
+	var ce *sudoku.CellError
+	if errors.As(err, &ce) {
+		fmt.Printf("  修改给定数字：%v（errors.As 得到 r%dc%d）\n", err, ce.Row+1, ce.Col+1)
+	}
+	fmt.Println("  清空给定数字同样不允许:", g.Clear(0, 1))
+
+	// r1c3 填 5：r1c1 的 5 既在同一行，也在同一宫
+	err = g.Set(0, 2, 5)
+	fmt.Println("  与同一行、同一宫冲突:", strings.ReplaceAll(err.Error(), "\n", "；"))
+
+	// 找一个同时与行、列、宫都冲突的空格和数字
+	fmt.Println("  一次 Set 报告全部三处冲突（errors.Join）：")
+	found := false
+	for r := 0; r < sudoku.Size && !found; r++ {
+		for c := 0; c < sudoku.Size && !found; c++ {
+			for d := uint8(1); d <= 9 && !found; d++ {
+				if g.Get(r, c) != 0 {
+					continue
+				}
+				err := g.Set(r, c, d)
+				if err == nil {
+					g.Clear(r, c) // 合法的填法，撤销后继续找
+				}
+				if errs := joined(err); len(errs) == 3 {
+					found = true
+					for _, e := range errs {
+						var re *sudoku.RuleError
+						if errors.As(e, &re) {
+							fmt.Printf("    %-6s %v\n", re.Unit, e)
+						}
+					}
+				}
+			}
+		}
+	}
+	fmt.Println("  出错的 Set 不修改网格:", *g == before)
+
+	err = g.Set(0, 2, 4)
+	fmt.Printf("  合法的填入 r1c3 = %d：%v，不是给定数字: %v\n", g.Get(0, 2), err, !g.Fixed(0, 2))
+	fmt.Println("  r1c4 的候选数:", g.Candidates(0, 3))
+	g.Reset()
+	fmt.Println("  Reset 清空填入的数字，保留给定数字:", *g == before)
+	fmt.Println()
+}
+
+func solving() {
+	fmt.Println("=== 3. 求解 ===")
+	g := sudoku.MustParse(classic)
+	sol, err := sudoku.Solve(g)
+	fmt.Printf("  例题的解与词条一致: %v（%v），原网格仍有 %d 个空格\n", sol.Line() == classicSolution, err, g.Empty())
+	r, _ := sudoku.Rate(g)
+	fmt.Printf("  例题只靠唯一候选数就能解完：%s %+v\n", r.Difficulty, r.Stats)
+
+	hard := sudoku.MustParse(inkala)
+	sol, err = sudoku.Solve(hard)
+	r, _ = sudoku.Rate(hard)
+	fmt.Printf("  Inkala 的“最难数独”：%d 个解，%s %+v, %v\n", sudoku.CountSolutions(hard, 2), r.Difficulty, r.Stats, err)
+	indent(sol.String())
+
+	var empty sudoku.Grid
+	fmt.Println("  空网格的解（数到 2 为止）:", sudoku.CountSolutions(&empty, 2))
+	nosol := sudoku.MustParse("12345678." + "........9" + strings.Repeat(".", 63))
+	_, err = sudoku.Solve(nosol)
+	fmt.Printf("  没有冲突（%v）但无解：%v\n", nosol.Validate(), err)
+	fmt.Println()
+}
+
+func generating(seed int64, show sudoku.Difficulty) {
+	fmt.Printf("=== 4. 生成唯一解题目（种子 %d）===\n", seed)
+	for d := sudoku.Easy; d <= sudoku.Expert; d++ {
+		rng := rand.New(rand.NewSource(seed))
+		g, r, err := sudoku.Generate(rng, d)
+		if err != nil {
+			fmt.Printf("  %s：%v\n", d, err)
+			continue
+		}
+		fmt.Printf("  %-6s %d 个给定数字，%+v\n", d, g.Givens(), r.Stats)
+		if d == show {
+			fmt.Println("    " + g.Line())
+			indent(g.String())
+			sol, _ := sudoku.Solve(g)
+			fmt.Println()
+			indent(sol.String())
+		}
+	}
+	_, _, err := sudoku.Generate(rand.New(rand.NewSource(seed)), sudoku.Difficulty(7))
+	fmt.Println("  未知的难度:", err)
+}
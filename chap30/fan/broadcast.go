@@ -0,0 +1,120 @@
+package fan
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Policy 决定订阅者的缓冲满了之后怎么办。
+type Policy int
+
+const (
+	// Block 等待订阅者取走值；它不读时广播停下，所有订阅者都收不到后面的值。
+	Block Policy = iota
+	// DropOldest 丢弃缓冲中最旧的值，放入新值：慢消费者总是看到最近的值。
+	DropOldest
+	// DropNewest 丢弃新值，缓冲保持不变：慢消费者看到的是它落后时的那些值。
+	DropNewest
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop-oldest"
+	case DropNewest:
+		return "drop-newest"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Sub 是一个订阅者的设置。
+type Sub struct {
+	Buffer int // 缓冲的值的个数；DropOldest 至少为 1
+	Policy Policy
+}
+
+// Subscriber 是 Broadcast 返回的一个订阅者。
+type Subscriber[T any] struct {
+	C       <-chan T // 输入关闭或 ctx 被取消后关闭
+	Sub     Sub
+	ch      chan T
+	dropped atomic.Uint64
+}
+
+// Dropped 返回因为缓冲已满而丢弃的值的个数（Block 策略总是 0）。
+func (s *Subscriber[T]) Dropped() uint64 { return s.dropped.Load() }
+
+// Broadcast 把每个值复制给每一个订阅者，按 subs 的顺序依次投递，每个订阅者按自己的 Policy 处理缓冲已满的情况。
+// 有 Block 订阅者时，每个订阅者应当在自己的 goroutine 中读取，否则先读一个订阅者可能等不到值。
+// 值是浅复制的：T 含有指针、切片或 map 时，订阅者共享它们指向的数据。
+func Broadcast[T any](ctx context.Context, in <-chan T, subs ...Sub) []*Subscriber[T] {
+	out := make([]*Subscriber[T], len(subs))
+	for i, sub := range subs {
+		if sub.Policy == DropOldest {
+			sub.Buffer = max(sub.Buffer, 1)
+		}
+		ch := make(chan T, max(sub.Buffer, 0))
+		out[i] = &Subscriber[T]{C: ch, Sub: sub, ch: ch}
+	}
+	go func() {
+		defer func() {
+			for _, s := range out {
+				close(s.ch)
+			}
+		}()
+		for {
+			var v T
+			var ok bool
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			for _, s := range out {
+				if !s.deliver(ctx, v) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// deliver 按策略把 v 交给订阅者；ctx 被取消时返回 false。
+func (s *Subscriber[T]) deliver(ctx context.Context, v T) bool {
+	switch s.Sub.Policy {
+	case DropNewest:
+		select {
+		case s.ch <- v:
+		default:
+			s.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- v:
+				return true
+			default:
+			}
+			// 缓冲已满：取走最旧的一个再试；消费者可能同时取走了它，那样就不算丢弃
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- v:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
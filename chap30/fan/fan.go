@@ -0,0 +1,104 @@
+// Package fan 提供语义明确的扇入、扇出（第30章 fanIn、fanOut 的通用版本）。
+//
+// chap30 的 fanOut(in, out1, out2) 用 select 把每个值发给恰好准备好的那个输出，
+// 名字像广播，实际是负载均衡；fanIn(input1, input2) 只能合并两个通道。这里把几种语义分开：
+//
+//   - Merge：任意个输入合并成一个输出（扇入）；
+//   - Distribute：每个值只交给一个输出，谁空闲给谁（负载均衡，也就是 chap30 fanOut 的实际行为）；
+//   - Broadcast：每个值复制给所有订阅者，每个订阅者有自己的缓冲和慢消费者策略；
+//   - Partition：按键的哈希路由，同一个键总是到同一个输出，同一个键的值保持顺序。
+//
+// 所有函数都接收 ctx：输入关闭或 ctx 被取消时，内部的 goroutine 退出并关闭全部输出。
+package fan
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Merge 把所有输入合并成一个输出，全部输入关闭后关闭输出；没有输入时输出立即关闭。
+// 不同输入之间的顺序不确定，同一个输入的值保持顺序。
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func(in <-chan T) {
+			defer wg.Done()
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(in)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Distribute 创建 n 个输出，把每个值交给其中恰好一个：同时有多个输出在等待时随机选一个，
+// 都不在等待时阻塞，直到有一个准备好。处理得快的消费者拿到的值更多。n < 1 时按 1 处理。
+func Distribute[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	n = max(n, 1)
+	outs, result := makeOutputs[T](n, 0)
+	go func() {
+		defer closeAll(outs)
+		// 前 n 个 case 是各个输出，最后一个是 ctx.Done()
+		cases := make([]reflect.SelectCase, n+1)
+		for i, o := range outs {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(o)}
+		}
+		cases[n] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		for {
+			var v T
+			var ok bool
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			rv := reflect.ValueOf(&v).Elem()
+			for i := 0; i < n; i++ {
+				cases[i].Send = rv
+			}
+			if chosen, _, _ := reflect.Select(cases); chosen == n {
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// makeOutputs 创建 n 个容量为 buffer 的通道，同时返回只读的视图。
+func makeOutputs[T any](n, buffer int) ([]chan T, []<-chan T) {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T, buffer)
+		result[i] = outs[i]
+	}
+	return outs, result
+}
+
+func closeAll[T any](outs []chan T) {
+	for _, o := range outs {
+		close(o)
+	}
+}
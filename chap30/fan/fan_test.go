@@ -0,0 +1,271 @@
+package fan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"books/chap30/leaktest"
+)
+
+// generate 与 chap30 的 generate 相同。
+func generate(nums ...int) <-chan int {
+	out := make(chan int)
+	go func() {
+		for _, n := range nums {
+			out <- n
+		}
+		close(out)
+	}()
+	return out
+}
+
+func collect[T any](in <-chan T) []T {
+	var out []T
+	for v := range in {
+		out = append(out, v)
+	}
+	return out
+}
+
+// collectAll 在各自的 goroutine 中读完每个输出。
+func collectAll[T any](outs []<-chan T) [][]T {
+	res := make([][]T, len(outs))
+	var wg sync.WaitGroup
+	for i, o := range outs {
+		wg.Add(1)
+		go func(i int, o <-chan T) {
+			defer wg.Done()
+			res[i] = collect(o)
+		}(i, o)
+	}
+	wg.Wait()
+	return res
+}
+
+func rangeInts(from, to int) []int {
+	var out []int
+	for i := from; i <= to; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+func TestMerge(t *testing.T) {
+	defer leaktest.Check(t)()
+	ctx := context.Background()
+	var ins []<-chan int
+	for k := 0; k < 5; k++ {
+		ins = append(ins, generate(rangeInts(k*100+1, k*100+20)...))
+	}
+	got := collect(Merge(ctx, ins...))
+	perInput := make([][]int, 5)
+	for _, v := range got {
+		perInput[(v-1)/100] = append(perInput[(v-1)/100], v)
+	}
+	for k, s := range perInput {
+		if want := rangeInts(k*100+1, k*100+20); fmt.Sprint(s) != fmt.Sprint(want) {
+			t.Errorf("input %d: got %v", k, s)
+		}
+	}
+	if got := collect(Merge(ctx, generate(1, 2, 3))); fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("Merge of one input = %v", got)
+	}
+	if got := collect(Merge[int](ctx)); len(got) != 0 {
+		t.Errorf("Merge without inputs = %v", got)
+	}
+}
+
+func TestDistribute(t *testing.T) {
+	defer leaktest.Check(t)()
+	outs := Distribute(context.Background(), generate(rangeInts(1, 60)...), 3)
+	res := make([][]int, len(outs))
+	var wg sync.WaitGroup
+	for i, o := range outs {
+		wg.Add(1)
+		go func(i int, o <-chan int) {
+			defer wg.Done()
+			for v := range o {
+				res[i] = append(res[i], v)
+				if i == 0 {
+					time.Sleep(2 * time.Millisecond) // 慢的 worker
+				}
+			}
+		}(i, o)
+	}
+	wg.Wait()
+	var all []int
+	for _, r := range res {
+		all = append(all, r...)
+	}
+	sort.Ints(all)
+	if fmt.Sprint(all) != fmt.Sprint(rangeInts(1, 60)) {
+		t.Errorf("values lost or duplicated: %v", all)
+	}
+	if len(res[0]) >= len(res[1]) || len(res[0]) >= len(res[2]) {
+		t.Errorf("slow output got %d values, others %d and %d", len(res[0]), len(res[1]), len(res[2]))
+	}
+	if outs := Distribute(context.Background(), generate(1, 2), 0); len(outs) != 1 || fmt.Sprint(collect(outs[0])) != "[1 2]" {
+		t.Error("Distribute with n = 0 should use one output")
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	defer leaktest.Check(t)()
+	ctx := context.Background()
+	subs := Broadcast(ctx, generate(1, 2, 3, 4, 5),
+		Sub{Policy: Block}, Sub{Buffer: 2, Policy: Block}, Sub{Buffer: 8, Policy: DropNewest})
+	var outs []<-chan int
+	for _, s := range subs {
+		outs = append(outs, s.C)
+	}
+	if res := collectAll(outs); fmt.Sprint(res) != "[[1 2 3 4 5] [1 2 3 4 5] [1 2 3 4 5]]" {
+		t.Errorf("Broadcast = %v", res)
+	}
+
+	// 慢消费者：输入全部发送完之后才开始读。send 返回时 Broadcast 已经收下这个值，
+	// 所以读的时候已经确定了缓冲里剩下哪些值
+	for _, tc := range []struct {
+		policy Policy
+		buffer int
+		want   string
+	}{
+		{DropNewest, 2, "[1 2]"},
+		{DropOldest, 2, "[4 5]"},
+		{DropOldest, 0, "[5]"}, // DropOldest 的缓冲至少为 1
+	} {
+		in := make(chan int)
+		s := Broadcast(ctx, in, Sub{Buffer: tc.buffer, Policy: tc.policy})[0]
+		for v := 1; v <= 5; v++ {
+			in <- v
+		}
+		close(in)
+		got := collect(s.C)
+		if fmt.Sprint(got) != tc.want || s.Dropped() != uint64(5-len(got)) {
+			t.Errorf("%v (buffer %d): got %v, dropped %d; want %s", tc.policy, tc.buffer, got, s.Dropped(), tc.want)
+		}
+	}
+}
+
+func TestBroadcastBlock(t *testing.T) {
+	defer leaktest.Check(t)()
+	in := make(chan int)
+	subs := Broadcast(context.Background(), in, Sub{Buffer: 1, Policy: Block}, Sub{Buffer: 10, Policy: DropNewest})
+	go func() {
+		for v := 1; v <= 4; v++ {
+			in <- v
+		}
+		close(in)
+	}()
+	// Block 订阅者（缓冲 1）不读：1 留在它的缓冲里，广播停在第 2 个值上
+	var fast []int
+	timeout := time.After(50 * time.Millisecond)
+wait:
+	for {
+		select {
+		case v := <-subs[1].C:
+			fast = append(fast, v)
+		case <-timeout:
+			break wait
+		}
+	}
+	if fmt.Sprint(fast) != "[1]" {
+		t.Errorf("while the Block subscriber is idle the fast one got %v", fast)
+	}
+	blocked := collect(subs[0].C)
+	fast = append(fast, collect(subs[1].C)...)
+	if fmt.Sprint(blocked) != "[1 2 3 4]" || fmt.Sprint(fast) != "[1 2 3 4]" || subs[0].Dropped() != 0 {
+		t.Errorf("after the Block subscriber resumes: %v / %v", blocked, fast)
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	for p, want := range map[Policy]string{Block: "block", DropOldest: "drop-oldest", DropNewest: "drop-newest", 7: "Policy(7)"} {
+		if p.String() != want {
+			t.Errorf("Policy(%d).String() = %q, want %q", int(p), p.String(), want)
+		}
+	}
+}
+
+type order struct {
+	user string
+	seq  int
+}
+
+func TestPartition(t *testing.T) {
+	defer leaktest.Check(t)()
+	users := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+	in := make(chan order)
+	go func() {
+		for seq := 1; seq <= 10; seq++ {
+			for _, u := range users {
+				in <- order{u, seq}
+			}
+		}
+		close(in)
+	}()
+	const n = 3
+	res := collectAll(Partition(context.Background(), in, n, func(o order) string { return o.user }))
+
+	total := 0
+	for p, orders := range res {
+		last := map[string]int{}
+		for _, o := range orders {
+			if PartitionOf(o.user, n) != p {
+				t.Errorf("%s routed to %d, want %d", o.user, p, PartitionOf(o.user, n))
+			}
+			if o.seq != last[o.user]+1 {
+				t.Errorf("%s: seq %d after %d", o.user, o.seq, last[o.user])
+			}
+			last[o.user] = o.seq
+		}
+		total += len(orders)
+	}
+	if total != 60 {
+		t.Errorf("%d orders delivered, want 60", total)
+	}
+}
+
+func TestPartitionOf(t *testing.T) {
+	for _, n := range []int{1, 0, -1, -8} {
+		if p := PartitionOf("alice", n); p != 0 {
+			t.Errorf("PartitionOf(alice, %d) = %d, want 0", n, p)
+		}
+	}
+	for _, key := range []string{"", "alice", "bob", "订单-42"} {
+		p := PartitionOf(key, 7)
+		if p < 0 || p >= 7 || p != PartitionOf(key, 7) {
+			t.Errorf("PartitionOf(%q, 7) = %d", key, p)
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	defer leaktest.Check(t)()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 一个永远不会关闭的输入：没有取消的话每个函数内部的 goroutine 都会一直等
+	forever := make(chan int)
+	merged := Merge(ctx, forever, forever)
+	dist := Distribute(ctx, forever, 2)
+	subs := Broadcast(ctx, forever, Sub{Policy: Block}, Sub{Buffer: 1, Policy: DropOldest})
+	parts := Partition(ctx, forever, 2, func(int) string { return "" })
+
+	// Block 订阅者在 send 中阻塞时同样能退出
+	busy := make(chan int, 1)
+	busy <- 1
+	stuck := Broadcast(ctx, busy, Sub{Policy: Block})
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	outs := append([]<-chan int{merged, subs[0].C, subs[1].C, stuck[0].C}, dist...)
+	outs = append(outs, parts...)
+	for i, o := range outs {
+		if got := collect(o); len(got) != 0 {
+			t.Errorf("output %d sent %v after cancel", i, got)
+		}
+	}
+}
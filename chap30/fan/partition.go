@@ -0,0 +1,42 @@
+package fan
+
+import (
+	"context"
+	"hash/fnv"
+)
+
+// PartitionOf 返回 key 在 n 个分区中的编号：FNV-1a 哈希对 n 取模，与进程、运行次数无关。
+// n < 1 时按 1 处理，总是返回 0，与 Partition 一致。
+func PartitionOf(key string, n int) int {
+	n = max(n, 1)
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// Partition 创建 n 个输出，把每个值发送到 PartitionOf(key(v), n) 号输出：
+// 同一个键总是到同一个输出，同一个键的值保持输入中的顺序，适合按用户、订单号等串行处理。
+// 发送是阻塞的，一个分区的消费者停下时整个 Partition 也会停下。n < 1 时按 1 处理。
+func Partition[T any](ctx context.Context, in <-chan T, n int, key func(T) string) []<-chan T {
+	n = max(n, 1)
+	outs, result := makeOutputs[T](n, 0)
+	go func() {
+		defer closeAll(outs)
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case outs[PartitionOf(key(v), n)] <- v:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return result
+}
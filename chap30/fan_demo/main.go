@@ -0,0 +1,263 @@
+// 独立运行：go run ./chap30/fan_demo
+// 演示：fan 各个函数的语义——Merge 任意个输入、Distribute 负载均衡（chap30 fanOut 的实际行为）、
+// Broadcast 的三种慢消费者策略、Partition 按键路由，以及取消后关闭全部输出。断言见 go test ./chap30/fan。
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"books/chap30/fan"
+	"books/chap30/leaktest"
+)
+
+// generate 与 chap30 的 generate 相同。
+func generate(nums ...int) <-chan int {
+	out := make(chan int)
+	go func() {
+		for _, n := range nums {
+			out <- n
+		}
+		close(out)
+	}()
+	return out
+}
+
+func collect[T any](in <-chan T) []T {
+	var out []T
+	for v := range in {
+		out = append(out, v)
+	}
+	return out
+}
+
+// collectAll 在各自的 goroutine 中读完每个输出。
+func collectAll[T any](outs []<-chan T) [][]T {
+	res := make([][]T, len(outs))
+	var wg sync.WaitGroup
+	for i, o := range outs {
+		wg.Add(1)
+		go func(i int, o <-chan T) {
+			defer wg.Done()
+			res[i] = collect(o)
+		}(i, o)
+	}
+	wg.Wait()
+	return res
+}
+
+func rangeInts(from, to int) []int {
+	var out []int
+	for i := from; i <= to; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+// increasing 报告 s 是否严格递增。
+func increasing(s []int) bool {
+	for i := 1; i < len(s); i++ {
+		if s[i] <= s[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+func main() {
+	merge()
+	distribute()
+	broadcast()
+	partition()
+	cancellation()
+}
+
+func merge() {
+	fmt.Println("=== 1. Merge：任意个输入 ===")
+	ctx := context.Background()
+	var ins []<-chan int
+	for k := 0; k < 5; k++ {
+		ins = append(ins, generate(rangeInts(k*100+1, k*100+20)...))
+	}
+	got := collect(fan.Merge(ctx, ins...))
+	perInput := make([][]int, 5)
+	for _, v := range got {
+		perInput[(v-1)/100] = append(perInput[(v-1)/100], v)
+	}
+	ordered := true
+	for _, s := range perInput {
+		ordered = ordered && len(s) == 20 && increasing(s)
+	}
+	fmt.Printf("  5 个输入共 %d 个值，每个输入内部保持顺序: %v\n", len(got), ordered)
+	fmt.Printf("  前 12 个值：%v\n", got[:12])
+	fmt.Println("  一个输入：原样转发", collect(fan.Merge(ctx, generate(1, 2, 3))))
+	fmt.Println("  没有输入：输出立即关闭", collect(fan.Merge[int](ctx)))
+	fmt.Println()
+}
+
+func distribute() {
+	fmt.Println("=== 2. Distribute：负载均衡 ===")
+	ctx := context.Background()
+	outs := fan.Distribute(ctx, generate(rangeInts(1, 60)...), 3)
+	res := make([][]int, len(outs))
+	var wg sync.WaitGroup
+	for i, o := range outs {
+		wg.Add(1)
+		go func(i int, o <-chan int) {
+			defer wg.Done()
+			for v := range o {
+				res[i] = append(res[i], v)
+				if i == 0 {
+					time.Sleep(2 * time.Millisecond) // 慢的 worker
+				}
+			}
+		}(i, o)
+	}
+	wg.Wait()
+	var all []int
+	for _, r := range res {
+		all = append(all, r...)
+	}
+	sort.Ints(all)
+	fmt.Println("  每个值恰好交给一个输出，没有重复也没有遗漏:", fmt.Sprint(all) == fmt.Sprint(rangeInts(1, 60)))
+	fmt.Printf("  慢的输出拿到的更少：%d / %d / %d（chap30 的 fanOut 就是这个行为，并不是广播）\n", len(res[0]), len(res[1]), len(res[2]))
+	fmt.Println()
+}
+
+func broadcast() {
+	fmt.Println("=== 3. Broadcast：复制给所有订阅者 ===")
+	ctx := context.Background()
+	subs := fan.Broadcast(ctx, generate(1, 2, 3, 4, 5),
+		fan.Sub{Policy: fan.Block}, fan.Sub{Buffer: 2, Policy: fan.Block}, fan.Sub{Buffer: 8, Policy: fan.DropNewest})
+	var outs []<-chan int
+	for _, s := range subs {
+		outs = append(outs, s.C)
+	}
+	fmt.Println("  消费者跟得上时每个订阅者都收到全部的值:", collectAll(outs))
+
+	// 慢消费者：输入全部发送完之后才开始读。send 返回时 Broadcast 已经收下这个值，
+	// 所以读的时候已经确定了缓冲里剩下哪些值
+	slow := func(policy fan.Policy) ([]int, uint64) {
+		in := make(chan int)
+		s := fan.Broadcast(ctx, in, fan.Sub{Buffer: 2, Policy: policy})[0]
+		for v := 1; v <= 5; v++ {
+			in <- v
+		}
+		close(in)
+		got := collect(s.C)
+		return got, s.Dropped()
+	}
+	got, dropped := slow(fan.DropNewest)
+	fmt.Printf("  %v（缓冲 2）：保留最早的 %v，丢弃 %d 个新值\n", fan.DropNewest, got, dropped)
+	got, dropped = slow(fan.DropOldest)
+	fmt.Printf("  %v（缓冲 2）：保留最近的 %v，丢弃 %d 个旧值\n", fan.DropOldest, got, dropped)
+
+	// Block：一个订阅者不读，其他订阅者也会停下
+	in := make(chan int)
+	subs = fan.Broadcast(ctx, in, fan.Sub{Buffer: 1, Policy: fan.Block}, fan.Sub{Buffer: 10, Policy: fan.DropNewest})
+	go func() {
+		for v := 1; v <= 4; v++ {
+			in <- v
+		}
+		close(in)
+	}()
+	var fast []int
+	timeout := time.After(50 * time.Millisecond)
+wait:
+	for {
+		select {
+		case v := <-subs[1].C:
+			fast = append(fast, v)
+		case <-timeout:
+			break wait
+		}
+	}
+	fmt.Printf("  %v 订阅者（缓冲 1）不读：1 留在它的缓冲里，广播停在第 2 个值上，快的订阅者只收到 %v\n", fan.Block, fast)
+	blocked := collect(subs[0].C)
+	fast = append(fast, collect(subs[1].C)...)
+	fmt.Printf("  它开始读之后广播继续，Block 不丢任何值：%v / %v（丢弃 %d）\n", blocked, fast, subs[0].Dropped())
+	fmt.Println()
+}
+
+type order struct {
+	user string
+	seq  int
+}
+
+func partition() {
+	fmt.Println("=== 4. Partition：按键路由 ===")
+	ctx := context.Background()
+	users := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+	in := make(chan order)
+	go func() {
+		for seq := 1; seq <= 10; seq++ {
+			for _, u := range users {
+				in <- order{u, seq}
+			}
+		}
+		close(in)
+	}()
+	const n = 3
+	res := collectAll(fan.Partition(ctx, in, n, func(o order) string { return o.user }))
+
+	var layout []string
+	for _, u := range users {
+		layout = append(layout, fmt.Sprintf("%s→%d", u, fan.PartitionOf(u, n)))
+	}
+	fmt.Printf("  同一个键总是到 PartitionOf(key, %d) 号输出：%s\n", n, strings.Join(layout, " "))
+	for p, orders := range res {
+		if len(orders) == 0 {
+			fmt.Printf("  输出 %d：没有订单\n", p)
+			continue
+		}
+		var seqs []string
+		for _, o := range orders {
+			if o.user == orders[0].user {
+				seqs = append(seqs, fmt.Sprint(o.seq))
+			}
+		}
+		fmt.Printf("  输出 %d：%d 个订单，%s 的顺序 %s\n", p, len(orders), orders[0].user, strings.Join(seqs, ","))
+	}
+	fmt.Println("  n < 1 时按 1 处理：PartitionOf(alice, 0) =", fan.PartitionOf("alice", 0))
+	fmt.Println()
+}
+
+func cancellation() {
+	fmt.Println("=== 5. 取消后关闭输出、没有泄漏 ===")
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 一个永远不会关闭的输入：没有取消的话每个函数内部的 goroutine 都会一直等
+	forever := make(chan int)
+	merged := fan.Merge(ctx, forever, forever)
+	dist := fan.Distribute(ctx, forever, 2)
+	subs := fan.Broadcast(ctx, forever, fan.Sub{Policy: fan.Block}, fan.Sub{Buffer: 1, Policy: fan.DropOldest})
+	parts := fan.Partition(ctx, forever, 2, func(int) string { return "" })
+
+	// Block 订阅者在 send 中阻塞时同样能退出
+	busy := make(chan int, 1)
+	busy <- 1
+	stuck := fan.Broadcast(ctx, busy, fan.Sub{Policy: fan.Block})
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	outs := append([]<-chan int{merged, subs[0].C, subs[1].C, stuck[0].C}, dist...)
+	outs = append(outs, parts...)
+	closed := 0
+	for _, o := range outs {
+		if len(collect(o)) == 0 {
+			closed++
+		}
+	}
+	fmt.Printf("  ctx 取消后 %d 个输出中有 %d 个关闭且没有值\n", len(outs), closed)
+	deadline := time.Now().Add(leaktest.Timeout)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	fmt.Printf("  goroutine 数回到 %d（开始时 %d）\n", runtime.NumGoroutine(), before)
+}
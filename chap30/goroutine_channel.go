@@ -342,6 +342,7 @@ func receiveOnly(ch <-chan int) {
 // ============================================
 
 // generate 生成数字
+// 这些阶段没有 context：消费者提前退出时 goroutine 会永远阻塞，可取消的泛型版本见 chap30/pipeline
 func generate(nums ...int) <-chan int {
 	out := make(chan int)
 	go func() {
@@ -382,6 +383,7 @@ func double(in <-chan int) <-chan int {
 // ============================================
 
 // fanOut 扇出：一个通道分发给多个通道
+// 每个值只发给恰好准备好的那个输出，是负载均衡而不是广播；通用版本见 chap30/fan 的 Distribute、Broadcast
 func fanOut(in <-chan int, out1, out2 chan<- int) {
 	defer close(out1)
 	defer close(out2)
@@ -395,6 +397,7 @@ func fanOut(in <-chan int, out1, out2 chan<- int) {
 }
 
 // fanIn 扇入：多个通道合并为一个
+// 只能合并两个通道；任意个数、可取消的版本见 chap30/fan 的 Merge
 func fanIn(input1, input2 <-chan int) <-chan int {
 	out := make(chan int)
 	
@@ -431,6 +434,7 @@ func fanIn(input1, input2 <-chan int) <-chan int {
 // ============================================
 
 // worker 工作池中的 worker
+// worker 数固定、不能取消任务、也没有错误：可调整大小、有界队列、能优雅关闭的泛型版本见 chap30/workerpool
 func worker(id int, jobs <-chan int, results chan<- int, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for job := range jobs {
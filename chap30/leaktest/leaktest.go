@@ -0,0 +1,66 @@
+// Package leaktest 检查 goroutine 泄漏，供测试使用。
+//
+// 用法：在启动并发代码之前调用 Check，取消或关闭之后调用它返回的函数：
+//
+//	done := leaktest.Check(t)
+//	... 启动流水线、读几个值、取消 ...
+//	done() // goroutine 数没有回到之前的值时报告泄漏，并输出仍在运行的 goroutine 的栈
+//
+// 整个测试都要检查时写成 defer leaktest.Check(t)()。
+//
+// 比较的是 runtime.NumGoroutine，同一进程中其他并发运行的测试会干扰结果，使用它的测试不要调用 t.Parallel。
+// TB 是 testing.TB 的子集，测试中直接传入 *testing.T，用法见 chap30/pipeline 的测试。
+package leaktest
+
+import (
+	"runtime"
+	"time"
+)
+
+// TB 是检查需要的 testing.TB 方法。
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Timeout 是 Check 返回的函数等待 goroutine 退出的默认时间。
+const Timeout = 2 * time.Second
+
+// Check 等价于 CheckTimeout(t, Timeout)。
+func Check(t TB) func() bool {
+	return CheckTimeout(t, Timeout)
+}
+
+// CheckTimeout 记录当前的 goroutine 数。返回的函数在 timeout 内反复检查，
+// 数量回落到记录的值或更少时返回 true；超时则通过 t.Errorf 报告，附带所有 goroutine 的栈，并返回 false。
+// 已经退出的 goroutine 可能还要过一会儿才从计数中消失，所以需要等待而不是只看一次。
+func CheckTimeout(t TB, timeout time.Duration) func() bool {
+	before := runtime.NumGoroutine()
+	return func() bool {
+		t.Helper()
+		deadline := time.Now().Add(timeout)
+		for {
+			now := runtime.NumGoroutine()
+			if now <= before {
+				return true
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("leaktest: %d goroutines before, %d after %v:\n%s", before, now, timeout, stacks())
+				return false
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+}
+
+// stacks 返回所有 goroutine 的栈。
+func stacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,59 @@
+package leaktest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder 记录失败而不是让测试失败，用来检查泄漏报告的内容。
+type recorder struct{ failures []string }
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// leakyGenerate 与 chap30 的 generate 相同，只是不会停：消费者走开后它阻塞在发送上，直到 stop 被关闭。
+func leakyGenerate(stop <-chan struct{}) <-chan int {
+	out := make(chan int)
+	go func() {
+		for i := 1; ; i++ {
+			select {
+			case out <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func TestCheckReportsLeak(t *testing.T) {
+	r := &recorder{}
+	done := CheckTimeout(r, 50*time.Millisecond)
+	stop := make(chan struct{})
+	defer close(stop)
+	nums := leakyGenerate(stop)
+	<-nums
+	<-nums
+	if done() || len(r.failures) != 1 {
+		t.Fatalf("leak not reported: %v", r.failures)
+	}
+	if !strings.Contains(r.failures[0], "leaktest.leakyGenerate") {
+		t.Errorf("report does not include the leaked goroutine's stack:\n%s", r.failures[0])
+	}
+}
+
+func TestCheckWaitsForExit(t *testing.T) {
+	r := &recorder{}
+	done := Check(r)
+	stop := make(chan struct{})
+	nums := leakyGenerate(stop)
+	<-nums
+	close(stop)
+	if !done() || len(r.failures) != 0 {
+		t.Errorf("goroutine that exits after cancellation reported as a leak: %v", r.failures)
+	}
+}
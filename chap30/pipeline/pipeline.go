@@ -0,0 +1,99 @@
+// Package pipeline 是第30章 generate → square → double 流水线的泛型、可取消版本。
+//
+// chap30 的 generate、square、double 只能处理 int，也不接收 context：消费者不再读取时，
+// 上游的 goroutine 永远阻塞在发送上，造成泄漏。这里的每个阶段都接收 ctx，
+// ctx 被取消时退出并关闭自己的输出通道，下游的 range 随之结束：
+//
+//	p, ctx := pipeline.New(context.Background())
+//	nums := pipeline.Source(ctx, 1, 2, 3, 4, 5)
+//	squares := pipeline.Map(ctx, nums, func(_ context.Context, n int) (int, error) { return n * n, nil })
+//	for v := range pipeline.Map(ctx, squares, double) { ... }
+//	err := p.Wait() // 第一个阶段的错误；提前退出时先调用 p.Stop()
+//
+// 阶段函数返回的第一个错误（panic 通过 chap28/safe 转换成 *safe.PanicError）会取消整条流水线，
+// 之后由 Wait 返回，context.Cause(ctx) 也是它。阶段必须使用 New 返回的 ctx 或从它派生的 context。
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	"books/chap28/safe"
+)
+
+// ErrStopped 是调用 Stop 后 context.Cause(ctx) 返回的错误；Wait 不把它当作失败。
+var ErrStopped = errors.New("pipeline: stopped")
+
+// Pipeline 跟踪所有阶段的 goroutine 和第一个错误。
+type Pipeline struct {
+	g    *safe.Group
+	ctx  context.Context // 只因 parent 或 Stop 而取消，用于区分取消的原因
+	stop context.CancelCauseFunc
+}
+
+type pipelineKey struct{}
+
+// New 返回新的流水线和传给各个阶段的 context。
+// 任何阶段出错、调用 Stop 或 parent 被取消时，这个 context 被取消，所有阶段随之退出。
+func New(parent context.Context) (*Pipeline, context.Context) {
+	ctx, stop := context.WithCancelCause(parent)
+	g, gctx := safe.GroupWithRecover(ctx)
+	p := &Pipeline{g: g, ctx: ctx, stop: stop}
+	return p, context.WithValue(gctx, pipelineKey{}, p)
+}
+
+// from 取出 ctx 所属的流水线。
+func from(ctx context.Context) *Pipeline {
+	p, ok := ctx.Value(pipelineKey{}).(*Pipeline)
+	if !ok {
+		panic("pipeline: context was not created by pipeline.New")
+	}
+	return p
+}
+
+// Stop 取消整条流水线，用于消费者提前退出：所有阶段关闭输出并结束，Wait 返回 nil。
+func (p *Pipeline) Stop() {
+	p.stop(ErrStopped)
+}
+
+// Wait 等待所有阶段的 goroutine 结束，返回第一个阶段错误；
+// 没有阶段出错但 parent 被取消时返回 context.Cause(parent)；正常结束或调用过 Stop 时返回 nil。
+func (p *Pipeline) Wait() error {
+	err := p.g.Wait()
+	if err == nil && p.ctx.Err() != nil {
+		if cause := context.Cause(p.ctx); !errors.Is(cause, ErrStopped) {
+			err = cause
+		}
+	}
+	p.stop(ErrStopped) // 释放 context 的资源
+	return err
+}
+
+// goStage 在流水线中运行一个阶段，f 返回后关闭 out（包括 panic 时）。
+func goStage[T any](ctx context.Context, out chan T, f func() error) {
+	from(ctx).g.Go(func() error {
+		defer close(out)
+		return f()
+	})
+}
+
+// send 把 v 发送到 out；ctx 先被取消时返回 false。
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recv 从 in 接收一个值；in 已关闭或 ctx 被取消时返回 false。
+func recv[T any](ctx context.Context, in <-chan T) (T, bool) {
+	select {
+	case v, ok := <-in:
+		return v, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
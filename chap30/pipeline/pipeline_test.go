@@ -0,0 +1,262 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"books/chap28/safe"
+	"books/chap30/leaktest"
+)
+
+func collect[T any](in <-chan T) []T {
+	var out []T
+	for v := range in {
+		out = append(out, v)
+	}
+	return out
+}
+
+// ---- chap30 的阶段写成 pipeline 的阶段函数 ----
+
+func square(_ context.Context, n int) (int, error) { return n * n, nil }
+func double(_ context.Context, n int) (int, error) { return n * 2, nil }
+
+// naturals 是无限的数据源。
+func naturals(ctx context.Context, emit func(int) bool) error {
+	for i := 1; ; i++ {
+		if !emit(i) {
+			return nil
+		}
+	}
+}
+
+var errBadInput = errors.New("bad input")
+
+func TestSquareDouble(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	nums := Source(ctx, 1, 2, 3, 4, 5)
+	got := collect(Map(ctx, Map(ctx, nums, square), double))
+	if err := p.Wait(); err != nil || fmt.Sprint(got) != "[2 8 18 32 50]" {
+		t.Errorf("generate → square → double = %v, %v", got, err)
+	}
+
+	p, ctx = New(context.Background())
+	words := Source(ctx, "goroutine", "channel", "select")
+	lens := collect(Map(ctx, words, func(_ context.Context, s string) (string, error) {
+		return fmt.Sprintf("%s=%d", s, len(s)), nil
+	}))
+	if err := p.Wait(); err != nil || strings.Join(lens, " ") != "goroutine=9 channel=7 select=6" {
+		t.Errorf("string stage = %v, %v", lens, err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	src := Generate(ctx, naturals)
+	odd := Filter(ctx, src, func(_ context.Context, n int) (bool, error) { return n%2 == 1, nil })
+	sq := OrderedParallelMap(ctx, odd, 4, square)
+	batches := Batch(ctx, sq, 3, 0)
+	tees := Tee(ctx, batches, 2)
+	all := Merge(ctx, tees...)
+	first := <-all
+	<-all
+	p.Stop()
+	rest := collect(all) // 取消后输出被关闭，range 结束
+	if err := p.Wait(); err != nil {
+		t.Errorf("Wait after Stop = %v", err)
+	}
+	if fmt.Sprint(first) != "[1 9 25]" {
+		t.Errorf("first batch = %v", first)
+	}
+	if len(rest) > 2 {
+		t.Errorf("%d batches after Stop", len(rest))
+	}
+}
+
+func TestParentCanceled(t *testing.T) {
+	defer leaktest.Check(t)()
+	parent, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	p, ctx := New(parent)
+	n := len(collect(Map(ctx, Generate(ctx, naturals), double)))
+	if err := p.Wait(); !errors.Is(err, context.DeadlineExceeded) || n == 0 {
+		t.Errorf("parent timeout: %d values, Wait = %v", n, err)
+	}
+}
+
+func TestErrorPropagates(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	parsed := Map(ctx, Generate(ctx, naturals), func(_ context.Context, n int) (int, error) {
+		if n == 4 {
+			return 0, fmt.Errorf("parse item %d: %w", n, errBadInput)
+		}
+		return n, nil
+	})
+	got := collect(Map(ctx, parsed, double))
+	err := p.Wait()
+	if !errors.Is(err, errBadInput) {
+		t.Fatalf("Wait = %v", err)
+	}
+	// 出错时正在途中的值可能送达也可能被丢弃，但不会有出错之后的值
+	if s := fmt.Sprint(got); len(got) > 3 || !strings.HasPrefix("[2 4 6]", strings.TrimSuffix(s, "]")) {
+		t.Errorf("downstream received %s", s)
+	}
+	if !errors.Is(context.Cause(ctx), errBadInput) {
+		t.Errorf("context.Cause = %v", context.Cause(ctx))
+	}
+
+	p, ctx = New(context.Background())
+	collect(OrderedParallelMap(ctx, Source(ctx, 1, 2, 3, 4, 5, 6), 3, func(_ context.Context, n int) (int, error) {
+		if n == 5 {
+			return 0, errBadInput
+		}
+		return n, nil
+	}))
+	if err := p.Wait(); !errors.Is(err, errBadInput) {
+		t.Errorf("worker error: Wait = %v", err)
+	}
+}
+
+func TestPanicInStage(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	var m map[int]bool
+	kept := collect(Filter(ctx, Source(ctx, 1, 2, 3), func(_ context.Context, n int) (bool, error) {
+		if n == 2 {
+			m[n] = true // nil map：panic
+		}
+		return true, nil
+	}))
+	err := p.Wait()
+	var pe *safe.PanicError
+	if !errors.As(err, &pe) || fmt.Sprint(kept) != "[1]" {
+		t.Errorf("panic in Filter: kept %v, Wait = %v", kept, err)
+	}
+}
+
+func TestForeignContextPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Source with a context not created by New did not panic")
+		}
+	}()
+	Source(context.Background(), 1)
+}
+
+func TestFilterBatch(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	evens := collect(Filter(ctx, Source(ctx, 1, 2, 3, 4, 5, 6, 7), func(_ context.Context, n int) (bool, error) {
+		return n%2 == 0, nil
+	}))
+	if fmt.Sprint(evens) != "[2 4 6]" {
+		t.Errorf("Filter = %v", evens)
+	}
+
+	for _, tc := range []struct {
+		size int
+		want string
+	}{
+		{3, "[[1 2 3] [4 5 6] [7]]"},
+		{0, "[[1] [2] [3] [4] [5] [6] [7]]"},
+	} {
+		batches := collect(Batch(ctx, Source(ctx, 1, 2, 3, 4, 5, 6, 7), tc.size, 0))
+		if fmt.Sprint(batches) != tc.want {
+			t.Errorf("Batch(size %d) = %v, want %s", tc.size, batches, tc.want)
+		}
+	}
+
+	slow := Generate(ctx, func(ctx context.Context, emit func(int) bool) error {
+		emit(1)
+		emit(2)
+		time.Sleep(60 * time.Millisecond)
+		emit(3)
+		return nil
+	})
+	if batches := collect(Batch(ctx, slow, 10, 20*time.Millisecond)); fmt.Sprint(batches) != "[[1 2] [3]]" {
+		t.Errorf("Batch with maxWait = %v", batches)
+	}
+	if err := p.Wait(); err != nil {
+		t.Errorf("Wait = %v", err)
+	}
+}
+
+func TestTeeMerge(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	outs := Tee(ctx, Source(ctx, "a", "b", "c"), 3)
+	copies := make([][]string, len(outs))
+	var wg sync.WaitGroup
+	for i, o := range outs {
+		wg.Add(1)
+		go func(i int, o <-chan string) {
+			defer wg.Done()
+			if i == 0 {
+				time.Sleep(10 * time.Millisecond) // 慢的消费者
+			}
+			copies[i] = collect(o)
+		}(i, o)
+	}
+	wg.Wait()
+	if want := [][]string{{"a", "b", "c"}, {"a", "b", "c"}, {"a", "b", "c"}}; !reflect.DeepEqual(copies, want) {
+		t.Errorf("Tee = %v", copies)
+	}
+
+	merged := collect(Merge(ctx,
+		Source(ctx, 1, 3, 5),
+		Source(ctx, 2, 4, 6),
+		Source(ctx, 10, 20, 30, 40)))
+	sum := 0
+	last := map[int]int{}
+	for _, v := range merged {
+		sum += v
+		group := v % 2
+		if v >= 10 {
+			group = 2
+		}
+		if v < last[group] {
+			t.Errorf("Merge reordered input %d: %v", group, merged)
+		}
+		last[group] = v
+	}
+	if len(merged) != 10 || sum != 121 {
+		t.Errorf("Merge = %v", merged)
+	}
+	if n := len(collect(Merge[int](ctx))); n != 0 {
+		t.Errorf("Merge without inputs sent %d values", n)
+	}
+	if err := p.Wait(); err != nil {
+		t.Errorf("Wait = %v", err)
+	}
+}
+
+func TestOrderedParallelMap(t *testing.T) {
+	defer leaktest.Check(t)()
+	p, ctx := New(context.Background())
+	var running, peak atomic.Int32
+	slowSquare := func(_ context.Context, n int) (int, error) {
+		cur := running.Add(1)
+		for old := peak.Load(); cur > old && !peak.CompareAndSwap(old, cur); old = peak.Load() {
+		}
+		time.Sleep(time.Duration(12-n) * 3 * time.Millisecond) // 前面的值反而更慢
+		running.Add(-1)
+		return n * n, nil
+	}
+	got := collect(OrderedParallelMap(ctx, Source(ctx, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11), 4, slowSquare))
+	if err := p.Wait(); err != nil || fmt.Sprint(got) != "[1 4 9 16 25 36 49 64 81 100 121]" {
+		t.Errorf("OrderedParallelMap = %v, %v", got, err)
+	}
+	if peak.Load() < 2 || peak.Load() > 4 {
+		t.Errorf("peak concurrency = %d, want 2..4", peak.Load())
+	}
+}
@@ -0,0 +1,244 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Source 依次发送 items，发送完后关闭输出（对应 chap30 的 generate）。
+func Source[T any](ctx context.Context, items ...T) <-chan T {
+	out := make(chan T)
+	goStage(ctx, out, func() error {
+		for _, v := range items {
+			if !send(ctx, out, v) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Generate 用 fn 产生数据：fn 调用 emit 发送一个值，emit 返回 false 表示流水线已取消，fn 应当立即返回。
+// fn 返回的错误使整条流水线失败。适合无限的或来自外部的数据源。
+func Generate[T any](ctx context.Context, fn func(ctx context.Context, emit func(T) bool) error) <-chan T {
+	out := make(chan T)
+	goStage(ctx, out, func() error {
+		return fn(ctx, func(v T) bool { return send(ctx, out, v) })
+	})
+	return out
+}
+
+// Map 对每个值调用 fn 并发送结果，顺序不变（对应 chap30 的 square、double）。
+// fn 返回错误时整条流水线失败。
+func Map[In, Out any](ctx context.Context, in <-chan In, fn func(context.Context, In) (Out, error)) <-chan Out {
+	out := make(chan Out)
+	goStage(ctx, out, func() error {
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return nil
+			}
+			r, err := fn(ctx, v)
+			if err != nil {
+				return err
+			}
+			if !send(ctx, out, r) {
+				return nil
+			}
+		}
+	})
+	return out
+}
+
+// Filter 只发送 keep 返回 true 的值。keep 返回错误时整条流水线失败。
+func Filter[T any](ctx context.Context, in <-chan T, keep func(context.Context, T) (bool, error)) <-chan T {
+	out := make(chan T)
+	goStage(ctx, out, func() error {
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return nil
+			}
+			k, err := keep(ctx, v)
+			if err != nil {
+				return err
+			}
+			if k && !send(ctx, out, v) {
+				return nil
+			}
+		}
+	})
+	return out
+}
+
+// Batch 把值按顺序攒成最多 size 个一组发送。maxWait > 0 时，一组中第一个值到达后最多等待 maxWait，
+// 时间到了即使不满也发送；输入关闭时发送剩下的不满的一组。size < 1 时按 1 处理。
+// ctx 被取消时丢弃还没发送的一组。
+func Batch[T any](ctx context.Context, in <-chan T, size int, maxWait time.Duration) <-chan []T {
+	size = max(size, 1)
+	out := make(chan []T)
+	goStage(ctx, out, func() error {
+		var batch []T
+		var timer *time.Timer
+		var expired <-chan time.Time // 没有待发送的组或不限时时为 nil，select 不会选中
+		flush := func() bool {
+			if timer != nil && expired != nil {
+				// go.mod 为 go 1.21，沿用旧的 Timer 语义：Stop 失败时要取走已经到期的值，Reset 后才不会误触发
+				if !timer.Stop() {
+					<-timer.C
+				}
+				expired = nil
+			}
+			b := batch
+			batch = nil
+			return send(ctx, out, b)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-expired:
+				expired = nil // 值已经取走
+				if !flush() {
+					return nil
+				}
+			case v, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+					return nil
+				}
+				if batch == nil {
+					batch = make([]T, 0, size)
+					if maxWait > 0 {
+						if timer == nil {
+							timer = time.NewTimer(maxWait)
+						} else {
+							timer.Reset(maxWait)
+						}
+						expired = timer.C
+					}
+				}
+				batch = append(batch, v)
+				if len(batch) == size && !flush() {
+					return nil
+				}
+			}
+		}
+	})
+	return out
+}
+
+// Tee 把每个值复制发送到 n 个输出，全部输出都收下一个值之后才读取下一个值，
+// 所以最慢的消费者决定整体速度；各个输出的接收顺序不限，不会因为先读哪个输出而死锁。
+// 需要为慢消费者缓冲或丢弃时使用 chap30/fan 的 Broadcast。
+func Tee[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+	from(ctx).g.Go(func() error {
+		defer func() {
+			for _, o := range outs {
+				close(o)
+			}
+		}()
+		cases := make([]reflect.SelectCase, n+1)
+		cases[n] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return nil
+			}
+			rv := reflect.ValueOf(&v).Elem()
+			for i, o := range outs {
+				cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(o), Send: rv}
+			}
+			for remaining := n; remaining > 0; remaining-- {
+				chosen, _, _ := reflect.Select(cases)
+				if chosen == n {
+					return nil
+				}
+				cases[chosen].Chan = reflect.Value{} // 已经发送过，零值 Chan 的 case 不会再被选中
+			}
+		}
+	})
+	return result
+}
+
+// Merge 把多个输入合并成一个输出，全部输入关闭后关闭输出（对应 chap30 的 fanIn，但不限于两个）。
+// 不同输入之间的顺序不确定，同一个输入的值保持顺序。
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	goStage(ctx, out, func() error {
+		var wg sync.WaitGroup
+		for _, in := range ins {
+			wg.Add(1)
+			go func(in <-chan T) {
+				defer wg.Done()
+				for {
+					v, ok := recv(ctx, in)
+					if !ok || !send(ctx, out, v) {
+						return
+					}
+				}
+			}(in)
+		}
+		wg.Wait()
+		return nil
+	})
+	return out
+}
+
+// OrderedParallelMap 并发调用 fn，同时运行的 fn 最多 workers 个，但按输入的顺序发送结果。
+// 已经算完、排队等待发送的结果也有上限，一个慢的值会让后面的值等待它。
+// fn 返回错误或 panic 时整条流水线失败。workers < 1 时按 1 处理。
+func OrderedParallelMap[In, Out any](ctx context.Context, in <-chan In, workers int, fn func(context.Context, In) (Out, error)) <-chan Out {
+	workers = max(workers, 1)
+	p := from(ctx)
+	// pending 按输入顺序排队，每个槽位在结果算好后收到一个值；sem 限制同时运行的 fn
+	pending := make(chan chan Out, workers)
+	sem := make(chan struct{}, workers)
+	goStage(ctx, pending, func() error {
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return nil
+			}
+			slot := make(chan Out, 1) // 有缓冲：即使已经没有人等待结果，worker 也不会阻塞
+			if !send(ctx, pending, slot) || !send(ctx, sem, struct{}{}) {
+				return nil
+			}
+			p.g.Go(func() error {
+				defer func() { <-sem }()
+				r, err := fn(ctx, v)
+				if err != nil {
+					return err
+				}
+				slot <- r
+				return nil
+			})
+		}
+	})
+
+	out := make(chan Out)
+	goStage(ctx, out, func() error {
+		for {
+			slot, ok := recv(ctx, pending)
+			if !ok {
+				return nil
+			}
+			r, ok := recv(ctx, slot)
+			if !ok || !send(ctx, out, r) {
+				return nil
+			}
+		}
+	})
+	return out
+}
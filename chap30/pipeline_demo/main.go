@@ -0,0 +1,233 @@
+// 独立运行：go run ./chap30/pipeline_demo
+// 演示：用 pipeline 重写 chap30 的 generate → square → double，各阶段的语义、
+// 错误在流水线中的传播，以及取消后 runtime.NumGoroutine 回到原来的值（没有泄漏）。断言见 go test ./chap30/pipeline。
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"books/chap28/safe"
+	"books/chap30/leaktest"
+	"books/chap30/pipeline"
+)
+
+// goroutines 等已经退出的 goroutine 从计数中消失后返回当前的 goroutine 数，最多等待 wait。
+func goroutines(want int, wait time.Duration) int {
+	deadline := time.Now().Add(wait)
+	for runtime.NumGoroutine() > want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return runtime.NumGoroutine()
+}
+
+func collect[T any](in <-chan T) []T {
+	var out []T
+	for v := range in {
+		out = append(out, v)
+	}
+	return out
+}
+
+// ---- chap30 的阶段写成 pipeline 的阶段函数 ----
+
+func square(_ context.Context, n int) (int, error) { return n * n, nil }
+func double(_ context.Context, n int) (int, error) { return n * 2, nil }
+
+// naturals 是无限的数据源。
+func naturals(ctx context.Context, emit func(int) bool) error {
+	for i := 1; ; i++ {
+		if !emit(i) {
+			return nil
+		}
+	}
+}
+
+// leakyGenerate 与 chap30 的 generate 相同，只是不会停：没有 context，消费者走开后它永远阻塞在发送上。
+func leakyGenerate() <-chan int {
+	out := make(chan int)
+	go func() {
+		for i := 1; ; i++ {
+			out <- i
+		}
+	}()
+	return out
+}
+
+func main() {
+	basic()
+	leaks()
+	errorsPropagate()
+	stages()
+	ordered()
+}
+
+func basic() {
+	fmt.Println("=== 1. generate → square → double ===")
+	p, ctx := pipeline.New(context.Background())
+	nums := pipeline.Source(ctx, 1, 2, 3, 4, 5)
+	got := collect(pipeline.Map(ctx, pipeline.Map(ctx, nums, square), double))
+	fmt.Println("  结果与 chap30 相同:", got)
+	fmt.Println("  Wait:", p.Wait())
+
+	p, ctx = pipeline.New(context.Background())
+	words := pipeline.Source(ctx, "goroutine", "channel", "select")
+	lens := collect(pipeline.Map(ctx, words, func(_ context.Context, s string) (string, error) {
+		return fmt.Sprintf("%s=%d", s, len(s)), nil
+	}))
+	fmt.Printf("  泛型 string → string：%s，Wait: %v\n", strings.Join(lens, " "), p.Wait())
+	fmt.Println()
+}
+
+func leaks() {
+	fmt.Println("=== 2. 消费者提前退出 ===")
+	before := runtime.NumGoroutine()
+	old := leakyGenerate()
+	<-old
+	<-old
+	fmt.Printf("  chap30 式的 generate：读两个值后走开，goroutine 从 %d 个变成 %d 个，泄漏的那个永远阻塞在发送上\n",
+		before, goroutines(before, 100*time.Millisecond))
+
+	before = runtime.NumGoroutine()
+	p, ctx := pipeline.New(context.Background())
+	src := pipeline.Generate(ctx, naturals)
+	odd := pipeline.Filter(ctx, src, func(_ context.Context, n int) (bool, error) { return n%2 == 1, nil })
+	sq := pipeline.OrderedParallelMap(ctx, odd, 4, square)
+	batches := pipeline.Batch(ctx, sq, 3, 0)
+	tees := pipeline.Tee(ctx, batches, 2)
+	all := pipeline.Merge(ctx, tees...)
+	first := [][]int{<-all, <-all}
+	p.Stop()
+	rest := collect(all) // 取消后输出被关闭，range 结束
+	fmt.Printf("  无限的 Generate → Filter → OrderedParallelMap → Batch → Tee → Merge：读到 %v 后 Stop，Wait: %v\n", first[0], p.Wait())
+	fmt.Printf("  Stop 之后所有输出都关闭了（剩余 %d 组），goroutine 数回到 %d（开始时 %d）\n",
+		len(rest), goroutines(before, leaktest.Timeout), before)
+
+	// parent 被取消时同样退出
+	before = runtime.NumGoroutine()
+	parent, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	p, ctx = pipeline.New(parent)
+	n := len(collect(pipeline.Map(ctx, pipeline.Generate(ctx, naturals), double)))
+	fmt.Printf("  parent 超时：读到 %d 个值后结束，Wait: %v\n", n, p.Wait())
+	fmt.Printf("  超时后 goroutine 数回到 %d（开始时 %d）\n", goroutines(before, leaktest.Timeout), before)
+	fmt.Println()
+}
+
+var errBadInput = errors.New("bad input")
+
+func errorsPropagate() {
+	fmt.Println("=== 3. 错误的传播 ===")
+	before := runtime.NumGoroutine()
+	p, ctx := pipeline.New(context.Background())
+	parsed := pipeline.Map(ctx, pipeline.Generate(ctx, naturals), func(_ context.Context, n int) (int, error) {
+		if n == 4 {
+			return 0, fmt.Errorf("parse item %d: %w", n, errBadInput)
+		}
+		return n, nil
+	})
+	got := collect(pipeline.Map(ctx, parsed, double))
+	err := p.Wait()
+	// 出错时正在途中的值可能送达也可能被丢弃，但不会有出错之后的值
+	fmt.Printf("  中间阶段出错：下游收到 %v 后输出关闭，Wait: %v\n", got, err)
+	fmt.Println("  context.Cause(ctx) 也是这个错误，上游的无限数据源随之停止:", context.Cause(ctx))
+	fmt.Printf("  出错后 goroutine 数回到 %d（开始时 %d）\n", goroutines(before, leaktest.Timeout), before)
+
+	p, ctx = pipeline.New(context.Background())
+	var m map[int]bool
+	kept := collect(pipeline.Filter(ctx, pipeline.Source(ctx, 1, 2, 3), func(_ context.Context, n int) (bool, error) {
+		if n == 2 {
+			m[n] = true // nil map：panic
+		}
+		return true, nil
+	}))
+	err = p.Wait()
+	var pe *safe.PanicError
+	fmt.Printf("  阶段函数 panic：收到 %v 后转换成 *safe.PanicError（%v），不会让进程崩溃：%v\n", kept, errors.As(err, &pe), err)
+
+	p, ctx = pipeline.New(context.Background())
+	collect(pipeline.OrderedParallelMap(ctx, pipeline.Source(ctx, 1, 2, 3, 4, 5, 6), 3, func(_ context.Context, n int) (int, error) {
+		if n == 5 {
+			return 0, errBadInput
+		}
+		return n, nil
+	}))
+	fmt.Println("  并发的 worker 出错同样使流水线失败:", p.Wait())
+	fmt.Println()
+}
+
+func stages() {
+	fmt.Println("=== 4. Filter、Batch、Tee、Merge ===")
+	p, ctx := pipeline.New(context.Background())
+	evens := collect(pipeline.Filter(ctx, pipeline.Source(ctx, 1, 2, 3, 4, 5, 6, 7), func(_ context.Context, n int) (bool, error) {
+		return n%2 == 0, nil
+	}))
+	fmt.Println("  Filter:", evens)
+
+	batches := collect(pipeline.Batch(ctx, pipeline.Source(ctx, 1, 2, 3, 4, 5, 6, 7), 3, 0))
+	fmt.Println("  Batch 按大小分组，输入关闭时发送不满的一组:", batches)
+
+	slow := pipeline.Generate(ctx, func(ctx context.Context, emit func(int) bool) error {
+		emit(1)
+		emit(2)
+		time.Sleep(60 * time.Millisecond)
+		emit(3)
+		return nil
+	})
+	batches = collect(pipeline.Batch(ctx, slow, 10, 20*time.Millisecond))
+	fmt.Println("  Batch 的 maxWait：不满也在 20ms 后发送:", batches)
+
+	outs := pipeline.Tee(ctx, pipeline.Source(ctx, "a", "b", "c"), 3)
+	copies := make([][]string, len(outs))
+	var wg sync.WaitGroup
+	for i, o := range outs {
+		wg.Add(1)
+		go func(i int, o <-chan string) {
+			defer wg.Done()
+			if i == 0 {
+				time.Sleep(10 * time.Millisecond) // 慢的消费者
+			}
+			copies[i] = collect(o)
+		}(i, o)
+	}
+	wg.Wait()
+	fmt.Println("  Tee：每个输出都收到全部的值，第一个消费者较慢:", copies)
+
+	merged := collect(pipeline.Merge(ctx,
+		pipeline.Source(ctx, 1, 3, 5),
+		pipeline.Source(ctx, 2, 4, 6),
+		pipeline.Source(ctx, 10, 20, 30, 40)))
+	fmt.Printf("  Merge 三个输入：%d 个值，同一输入内顺序不变：%v\n", len(merged), merged)
+	fmt.Println("  Merge 没有输入时立即关闭:", collect(pipeline.Merge[int](ctx)))
+	fmt.Println("  Wait:", p.Wait())
+	fmt.Println()
+}
+
+func ordered() {
+	fmt.Println("=== 5. OrderedParallelMap ===")
+	p, ctx := pipeline.New(context.Background())
+	var running, peak atomic.Int32
+	slowSquare := func(_ context.Context, n int) (int, error) {
+		cur := running.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(12-n) * 3 * time.Millisecond) // 前面的值反而更慢
+		running.Add(-1)
+		return n * n, nil
+	}
+	start := time.Now()
+	got := collect(pipeline.OrderedParallelMap(ctx, pipeline.Source(ctx, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11), 4, slowSquare))
+	elapsed := time.Since(start)
+	fmt.Printf("  结果按输入顺序：%v，Wait: %v\n", got, p.Wait())
+	fmt.Printf("  同时运行的 fn 最多 4 个（峰值 %d），用时 %v，顺序执行约需 198ms\n", peak.Load(), elapsed.Round(time.Millisecond))
+}
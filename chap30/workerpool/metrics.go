@@ -0,0 +1,127 @@
+package workerpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultBuckets 是运行时间直方图默认的桶上界。
+var DefaultBuckets = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+}
+
+// Metrics 是工作池某一时刻的快照。
+type Metrics struct {
+	Workers   int    // Resize 设置的 worker 数
+	Queued    int    // 正在排队的任务数
+	Running   int    // 正在运行的任务数
+	Completed uint64 // 成功完成的任务数
+	Failed    uint64 // 返回错误、panic、超时或被取消的任务数
+	Latency   Histogram
+}
+
+// Histogram 是任务运行时间的直方图（只统计实际运行过的任务）。
+type Histogram struct {
+	Bounds []time.Duration // 桶的上界（含）
+	Counts []uint64        // len(Bounds)+1 个桶，最后一个是超过最大上界的
+	Count  uint64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+// Mean 返回平均运行时间。
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile 返回第 q（0..1）分位数所在的桶的上界；落在最后一个桶时返回 Max。
+func (h Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.Count) + 0.5)
+	rank = min(max(rank, 1), h.Count)
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen >= rank {
+			if i < len(h.Bounds) {
+				return h.Bounds[i]
+			}
+			break
+		}
+	}
+	return h.Max
+}
+
+// String 每个非空的桶输出一行：上界、个数和条形图。
+func (h Histogram) String() string {
+	var b strings.Builder
+	for i, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		label := "+Inf"
+		if i < len(h.Bounds) {
+			label = "≤" + h.Bounds[i].String()
+		}
+		fmt.Fprintf(&b, "%8s %4d %s\n", label, c, strings.Repeat("█", int((c*30+h.Count-1)/h.Count)))
+	}
+	return b.String()
+}
+
+// counters 是在 Pool.mu 保护下更新的统计。
+type counters struct {
+	completed, failed uint64
+	hist              Histogram
+}
+
+func newCounters(bounds []time.Duration) counters {
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	return counters{hist: Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}}
+}
+
+// record 统计一个任务的结果；ran 为 false 表示任务没有运行，不计入直方图。
+// 不能用 run == 0 判断：计时器精度较粗时，运行过的任务也可能测得 0。
+func (c *counters) record(err error, run time.Duration, ran bool) {
+	if err != nil {
+		c.failed++
+	} else {
+		c.completed++
+	}
+	if !ran {
+		return
+	}
+	h := &c.hist
+	i := sort.Search(len(h.Bounds), func(i int) bool { return run <= h.Bounds[i] })
+	h.Counts[i]++
+	h.Count++
+	h.Sum += run
+	h.Max = max(h.Max, run)
+}
+
+// Metrics 返回当前的统计快照。
+func (p *Pool[In, Out]) Metrics() Metrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.stats.hist
+	h.Bounds = append([]time.Duration(nil), h.Bounds...)
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return Metrics{
+		Workers:   p.target,
+		Queued:    len(p.queue),
+		Running:   p.running,
+		Completed: p.stats.completed,
+		Failed:    p.stats.failed,
+		Latency:   h,
+	}
+}
@@ -0,0 +1,299 @@
+// Package workerpool 是第30章工作池模式的完整版本。
+//
+// chap30 的 worker(id, jobs, results, wg) 固定 3 个，用 time.Sleep 模拟处理后返回平方：
+// 不能调整 worker 数，jobs 通道满了只能阻塞，无法取消单个任务，也收集不到错误。这里的 Pool：
+//
+//	p := workerpool.New(square, workerpool.Options{Workers: 3, QueueSize: 10, Timeout: time.Second})
+//	go func() { for r := range p.Results() { ... } }() // 必须一直读到关闭
+//	id, err := p.Submit(ctx, 7)                          // 队列满时等待（背压）；TrySubmit 返回 ErrQueueFull
+//	p.Resize(8)
+//	err = p.Shutdown(ctx)                                // 处理完已提交的任务；ctx 到期时中止
+//
+// 每个任务都有自己的 context：Submit 传入的 ctx、Options.Timeout 和 Shutdown 的中止都会取消它。
+// 任务函数的 panic 被转换成 *safe.PanicError 作为这个任务的错误，worker 继续处理下一个任务。
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"books/chap28/safe"
+)
+
+var (
+	// ErrQueueFull 表示 TrySubmit 时等待队列已满。
+	ErrQueueFull = errors.New("workerpool: queue is full")
+	// ErrClosed 表示已经调用过 Shutdown，不再接受任务。
+	ErrClosed = errors.New("workerpool: pool is shut down")
+	// ErrAborted 是 Shutdown 的 ctx 到期后，被中止的任务的 context.Cause 和结果中的错误。
+	ErrAborted = errors.New("workerpool: job aborted by shutdown")
+)
+
+// Options 是 Pool 的设置。
+type Options struct {
+	Workers   int           // 初始 worker 数，小于 1 时为 1
+	QueueSize int           // 等待队列的容量（不含正在运行的任务），小于 1 时为 1
+	Timeout   time.Duration // 每个任务从开始运行算起的超时，0 表示不限
+	Ordered   bool          // 为 true 时按提交顺序输出结果，先完成的结果等待前面的任务
+	// LatencyBuckets 是运行时间直方图的桶上界，按从小到大排列；为 nil 时使用 DefaultBuckets。
+	LatencyBuckets []time.Duration
+}
+
+// Result 是一个任务的结果。每个成功提交的任务恰好产生一个 Result。
+type Result[In, Out any] struct {
+	ID   uint64 // Submit 返回的编号，从 1 开始按提交顺序递增
+	In   In
+	Out  Out
+	Err  error         // 任务函数的错误、*safe.PanicError，或者任务 context 被取消的原因
+	Wait time.Duration // 在队列中等待的时间
+	Run  time.Duration // 运行时间；任务没有运行（开始前已被取消）时为 0
+}
+
+type job[In any] struct {
+	id        uint64
+	ctx       context.Context
+	in        In
+	submitted time.Time
+}
+
+// Pool 是固定输入、输出类型的工作池。用 New 创建。
+type Pool[In, Out any] struct {
+	fn   func(context.Context, In) (Out, error)
+	opts Options
+
+	slots  chan struct{} // 队列中的空位，容量为 QueueSize；Submit 取一个，worker 取出任务时放回
+	closed chan struct{} // Shutdown 时关闭，唤醒等待空位的 Submit
+
+	mu      sync.Mutex
+	cond    *sync.Cond // 有新任务、Resize 或 Shutdown 时唤醒空闲的 worker
+	queue   []job[In]
+	nextID  uint64
+	target  int // Resize 设置的 worker 数
+	live    int // 正在运行的 worker goroutine 数
+	running int // 正在执行的任务数
+	closing bool
+	stats   counters
+
+	abortCtx context.Context
+	abort    context.CancelCauseFunc
+
+	workers  sync.WaitGroup
+	done     chan Result[In, Out] // worker → 输出 goroutine
+	results  chan Result[In, Out]
+	finished chan struct{} // results 关闭后关闭
+}
+
+// New 创建工作池并启动 opts.Workers 个 worker，fn 是每个任务执行的函数，应当响应 ctx 的取消。
+func New[In, Out any](fn func(context.Context, In) (Out, error), opts Options) *Pool[In, Out] {
+	opts.Workers = max(opts.Workers, 1)
+	opts.QueueSize = max(opts.QueueSize, 1)
+	if opts.LatencyBuckets == nil {
+		opts.LatencyBuckets = DefaultBuckets
+	}
+	p := &Pool[In, Out]{
+		fn:       fn,
+		opts:     opts,
+		slots:    make(chan struct{}, opts.QueueSize),
+		closed:   make(chan struct{}),
+		done:     make(chan Result[In, Out]),
+		results:  make(chan Result[In, Out], opts.QueueSize),
+		finished: make(chan struct{}),
+		stats:    newCounters(opts.LatencyBuckets),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.abortCtx, p.abort = context.WithCancelCause(context.Background())
+	p.mu.Lock()
+	p.resize(opts.Workers)
+	p.mu.Unlock()
+	go p.emit()
+	return p
+}
+
+// Results 返回结果通道，Shutdown 完成后关闭。必须一直读到关闭：
+// 没有人读取时 worker 在发送结果时阻塞，新任务随之排队，Shutdown 也无法完成。
+func (p *Pool[In, Out]) Results() <-chan Result[In, Out] { return p.results }
+
+// Submit 提交一个任务，返回它的编号。队列已满时等待空位（背压），直到 ctx 被取消（返回 ctx.Err()）
+// 或工作池被关闭（返回 ErrClosed）。ctx 同时是任务的 context：提交后取消它，任务还没运行时不再运行，
+// 正在运行时 fn 收到取消。
+func (p *Pool[In, Out]) Submit(ctx context.Context, in In) (uint64, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-p.closed:
+		return 0, ErrClosed
+	}
+	return p.enqueue(ctx, in)
+}
+
+// TrySubmit 与 Submit 相同，但队列已满时立即返回 ErrQueueFull。
+func (p *Pool[In, Out]) TrySubmit(ctx context.Context, in In) (uint64, error) {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		select {
+		case <-p.closed:
+			return 0, ErrClosed
+		default:
+			return 0, ErrQueueFull
+		}
+	}
+	return p.enqueue(ctx, in)
+}
+
+// enqueue 在取得空位之后把任务放进队列。
+func (p *Pool[In, Out]) enqueue(ctx context.Context, in In) (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing {
+		<-p.slots
+		return 0, ErrClosed
+	}
+	p.nextID++
+	p.queue = append(p.queue, job[In]{id: p.nextID, ctx: ctx, in: in, submitted: time.Now()})
+	p.cond.Signal()
+	return p.nextID, nil
+}
+
+// Resize 把 worker 数调整为 n（小于 1 时为 1）。增加时立即启动新的 worker；
+// 减少时多出的 worker 在完成手头的任务后退出，不会中断正在运行的任务。Shutdown 之后调用没有效果。
+func (p *Pool[In, Out]) Resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closing {
+		p.resize(max(n, 1))
+	}
+}
+
+// resize 在持有 mu 时调用。
+func (p *Pool[In, Out]) resize(n int) {
+	p.target = n
+	for p.live < p.target {
+		p.live++
+		p.workers.Add(1)
+		go p.worker()
+	}
+	p.cond.Broadcast() // 让多余的空闲 worker 醒来退出
+}
+
+// Size 返回 Resize 设置的 worker 数。
+func (p *Pool[In, Out]) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.target
+}
+
+func (p *Pool[In, Out]) worker() {
+	defer p.workers.Done()
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closing && p.live <= p.target {
+			p.cond.Wait()
+		}
+		if p.live > p.target || len(p.queue) == 0 {
+			// 缩容，或者已经关闭且队列为空
+			p.live--
+			p.mu.Unlock()
+			return
+		}
+		j := p.queue[0]
+		var zero job[In]
+		p.queue[0] = zero // 不再引用已取出的任务
+		p.queue = p.queue[1:]
+		p.running++
+		p.mu.Unlock()
+		<-p.slots
+
+		r, ran := p.run(j)
+
+		p.mu.Lock()
+		p.running--
+		p.stats.record(r.Err, r.Run, ran)
+		p.mu.Unlock()
+		p.done <- r
+	}
+}
+
+// run 为任务创建 context 并调用 fn；任务开始前已被取消、没有调用 fn 时 ran 为 false。
+func (p *Pool[In, Out]) run(j job[In]) (r Result[In, Out], ran bool) {
+	r = Result[In, Out]{ID: j.id, In: j.in, Wait: time.Since(j.submitted)}
+	ctx, cancel := context.WithCancelCause(j.ctx)
+	defer cancel(nil)
+	stop := context.AfterFunc(p.abortCtx, func() { cancel(ErrAborted) })
+	defer stop()
+	if p.opts.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, p.opts.Timeout)
+		defer cancelTimeout()
+	}
+	// AfterFunc 在另一个 goroutine 中取消 ctx，中止之后取出的任务要直接检查 abortCtx
+	if p.abortCtx.Err() != nil {
+		r.Err = ErrAborted
+		return r, false
+	}
+	if ctx.Err() != nil {
+		r.Err = context.Cause(ctx) // 开始前已被取消，不再运行
+		return r, false
+	}
+
+	start := time.Now()
+	r.Out, r.Err = safe.Try(func() (Out, error) { return p.fn(ctx, j.in) })
+	r.Run = time.Since(start)
+	if r.Err != nil && ctx.Err() != nil && errors.Is(r.Err, ctx.Err()) {
+		r.Err = context.Cause(ctx) // fn 返回 ctx.Err() 时换成更具体的原因，例如 ErrAborted
+	}
+	return r, true
+}
+
+// emit 把结果转发到 Results，需要时按编号排序；所有 worker 退出后关闭 Results。
+func (p *Pool[In, Out]) emit() {
+	defer close(p.finished)
+	defer close(p.results)
+	pending := make(map[uint64]Result[In, Out])
+	next := uint64(1)
+	for r := range p.done {
+		if !p.opts.Ordered {
+			p.results <- r
+			continue
+		}
+		pending[r.ID] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			p.results <- r
+			next++
+		}
+	}
+}
+
+// Shutdown 停止接受新任务（Submit 返回 ErrClosed），等待已提交的任务全部完成、Results 关闭后返回 nil。
+// ctx 先到期时中止：正在运行的任务的 context 以 ErrAborted 取消，还在排队的任务不再运行、结果的错误为 ErrAborted；
+// 然后等 worker 全部退出，返回 ctx.Err()。可以多次调用。
+func (p *Pool[In, Out]) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closing {
+		p.closing = true
+		close(p.closed)
+		go func() {
+			p.workers.Wait()
+			close(p.done)
+		}()
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	select {
+	case <-p.finished:
+		return nil
+	case <-ctx.Done():
+		p.abort(ErrAborted)
+		<-p.finished
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,353 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"books/chap28/safe"
+	"books/chap30/leaktest"
+)
+
+// collect 在后台读完 Results，关闭后把全部结果交给返回的通道。
+func collect[In, Out any](p *Pool[In, Out]) <-chan []Result[In, Out] {
+	out := make(chan []Result[In, Out], 1)
+	go func() {
+		var all []Result[In, Out]
+		for r := range p.Results() {
+			all = append(all, r)
+		}
+		out <- all
+	}()
+	return out
+}
+
+// square 是 chap30 worker 的处理逻辑：模拟处理时间后返回平方，处理期间响应取消。
+func square(ctx context.Context, n int) (int, error) {
+	select {
+	case <-time.After(time.Duration(10-n%10) * time.Millisecond):
+		return n * n, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// waitFor 轮询直到 cond 成立，超时则让测试失败。
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func squares(t *testing.T, ordered bool) []Result[int, int] {
+	p := New(square, Options{Workers: 3, QueueSize: 10, Ordered: ordered})
+	res := collect(p)
+	for j := 1; j <= 9; j++ {
+		if _, err := p.Submit(context.Background(), j); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return <-res
+}
+
+func TestOrdering(t *testing.T) {
+	defer leaktest.Check(t)()
+	var ids, outs []int
+	for _, r := range squares(t, true) {
+		ids = append(ids, int(r.ID))
+		outs = append(outs, r.Out)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5 6 7 8 9]" || fmt.Sprint(outs) != "[1 4 9 16 25 36 49 64 81]" {
+		t.Errorf("Ordered: ids %v, outs %v", ids, outs)
+	}
+
+	outs = outs[:0]
+	for _, r := range squares(t, false) {
+		outs = append(outs, r.Out)
+	}
+	sort.Ints(outs)
+	if fmt.Sprint(outs) != "[1 4 9 16 25 36 49 64 81]" {
+		t.Errorf("unordered results = %v", outs)
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	defer leaktest.Check(t)()
+	gate := make(chan struct{})
+	p := New(func(ctx context.Context, n int) (int, error) {
+		<-gate
+		return n, nil
+	}, Options{Workers: 1, QueueSize: 2})
+	res := collect(p)
+	ctx := context.Background()
+
+	p.Submit(ctx, 1)
+	waitFor(t, "job 1 to run", func() bool { return p.Metrics().Running == 1 })
+	p.Submit(ctx, 2)
+	p.Submit(ctx, 3)
+	if m := p.Metrics(); m.Queued != 2 || m.Running != 1 {
+		t.Errorf("queued %d, running %d", m.Queued, m.Running)
+	}
+
+	if _, err := p.TrySubmit(ctx, 4); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("TrySubmit on a full queue = %v", err)
+	}
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	_, err := p.Submit(short, 4)
+	cancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit on a full queue = %v, want DeadlineExceeded", err)
+	}
+
+	accepted := make(chan uint64)
+	go func() {
+		id, _ := p.Submit(ctx, 5)
+		accepted <- id
+	}()
+	gate <- struct{}{} // 1 号完成，2 号开始运行，队列空出一个位置
+	if id := <-accepted; id != 4 {
+		t.Errorf("waiting Submit got id %d, want 4 (failed submissions take no id)", id)
+	}
+	close(gate)
+	p.Shutdown(ctx)
+	if n := len(<-res); n != 4 {
+		t.Errorf("%d results, want 4", n)
+	}
+}
+
+func TestPerJob(t *testing.T) {
+	defer leaktest.Check(t)()
+	p := New(func(ctx context.Context, n int) (int, error) {
+		switch n {
+		case 0:
+			panic("除以零")
+		case 1:
+			<-ctx.Done() // 不会自己结束，只能等超时
+			return 0, ctx.Err()
+		case 2:
+			return 0, fmt.Errorf("任务 %d 失败", n)
+		}
+		return 100 / n, nil
+	}, Options{Workers: 1, QueueSize: 10, Timeout: 20 * time.Millisecond, Ordered: true})
+	res := collect(p)
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+
+	p.Submit(ctx, 0)
+	p.Submit(ctx, 1)
+	p.Submit(canceled, 5)
+	cancel() // 1 号任务还要运行 20ms，这时 5 号在排队
+	p.Submit(ctx, 2)
+	p.Submit(ctx, 4)
+	p.Shutdown(ctx)
+	all := <-res
+	if len(all) != 5 {
+		t.Fatalf("%d results, want 5", len(all))
+	}
+
+	var pe *safe.PanicError
+	if !errors.As(all[0].Err, &pe) || pe.Value != "除以零" {
+		t.Errorf("panic: %v", all[0].Err)
+	}
+	if !errors.Is(all[1].Err, context.DeadlineExceeded) || all[1].Run < 20*time.Millisecond {
+		t.Errorf("timeout: %v after %v", all[1].Err, all[1].Run)
+	}
+	if !errors.Is(all[2].Err, context.Canceled) || all[2].Run != 0 {
+		t.Errorf("canceled while queued: %v, ran %v", all[2].Err, all[2].Run)
+	}
+	if all[3].Err == nil || all[3].Err.Error() != "任务 2 失败" {
+		t.Errorf("plain error: %v", all[3].Err)
+	}
+	if all[4].Err != nil || all[4].Out != 25 {
+		t.Errorf("worker did not recover: %d, %v", all[4].Out, all[4].Err)
+	}
+	// 被取消的 5 号没有运行，不计入直方图
+	if m := p.Metrics(); m.Completed != 1 || m.Failed != 4 || m.Latency.Count != 4 {
+		t.Errorf("completed %d, failed %d, histogram %d", m.Completed, m.Failed, m.Latency.Count)
+	}
+}
+
+func TestResize(t *testing.T) {
+	defer leaktest.Check(t)()
+	var cur atomic.Int32
+	gate := make(chan struct{})
+	p := New(func(ctx context.Context, n int) (int, error) {
+		cur.Add(1)
+		<-gate
+		cur.Add(-1)
+		return n, nil
+	}, Options{Workers: 2, QueueSize: 20})
+	res := collect(p)
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		p.Submit(ctx, i)
+	}
+	running := func(n int32) func() bool {
+		return func() bool { return cur.Load() == n && p.Metrics().Running == int(n) }
+	}
+	waitFor(t, "2 running jobs", running(2))
+	p.Resize(6)
+	waitFor(t, "6 running jobs after Resize(6)", running(6))
+	if p.Size() != 6 {
+		t.Errorf("Size = %d", p.Size())
+	}
+
+	p.Resize(1)
+	if p.Size() != 1 || cur.Load() != 6 {
+		t.Errorf("Resize(1) interrupted running jobs: Size %d, running %d", p.Size(), cur.Load())
+	}
+	for i := 0; i < 6; i++ {
+		gate <- struct{}{} // 逐个放行：多余的 worker 完成手头的任务后退出
+	}
+	waitFor(t, "1 running job after shrinking", running(1))
+	close(gate)
+	p.Shutdown(ctx)
+	if n := len(<-res); n != 20 {
+		t.Errorf("%d results, want 20", n)
+	}
+	p.Resize(4)
+	if p.Size() != 1 {
+		t.Error("Resize after Shutdown took effect")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	defer leaktest.Check(t)()
+	ctx := context.Background()
+	p := New(square, Options{Workers: 2, QueueSize: 10})
+	res := collect(p)
+	for j := 1; j <= 8; j++ {
+		p.Submit(ctx, j)
+	}
+	if err := p.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	for _, r := range <-res {
+		if r.Err != nil || r.Out != r.In*r.In {
+			t.Errorf("job %d: %d, %v", r.In, r.Out, r.Err)
+		}
+	}
+	if _, err := p.Submit(ctx, 9); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Shutdown = %v", err)
+	}
+	if _, err := p.TrySubmit(ctx, 9); !errors.Is(err, ErrClosed) {
+		t.Errorf("TrySubmit after Shutdown = %v", err)
+	}
+	if err := p.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown = %v", err)
+	}
+}
+
+func TestShutdownAbort(t *testing.T) {
+	defer leaktest.Check(t)()
+	ctx := context.Background()
+	p := New(func(ctx context.Context, n int) (int, error) {
+		<-ctx.Done() // 只有中止才会结束
+		return 0, ctx.Err()
+	}, Options{Workers: 2, QueueSize: 10})
+	res := collect(p)
+	for j := 1; j <= 6; j++ {
+		p.Submit(ctx, j)
+	}
+	waitFor(t, "2 running jobs", func() bool { return p.Metrics().Running == 2 })
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v", err)
+	}
+	ran := 0
+	for _, r := range <-res {
+		if !errors.Is(r.Err, ErrAborted) {
+			t.Errorf("job %d: %v, want ErrAborted", r.In, r.Err)
+		}
+		if r.Run > 0 {
+			ran++
+		}
+	}
+	if m := p.Metrics(); ran != 2 || m.Failed != 6 || m.Latency.Count != 2 {
+		t.Errorf("%d jobs ran, failed %d, histogram %d; want 2, 6, 2", ran, m.Failed, m.Latency.Count)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	defer leaktest.Check(t)()
+	ctx := context.Background()
+	p := New(func(ctx context.Context, n int) (int, error) {
+		d := time.Millisecond
+		if n%10 == 0 {
+			d = 30 * time.Millisecond // 每 10 个任务有一个慢的
+		}
+		time.Sleep(d)
+		if n%4 == 0 {
+			return 0, errors.New("失败")
+		}
+		return n, nil
+	}, Options{Workers: 8, QueueSize: 40})
+	res := collect(p)
+	for j := 1; j <= 40; j++ {
+		p.Submit(ctx, j)
+	}
+	p.Shutdown(ctx)
+	<-res
+	m := p.Metrics()
+	if m.Completed != 30 || m.Failed != 10 || m.Queued != 0 || m.Running != 0 || m.Workers != 8 {
+		t.Errorf("Metrics = %+v", m)
+	}
+	h := m.Latency
+	var sum uint64
+	for _, c := range h.Counts {
+		sum += c
+	}
+	if h.Count != 40 || sum != 40 {
+		t.Errorf("histogram count %d, buckets sum %d", h.Count, sum)
+	}
+	if h.Quantile(0.5) > 20*time.Millisecond || h.Quantile(0.99) < 30*time.Millisecond || h.Max < 30*time.Millisecond {
+		t.Errorf("p50 %v, p99 %v, max %v", h.Quantile(0.5), h.Quantile(0.99), h.Max)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	c := newCounters([]time.Duration{10 * time.Millisecond, time.Millisecond})
+	if fmt.Sprint(c.hist.Bounds) != "[1ms 10ms]" {
+		t.Errorf("bounds not sorted: %v", c.hist.Bounds)
+	}
+	c.record(nil, 0, true) // 计时器精度太粗时运行过的任务也可能是 0
+	c.record(errors.New("x"), 5*time.Millisecond, true)
+	c.record(context.Canceled, 0, false)
+	c.record(nil, time.Second, true)
+	h := c.hist
+	if c.completed != 2 || c.failed != 2 {
+		t.Errorf("completed %d, failed %d", c.completed, c.failed)
+	}
+	if fmt.Sprint(h.Counts) != "[1 1 1]" || h.Count != 3 || h.Max != time.Second {
+		t.Errorf("histogram = %+v", h)
+	}
+	if h.Mean() != (5*time.Millisecond+time.Second)/3 {
+		t.Errorf("Mean = %v", h.Mean())
+	}
+	for q, want := range map[float64]time.Duration{0: time.Millisecond, 0.5: 10 * time.Millisecond, 1: time.Second} {
+		if got := h.Quantile(q); got != want {
+			t.Errorf("Quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if s := h.String(); strings.Count(s, "\n") != 3 || !strings.Contains(s, "+Inf") {
+		t.Errorf("String:\n%s", s)
+	}
+	var empty Histogram
+	if empty.Mean() != 0 || empty.Quantile(0.5) != 0 || empty.String() != "" {
+		t.Error("empty histogram")
+	}
+}
@@ -0,0 +1,279 @@
+// 独立运行：go run ./chap30/workerpool_demo
+// 演示：用 workerpool 重写 chap30 worker 的平方示例，有序/无序结果、背压与 ErrQueueFull、
+// 每个任务的超时和取消、panic 隔离、扩缩容、两种关闭方式和统计，最后看 goroutine 数回到开始时的值。断言见 go test ./chap30/workerpool。
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"books/chap28/safe"
+	"books/chap30/workerpool"
+)
+
+// collect 在后台读完 Results，关闭后把全部结果交给返回的通道。
+func collect[In, Out any](p *workerpool.Pool[In, Out]) <-chan []workerpool.Result[In, Out] {
+	out := make(chan []workerpool.Result[In, Out], 1)
+	go func() {
+		var all []workerpool.Result[In, Out]
+		for r := range p.Results() {
+			all = append(all, r)
+		}
+		out <- all
+	}()
+	return out
+}
+
+// square 是 chap30 worker 的处理逻辑：模拟处理时间后返回平方，处理期间响应取消。
+func square(ctx context.Context, n int) (int, error) {
+	select {
+	case <-time.After(time.Duration(10-n%10) * time.Millisecond):
+		return n * n, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// waitFor 轮询直到 cond 成立或超过一秒。
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+func main() {
+	before := runtime.NumGoroutine()
+
+	ordering()
+	backpressure()
+	perJob()
+	resize()
+	shutdown()
+	metrics()
+
+	fmt.Println("\n=== 7. 没有泄漏 ===")
+	waitFor(func() bool { return runtime.NumGoroutine() <= before })
+	fmt.Printf("  所有工作池关闭后 goroutine 数为 %d（开始时 %d）\n", runtime.NumGoroutine(), before)
+}
+
+func run(ordered bool) []workerpool.Result[int, int] {
+	p := workerpool.New(square, workerpool.Options{Workers: 3, QueueSize: 10, Ordered: ordered})
+	res := collect(p)
+	for j := 1; j <= 9; j++ {
+		p.Submit(context.Background(), j)
+	}
+	p.Shutdown(context.Background())
+	return <-res
+}
+
+func ordering() {
+	fmt.Println("=== 1. 平方示例：有序和无序结果 ===")
+	var ids, outs []int
+	for _, r := range run(true) {
+		ids = append(ids, int(r.ID))
+		outs = append(outs, r.Out)
+	}
+	fmt.Printf("  Ordered：编号 %v，结果 %v\n", ids, outs)
+
+	outs = outs[:0]
+	for _, r := range run(false) {
+		outs = append(outs, r.Out)
+	}
+	fmt.Println("  无序：小的数处理得慢，结果按完成顺序到达:", outs)
+	fmt.Println()
+}
+
+func backpressure() {
+	fmt.Println("=== 2. 有界队列：背压和 ErrQueueFull ===")
+	gate := make(chan struct{})
+	p := workerpool.New(func(ctx context.Context, n int) (int, error) {
+		<-gate
+		return n, nil
+	}, workerpool.Options{Workers: 1, QueueSize: 2})
+	res := collect(p)
+	ctx := context.Background()
+
+	p.Submit(ctx, 1)
+	waitFor(func() bool { return p.Metrics().Running == 1 })
+	p.Submit(ctx, 2)
+	p.Submit(ctx, 3)
+	m := p.Metrics()
+	fmt.Printf("  1 号任务在运行，队列已满：排队 %d、运行 %d\n", m.Queued, m.Running)
+
+	_, err := p.TrySubmit(ctx, 4)
+	fmt.Println("  TrySubmit 立即返回:", err)
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	start := time.Now()
+	_, err = p.Submit(short, 4)
+	cancel()
+	fmt.Printf("  Submit 等待空位 %v 直到 ctx 到期：%v\n", time.Since(start).Round(time.Millisecond), err)
+
+	accepted := make(chan uint64)
+	go func() {
+		id, _ := p.Submit(ctx, 5)
+		accepted <- id
+	}()
+	gate <- struct{}{} // 1 号完成，2 号开始运行，队列空出一个位置
+	id := <-accepted
+	fmt.Printf("  空出位置后等待中的 Submit 被接受，编号 %d（失败的提交不占编号）\n", id)
+	close(gate)
+	p.Shutdown(ctx)
+	fmt.Printf("  %d 个被接受的任务都有结果\n", len(<-res))
+	fmt.Println()
+}
+
+func perJob() {
+	fmt.Println("=== 3. 每个任务的超时、取消和 panic 隔离 ===")
+	p := workerpool.New(func(ctx context.Context, n int) (int, error) {
+		switch n {
+		case 0:
+			panic("除以零")
+		case 1:
+			<-ctx.Done() // 不会自己结束，只能等超时
+			return 0, ctx.Err()
+		case 2:
+			return 0, fmt.Errorf("任务 %d 失败", n)
+		}
+		return 100 / n, nil
+	}, workerpool.Options{Workers: 1, QueueSize: 10, Timeout: 20 * time.Millisecond, Ordered: true})
+	res := collect(p)
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+
+	p.Submit(ctx, 0)
+	p.Submit(ctx, 1)
+	p.Submit(canceled, 5)
+	cancel() // 1 号任务还要运行 20ms，这时 5 号在排队
+	p.Submit(ctx, 2)
+	p.Submit(ctx, 4)
+	p.Shutdown(ctx)
+	all := <-res
+
+	var pe *safe.PanicError
+	if errors.As(all[0].Err, &pe) {
+		fmt.Println("  panic 成为这个任务的错误:", pe.Value)
+	}
+	fmt.Printf("  超时：%v，运行了 %v\n", all[1].Err, all[1].Run.Round(time.Millisecond))
+	fmt.Printf("  排队时提交的 ctx 被取消：%v，运行时间 %v\n", all[2].Err, all[2].Run)
+	fmt.Println("  普通错误原样返回:", all[3].Err)
+	fmt.Printf("  唯一的 worker 在 panic 和超时之后继续工作：100/4 = %d\n", all[4].Out)
+	m := p.Metrics()
+	fmt.Printf("  统计：成功 %d、失败 %d，直方图只记录运行过的 %d 个\n", m.Completed, m.Failed, m.Latency.Count)
+	fmt.Println()
+}
+
+func resize() {
+	fmt.Println("=== 4. Resize：扩容和缩容 ===")
+	var cur atomic.Int32
+	gate := make(chan struct{})
+	p := workerpool.New(func(ctx context.Context, n int) (int, error) {
+		cur.Add(1)
+		<-gate
+		cur.Add(-1)
+		return n, nil
+	}, workerpool.Options{Workers: 2, QueueSize: 20})
+	res := collect(p)
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		p.Submit(ctx, i)
+	}
+	running := func(n int32) func() bool {
+		return func() bool { return cur.Load() == n && p.Metrics().Running == int(n) }
+	}
+	waitFor(running(2))
+	fmt.Printf("  2 个 worker：同时运行 %d 个任务\n", cur.Load())
+	p.Resize(6)
+	waitFor(running(6))
+	fmt.Printf("  Resize(6) 后新的 worker 立即开始工作：同时运行 %d 个\n", cur.Load())
+
+	p.Resize(1)
+	fmt.Printf("  Resize(1) 不打断正在运行的任务：Size %d，仍在运行 %d 个\n", p.Size(), cur.Load())
+	for i := 0; i < 6; i++ {
+		gate <- struct{}{} // 逐个放行：多余的 worker 完成手头的任务后退出
+	}
+	waitFor(running(1))
+	fmt.Printf("  缩容后只剩 %d 个任务在运行\n", cur.Load())
+	close(gate)
+	p.Shutdown(ctx)
+	fmt.Printf("  %d 个任务全部完成\n", len(<-res))
+	fmt.Println()
+}
+
+func shutdown() {
+	fmt.Println("=== 5. Shutdown：处理完再关闭，或者到期中止 ===")
+	ctx := context.Background()
+	p := workerpool.New(square, workerpool.Options{Workers: 2, QueueSize: 10})
+	res := collect(p)
+	for j := 1; j <= 8; j++ {
+		p.Submit(ctx, j)
+	}
+	err := p.Shutdown(ctx)
+	all := <-res
+	fmt.Printf("  Shutdown 等到已提交的 %d 个任务全部完成：%v\n", len(all), err)
+	_, err = p.Submit(ctx, 9)
+	fmt.Println("  之后的提交:", err)
+	fmt.Println("  再次调用 Shutdown 立即返回:", p.Shutdown(ctx))
+
+	p = workerpool.New(func(ctx context.Context, n int) (int, error) {
+		<-ctx.Done() // 只有中止才会结束
+		return 0, ctx.Err()
+	}, workerpool.Options{Workers: 2, QueueSize: 10})
+	res = collect(p)
+	for j := 1; j <= 6; j++ {
+		p.Submit(ctx, j)
+	}
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = p.Shutdown(short)
+	all = <-res
+	ran, aborted := 0, 0
+	for _, r := range all {
+		if errors.Is(r.Err, workerpool.ErrAborted) {
+			aborted++
+		}
+		if r.Run > 0 {
+			ran++
+		}
+	}
+	fmt.Printf("  ctx 到期后 %v 中止：%v\n", time.Since(start).Round(time.Millisecond), err)
+	fmt.Printf("  %d 个任务的错误都是 ErrAborted：%d 个运行中被取消，%d 个排队的不再运行\n", aborted, ran, len(all)-ran)
+	fmt.Println()
+}
+
+func metrics() {
+	fmt.Println("=== 6. 统计和运行时间直方图 ===")
+	ctx := context.Background()
+	p := workerpool.New(func(ctx context.Context, n int) (int, error) {
+		d := time.Millisecond
+		if n%10 == 0 {
+			d = 30 * time.Millisecond // 每 10 个任务有一个慢的
+		}
+		time.Sleep(d)
+		if n%4 == 0 {
+			return 0, errors.New("失败")
+		}
+		return n, nil
+	}, workerpool.Options{Workers: 8, QueueSize: 40})
+	res := collect(p)
+	for j := 1; j <= 40; j++ {
+		p.Submit(ctx, j)
+	}
+	p.Shutdown(ctx)
+	<-res
+	m := p.Metrics()
+	h := m.Latency
+	fmt.Print(h)
+	fmt.Printf("  成功 %d、失败 %d、排队 %d、运行 %d，直方图记录了 %d 次运行\n", m.Completed, m.Failed, m.Queued, m.Running, h.Count)
+	fmt.Printf("  p50 ≤ %v，p99 ≤ %v，最大 %v，平均 %v\n",
+		h.Quantile(0.5), h.Quantile(0.99), h.Max.Round(time.Millisecond), h.Mean().Round(time.Millisecond))
+}